@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // User time zones must resolve even without system zoneinfo
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -16,8 +18,16 @@ import (
 	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
 	"github.com/sugiiianaa/remember-my-story/internal/database"
 	"github.com/sugiiianaa/remember-my-story/internal/handlers"
+	"github.com/sugiiianaa/remember-my-story/internal/idempotency"
+	"github.com/sugiiianaa/remember-my-story/internal/jobs"
 	"github.com/sugiiianaa/remember-my-story/internal/middleware"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
 	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/internal/storage"
+	"github.com/sugiiianaa/remember-my-story/internal/transcription"
+	"github.com/sugiiianaa/remember-my-story/internal/webpush"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
 	"gorm.io/gorm"
 )
 
@@ -32,8 +42,12 @@ func main() {
 	}).Info("Starting application with the following settings")
 
 	db := initDatabase(logger)
-	router := setupRouter(logger, env, db)
+	scheduler := jobs.NewScheduler(logger, initLeaderElector(logger, db))
+	router := setupRouter(logger, env, db, scheduler)
+
+	scheduler.Start()
 	startServer(router, logger)
+	scheduler.Stop()
 }
 
 // --------------------------
@@ -95,55 +109,624 @@ func initDatabase(logger *logrus.Logger) *gorm.DB {
 	return db
 }
 
+func initBlobStore(logger *logrus.Logger) storage.BlobStore {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+
+	if driver == "s3" {
+		store, err := storage.NewS3BlobStore(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+		}, nil)
+		if err != nil {
+			logger.Fatal("Failed to configure S3 blob store: ", err)
+		}
+		return store
+	}
+
+	dir := os.Getenv("STORAGE_LOCAL_DIR")
+	if dir == "" {
+		dir = "./data/blobs"
+	}
+
+	store, err := storage.NewLocalBlobStore(dir)
+	if err != nil {
+		logger.Fatal("Failed to configure local blob store: ", err)
+	}
+	return store
+}
+
+func initTranscriber() transcription.Provider {
+	switch strings.ToLower(os.Getenv("TRANSCRIPTION_PROVIDER")) {
+	case "http":
+		return transcription.NewHTTPProvider(os.Getenv("TRANSCRIPTION_URL"), os.Getenv("TRANSCRIPTION_API_KEY"), nil)
+	default:
+		return transcription.NoopProvider{}
+	}
+}
+
+// initURLSigningSecret returns URL_SIGNING_SECRET, or without it a key
+// derived from the JWT secret, so download URLs and tokens never share a
+// key.
+func initURLSigningSecret(jwtSecret string) string {
+	if secret := os.Getenv("URL_SIGNING_SECRET"); secret != "" {
+		return secret
+	}
+	return helpers.DeriveKey(jwtSecret, "remember-my-story/attachment-url-signing")
+}
+
+func initIdempotencyStore(db *gorm.DB) idempotency.Store {
+	if strings.ToLower(os.Getenv("IDEMPOTENCY_STORE")) == "memory" {
+		return idempotency.NewMemoryStore()
+	}
+	return idempotency.NewPostgresStore(db)
+}
+
+// schedulerLockKey is the advisory lock key held by the replica that runs
+// leader-only jobs.
+const schedulerLockKey = 7_301_001
+
+func initLeaderElector(logger *logrus.Logger, db *gorm.DB) jobs.LeaderElector {
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("Failed to access database connection pool: ", err)
+	}
+	return jobs.NewPostgresLeaderElector(sqlDB, schedulerLockKey)
+}
+
+// initPushClient configures Web Push from VAPID_PRIVATE_KEY (and the
+// matching VAPID_PUBLIC_KEY). Push stays disabled when no key is set.
+func initPushClient(logger *logrus.Logger) *webpush.Client {
+	privateKey := os.Getenv("VAPID_PRIVATE_KEY")
+	if privateKey == "" {
+		logger.Warn("VAPID_PRIVATE_KEY not set, web push is disabled")
+		return nil
+	}
+
+	keys, err := webpush.ParseVAPIDKeys(os.Getenv("VAPID_PUBLIC_KEY"), privateKey)
+	if err != nil {
+		logger.Fatal("Failed to parse VAPID keys: ", err)
+	}
+
+	subject := os.Getenv("VAPID_SUBJECT")
+	if subject == "" {
+		logger.Fatal("VAPID_SUBJECT environment variable not set")
+	}
+
+	return webpush.NewClient(webpush.Config{
+		Keys:        keys,
+		Subject:     subject,
+		TTL:         time.Duration(getEnvInt("WEB_PUSH_TTL_HOURS", 24)) * time.Hour,
+		MaxAttempts: getEnvInt("WEB_PUSH_MAX_ATTEMPTS", 3),
+		Backoff:     time.Duration(getEnvInt("WEB_PUSH_BACKOFF_SECONDS", 1)) * time.Second,
+	}, nil)
+}
+
+func initDispatcher(logger *logrus.Logger, pushSender notify.PushSender) *notify.Dispatcher {
+	dispatcher := notify.NewDispatcher()
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		dispatcher.Register(notify.ChannelEmail, notify.NewEmailNotifier(notify.SMTPConfig{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}))
+	} else {
+		logger.Warn("SMTP_HOST not set, email notifications are disabled")
+	}
+	dispatcher.Register(notify.ChannelWebhook, notify.NewWebhookNotifier(nil))
+	dispatcher.Register(notify.ChannelWebPush, notify.NewWebPushNotifier(pushSender))
+
+	return dispatcher
+}
+
+// getEnvInt reads an integer environment variable, falling back to
+// defaultValue when it is unset or malformed.
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
 // --------------------------
 // Router/Server functions
 // --------------------------
-func setupRouter(logger *logrus.Logger, env string, db *gorm.DB) *gin.Engine {
+func setupRouter(logger *logrus.Logger, env string, db *gorm.DB, scheduler *jobs.Scheduler) *gin.Engine {
 	// jwt setup
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
 		logger.Fatal("JWT_SECRET environment variable not set")
 	}
-	authMiddleware := middleware.AuthMiddleware(jwtSecret)
 
-	// journal setup
+	// idempotency setup
+	idempotencyStore := initIdempotencyStore(db)
+	idempotencyMiddleware := middleware.IdempotencyMiddleware(idempotencyStore, middleware.IdempotencyConfig{
+		TTL:         time.Duration(getEnvInt("IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour,
+		LockTTL:     time.Duration(getEnvInt("IDEMPOTENCY_LOCK_SECONDS", 60)) * time.Second,
+		WaitTimeout: time.Duration(getEnvInt("IDEMPOTENCY_WAIT_SECONDS", 5)) * time.Second,
+		MaxBodySize: int64(getEnvInt("IDEMPOTENCY_MAX_BODY_KB", 1024)) << 10,
+	})
+	scheduler.Every(time.Hour, jobs.NewIdempotencyPurgeJob(idempotencyStore, logger))
+
+	// user setup
+	userRepo := repositories.NewUserRepository(db)
+	settingsHandler := handlers.NewSettingsHandler(services.NewUserService(userRepo))
+
+	// access setup
 	journalRepo := repositories.NewJournalRepository(db)
-	journalService := services.NewJournalService(journalRepo)
-	journalHandler := handlers.NewJournalHandler(journalService)
+	notebookRepo := repositories.NewNotebookRepository(db)
+	grantRepo := repositories.NewReaderGrantRepository(db)
+	accessPolicy := services.NewAccessPolicy(journalRepo, grantRepo, notebookRepo)
+
+	// security event setup
+	securityEventService := services.NewSecurityEventService(repositories.NewSecurityEventRepository(db))
+	securityEventHandler := handlers.NewSecurityEventHandler(securityEventService)
 
 	// auth setup
-	userRepo := repositories.NewUserRepository(db)
-	authService := services.NewAuthService(*userRepo, jwtSecret)
+	authService := services.NewAuthService(*userRepo, notebookRepo, securityEventService, jwtSecret, services.LoginConfig{
+		MaxFailedAttempts: getEnvInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		LockoutDuration:   time.Duration(getEnvInt("LOGIN_LOCKOUT_MINUTES", 15)) * time.Minute,
+	})
 	authHandler := handlers.NewAuthHandler(*authService)
+	authMiddleware := middleware.AuthMiddleware(jwtSecret, authService)
+
+	// admin setup
+	adminService := services.NewAdminService(repositories.NewAdminRepository(db), userRepo, securityEventService)
+	if emails := os.Getenv("ADMIN_EMAILS"); emails != "" {
+		if _, err := adminService.PromoteAdmins(strings.Split(emails, ",")); err != nil {
+			logger.Fatal("Failed to promote the configured admins: ", err)
+		}
+	}
+	adminHandler := handlers.NewAdminHandler(adminService)
+
+	// attachment setup
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	attachmentService := services.NewAttachmentService(attachmentRepo, journalRepo, initBlobStore(logger), initTranscriber(), services.AttachmentConfig{
+		MaxFileSize:      int64(getEnvInt("ATTACHMENT_MAX_SIZE_MB", 10)) << 20,
+		MaxAudioFileSize: int64(getEnvInt("ATTACHMENT_MAX_AUDIO_SIZE_MB", 50)) << 20,
+		URLSecret:        initURLSigningSecret(jwtSecret),
+		URLTTL:           time.Duration(getEnvInt("ATTACHMENT_URL_TTL_MINUTES", 15)) * time.Minute,
+		BaseURL:          os.Getenv("APP_BASE_URL"),
+	})
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, accessPolicy)
+	scheduler.EveryOnLeader(10*time.Second, jobs.NewTranscriptionJob(attachmentService, logger))
+
+	// webhook setup
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), services.WebhookConfig{
+		MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		Backoff:      time.Duration(getEnvInt("WEBHOOK_BACKOFF_SECONDS", 30)) * time.Second,
+		MaxBackoff:   time.Duration(getEnvInt("WEBHOOK_MAX_BACKOFF_MINUTES", 360)) * time.Minute,
+		DisableAfter: getEnvInt("WEBHOOK_DISABLE_AFTER_FAILURES", 20),
+		Timeout:      time.Duration(getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10)) * time.Second,
+	})
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	scheduler.EveryOnLeader(15*time.Second, jobs.NewWebhookDeliveryJob(webhookService, logger))
+
+	// revision setup
+	revisionRepo := repositories.NewRevisionRepository(db)
+	revisionService := services.NewRevisionService(revisionRepo, journalRepo, services.RevisionRetention{
+		MaxPerEntry: getEnvInt("REVISION_MAX_PER_ENTRY", 0),
+		MaxAge:      time.Duration(getEnvInt("REVISION_MAX_AGE_DAYS", 0)) * 24 * time.Hour,
+	}, webhookService)
+	revisionHandler := handlers.NewRevisionHandler(revisionService, accessPolicy)
+
+	// routine setup
+	routineService := services.NewRoutineService(repositories.NewRoutineRepository(db), journalRepo)
+	routineHandler := handlers.NewRoutineHandler(routineService)
+
+	// prompt setup
+	promptService := services.NewPromptService(repositories.NewPromptRepository(db), userRepo)
+	if err := promptService.SeedLibrary(); err != nil {
+		logger.Fatal("Failed to seed the prompts library: ", err)
+	}
+	promptHandler := handlers.NewPromptHandler(promptService)
+
+	// template setup
+	templateService := services.NewTemplateService(repositories.NewTemplateRepository(db))
+	templateHandler := handlers.NewTemplateHandler(templateService)
+
+	// notebook setup
+	notebookService := services.NewNotebookService(notebookRepo, journalRepo, securityEventService)
+	notebookHandler := handlers.NewNotebookHandler(notebookService)
+
+	// share setup
+	shareService := services.NewShareService(repositories.NewShareLinkRepository(db), journalRepo, notebookRepo, services.ShareConfig{
+		BaseURL:             os.Getenv("APP_BASE_URL"),
+		DefaultExpiry:       time.Duration(getEnvInt("SHARE_DEFAULT_EXPIRY_HOURS", 7*24)) * time.Hour,
+		MaxExpiry:           time.Duration(getEnvInt("SHARE_MAX_EXPIRY_DAYS", 90)) * 24 * time.Hour,
+		MaxPasswordAttempts: getEnvInt("SHARE_MAX_PASSWORD_ATTEMPTS", 10),
+		PasswordWindow:      time.Duration(getEnvInt("SHARE_PASSWORD_WINDOW_MINUTES", 15)) * time.Minute,
+	})
+	shareHandler := handlers.NewShareHandler(shareService)
+
+	// journal setup
+	journalService := services.NewJournalService(journalRepo, revisionService, routineService, webhookService, promptService, templateService, notebookService)
+	journalHandler := handlers.NewJournalHandler(journalService, accessPolicy)
+
+	// task setup
+	carryOverService := services.NewCarryOverService(journalRepo, userRepo, journalService)
+	taskHandler := handlers.NewTaskHandler(journalService, carryOverService, accessPolicy)
+	scheduler.EveryOnLeader(15*time.Minute, jobs.NewCarryOverJob(carryOverService, logger))
+
+	// push setup
+	pushService := services.NewPushService(repositories.NewPushSubscriptionRepository(db), initPushClient(logger))
+	pushHandler := handlers.NewPushHandler(pushService)
+
+	// reminder setup
+	dispatcher := initDispatcher(logger, pushService)
+	reminderService := services.NewReminderService(userRepo, journalRepo, dispatcher)
+	reminderHandler := handlers.NewReminderHandler(reminderService)
+	scheduler.EveryOnLeader(time.Minute, jobs.NewReminderJob(reminderService, logger))
+
+	// reader setup
+	readerHandler := handlers.NewReaderHandler(services.NewReaderService(grantRepo, userRepo, notebookRepo, journalRepo, dispatcher))
+
+	// comment setup
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(db), userRepo, dispatcher)
+	scheduler.EveryOnLeader(15*time.Second, jobs.NewNotificationJob(notificationService, logger))
+	commentService := services.NewCommentService(repositories.NewCommentRepository(db), journalRepo, userRepo, accessPolicy, webhookService, notificationService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+
+	// organization setup
+	orgRepo := repositories.NewOrganizationRepository(db)
+	organizationHandler := handlers.NewOrganizationHandler(services.NewOrganizationService(orgRepo, userRepo))
+	consentHandler := handlers.NewConsentHandler(services.NewConsentService(repositories.NewConsentRepository(db), orgRepo, userRepo, notebookRepo, dispatcher))
+
+	// memory setup
+	memoryService := services.NewMemoryService(journalRepo, userRepo, dispatcher)
+	memoryHandler := handlers.NewMemoryHandler(memoryService)
+	scheduler.EveryOnLeader(time.Minute, jobs.NewMemoryDigestJob(memoryService, logger))
+
+	// review setup
+	reviewService := services.NewReviewService(repositories.NewReviewRepository(db), journalRepo, userRepo)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	scheduler.EveryOnLeader(time.Hour, jobs.NewReviewJob(reviewService, logger))
+
+	// batch setup
+	batchHandler := handlers.NewBatchHandler(services.NewBatchService(journalService, accessPolicy))
+
+	// insights setup
+	insightsHandler := handlers.NewInsightsHandler(services.NewInsightsService(repositories.NewInsightsRepository(db), userRepo))
+
+	// trash setup
+	trashService := services.NewTrashService(journalRepo, attachmentService,
+		time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30))*24*time.Hour)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	scheduler.EveryOnLeader(time.Hour, jobs.NewTrashPurgeJob(trashService, logger))
+
+	// sync setup
+	syncService := services.NewSyncService(repositories.NewSyncRepository(db), journalRepo, webhookService)
+	syncHandler := handlers.NewSyncHandler(syncService)
 
 	router := gin.New()
 
+	// Client IPs end up in the audit log and rate limits, so
+	// X-Forwarded-For is only believed from the configured proxies
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		for _, proxy := range strings.Split(proxies, ",") {
+			trustedProxies = append(trustedProxies, strings.TrimSpace(proxy))
+		}
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
+
 	router.Use(
 		middleware.LoggingMiddleware(logger, env),
 	)
 
-	registerRoutes(router, journalHandler, authHandler, authMiddleware)
+	registerRoutes(router, routeHandlers{
+		journal:      journalHandler,
+		auth:         authHandler,
+		attachment:   attachmentHandler,
+		revision:     revisionHandler,
+		trash:        trashHandler,
+		sync:         syncHandler,
+		batch:        batchHandler,
+		routine:      routineHandler,
+		insights:     insightsHandler,
+		task:         taskHandler,
+		settings:     settingsHandler,
+		reminder:     reminderHandler,
+		push:         pushHandler,
+		webhook:      webhookHandler,
+		memory:       memoryHandler,
+		review:       reviewHandler,
+		prompt:       promptHandler,
+		template:     templateHandler,
+		notebook:     notebookHandler,
+		share:        shareHandler,
+		reader:       readerHandler,
+		comment:      commentHandler,
+		organization: organizationHandler,
+		consent:      consentHandler,
+		admin:        adminHandler,
+		security:     securityEventHandler,
+	}, authMiddleware, idempotencyMiddleware)
 	return router
 }
 
+type routeHandlers struct {
+	journal      *handlers.JournalHandler
+	auth         *handlers.AuthHandler
+	attachment   *handlers.AttachmentHandler
+	revision     *handlers.RevisionHandler
+	trash        *handlers.TrashHandler
+	sync         *handlers.SyncHandler
+	batch        *handlers.BatchHandler
+	routine      *handlers.RoutineHandler
+	insights     *handlers.InsightsHandler
+	task         *handlers.TaskHandler
+	settings     *handlers.SettingsHandler
+	reminder     *handlers.ReminderHandler
+	push         *handlers.PushHandler
+	webhook      *handlers.WebhookHandler
+	memory       *handlers.MemoryHandler
+	review       *handlers.ReviewHandler
+	prompt       *handlers.PromptHandler
+	template     *handlers.TemplateHandler
+	notebook     *handlers.NotebookHandler
+	share        *handlers.ShareHandler
+	reader       *handlers.ReaderHandler
+	comment      *handlers.CommentHandler
+	organization *handlers.OrganizationHandler
+	consent      *handlers.ConsentHandler
+	admin        *handlers.AdminHandler
+	security     *handlers.SecurityEventHandler
+}
+
 func registerRoutes(
 	router *gin.Engine,
-	handler *handlers.JournalHandler,
-	authHandler *handlers.AuthHandler,
-	authMiddleware gin.HandlerFunc) {
+	h routeHandlers,
+	authMiddleware gin.HandlerFunc,
+	idempotencyMiddleware gin.HandlerFunc) {
 	api := router.Group("api/v1")
 	{
 		auth := api.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
+			auth.POST("/register", idempotencyMiddleware, h.auth.Register)
+			// Not idempotent: replaying a login would mean storing its token
+			auth.POST("/login", h.auth.Login)
 		}
 
 		journals := api.Group("/journals")
-		journals.Use(authMiddleware)
+		journals.Use(authMiddleware, idempotencyMiddleware)
+		{
+			journals.POST("", h.journal.CreateEntry)
+			journals.GET("/:id", h.journal.GetEntry)
+			journals.PUT("/:id", h.journal.UpdateEntry)
+			journals.PATCH("/:id/autosave", h.journal.Autosave)
+			journals.POST("/:id/publish", h.journal.Publish)
+			journals.DELETE("/:id", h.journal.DeleteEntry)
+
+			journals.PUT("/:id/tasks/order", h.task.Reorder)
+			journals.POST("/:id/carry-over", h.task.CarryOver)
+
+			journals.GET("/:id/revisions", h.revision.List)
+			journals.GET("/:id/revisions/diff", h.revision.Diff)
+			journals.GET("/:id/revisions/:rev", h.revision.Get)
+			journals.POST("/:id/revisions/:rev/restore", h.revision.Restore)
+
+			journals.POST("/:id/attachments", h.attachment.Upload)
+			journals.GET("/:id/attachments", h.attachment.List)
+			journals.GET("/:id/attachments/:attachmentId", h.attachment.Get)
+			journals.DELETE("/:id/attachments/:attachmentId", h.attachment.Delete)
+			journals.POST("/:id/attachments/:attachmentId/transcribe", h.attachment.Transcribe)
+			journals.PUT("/:id/attachments/:attachmentId/transcript", h.attachment.UpdateTranscript)
+
+			journals.GET("/:id/comments", h.comment.List)
+			journals.POST("/:id/comments", h.comment.Create)
+			journals.PUT("/:id/comments/:commentId", h.comment.Update)
+			journals.DELETE("/:id/comments/:commentId", h.comment.Delete)
+			journals.PUT("/:id/comment-settings", h.comment.UpdateSettings)
+
+			journals.GET("/:id/reactions", h.comment.Reactions)
+			journals.PUT("/:id/reactions/:kind", h.comment.React)
+			journals.DELETE("/:id/reactions/:kind", h.comment.Unreact)
+		}
+
+		me := api.Group("/me")
+		me.Use(authMiddleware, idempotencyMiddleware)
+		{
+			me.GET("/settings", h.settings.Get)
+			me.PUT("/settings", h.settings.Update)
+			me.GET("/reminders", h.reminder.Get)
+			me.PUT("/reminders", h.reminder.Update)
+			me.GET("/invitations", h.reader.Invitations)
+			me.POST("/invitations/:id/accept", h.reader.Accept)
+			me.POST("/invitations/:id/decline", h.reader.Decline)
+			me.GET("/consents", h.consent.Mine)
+			me.POST("/consents/:id/grant", h.consent.Grant)
+			me.POST("/consents/:id/decline", h.consent.Decline)
+			me.POST("/consents/:id/revoke", h.consent.Revoke)
+			me.GET("/security-events", h.security.Mine)
+		}
+
+		push := api.Group("/push")
+		push.Use(authMiddleware, idempotencyMiddleware)
+		{
+			push.GET("/config", h.push.Config)
+			push.POST("/subscriptions", h.push.Subscribe)
+			push.GET("/subscriptions", h.push.List)
+			push.DELETE("/subscriptions/:id", h.push.Unsubscribe)
+		}
+
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(authMiddleware, idempotencyMiddleware)
+		{
+			webhooks.POST("", h.webhook.Create)
+			webhooks.GET("", h.webhook.List)
+			webhooks.GET("/:id", h.webhook.Get)
+			webhooks.PUT("/:id", h.webhook.Update)
+			webhooks.DELETE("/:id", h.webhook.Delete)
+			webhooks.POST("/:id/rotate-secret", h.webhook.RotateSecret)
+			webhooks.GET("/:id/deliveries", h.webhook.Deliveries)
+			webhooks.POST("/:id/deliveries/:deliveryId/redeliver", h.webhook.Redeliver)
+		}
+
+		routines := api.Group("/routines")
+		routines.Use(authMiddleware, idempotencyMiddleware)
+		{
+			routines.POST("", h.routine.Create)
+			routines.GET("", h.routine.List)
+			routines.GET("/:id", h.routine.Get)
+			routines.PUT("/:id", h.routine.Update)
+			routines.DELETE("/:id", h.routine.Delete)
+			routines.GET("/:id/occurrences", h.routine.Occurrences)
+			routines.PUT("/:id/occurrences/:date", h.routine.SetOccurrence)
+			routines.DELETE("/:id/occurrences/:date", h.routine.ClearOccurrence)
+		}
+
+		insights := api.Group("/insights")
+		insights.Use(authMiddleware)
+		{
+			insights.GET("/habits", h.insights.Habits)
+		}
+
+		memories := api.Group("/memories")
+		memories.Use(authMiddleware)
+		{
+			memories.GET("/on-this-day", h.memory.OnThisDay)
+		}
+
+		reviews := api.Group("/reviews")
+		reviews.Use(authMiddleware)
+		{
+			reviews.GET("/:period", h.review.Get)
+			reviews.GET("/:period/history", h.review.History)
+		}
+
+		prompts := api.Group("/prompts")
+		prompts.Use(authMiddleware, idempotencyMiddleware)
+		{
+			prompts.GET("", h.prompt.List)
+			prompts.POST("", h.prompt.Create)
+			prompts.GET("/daily", h.prompt.Daily)
+			prompts.PUT("/:id", h.prompt.Update)
+			prompts.DELETE("/:id", h.prompt.Delete)
+			prompts.GET("/:id/entries", h.prompt.Entries)
+		}
+
+		templates := api.Group("/templates")
+		templates.Use(authMiddleware, idempotencyMiddleware)
+		{
+			templates.GET("", h.template.List)
+			templates.POST("", h.template.Create)
+			templates.GET("/:id", h.template.Get)
+			templates.PUT("/:id", h.template.Update)
+			templates.DELETE("/:id", h.template.Delete)
+			templates.GET("/:id/versions", h.template.Versions)
+			templates.GET("/:id/versions/:version", h.template.Version)
+		}
+
+		notebooks := api.Group("/notebooks")
+		notebooks.Use(authMiddleware, idempotencyMiddleware)
+		{
+			notebooks.GET("", h.notebook.List)
+			notebooks.POST("", h.notebook.Create)
+			notebooks.GET("/:id", h.notebook.Get)
+			notebooks.PUT("/:id", h.notebook.Update)
+			notebooks.DELETE("/:id", h.notebook.Delete)
+			notebooks.GET("/:id/entries", h.notebook.Entries)
+			notebooks.POST("/:id/entries/move", h.notebook.MoveEntries)
+			notebooks.GET("/:id/export", h.notebook.Export)
+		}
+
+		shares := api.Group("/shares")
+		shares.Use(authMiddleware, idempotencyMiddleware)
+		{
+			shares.POST("", h.share.Create)
+			shares.GET("", h.share.List)
+			shares.GET("/:id", h.share.Get)
+			shares.DELETE("/:id", h.share.Revoke)
+			shares.GET("/:id/accesses", h.share.Accesses)
+		}
+
+		readers := api.Group("/readers")
+		readers.Use(authMiddleware, idempotencyMiddleware)
+		{
+			readers.POST("", h.reader.Invite)
+			readers.GET("", h.reader.List)
+			readers.PUT("/:id", h.reader.UpdateRole)
+			readers.DELETE("/:id", h.reader.Revoke)
+			readers.GET("/:id/activity", h.reader.Activity)
+		}
+
+		sharedWithMe := api.Group("/shared-with-me")
+		sharedWithMe.Use(authMiddleware, idempotencyMiddleware)
+		{
+			sharedWithMe.GET("", h.reader.SharedWithMe)
+			sharedWithMe.DELETE("/:id", h.reader.Leave)
+			sharedWithMe.GET("/:id/entries", h.reader.Entries)
+		}
+
+		organizations := api.Group("/organizations")
+		organizations.Use(authMiddleware, idempotencyMiddleware)
+		{
+			organizations.POST("", h.organization.Create)
+			organizations.GET("", h.organization.List)
+			organizations.GET("/:id", h.organization.Get)
+			organizations.PUT("/:id", h.organization.Update)
+			organizations.GET("/:id/members", h.organization.Members)
+			organizations.POST("/:id/members", h.organization.AddMember)
+			organizations.DELETE("/:id/members/:userId", h.organization.RemoveMember)
+
+			organizations.POST("/:id/consents", h.consent.Request)
+			organizations.GET("/:id/consents", h.consent.Requests)
+			organizations.GET("/:id/dashboard", h.consent.Dashboard)
+			organizations.GET("/:id/clients/:clientId/entries", h.consent.ClientEntries)
+		}
+
+		// Public, read-only view of share links
+		api.GET("/shared/:token", h.share.Open)
+
+		trash := api.Group("/trash")
+		trash.Use(authMiddleware, idempotencyMiddleware)
+		{
+			trash.GET("", h.trash.List)
+			trash.POST("/:id/restore", h.trash.Restore)
+			trash.DELETE("/:id", h.trash.Delete)
+		}
+
+		sync := api.Group("/sync")
+		sync.Use(authMiddleware, idempotencyMiddleware)
+		{
+			sync.GET("", h.sync.Pull)
+			sync.POST("", h.sync.Push)
+		}
+
+		api.POST("/batch", authMiddleware, idempotencyMiddleware, h.batch.Execute)
+
+		admin := api.Group("/admin")
+		admin.Use(authMiddleware, middleware.RequireRole(models.RoleAdmin), idempotencyMiddleware)
+		{
+			admin.GET("/users", h.admin.Users)
+			admin.GET("/users/:id", h.admin.User)
+			admin.POST("/users/:id/disable", h.admin.Disable)
+			admin.POST("/users/:id/enable", h.admin.Enable)
+			admin.POST("/users/:id/unlock", h.admin.Unlock)
+			admin.POST("/users/:id/logout", h.admin.Logout)
+			admin.PUT("/users/:id/role", h.admin.SetRole)
+			admin.GET("/security-events", h.admin.SecurityEvents)
+			admin.GET("/stats", h.admin.Stats)
+		}
+
+		// Signed download links carry their own authorization
+		attachments := api.Group("/attachments")
 		{
-			journals.POST("", handler.CreateEntry)
-			// journals.GET("/:id", handler.GetEntry)
+			attachments.GET("/:attachmentId/content", h.attachment.Download)
+			attachments.GET("/:attachmentId/thumbnail", h.attachment.Thumbnail)
 		}
 	}
 }
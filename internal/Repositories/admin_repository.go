@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// AdminRepository serves the admin API. Its queries read account columns
+// and counts only, never journal text.
+type AdminRepository struct {
+	db *gorm.DB
+}
+
+func NewAdminRepository(db *gorm.DB) *AdminRepository {
+	return &AdminRepository{db}
+}
+
+// accountColumns are the user columns an admin may see.
+var accountColumns = []string{
+	"id", "email", "full_name", "role", "disabled_at", "disabled_reason",
+	"failed_logins", "locked_until", "last_login_at", "created_at",
+}
+
+// FindUsers pages through the users matching the query, newest first,
+// and counts all matches.
+func (r *AdminRepository) FindUsers(query models.AdminUserQuery, now time.Time, limit, offset int) ([]models.User, int64, error) {
+	db := r.db.Model(&models.User{})
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + likeEscaper.Replace(search) + "%"
+		db = db.Where("(email ILIKE ? OR full_name ILIKE ?)", pattern, pattern)
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+	switch query.Status {
+	case models.AccountStatusDisabled:
+		db = db.Where("disabled_at IS NOT NULL")
+	case models.AccountStatusLocked:
+		db = db.Where("disabled_at IS NULL AND locked_until > ?", now)
+	case models.AccountStatusActive:
+		db = db.Where("disabled_at IS NULL AND (locked_until IS NULL OR locked_until <= ?)", now)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []models.User
+	err := db.
+		Select(accountColumns).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users).Error
+
+	return users, total, err
+}
+
+func (r *AdminRepository) FindUser(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.Select(accountColumns).First(&user, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	return &user, err
+}
+
+// CountEntries counts a user's entries, trashed ones excluded.
+func (r *AdminRepository) CountEntries(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.JournalEntry{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// UserStats counts accounts by state and recent activity.
+func (r *AdminRepository) UserStats(now time.Time) (*models.AdminUserStats, error) {
+	week, month := now.AddDate(0, 0, -7), now.AddDate(0, 0, -30)
+
+	var stats models.AdminUserStats
+	err := r.db.Model(&models.User{}).
+		Select(`
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE disabled_at IS NULL AND (locked_until IS NULL OR locked_until <= @now)) AS active,
+			COUNT(*) FILTER (WHERE disabled_at IS NOT NULL) AS disabled,
+			COUNT(*) FILTER (WHERE disabled_at IS NULL AND locked_until > @now) AS locked,
+			COUNT(*) FILTER (WHERE role = @admin) AS admins,
+			COUNT(*) FILTER (WHERE created_at >= @week) AS new_last7_days,
+			COUNT(*) FILTER (WHERE created_at >= @month) AS new_last30_days,
+			COUNT(*) FILTER (WHERE last_login_at >= @week) AS logged_in_last7_days,
+			COUNT(*) FILTER (WHERE last_login_at >= @month) AS logged_in_last30_days`,
+			map[string]interface{}{"now": now, "admin": models.RoleAdmin, "week": week, "month": month}).
+		Scan(&stats).Error
+
+	return &stats, err
+}
+
+// ContentStats counts what users keep in the app without reading any of
+// it.
+func (r *AdminRepository) ContentStats(now time.Time) (*models.AdminContentStats, error) {
+	week, month := now.AddDate(0, 0, -7), now.AddDate(0, 0, -30)
+
+	var stats models.AdminContentStats
+	err := r.db.Model(&models.JournalEntry{}).
+		Select(`
+			COUNT(*) AS entries,
+			COUNT(*) FILTER (WHERE status = @published) AS published_entries,
+			COUNT(*) FILTER (WHERE created_at >= @week) AS entries_last7_days,
+			COUNT(*) FILTER (WHERE created_at >= @month) AS entries_last30_days,
+			COUNT(DISTINCT user_id) FILTER (WHERE created_at >= @month) AS writers_last30_days`,
+			map[string]interface{}{"published": enums.EntryStatus.Published, "week": week, "month": month}).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []struct {
+		model interface{}
+		where string
+		args  []interface{}
+		dest  *int64
+	}{
+		{&models.Notebook{}, "", nil, &stats.Notebooks},
+		{&models.Attachment{}, "", nil, &stats.Attachments},
+		{&models.ShareLink{}, "revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?) AND (max_views IS NULL OR view_count < max_views)",
+			[]interface{}{now}, &stats.ActiveShareLinks},
+		{&models.ReaderGrant{}, "status = ?", []interface{}{models.GrantStatusAccepted}, &stats.ReaderGrants},
+		{&models.Organization{}, "", nil, &stats.Organizations},
+	}
+	for _, count := range counts {
+		db := r.db.Model(count.model)
+		if count.where != "" {
+			db = db.Where(count.where, count.args...)
+		}
+		if err := db.Count(count.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	err = r.db.Model(&models.Attachment{}).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&stats.AttachmentBytes).Error
+
+	return &stats, err
+}
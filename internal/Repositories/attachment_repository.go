@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) *AttachmentRepository {
+	return &AttachmentRepository{db}
+}
+
+func (r *AttachmentRepository) Create(attachment *models.Attachment) (uint, error) {
+	if err := r.db.Create(attachment).Error; err != nil {
+		return 0, err
+	}
+	return attachment.ID, nil
+}
+
+func (r *AttachmentRepository) FindByID(id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := r.db.First(&attachment, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &attachment, err
+}
+
+func (r *AttachmentRepository) FindByJournalEntryID(journalEntryID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.
+		Where("journal_entry_id = ?", journalEntryID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+func (r *AttachmentRepository) Delete(attachment *models.Attachment) error {
+	return r.db.Delete(attachment).Error
+}
+
+func (r *AttachmentRepository) UpdateTranscript(id uint, status enums.TranscriptStatusType, transcript string) error {
+	return r.db.Model(&models.Attachment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"transcript":        transcript,
+			"transcript_status": status,
+		}).Error
+}
+
+// RequestTranscript drops the attachment's transcript and queues a new
+// transcription, requested at requestedAt.
+func (r *AttachmentRepository) RequestTranscript(id uint, requestedAt time.Time) error {
+	return r.db.Model(&models.Attachment{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"transcript":              "",
+			"transcript_status":       enums.TranscriptStatus.Pending,
+			"transcript_requested_at": requestedAt,
+		}).Error
+}
+
+// FindPendingTranscripts returns up to limit voice memos waiting for a
+// transcription with an ID above afterID, in ID order.
+func (r *AttachmentRepository) FindPendingTranscripts(afterID uint, limit int) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.
+		Where("transcript_status = ? AND id > ?", enums.TranscriptStatus.Pending, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&attachments).Error
+
+	return attachments, err
+}
+
+// CompleteTranscript stores the outcome of the transcription requested at
+// requestedAt. It reports false and changes nothing when the attachment
+// was queued again or its transcript written by hand in the meantime.
+func (r *AttachmentRepository) CompleteTranscript(id uint, requestedAt *time.Time, status enums.TranscriptStatusType, transcript string) (bool, error) {
+	db := r.db.Model(&models.Attachment{}).
+		Where("id = ? AND transcript_status = ?", id, enums.TranscriptStatus.Pending)
+	if requestedAt == nil {
+		// Queued before requests were timestamped
+		db = db.Where("transcript_requested_at IS NULL")
+	} else {
+		db = db.Where("transcript_requested_at = ?", *requestedAt)
+	}
+
+	result := db.Updates(map[string]interface{}{
+		"transcript":        transcript,
+		"transcript_status": status,
+	})
+	return result.RowsAffected > 0, result.Error
+}
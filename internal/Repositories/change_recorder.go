@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+// syncLockNamespace is the first key of the per-user advisory lock taken
+// by every transaction that writes to the sync change feed.
+const syncLockNamespace = 31
+
+// changeRecorder appends rows to the sync change feed from inside the
+// transaction that modified the records. The first write takes a per-user
+// transaction advisory lock so sequence numbers become visible to readers
+// in the same order they were assigned.
+type changeRecorder struct {
+	tx     *gorm.DB
+	userID uint
+	locked bool
+}
+
+func newChangeRecorder(tx *gorm.DB, userID uint) *changeRecorder {
+	return &changeRecorder{tx: tx, userID: userID}
+}
+
+func (c *changeRecorder) lock() error {
+	if c.locked {
+		return nil
+	}
+	if err := c.tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", syncLockNamespace, int32(c.userID)).Error; err != nil {
+		return err
+	}
+	c.locked = true
+	return nil
+}
+
+func (c *changeRecorder) record(entityType string, entityID uint, operation string) (uint64, error) {
+	if err := c.lock(); err != nil {
+		return 0, err
+	}
+
+	change := models.SyncChange{
+		UserID:     c.userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Operation:  operation,
+	}
+	if err := c.tx.Create(&change).Error; err != nil {
+		return 0, err
+	}
+	return change.Seq, nil
+}
+
+// entryTree records an upsert for an entry and all of its live tasks and
+// subtasks, e.g. after it was created or restored from the trash.
+func (c *changeRecorder) entryTree(entryID uint) error {
+	if _, err := c.record(models.SyncEntityJournalEntry, entryID, models.SyncOperationUpsert); err != nil {
+		return err
+	}
+
+	var tasks []models.DailyTask
+	if err := c.tx.Preload("SubTasks").Where("journal_entry_id = ?", entryID).Find(&tasks).Error; err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if _, err := c.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationUpsert); err != nil {
+			return err
+		}
+		for _, subTask := range task.SubTasks {
+			if _, err := c.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
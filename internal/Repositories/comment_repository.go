@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) *CommentRepository {
+	return &CommentRepository{db}
+}
+
+func (r *CommentRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *CommentRepository) WithTx(tx *gorm.DB) *CommentRepository {
+	return &CommentRepository{tx}
+}
+
+func (r *CommentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+func (r *CommentRepository) Save(comment *models.Comment) error {
+	return r.db.Save(comment).Error
+}
+
+// Delete removes a comment together with its replies.
+func (r *CommentRepository) Delete(comment *models.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("parent_id = ?", comment.ID).Delete(&models.Comment{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(comment).Error
+	})
+}
+
+// FindByEntryID lists the comments on an entry, oldest first.
+func (r *CommentRepository) FindByEntryID(entryID uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.
+		Where("journal_entry_id = ?", entryID).
+		Order("created_at ASC, id ASC").
+		Find(&comments).Error
+	return comments, err
+}
+
+func (r *CommentRepository) FindByIDAndEntryID(id, entryID uint) (*models.Comment, error) {
+	var comment models.Comment
+	err := r.db.Where("journal_entry_id = ?", entryID).First(&comment, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &comment, err
+}
+
+// AddReaction stores a reaction and reports whether it is new; reacting
+// twice with the same kind is a no-op.
+func (r *CommentRepository) AddReaction(reaction *models.Reaction) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "journal_entry_id"}, {Name: "user_id"}, {Name: "kind"},
+		},
+		DoNothing: true,
+	}).Create(reaction)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *CommentRepository) RemoveReaction(entryID, userID uint, kind string) error {
+	return r.db.
+		Where("journal_entry_id = ? AND user_id = ? AND kind = ?", entryID, userID, kind).
+		Delete(&models.Reaction{}).Error
+}
+
+// CountReactions counts an entry's reactions by kind, noting the ones
+// userID left.
+func (r *CommentRepository) CountReactions(entryID, userID uint) ([]models.ReactionCount, error) {
+	var counts []models.ReactionCount
+	err := r.db.Model(&models.Reaction{}).
+		Select("kind, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted", userID).
+		Where("journal_entry_id = ?", entryID).
+		Group("kind").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,216 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// consentedEntriesJoin narrows journal_entries to the entries shared
+// through an active consent: the entry must be in one of the consent's
+// notebooks, entries without a notebook counting as part of the client's
+// default notebook, and the practitioner must still belong to the
+// organization. Every practitioner query goes through it, so revoking a
+// consent or removing a member takes effect on the next query.
+const consentedEntriesJoin = `
+	JOIN client_consents cc ON cc.client_id = journal_entries.user_id
+		AND cc.status = @active AND cc.deleted_at IS NULL
+	JOIN organization_members om ON om.organization_id = cc.organization_id
+		AND om.user_id = cc.practitioner_id
+	LEFT JOIN notebooks dn ON dn.user_id = journal_entries.user_id
+		AND dn.is_default AND dn.deleted_at IS NULL
+	JOIN consent_notebooks cn ON cn.consent_id = cc.id
+		AND cn.notebook_id = COALESCE(journal_entries.notebook_id, dn.id)`
+
+// ConsentMoodWeek counts the moods of one client's shared entries in one
+// ISO week.
+type ConsentMoodWeek struct {
+	ConsentID uint
+	WeekStart time.Time
+	Mood      enums.MoodType
+	Count     int
+}
+
+type ConsentLastEntry struct {
+	ConsentID     uint
+	LastEntryDate time.Time
+}
+
+type ConsentRepository struct {
+	db *gorm.DB
+}
+
+func NewConsentRepository(db *gorm.DB) *ConsentRepository {
+	return &ConsentRepository{db}
+}
+
+func (r *ConsentRepository) Create(consent *models.ClientConsent) error {
+	return r.db.Omit("Notebooks").Create(consent).Error
+}
+
+func (r *ConsentRepository) Save(consent *models.ClientConsent) error {
+	return r.db.Omit("Notebooks").Save(consent).Error
+}
+
+// SaveWithNotebooks saves a consent and replaces its notebooks.
+func (r *ConsentRepository) SaveWithNotebooks(consent *models.ClientConsent, notebookIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Notebooks").Save(consent).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("consent_id = ?", consent.ID).Delete(&models.ConsentNotebook{}).Error; err != nil {
+			return err
+		}
+
+		notebooks := make([]models.ConsentNotebook, 0, len(notebookIDs))
+		for _, id := range notebookIDs {
+			notebooks = append(notebooks, models.ConsentNotebook{ConsentID: consent.ID, NotebookID: id})
+		}
+		if len(notebooks) > 0 {
+			if err := tx.Create(&notebooks).Error; err != nil {
+				return err
+			}
+		}
+		consent.Notebooks = notebooks
+		return nil
+	})
+}
+
+// FindByPractitioner lists the consents a practitioner asked for in an
+// organization, newest first.
+func (r *ConsentRepository) FindByPractitioner(organizationID, practitionerID uint) ([]models.ClientConsent, error) {
+	var consents []models.ClientConsent
+	err := r.db.
+		Preload("Notebooks").
+		Where("organization_id = ? AND practitioner_id = ?", organizationID, practitionerID).
+		Order("created_at DESC, id DESC").
+		Find(&consents).Error
+	return consents, err
+}
+
+// FindActiveByPractitioner lists the consents currently active for a
+// practitioner in an organization.
+func (r *ConsentRepository) FindActiveByPractitioner(organizationID, practitionerID uint) ([]models.ClientConsent, error) {
+	var consents []models.ClientConsent
+	err := r.db.
+		Preload("Notebooks").
+		Where("organization_id = ? AND practitioner_id = ? AND status = ?",
+			organizationID, practitionerID, models.ConsentStatusActive).
+		Order("granted_at ASC, id ASC").
+		Find(&consents).Error
+	return consents, err
+}
+
+func (r *ConsentRepository) FindActiveForClient(organizationID, practitionerID, clientID uint) (*models.ClientConsent, error) {
+	var consent models.ClientConsent
+	err := r.db.
+		Where("organization_id = ? AND practitioner_id = ? AND client_id = ? AND status = ?",
+			organizationID, practitionerID, clientID, models.ConsentStatusActive).
+		First(&consent).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &consent, err
+}
+
+// FindForClient lists the requests addressed to the client's email and
+// the consents the client answered, newest first.
+func (r *ConsentRepository) FindForClient(clientID uint, email string) ([]models.ClientConsent, error) {
+	var consents []models.ClientConsent
+	err := r.db.
+		Preload("Notebooks").
+		Where("(client_id = ? OR (client_id IS NULL AND status = ? AND LOWER(invite_email) = LOWER(?)))",
+			clientID, models.ConsentStatusPending, email).
+		Order("created_at DESC, id DESC").
+		Find(&consents).Error
+	return consents, err
+}
+
+func (r *ConsentRepository) FindByIDForClient(id, clientID uint, email string) (*models.ClientConsent, error) {
+	var consent models.ClientConsent
+	err := r.db.
+		Preload("Notebooks").
+		Where("(client_id = ? OR (client_id IS NULL AND status = ? AND LOWER(invite_email) = LOWER(?)))",
+			clientID, models.ConsentStatusPending, email).
+		First(&consent, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &consent, err
+}
+
+// ExistsOpen reports whether the practitioner already has a pending or
+// active consent with the email in the organization.
+func (r *ConsentRepository) ExistsOpen(organizationID, practitionerID uint, email string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ClientConsent{}).
+		Where("organization_id = ? AND practitioner_id = ? AND LOWER(invite_email) = LOWER(?) AND status IN ?",
+			organizationID, practitionerID, email,
+			[]string{models.ConsentStatusPending, models.ConsentStatusActive}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MoodWeeks counts the moods of the published entries each of the
+// practitioner's clients shares, by ISO week, since the given day.
+func (r *ConsentRepository) MoodWeeks(organizationID, practitionerID uint, since time.Time) ([]ConsentMoodWeek, error) {
+	var weeks []ConsentMoodWeek
+	err := r.db.Model(&models.JournalEntry{}).
+		Select(`cc.id AS consent_id,
+			date_trunc('week', journal_entries.date)::date AS week_start,
+			journal_entries.mood,
+			COUNT(*) AS count`).
+		Scopes(r.consented(organizationID, practitionerID)).
+		Where("journal_entries.date >= ?", since).
+		Group("cc.id, week_start, journal_entries.mood").
+		Order("cc.id, week_start").
+		Scan(&weeks).Error
+	return weeks, err
+}
+
+// LastEntries returns the date of the latest entry each of the
+// practitioner's clients shares.
+func (r *ConsentRepository) LastEntries(organizationID, practitionerID uint) ([]ConsentLastEntry, error) {
+	var entries []ConsentLastEntry
+	err := r.db.Model(&models.JournalEntry{}).
+		Select("cc.id AS consent_id, MAX(journal_entries.date) AS last_entry_date").
+		Scopes(r.consented(organizationID, practitionerID)).
+		Group("cc.id").
+		Scan(&entries).Error
+	return entries, err
+}
+
+// FindEntries returns a page of the entries a client shares with the
+// practitioner, newest first, with their tasks.
+func (r *ConsentRepository) FindEntries(organizationID, practitionerID, clientID uint, limit, offset int) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.
+		Select("journal_entries.*").
+		Preload("DailyTasks", orderTasks).
+		Preload("DailyTasks.SubTasks").
+		Scopes(r.consented(organizationID, practitionerID)).
+		Where("journal_entries.user_id = ?", clientID).
+		Order("journal_entries.date DESC, journal_entries.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+	return entries, err
+}
+
+// consented limits journal_entries to the published entries shared with
+// the practitioner through active consents.
+func (r *ConsentRepository) consented(organizationID, practitionerID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins(consentedEntriesJoin, map[string]interface{}{"active": models.ConsentStatusActive}).
+			Where("cc.organization_id = ? AND cc.practitioner_id = ?", organizationID, practitionerID).
+			Where("journal_entries.status = ?", enums.EntryStatus.Published)
+	}
+}
@@ -0,0 +1,9 @@
+package repositories
+
+import "errors"
+
+var (
+	ErrRecordNotFound  = errors.New("record not found")
+	ErrVersionConflict = errors.New("record was modified by another request")
+	ErrInvalidSyncData = errors.New("change is missing required fields")
+)
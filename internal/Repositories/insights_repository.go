@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HabitTotals is the completion count of one habit.
+type HabitTotals struct {
+	HabitKey    string
+	Label       string
+	RoutineID   *uint
+	Tracked     int
+	Completed   int
+	LastTracked time.Time
+}
+
+// HabitWeekdayTotals is the completion count of one habit on one ISO
+// weekday (1 = Monday).
+type HabitWeekdayTotals struct {
+	HabitKey  string
+	Weekday   int
+	Tracked   int
+	Completed int
+}
+
+type HabitStreak struct {
+	HabitKey      string
+	CurrentStreak int
+	LongestStreak int
+}
+
+type WeeklyTotals struct {
+	WeekStart time.Time
+	Habits    int
+	Tracked   int
+	Completed int
+}
+
+// HabitQuery selects the tasks that are aggregated.
+type HabitQuery struct {
+	UserID             uint
+	From               time.Time
+	To                 time.Time
+	TimeZone           string // IANA name of the zone whose calendar days are counted
+	DeriveFromSubTasks bool
+}
+
+// habitDaysCTE yields one row per habit and day. Tasks copied from a
+// routine are grouped by routine, other tasks by their normalized text.
+// A day counts as completed when every task of the habit that day is
+// done; with derive set, a task with subtasks is done when all of its
+// subtasks are.
+const habitDaysCTE = `
+WITH task_status AS (
+	SELECT
+		dt.id,
+		dt.routine_id,
+		COALESCE(r.task, dt.task) AS label,
+		COALESCE('routine:' || dt.routine_id::text, 'task:' || lower(btrim(dt.task))) AS habit_key,
+		(je.date AT TIME ZONE @tz)::date AS day,
+		CASE
+			WHEN @derive AND COUNT(st.id) > 0 THEN bool_and(st.status)
+			ELSE dt.status
+		END AS completed
+	FROM daily_tasks dt
+	JOIN journal_entries je ON je.id = dt.journal_entry_id AND je.deleted_at IS NULL
+	LEFT JOIN routines r ON r.id = dt.routine_id
+	LEFT JOIN daily_sub_tasks st ON st.daily_task_id = dt.id AND st.deleted_at IS NULL
+	WHERE je.user_id = @user
+		AND dt.deleted_at IS NULL
+		AND (je.date AT TIME ZONE @tz)::date BETWEEN @from AND @to
+	GROUP BY dt.id, dt.routine_id, r.task, dt.task, dt.status, je.date
+),
+habit_days AS (
+	SELECT
+		habit_key,
+		MAX(routine_id) AS routine_id,
+		MAX(label) AS label,
+		day,
+		bool_and(completed) AS completed
+	FROM task_status
+	GROUP BY habit_key, day
+)`
+
+type InsightsRepository struct {
+	db *gorm.DB
+}
+
+func NewInsightsRepository(db *gorm.DB) *InsightsRepository {
+	return &InsightsRepository{db}
+}
+
+func (r *InsightsRepository) HabitTotals(query HabitQuery) ([]HabitTotals, error) {
+	var totals []HabitTotals
+	err := r.db.Raw(habitDaysCTE+`
+		SELECT
+			habit_key,
+			MAX(label) AS label,
+			MAX(routine_id) AS routine_id,
+			COUNT(*) AS tracked,
+			COUNT(*) FILTER (WHERE completed) AS completed,
+			MAX(day) AS last_tracked
+		FROM habit_days
+		GROUP BY habit_key
+		ORDER BY tracked DESC, habit_key`, habitQueryArgs(query)).
+		Scan(&totals).Error
+
+	return totals, err
+}
+
+func (r *InsightsRepository) HabitWeekdayTotals(query HabitQuery) ([]HabitWeekdayTotals, error) {
+	var totals []HabitWeekdayTotals
+	err := r.db.Raw(habitDaysCTE+`
+		SELECT
+			habit_key,
+			EXTRACT(ISODOW FROM day)::int AS weekday,
+			COUNT(*) AS tracked,
+			COUNT(*) FILTER (WHERE completed) AS completed
+		FROM habit_days
+		GROUP BY habit_key, weekday
+		ORDER BY habit_key, weekday`, habitQueryArgs(query)).
+		Scan(&totals).Error
+
+	return totals, err
+}
+
+// HabitStreaks finds runs of completed days with the gaps-and-islands
+// technique: within a run, the position among all tracked days minus the
+// position among completed days is constant.
+func (r *InsightsRepository) HabitStreaks(query HabitQuery) ([]HabitStreak, error) {
+	var streaks []HabitStreak
+	err := r.db.Raw(habitDaysCTE+`,
+		numbered AS (
+			SELECT
+				habit_key,
+				completed,
+				ROW_NUMBER() OVER (PARTITION BY habit_key ORDER BY day) AS position,
+				COUNT(*) OVER (PARTITION BY habit_key) AS total
+			FROM habit_days
+		),
+		islands AS (
+			SELECT
+				habit_key,
+				position - ROW_NUMBER() OVER (PARTITION BY habit_key ORDER BY position) AS island,
+				position,
+				total
+			FROM numbered
+			WHERE completed
+		),
+		runs AS (
+			SELECT habit_key, COUNT(*) AS length, MAX(position) = MAX(total) AS is_current
+			FROM islands
+			GROUP BY habit_key, island
+		)
+		SELECT
+			habit_key,
+			COALESCE(MAX(length) FILTER (WHERE is_current), 0) AS current_streak,
+			MAX(length) AS longest_streak
+		FROM runs
+		GROUP BY habit_key`, habitQueryArgs(query)).
+		Scan(&streaks).Error
+
+	return streaks, err
+}
+
+// WeeklyTotals groups completion by ISO week (starting on Monday).
+func (r *InsightsRepository) WeeklyTotals(query HabitQuery) ([]WeeklyTotals, error) {
+	var totals []WeeklyTotals
+	err := r.db.Raw(habitDaysCTE+`
+		SELECT
+			date_trunc('week', day)::date AS week_start,
+			COUNT(DISTINCT habit_key) AS habits,
+			COUNT(*) AS tracked,
+			COUNT(*) FILTER (WHERE completed) AS completed
+		FROM habit_days
+		GROUP BY week_start
+		ORDER BY week_start`, habitQueryArgs(query)).
+		Scan(&totals).Error
+
+	return totals, err
+}
+
+func habitQueryArgs(query HabitQuery) map[string]interface{} {
+	return map[string]interface{}{
+		"user":   query.UserID,
+		"from":   query.From.Format("2006-01-02"),
+		"to":     query.To.Format("2006-01-02"),
+		"tz":     query.TimeZone,
+		"derive": query.DeriveFromSubTasks,
+	}
+}
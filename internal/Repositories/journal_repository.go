@@ -1,10 +1,16 @@
 package repositories
 
 import (
+	"database/sql"
 	"errors"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type JournalRepository struct {
@@ -15,22 +21,852 @@ func NewJournalRepository(db *gorm.DB) *JournalRepository {
 	return &JournalRepository{db}
 }
 
+// Transaction runs fn in a database transaction. Repositories bound to tx
+// with WithTx take part in it.
+func (r *JournalRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *JournalRepository) WithTx(tx *gorm.DB) *JournalRepository {
+	return &JournalRepository{tx}
+}
+
+// Create stores a new entry with its tasks and records it as revision 1.
 func (r *JournalRepository) Create(entry *models.JournalEntry) (uint, error) {
-	if err := r.db.Create(entry).Error; err != nil {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Attachments are uploaded to an existing entry, never created with it
+		if err := tx.Omit("Attachments").Create(entry).Error; err != nil {
+			return err
+		}
+		if err := newChangeRecorder(tx, entry.UserID).entryTree(entry.ID); err != nil {
+			return err
+		}
+		return appendRevision(tx, entry.ID, nil)
+	})
+	if err != nil {
 		return 0, err
 	}
 	return entry.ID, nil
 }
 
+// Update overwrites the content of an existing entry and brings its tasks
+// in line with entry.DailyTasks: tasks and subtasks with a known ID are
+// updated, those without an ID are created and the rest are deleted. A
+// revision snapshot is written in the same transaction.
+//
+// When expectedVersion is non-zero the update only applies if the stored
+// version still matches, otherwise ErrVersionConflict is returned.
+func (r *JournalRepository) Update(entry *models.JournalEntry, expectedVersion int, restoredFrom *int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := bumpVersion(tx, entry.ID, expectedVersion, map[string]interface{}{
+			"mood":                 entry.Mood,
+			"this_day_description": entry.ThisDayDescription,
+			"daily_reflection":     entry.DailyReflection,
+			"status":               entry.Status,
+			"prompt_id":            entry.PromptID,
+			"template_id":          entry.TemplateID,
+			"template_version":     entry.TemplateVersion,
+			"template_values":      entry.TemplateValues,
+		})
+		if err != nil {
+			return err
+		}
+
+		changes := newChangeRecorder(tx, entry.UserID)
+		if _, err := changes.record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationUpsert); err != nil {
+			return err
+		}
+
+		if err := syncDailyTasks(tx, changes, entry.ID, entry.DailyTasks); err != nil {
+			return err
+		}
+
+		return appendRevision(tx, entry.ID, restoredFrom)
+	})
+}
+
+// Autosave writes a partial update coming from the editor. To avoid one
+// revision every few seconds, a revision is only recorded when the latest
+// one is older than revisionInterval.
+func (r *JournalRepository) Autosave(id, userID uint, expectedVersion int, fields map[string]interface{}, revisionInterval time.Duration) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := bumpVersion(tx, id, expectedVersion, fields); err != nil {
+			return err
+		}
+
+		if _, err := newChangeRecorder(tx, userID).record(models.SyncEntityJournalEntry, id, models.SyncOperationUpsert); err != nil {
+			return err
+		}
+
+		var latest models.JournalRevision
+		err := tx.Select("created_at").
+			Where("journal_entry_id = ?", id).
+			Order("revision DESC").
+			Take(&latest).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err == nil && time.Since(latest.CreatedAt) < revisionInterval {
+			return nil
+		}
+
+		return appendRevision(tx, id, nil)
+	})
+}
+
 func (r *JournalRepository) FindByID(id uint) (*models.JournalEntry, error) {
 	var entry models.JournalEntry
 	err := r.db.
-		Preload("DailyTasks.SubRTasks").
+		Preload("DailyTasks", orderTasks).
+		Preload("DailyTasks.SubTasks").
+		Preload("Attachments").
 		First(&entry, id).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("record not found")
+		return nil, ErrRecordNotFound
 	}
 
 	return &entry, err
 }
+
+func (r *JournalRepository) FindByIDAndUserID(id, userID uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := r.db.
+		Preload("DailyTasks", orderTasks).
+		Preload("DailyTasks.SubTasks").
+		Preload("Attachments").
+		Where("user_id = ?", userID).
+		First(&entry, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &entry, err
+}
+
+// AddTasks appends tasks copied from elsewhere (a routine or an earlier
+// day) to an entry. Tasks whose source was already copied, even if the
+// user has since deleted the copy, are skipped by the unique indexes on
+// the source columns. It returns how many tasks were added.
+func (r *JournalRepository) AddTasks(entry *models.JournalEntry, tasks []models.DailyTask) (int, error) {
+	added := 0
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		changes := newChangeRecorder(tx, entry.UserID)
+
+		for _, task := range tasks {
+			subTasks := task.SubTasks
+			task.ID = 0
+			task.JournalEntryID = entry.ID
+			task.SubTasks = nil
+
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&task)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			added++
+
+			if _, err := changes.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationUpsert); err != nil {
+				return err
+			}
+			for _, subTask := range subTasks {
+				subTask.ID = 0
+				subTask.DailyTaskID = task.ID
+				if err := tx.Create(&subTask).Error; err != nil {
+					return err
+				}
+				if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert); err != nil {
+					return err
+				}
+			}
+		}
+
+		if added == 0 {
+			return nil
+		}
+		if err := bumpVersion(tx, entry.ID, 0, map[string]interface{}{}); err != nil {
+			return err
+		}
+		_, err := changes.record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationUpsert)
+		return err
+	})
+
+	return added, err
+}
+
+// FindByUserIDAndDate returns the user's entry dated within [from, to).
+func (r *JournalRepository) FindByUserIDAndDate(userID uint, from, to time.Time) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := r.db.
+		Preload("DailyTasks", orderTasks).
+		Preload("DailyTasks.SubTasks").
+		Where("user_id = ? AND date >= ? AND date < ?", userID, from, to).
+		Order("date ASC, id ASC").
+		First(&entry).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &entry, err
+}
+
+// TimeRange is a half-open interval [From, To).
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// FindPublishedInRanges returns the user's published entries dated within
+// any of ranges, oldest first. Each range is a separate indexed range
+// condition on date.
+func (r *JournalRepository) FindPublishedInRanges(userID uint, ranges []TimeRange) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	if len(ranges) == 0 {
+		return entries, nil
+	}
+
+	dates := r.db.Where("date >= ? AND date < ?", ranges[0].From, ranges[0].To)
+	for _, dateRange := range ranges[1:] {
+		dates = dates.Or("date >= ? AND date < ?", dateRange.From, dateRange.To)
+	}
+
+	err := r.db.
+		Where("user_id = ? AND status = ?", userID, enums.EntryStatus.Published).
+		Where(dates).
+		Order("date ASC, id ASC").
+		Find(&entries).Error
+
+	return entries, err
+}
+
+// FindPublishedBetween returns the user's published entries dated within
+// [from, to) with their tasks, oldest first.
+func (r *JournalRepository) FindPublishedBetween(userID uint, from, to time.Time) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.
+		Preload("DailyTasks", orderTasks).
+		Where("user_id = ? AND status = ? AND date >= ? AND date < ?", userID, enums.EntryStatus.Published, from, to).
+		Order("date ASC, id ASC").
+		Find(&entries).Error
+
+	return entries, err
+}
+
+// FindFirstEntryDate returns the date of the user's oldest entry.
+func (r *JournalRepository) FindFirstEntryDate(userID uint) (*time.Time, error) {
+	var entry models.JournalEntry
+	err := r.db.
+		Select("date").
+		Where("user_id = ?", userID).
+		Order("date ASC").
+		First(&entry).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry.Date, nil
+}
+
+// FindCompletedTaskIDs returns the IDs of the entry's completed tasks.
+func (r *JournalRepository) FindCompletedTaskIDs(entryID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.DailyTask{}).
+		Where("journal_entry_id = ? AND status = ?", entryID, true).
+		Pluck("id", &ids).Error
+
+	return ids, err
+}
+
+// HasPublishedEntry reports whether the user published an entry dated
+// within [from, to).
+func (r *JournalRepository) HasPublishedEntry(userID uint, from, to time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.JournalEntry{}).
+		Where("user_id = ? AND date >= ? AND date < ? AND status = ?", userID, from, to, enums.EntryStatus.Published).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// FindCarriedOverSourceIDs returns which of taskIDs were already carried
+// over to another day.
+func (r *JournalRepository) FindCarriedOverSourceIDs(taskIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(taskIDs) == 0 {
+		return ids, nil
+	}
+
+	err := r.db.Unscoped().
+		Model(&models.DailyTask{}).
+		Where("carried_over_from_id IN ?", taskIDs).
+		Pluck("carried_over_from_id", &ids).Error
+
+	return ids, err
+}
+
+// FindTaskByIDAndUserID looks a task up through the entry it belongs to.
+func (r *JournalRepository) FindTaskByIDAndUserID(taskID, userID uint) (*models.DailyTask, error) {
+	var task models.DailyTask
+	err := r.db.
+		Joins("JOIN journal_entries ON journal_entries.id = daily_tasks.journal_entry_id AND journal_entries.deleted_at IS NULL").
+		Where("journal_entries.user_id = ?", userID).
+		First(&task, "daily_tasks.id = ?", taskID).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &task, err
+}
+
+// FindHeader loads an entry without its tasks and attachments, for
+// access checks.
+func (r *JournalRepository) FindHeader(id uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := r.db.First(&entry, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &entry, err
+}
+
+// FindTaskEntryID returns the entry a task belongs to.
+func (r *JournalRepository) FindTaskEntryID(taskID uint) (uint, error) {
+	var task models.DailyTask
+	err := r.db.Select("id", "journal_entry_id").First(&task, taskID).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, ErrRecordNotFound
+	}
+
+	return task.JournalEntryID, err
+}
+
+// Delete moves an entry to the trash. Its tasks, attachments and revisions
+// are left untouched so the entry can be restored as it was; sync clients
+// only receive a tombstone for the entry and drop its children locally.
+func (r *JournalRepository) Delete(entry *models.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(entry).Error; err != nil {
+			return err
+		}
+		_, err := newChangeRecorder(tx, entry.UserID).record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationDelete)
+		return err
+	})
+}
+
+func (r *JournalRepository) FindTrashedByUserID(userID uint) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Order("deleted_at DESC").
+		Find(&entries).Error
+
+	return entries, err
+}
+
+func (r *JournalRepository) FindTrashedByIDAndUserID(id, userID uint) (*models.JournalEntry, error) {
+	var entry models.JournalEntry
+	err := r.db.Unscoped().
+		Preload("Attachments").
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		First(&entry, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &entry, err
+}
+
+// FindTrashedBefore returns up to limit entries that were moved to the
+// trash before cutoff, oldest first.
+func (r *JournalRepository) FindTrashedBefore(cutoff time.Time, limit int) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.Unscoped().
+		Preload("Attachments").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("deleted_at ASC").
+		Limit(limit).
+		Find(&entries).Error
+
+	return entries, err
+}
+
+func (r *JournalRepository) Restore(entry *models.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().
+			Model(entry).
+			Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+		return newChangeRecorder(tx, entry.UserID).entryTree(entry.ID)
+	})
+}
+
+// AppendRevision records the current state of an entry as a new revision.
+func (r *JournalRepository) AppendRevision(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return appendRevision(tx, id, nil)
+	})
+}
+
+// HardDelete permanently removes an entry and everything hanging off it:
+// tasks, attachments, revisions, comments, reactions, share links and the
+// readers' access trail. Its sync history is replaced by delete tombstones
+// so offline clients still learn it is gone. Stored attachment files must
+// be cleaned up by the caller.
+func (r *JournalRepository) HardDelete(entry *models.JournalEntry) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Each statement starts from this session, so none of them picks up
+		// the conditions of another
+		db := tx.Unscoped().Session(&gorm.Session{})
+
+		var taskIDs, subTaskIDs []uint
+		err := db.Model(&models.DailyTask{}).
+			Where("journal_entry_id = ?", entry.ID).
+			Pluck("id", &taskIDs).Error
+		if err != nil {
+			return err
+		}
+		if len(taskIDs) > 0 {
+			err := db.Model(&models.DailySubTask{}).
+				Where("daily_task_id IN ?", taskIDs).
+				Pluck("id", &subTaskIDs).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(subTaskIDs) > 0 {
+			if err := db.Where("id IN ?", subTaskIDs).Delete(&models.DailySubTask{}).Error; err != nil {
+				return err
+			}
+		}
+
+		byEntry := []interface{}{
+			&models.DailyTask{},
+			&models.Attachment{},
+			&models.JournalRevision{},
+			&models.Comment{},
+			&models.Reaction{},
+			&models.ReaderAccess{},
+		}
+		for _, model := range byEntry {
+			if err := db.Where("journal_entry_id = ?", entry.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		linkIDs := db.Model(&models.ShareLink{}).
+			Select("id").
+			Where("journal_entry_id = ?", entry.ID)
+		if err := db.Where("share_link_id IN (?)", linkIDs).Delete(&models.ShareAccess{}).Error; err != nil {
+			return err
+		}
+		if err := db.Where("journal_entry_id = ?", entry.ID).Delete(&models.ShareLink{}).Error; err != nil {
+			return err
+		}
+
+		if err := db.Delete(&models.JournalEntry{}, entry.ID).Error; err != nil {
+			return err
+		}
+
+		return replaceSyncHistory(db, entry.UserID, map[string][]uint{
+			models.SyncEntityJournalEntry: {entry.ID},
+			models.SyncEntityDailyTask:    taskIDs,
+			models.SyncEntitySubTask:      subTaskIDs,
+		})
+	})
+}
+
+// replaceSyncHistory drops the change feed rows of purged records and
+// records a single delete for each of them instead.
+func replaceSyncHistory(db *gorm.DB, userID uint, ids map[string][]uint) error {
+	changes := newChangeRecorder(db, userID)
+	for _, entityType := range []string{models.SyncEntityJournalEntry, models.SyncEntityDailyTask, models.SyncEntitySubTask} {
+		if len(ids[entityType]) == 0 {
+			continue
+		}
+		err := db.
+			Where("user_id = ? AND entity_type = ? AND entity_id IN ?", userID, entityType, ids[entityType]).
+			Delete(&models.SyncChange{}).Error
+		if err != nil {
+			return err
+		}
+		for _, id := range ids[entityType] {
+			if _, err := changes.record(entityType, id, models.SyncOperationDelete); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func orderTasks(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+// bumpVersion applies fields to an entry and increments its version,
+// guarding against lost updates when expectedVersion is set.
+func bumpVersion(tx *gorm.DB, id uint, expectedVersion int, fields map[string]interface{}) error {
+	fields["version"] = gorm.Expr("version + 1")
+
+	query := tx.Model(&models.JournalEntry{}).Where("id = ?", id)
+	if expectedVersion > 0 {
+		query = query.Where("version = ?", expectedVersion)
+	}
+
+	result := query.Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	return nil
+}
+
+func syncDailyTasks(tx *gorm.DB, changes *changeRecorder, journalEntryID uint, tasks []models.DailyTask) error {
+	var existing []models.DailyTask
+	if err := tx.Preload("SubTasks").Where("journal_entry_id = ?", journalEntryID).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	existingByID := make(map[uint]models.DailyTask, len(existing))
+	for _, task := range existing {
+		existingByID[task.ID] = task
+	}
+
+	kept := make(map[uint]bool, len(tasks))
+	for position, task := range tasks {
+		task.Position = position
+		current, found := existingByID[task.ID]
+		if !found {
+			task.ID = 0
+			task.JournalEntryID = journalEntryID
+			for i := range task.SubTasks {
+				task.SubTasks[i].ID = 0
+			}
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+			if _, err := changes.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationUpsert); err != nil {
+				return err
+			}
+			for _, subTask := range task.SubTasks {
+				if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		kept[task.ID] = true
+		if !sameTaskFields(current, task) {
+			err := tx.Model(&current).Updates(map[string]interface{}{
+				"task":     task.Task,
+				"status":   task.Status,
+				"position": task.Position,
+				"priority": task.Priority,
+				"due_time": task.DueTime,
+				"notes":    task.Notes,
+			}).Error
+			if err != nil {
+				return err
+			}
+			if _, err := changes.record(models.SyncEntityDailyTask, current.ID, models.SyncOperationUpsert); err != nil {
+				return err
+			}
+		}
+
+		if err := syncDailySubTasks(tx, changes, current, task.SubTasks); err != nil {
+			return err
+		}
+	}
+
+	for _, task := range existing {
+		if kept[task.ID] {
+			continue
+		}
+		for _, subTask := range task.SubTasks {
+			if err := tx.Delete(&subTask).Error; err != nil {
+				return err
+			}
+			if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationDelete); err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&task).Error; err != nil {
+			return err
+		}
+		if _, err := changes.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationDelete); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sameTaskFields(a, b models.DailyTask) bool {
+	sameDueTime := (a.DueTime == nil && b.DueTime == nil) ||
+		(a.DueTime != nil && b.DueTime != nil && *a.DueTime == *b.DueTime)
+
+	return a.Task == b.Task &&
+		a.Status == b.Status &&
+		a.Position == b.Position &&
+		a.Priority == b.Priority &&
+		a.Notes == b.Notes &&
+		sameDueTime
+}
+
+func syncDailySubTasks(tx *gorm.DB, changes *changeRecorder, task models.DailyTask, subTasks []models.DailySubTask) error {
+	existingByID := make(map[uint]models.DailySubTask, len(task.SubTasks))
+	for _, subTask := range task.SubTasks {
+		existingByID[subTask.ID] = subTask
+	}
+
+	kept := make(map[uint]bool, len(subTasks))
+	for _, subTask := range subTasks {
+		current, found := existingByID[subTask.ID]
+		if !found {
+			subTask.ID = 0
+			subTask.DailyTaskID = task.ID
+			if err := tx.Create(&subTask).Error; err != nil {
+				return err
+			}
+			if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert); err != nil {
+				return err
+			}
+			continue
+		}
+
+		kept[subTask.ID] = true
+		if current.SubTask == subTask.SubTask && current.Status == subTask.Status {
+			continue
+		}
+
+		err := tx.Model(&models.DailySubTask{}).
+			Where("id = ?", subTask.ID).
+			Updates(map[string]interface{}{
+				"sub_task": subTask.SubTask,
+				"status":   subTask.Status,
+			}).Error
+		if err != nil {
+			return err
+		}
+		if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert); err != nil {
+			return err
+		}
+	}
+
+	for _, subTask := range task.SubTasks {
+		if kept[subTask.ID] {
+			continue
+		}
+		if err := tx.Delete(&models.DailySubTask{}, subTask.ID).Error; err != nil {
+			return err
+		}
+		if _, err := changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationDelete); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NotebookQuery selects the entries listed or exported for a notebook.
+// IncludeUnassigned adds the entries without a notebook, which belong to
+// the default notebook. A zero NotebookID matches every notebook, which
+// Hashtag can narrow to the entries mentioning #Hashtag.
+type NotebookQuery struct {
+	NotebookID        uint
+	IncludeUnassigned bool
+	Hashtag           string
+	PublishedOnly     bool
+	Search            string
+	From, To          *time.Time
+}
+
+func (q NotebookQuery) scope(userID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("journal_entries.user_id = ?", userID)
+		switch {
+		case q.NotebookID == 0:
+			// Every notebook
+		case q.IncludeUnassigned:
+			db = db.Where("(journal_entries.notebook_id = ? OR journal_entries.notebook_id IS NULL)", q.NotebookID)
+		default:
+			db = db.Where("journal_entries.notebook_id = ?", q.NotebookID)
+		}
+		if q.Hashtag != "" {
+			// Same word boundaries as the hashtags found in reviews
+			pattern := `(^|[^[:alnum:]#'])#` + regexp.QuoteMeta(q.Hashtag) + `([^[:alnum:]']|$)`
+			db = db.Where("(journal_entries.this_day_description ~* @p OR journal_entries.daily_reflection ~* @p)",
+				sql.Named("p", pattern))
+		}
+		if q.PublishedOnly {
+			db = db.Where("journal_entries.status = ?", enums.EntryStatus.Published)
+		}
+		if q.From != nil {
+			db = db.Where("journal_entries.date >= ?", *q.From)
+		}
+		if q.To != nil {
+			db = db.Where("journal_entries.date < ?", *q.To)
+		}
+		if q.Search != "" {
+			pattern := "%" + likeEscaper.Replace(q.Search) + "%"
+			db = db.Where(`(journal_entries.this_day_description ILIKE @p
+				OR journal_entries.daily_reflection ILIKE @p
+				OR EXISTS (SELECT 1 FROM daily_tasks
+					WHERE daily_tasks.journal_entry_id = journal_entries.id
+					AND daily_tasks.deleted_at IS NULL AND daily_tasks.task ILIKE @p))`,
+				sql.Named("p", pattern))
+		}
+		return db
+	}
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// FindInNotebook returns a page of a notebook's entries, newest first.
+func (r *JournalRepository) FindInNotebook(userID uint, query NotebookQuery, limit, offset int) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.
+		Scopes(query.scope(userID)).
+		Order("journal_entries.date DESC, journal_entries.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries).Error
+
+	return entries, err
+}
+
+// FindAllInNotebook returns every entry of a notebook with its tasks,
+// oldest first, for export.
+func (r *JournalRepository) FindAllInNotebook(userID uint, query NotebookQuery) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.
+		Preload("DailyTasks", orderTasks).
+		Preload("DailyTasks.SubTasks").
+		Scopes(query.scope(userID)).
+		Order("journal_entries.date ASC, journal_entries.id ASC").
+		Find(&entries).Error
+
+	return entries, err
+}
+
+// CountByNotebook counts the user's entries per notebook. Entries without
+// a notebook are counted under key 0.
+func (r *JournalRepository) CountByNotebook(userID uint) (map[uint]int64, error) {
+	var rows []struct {
+		NotebookID *uint
+		Count      int64
+	}
+	err := r.db.Model(&models.JournalEntry{}).
+		Select("notebook_id, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("notebook_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		var id uint
+		if row.NotebookID != nil {
+			id = *row.NotebookID
+		}
+		counts[id] += row.Count
+	}
+	return counts, nil
+}
+
+// MoveToNotebook assigns the user's entries with the given IDs to a
+// notebook and returns the IDs that were moved. Each moved entry gets a
+// new version but no revision, since its content is unchanged.
+func (r *JournalRepository) MoveToNotebook(userID uint, ids []uint, notebookID uint) ([]uint, error) {
+	var moved []uint
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.JournalEntry{}).
+			Where("user_id = ? AND id IN ? AND (notebook_id IS NULL OR notebook_id <> ?)", userID, ids, notebookID).
+			Order("id ASC").
+			Pluck("id", &moved).Error
+		if err != nil || len(moved) == 0 {
+			return err
+		}
+
+		return moveEntries(tx, userID, moved, notebookID)
+	})
+
+	return moved, err
+}
+
+// MoveNotebookEntries moves every entry of one notebook into another,
+// including trashed ones so they are restored into a notebook that still
+// exists.
+func (r *JournalRepository) MoveNotebookEntries(userID, fromID, toID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Unscoped().Model(&models.JournalEntry{}).
+			Where("user_id = ? AND notebook_id = ?", userID, fromID).
+			Order("id ASC").
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return moveEntries(tx, userID, ids, toID)
+	})
+}
+
+func moveEntries(tx *gorm.DB, userID uint, ids []uint, notebookID uint) error {
+	err := tx.Unscoped().Model(&models.JournalEntry{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"notebook_id": notebookID,
+			"version":     gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	changes := newChangeRecorder(tx, userID)
+	for _, id := range ids {
+		if _, err := changes.record(models.SyncEntityJournalEntry, id, models.SyncOperationUpsert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetCommentsDisabled opens or closes an entry to new comments.
+func (r *JournalRepository) SetCommentsDisabled(id, userID uint, disabled bool) error {
+	result := r.db.Model(&models.JournalEntry{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("comments_disabled", disabled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
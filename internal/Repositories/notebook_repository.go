@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotebookRepository struct {
+	db *gorm.DB
+}
+
+func NewNotebookRepository(db *gorm.DB) *NotebookRepository {
+	return &NotebookRepository{db}
+}
+
+// Transaction runs fn in a database transaction.
+func (r *NotebookRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *NotebookRepository) WithTx(tx *gorm.DB) *NotebookRepository {
+	return &NotebookRepository{tx}
+}
+
+func (r *NotebookRepository) Create(notebook *models.Notebook) error {
+	return r.db.Create(notebook).Error
+}
+
+func (r *NotebookRepository) Save(notebook *models.Notebook) error {
+	return r.db.Save(notebook).Error
+}
+
+func (r *NotebookRepository) Delete(notebook *models.Notebook) error {
+	return r.db.Delete(notebook).Error
+}
+
+// EnsureDefault returns the user's default notebook, creating it first if
+// the user has none yet.
+func (r *NotebookRepository) EnsureDefault(userID uint) (*models.Notebook, error) {
+	notebook := &models.Notebook{
+		UserID:    userID,
+		Name:      models.DefaultNotebookName,
+		IsDefault: true,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "is_default"}}},
+		DoNothing:   true,
+	}).Create(notebook).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindDefault(userID)
+}
+
+func (r *NotebookRepository) FindDefault(userID uint) (*models.Notebook, error) {
+	var notebook models.Notebook
+	err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&notebook).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &notebook, err
+}
+
+func (r *NotebookRepository) FindByUserID(userID uint, includeArchived bool) ([]models.Notebook, error) {
+	query := r.db.Where("user_id = ?", userID)
+	if !includeArchived {
+		query = query.Where("archived = ?", false)
+	}
+
+	var notebooks []models.Notebook
+	err := query.Order("is_default DESC, sort_order ASC, name ASC, id ASC").Find(&notebooks).Error
+	return notebooks, err
+}
+
+func (r *NotebookRepository) FindByIDAndUserID(id, userID uint) (*models.Notebook, error) {
+	var notebook models.Notebook
+	err := r.db.Where("user_id = ?", userID).First(&notebook, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &notebook, err
+}
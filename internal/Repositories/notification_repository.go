@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+// NotificationRepository stores the queue of notifications waiting to be
+// sent.
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *NotificationRepository) WithTx(tx *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{tx}
+}
+
+func (r *NotificationRepository) Create(notification *models.QueuedNotification) error {
+	return r.db.Create(notification).Error
+}
+
+// FindQueued returns up to limit queued notifications, oldest first.
+func (r *NotificationRepository) FindQueued(limit int) ([]models.QueuedNotification, error) {
+	var notifications []models.QueuedNotification
+	err := r.db.Order("id ASC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *NotificationRepository) Delete(id uint) error {
+	return r.db.Delete(&models.QueuedNotification{}, id).Error
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Membership is an organization with the role a user has in it.
+type Membership struct {
+	models.Organization
+	Role string
+}
+
+type OrganizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) *OrganizationRepository {
+	return &OrganizationRepository{db}
+}
+
+// Create stores an organization with its first owner.
+func (r *OrganizationRepository) Create(organization *models.Organization, ownerID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         ownerID,
+			Role:           models.OrgRoleOwner,
+		}).Error
+	})
+}
+
+func (r *OrganizationRepository) Save(organization *models.Organization) error {
+	return r.db.Save(organization).Error
+}
+
+func (r *OrganizationRepository) FindByIDs(ids []uint) ([]models.Organization, error) {
+	var organizations []models.Organization
+	if len(ids) == 0 {
+		return organizations, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&organizations).Error
+	return organizations, err
+}
+
+// FindByMemberID lists the organizations the user belongs to.
+func (r *OrganizationRepository) FindByMemberID(userID uint) ([]Membership, error) {
+	var memberships []Membership
+	err := r.db.Model(&models.Organization{}).
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name ASC, organizations.id ASC").
+		Scan(&memberships).Error
+	return memberships, err
+}
+
+// FindMembership returns an organization with the user's role in it.
+func (r *OrganizationRepository) FindMembership(id, userID uint) (*Membership, error) {
+	var memberships []Membership
+	err := r.db.Model(&models.Organization{}).
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organizations.id = ? AND organization_members.user_id = ?", id, userID).
+		Limit(1).
+		Scan(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return &memberships[0], nil
+}
+
+// AddMember adds a user to an organization and reports whether they were
+// not a member yet.
+func (r *OrganizationRepository) AddMember(member *models.OrganizationMember) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoNothing: true,
+	}).Create(member)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *OrganizationRepository) RemoveMember(organizationID, userID uint) error {
+	return r.db.
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Delete(&models.OrganizationMember{}).Error
+}
+
+func (r *OrganizationRepository) FindMember(organizationID, userID uint) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&member).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &member, err
+}
+
+func (r *OrganizationRepository) FindMembers(organizationID uint) ([]models.OrganizationMember, error) {
+	var members []models.OrganizationMember
+	err := r.db.Where("organization_id = ?", organizationID).Order("created_at ASC, id ASC").Find(&members).Error
+	return members, err
+}
+
+func (r *OrganizationRepository) CountOwners(organizationID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND role = ?", organizationID, models.OrgRoleOwner).
+		Count(&count).Error
+	return count, err
+}
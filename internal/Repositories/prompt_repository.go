@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PromptRepository struct {
+	db *gorm.DB
+}
+
+func NewPromptRepository(db *gorm.DB) *PromptRepository {
+	return &PromptRepository{db}
+}
+
+// Seed adds the library prompts that are not stored yet. Prompts already
+// seeded, including ones deactivated since, are left untouched.
+func (r *PromptRepository) Seed(prompts []models.Prompt) error {
+	if len(prompts) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "seed_key"}},
+		DoNothing: true,
+	}).Create(&prompts).Error
+}
+
+func (r *PromptRepository) Create(prompt *models.Prompt) error {
+	return r.db.Create(prompt).Error
+}
+
+func (r *PromptRepository) Save(prompt *models.Prompt) error {
+	return r.db.Save(prompt).Error
+}
+
+func (r *PromptRepository) Delete(prompt *models.Prompt) error {
+	return r.db.Delete(prompt).Error
+}
+
+// FindForUser lists the active library prompts and all of the user's own
+// prompts. theme and mood narrow the list when set; prompts suited to any
+// mood always match.
+func (r *PromptRepository) FindForUser(userID uint, theme string, mood enums.MoodType) ([]models.Prompt, error) {
+	query := r.db.Where("(user_id IS NULL AND active = ?) OR user_id = ?", true, userID)
+	if theme != "" {
+		query = query.Where("theme = ?", theme)
+	}
+	if mood != enums.Mood.Unknown {
+		query = query.Where("mood IN ?", []enums.MoodType{enums.Mood.Unknown, mood})
+	}
+
+	var prompts []models.Prompt
+	err := query.Order("id ASC").Find(&prompts).Error
+	return prompts, err
+}
+
+// FindActiveForUser lists the prompts the daily prompt is picked from.
+func (r *PromptRepository) FindActiveForUser(userID uint) ([]models.Prompt, error) {
+	var prompts []models.Prompt
+	err := r.db.
+		Where("active = ? AND (user_id IS NULL OR user_id = ?)", true, userID).
+		Order("id ASC").
+		Find(&prompts).Error
+
+	return prompts, err
+}
+
+// FindOwned returns a prompt the user wrote.
+func (r *PromptRepository) FindOwned(id, userID uint) (*models.Prompt, error) {
+	var prompt models.Prompt
+	err := r.db.Where("user_id = ?", userID).First(&prompt, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &prompt, err
+}
+
+// FindVisible returns a library prompt or one of the user's own.
+func (r *PromptRepository) FindVisible(id, userID uint) (*models.Prompt, error) {
+	var prompt models.Prompt
+	err := r.db.Where("user_id IS NULL OR user_id = ?", userID).First(&prompt, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &prompt, err
+}
+
+func (r *PromptRepository) FindPick(userID uint, date time.Time) (*models.PromptPick, error) {
+	var pick models.PromptPick
+	err := r.db.Where("user_id = ? AND date = ?", userID, date).First(&pick).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &pick, err
+}
+
+// CreatePick stores the pick for a day unless a concurrent request already
+// did, and returns the stored one.
+func (r *PromptRepository) CreatePick(pick *models.PromptPick) (*models.PromptPick, error) {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoNothing: true,
+	}).Create(pick).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.FindPick(pick.UserID, pick.Date)
+}
+
+// FindRecentPromptIDs returns the prompts picked for or answered by the
+// user since the given day.
+func (r *PromptRepository) FindRecentPromptIDs(userID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.Raw(`
+		SELECT prompt_id FROM prompt_picks WHERE user_id = @user AND date >= @since
+		UNION
+		SELECT prompt_id FROM journal_entries
+		WHERE user_id = @user AND date >= @since AND prompt_id IS NOT NULL AND deleted_at IS NULL`,
+		map[string]interface{}{"user": userID, "since": since},
+	).Scan(&ids).Error
+
+	return ids, err
+}
+
+// FindAnswers returns the user's published entries that answered the
+// prompt, newest first.
+func (r *PromptRepository) FindAnswers(promptID, userID uint) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.
+		Where("user_id = ? AND prompt_id = ? AND status = ?", userID, promptID, enums.EntryStatus.Published).
+		Order("date DESC, id DESC").
+		Find(&entries).Error
+
+	return entries, err
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPushSubscriptionRepository(db *gorm.DB) *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{db}
+}
+
+// Upsert stores the subscription, taking over an existing one with the
+// same endpoint, e.g. when another user signs in on the same browser.
+func (r *PushSubscriptionRepository) Upsert(subscription *models.PushSubscription) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"user_id":     subscription.UserID,
+			"p256dh":      subscription.P256dh,
+			"auth":        subscription.Auth,
+			"device_name": subscription.DeviceName,
+			"user_agent":  subscription.UserAgent,
+			"updated_at":  time.Now(),
+			"deleted_at":  nil,
+		}),
+	}).Create(subscription).Error
+}
+
+func (r *PushSubscriptionRepository) FindByEndpoint(endpoint string) (*models.PushSubscription, error) {
+	var subscription models.PushSubscription
+	err := r.db.Where("endpoint = ?", endpoint).First(&subscription).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &subscription, err
+}
+
+func (r *PushSubscriptionRepository) FindByUserID(userID uint) ([]models.PushSubscription, error) {
+	var subscriptions []models.PushSubscription
+	err := r.db.
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&subscriptions).Error
+
+	return subscriptions, err
+}
+
+// DeleteByIDAndUserID removes a subscription for good; a browser that
+// subscribes again gets a new endpoint anyway.
+func (r *PushSubscriptionRepository) DeleteByIDAndUserID(id, userID uint) error {
+	result := r.db.Unscoped().Where("id = ? AND user_id = ?", id, userID).Delete(&models.PushSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteByEndpoint prunes a subscription the push service reported gone.
+func (r *PushSubscriptionRepository) DeleteByEndpoint(endpoint string) error {
+	return r.db.Unscoped().Where("endpoint = ?", endpoint).Delete(&models.PushSubscription{}).Error
+}
+
+func (r *PushSubscriptionRepository) MarkUsed(id uint, at time.Time) error {
+	return r.db.Model(&models.PushSubscription{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+type ReaderGrantRepository struct {
+	db *gorm.DB
+}
+
+func NewReaderGrantRepository(db *gorm.DB) *ReaderGrantRepository {
+	return &ReaderGrantRepository{db}
+}
+
+func (r *ReaderGrantRepository) Create(grant *models.ReaderGrant) error {
+	return r.db.Create(grant).Error
+}
+
+func (r *ReaderGrantRepository) Save(grant *models.ReaderGrant) error {
+	return r.db.Save(grant).Error
+}
+
+// FindByOwnerID lists the grants and invitations the owner made.
+func (r *ReaderGrantRepository) FindByOwnerID(ownerID uint) ([]models.ReaderGrant, error) {
+	var grants []models.ReaderGrant
+	err := r.db.Where("owner_id = ?", ownerID).Order("created_at DESC, id DESC").Find(&grants).Error
+	return grants, err
+}
+
+func (r *ReaderGrantRepository) FindByIDAndOwnerID(id, ownerID uint) (*models.ReaderGrant, error) {
+	var grant models.ReaderGrant
+	err := r.db.Where("owner_id = ?", ownerID).First(&grant, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &grant, err
+}
+
+// FindPendingByEmail lists the open invitations addressed to an email.
+func (r *ReaderGrantRepository) FindPendingByEmail(email string) ([]models.ReaderGrant, error) {
+	var grants []models.ReaderGrant
+	err := r.db.
+		Where("LOWER(invite_email) = LOWER(?) AND status = ?", email, models.GrantStatusPending).
+		Order("created_at DESC, id DESC").
+		Find(&grants).Error
+	return grants, err
+}
+
+func (r *ReaderGrantRepository) FindPendingByIDAndEmail(id uint, email string) (*models.ReaderGrant, error) {
+	var grant models.ReaderGrant
+	err := r.db.
+		Where("LOWER(invite_email) = LOWER(?) AND status = ?", email, models.GrantStatusPending).
+		First(&grant, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &grant, err
+}
+
+// FindAcceptedByReaderID lists the grants the reader currently holds.
+func (r *ReaderGrantRepository) FindAcceptedByReaderID(readerID uint) ([]models.ReaderGrant, error) {
+	var grants []models.ReaderGrant
+	err := r.db.
+		Where("reader_id = ? AND status = ?", readerID, models.GrantStatusAccepted).
+		Order("created_at DESC, id DESC").
+		Find(&grants).Error
+	return grants, err
+}
+
+func (r *ReaderGrantRepository) FindAcceptedByIDAndReaderID(id, readerID uint) (*models.ReaderGrant, error) {
+	var grant models.ReaderGrant
+	err := r.db.
+		Where("reader_id = ? AND status = ?", readerID, models.GrantStatusAccepted).
+		First(&grant, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &grant, err
+}
+
+// FindAccepted lists the grants the owner gave the reader.
+func (r *ReaderGrantRepository) FindAccepted(ownerID, readerID uint) ([]models.ReaderGrant, error) {
+	var grants []models.ReaderGrant
+	err := r.db.
+		Where("owner_id = ? AND reader_id = ? AND status = ?", ownerID, readerID, models.GrantStatusAccepted).
+		Find(&grants).Error
+	return grants, err
+}
+
+// ExistsOpen reports whether the owner already invited the email to the
+// same notebook or tag and the invitation is pending or accepted.
+func (r *ReaderGrantRepository) ExistsOpen(ownerID uint, email string, notebookID *uint, tag string) (bool, error) {
+	query := r.db.Model(&models.ReaderGrant{}).
+		Where("owner_id = ? AND LOWER(invite_email) = LOWER(?) AND status IN ?",
+			ownerID, email, []string{models.GrantStatusPending, models.GrantStatusAccepted})
+	if notebookID != nil {
+		query = query.Where("notebook_id = ?", *notebookID)
+	} else {
+		query = query.Where("notebook_id IS NULL AND tag = ?", tag)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ReaderGrantRepository) LogAccess(access *models.ReaderAccess) error {
+	return r.db.Create(access).Error
+}
+
+// FindAccesses returns the grant's most recent audit records, newest first.
+func (r *ReaderGrantRepository) FindAccesses(grantID uint, limit int) ([]models.ReaderAccess, error) {
+	var accesses []models.ReaderAccess
+	err := r.db.
+		Where("grant_id = ?", grantID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&accesses).Error
+	return accesses, err
+}
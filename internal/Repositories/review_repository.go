@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db}
+}
+
+func (r *ReviewRepository) Find(userID uint, period string, startDate time.Time) (*models.Review, error) {
+	var review models.Review
+	err := r.db.
+		Where("user_id = ? AND period = ? AND start_date = ?", userID, period, startDate).
+		First(&review).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &review, err
+}
+
+// Save stores a review, replacing an earlier report of the same period.
+func (r *ReviewRepository) Save(review *models.Review) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "period"}, {Name: "start_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"end_date", "final", "report", "generated_at", "updated_at"}),
+	}).Create(review).Error
+}
+
+// FindByUserID lists the user's reviews of a period, newest first.
+func (r *ReviewRepository) FindByUserID(userID uint, period string, limit int) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.
+		Where("user_id = ? AND period = ?", userID, period).
+		Order("start_date DESC").
+		Limit(limit).
+		Find(&reviews).Error
+
+	return reviews, err
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+type RevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewRevisionRepository(db *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *RevisionRepository) WithTx(tx *gorm.DB) *RevisionRepository {
+	return &RevisionRepository{tx}
+}
+
+func (r *RevisionRepository) FindByJournalEntryID(journalEntryID uint) ([]models.JournalRevision, error) {
+	var revisions []models.JournalRevision
+	err := r.db.
+		Omit("snapshot").
+		Where("journal_entry_id = ?", journalEntryID).
+		Order("revision DESC").
+		Find(&revisions).Error
+
+	return revisions, err
+}
+
+func (r *RevisionRepository) FindByRevision(journalEntryID uint, revision int) (*models.JournalRevision, error) {
+	var rev models.JournalRevision
+	err := r.db.
+		Where("journal_entry_id = ? AND revision = ?", journalEntryID, revision).
+		First(&rev).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &rev, err
+}
+
+// Prune applies the retention policy to the revisions of one entry. Only
+// revisions beyond the newest keepLast, or older than olderThan, are
+// removed; zero values disable the respective rule. The latest revision
+// is always kept.
+func (r *RevisionRepository) Prune(journalEntryID uint, keepLast int, olderThan time.Time) error {
+	if keepLast <= 0 && olderThan.IsZero() {
+		return nil
+	}
+
+	var latest int
+	err := r.db.Model(&models.JournalRevision{}).
+		Select("COALESCE(MAX(revision), 0)").
+		Where("journal_entry_id = ?", journalEntryID).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	var conditions []string
+	var args []interface{}
+	if keepLast > 0 {
+		conditions = append(conditions, "revision <= ?")
+		args = append(args, latest-keepLast)
+	}
+	if !olderThan.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, olderThan)
+	}
+
+	return r.db.
+		Where("journal_entry_id = ? AND revision < ?", journalEntryID, latest).
+		Where("("+strings.Join(conditions, " OR ")+")", args...).
+		Delete(&models.JournalRevision{}).Error
+}
+
+// appendRevision snapshots the current state of an entry as its next
+// revision. It must run inside the transaction that changed the entry.
+func appendRevision(tx *gorm.DB, journalEntryID uint, restoredFrom *int) error {
+	var entry models.JournalEntry
+	err := tx.
+		Preload("DailyTasks", func(db *gorm.DB) *gorm.DB { return db.Order("position, id") }).
+		Preload("DailyTasks.SubTasks", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&entry, journalEntryID).Error
+	if err != nil {
+		return err
+	}
+
+	snapshot, err := json.Marshal(models.NewEntrySnapshot(&entry))
+	if err != nil {
+		return err
+	}
+
+	var latest int
+	err = tx.Model(&models.JournalRevision{}).
+		Select("COALESCE(MAX(revision), 0)").
+		Where("journal_entry_id = ?", journalEntryID).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&models.JournalRevision{
+		JournalEntryID:       journalEntryID,
+		Revision:             latest + 1,
+		UserID:               entry.UserID,
+		Snapshot:             models.JSONB(snapshot),
+		RestoredFromRevision: restoredFrom,
+	}).Error
+}
@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoutineRepository struct {
+	db *gorm.DB
+}
+
+func NewRoutineRepository(db *gorm.DB) *RoutineRepository {
+	return &RoutineRepository{db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *RoutineRepository) WithTx(tx *gorm.DB) *RoutineRepository {
+	return &RoutineRepository{tx}
+}
+
+func (r *RoutineRepository) Create(routine *models.Routine) error {
+	return r.db.Create(routine).Error
+}
+
+func (r *RoutineRepository) FindByUserID(userID uint) ([]models.Routine, error) {
+	var routines []models.Routine
+	err := r.db.
+		Preload("SubTasks", orderSubTasks).
+		Where("user_id = ?", userID).
+		Order("id ASC").
+		Find(&routines).Error
+
+	return routines, err
+}
+
+func (r *RoutineRepository) FindByIDAndUserID(id, userID uint) (*models.Routine, error) {
+	var routine models.Routine
+	err := r.db.
+		Preload("SubTasks", orderSubTasks).
+		Where("user_id = ?", userID).
+		First(&routine, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &routine, err
+}
+
+// FindDueCandidates returns the active routines of a user whose date range
+// covers date. The recurrence rule itself is evaluated by the caller.
+func (r *RoutineRepository) FindDueCandidates(userID uint, date time.Time) ([]models.Routine, error) {
+	var routines []models.Routine
+	err := r.db.
+		Preload("SubTasks", orderSubTasks).
+		Where("user_id = ? AND active = ?", userID, true).
+		Where("start_date <= ?", date).
+		Where("(end_date IS NULL OR end_date >= ?)", date).
+		Order("id ASC").
+		Find(&routines).Error
+
+	return routines, err
+}
+
+// Update saves the routine and replaces its subtasks.
+func (r *RoutineRepository) Update(routine *models.Routine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(routine).
+			Select("task", "recurrence", "start_date", "end_date", "active").
+			Updates(routine).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("routine_id = ?", routine.ID).Delete(&models.RoutineSubTask{}).Error; err != nil {
+			return err
+		}
+		for i := range routine.SubTasks {
+			routine.SubTasks[i].ID = 0
+			routine.SubTasks[i].RoutineID = routine.ID
+		}
+		if len(routine.SubTasks) > 0 {
+			return tx.Create(&routine.SubTasks).Error
+		}
+		return nil
+	})
+}
+
+// Delete removes a routine. Tasks already copied into entries stay.
+func (r *RoutineRepository) Delete(routine *models.Routine) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("routine_id = ?", routine.ID).Delete(&models.RoutineSubTask{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("routine_id = ?", routine.ID).Delete(&models.RoutineException{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(routine).Error
+	})
+}
+
+func (r *RoutineRepository) FindExceptionsOn(routineIDs []uint, date time.Time) ([]models.RoutineException, error) {
+	var exceptions []models.RoutineException
+	err := r.db.
+		Where("routine_id IN ? AND date = ?", routineIDs, date).
+		Find(&exceptions).Error
+
+	return exceptions, err
+}
+
+func (r *RoutineRepository) FindExceptionsBetween(routineID uint, from, to time.Time) ([]models.RoutineException, error) {
+	var exceptions []models.RoutineException
+	err := r.db.
+		Where("routine_id = ? AND date BETWEEN ? AND ?", routineID, from, to).
+		Find(&exceptions).Error
+
+	return exceptions, err
+}
+
+// SaveException creates or replaces the exception for one occurrence.
+func (r *RoutineRepository) SaveException(exception *models.RoutineException) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "routine_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"skip", "task", "sub_tasks", "updated_at"}),
+	}).Create(exception).Error
+}
+
+func (r *RoutineRepository) DeleteException(routineID uint, date time.Time) error {
+	result := r.db.Unscoped().
+		Where("routine_id = ? AND date = ?", routineID, date).
+		Delete(&models.RoutineException{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func orderSubTasks(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
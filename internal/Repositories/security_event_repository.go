@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+// SecurityEventRepository appends to and reads the security audit log. It
+// deliberately has no way to change or remove an event.
+type SecurityEventRepository struct {
+	db *gorm.DB
+}
+
+func NewSecurityEventRepository(db *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *SecurityEventRepository) WithTx(tx *gorm.DB) *SecurityEventRepository {
+	return &SecurityEventRepository{tx}
+}
+
+func (r *SecurityEventRepository) Create(event *models.SecurityEvent) error {
+	return r.db.Create(event).Error
+}
+
+// Find pages through the matching events, newest first.
+func (r *SecurityEventRepository) Find(query models.SecurityEventQuery, limit, offset int) ([]models.SecurityEvent, error) {
+	db := r.db.Model(&models.SecurityEvent{})
+	if query.UserID != nil {
+		db = db.Where("user_id = ?", *query.UserID)
+	}
+	if query.Type != "" {
+		db = db.Where("type = ?", query.Type)
+	}
+
+	var events []models.SecurityEvent
+	err := db.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error
+	return events, err
+}
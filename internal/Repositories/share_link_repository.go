@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+type ShareLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewShareLinkRepository(db *gorm.DB) *ShareLinkRepository {
+	return &ShareLinkRepository{db}
+}
+
+func (r *ShareLinkRepository) Create(link *models.ShareLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *ShareLinkRepository) FindByUserID(userID uint) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&links).Error
+	return links, err
+}
+
+func (r *ShareLinkRepository) FindByIDAndUserID(id, userID uint) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := r.db.Where("user_id = ?", userID).First(&link, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &link, err
+}
+
+func (r *ShareLinkRepository) FindByTokenHash(tokenHash string) (*models.ShareLink, error) {
+	var link models.ShareLink
+	err := r.db.Where("token_hash = ?", tokenHash).First(&link).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &link, err
+}
+
+// Revoke closes the link for good. Revoking twice keeps the first time.
+func (r *ShareLinkRepository) Revoke(link *models.ShareLink, at time.Time) error {
+	err := r.db.Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", link.ID).
+		Update("revoked_at", at).Error
+	if err != nil {
+		return err
+	}
+	if link.RevokedAt == nil {
+		link.RevokedAt = &at
+	}
+	return nil
+}
+
+// ClaimView counts a view if the link is still open: not revoked, not
+// expired and under its view limit. It reports false otherwise, so
+// concurrent requests cannot exceed the limit.
+func (r *ShareLinkRepository) ClaimView(link *models.ShareLink, at time.Time) (bool, error) {
+	result := r.db.Model(&models.ShareLink{}).
+		Where("id = ? AND revoked_at IS NULL", link.ID).
+		Where("expires_at IS NULL OR expires_at > ?", at).
+		Where("max_views IS NULL OR view_count < max_views").
+		Updates(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + 1"),
+			"last_viewed_at": at,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	link.ViewCount++
+	link.LastViewedAt = &at
+	return true, nil
+}
+
+func (r *ShareLinkRepository) LogAccess(access *models.ShareAccess) error {
+	return r.db.Create(access).Error
+}
+
+// CountAccesses counts the link's accesses with the outcome since a time.
+func (r *ShareLinkRepository) CountAccesses(linkID uint, outcome string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ShareAccess{}).
+		Where("share_link_id = ? AND outcome = ? AND created_at >= ?", linkID, outcome, since).
+		Count(&count).Error
+	return count, err
+}
+
+// FindAccesses returns the link's most recent accesses, newest first.
+func (r *ShareLinkRepository) FindAccesses(linkID uint, limit int) ([]models.ShareAccess, error) {
+	var accesses []models.ShareAccess
+	err := r.db.
+		Where("share_link_id = ?", linkID).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&accesses).Error
+	return accesses, err
+}
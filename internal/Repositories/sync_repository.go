@@ -0,0 +1,489 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// SyncApplyResult is the outcome of applying one pushed change. When the
+// change conflicts with newer server state, Conflict holds that state and
+// nothing was written.
+type SyncApplyResult struct {
+	ID             uint
+	Seq            uint64
+	JournalEntryID uint
+	Conflict       *models.SyncRecord
+}
+
+type SyncRepository struct {
+	db *gorm.DB
+}
+
+func NewSyncRepository(db *gorm.DB) *SyncRepository {
+	return &SyncRepository{db}
+}
+
+// --------------------------
+// Change feed
+// --------------------------
+
+func (r *SyncRepository) FindChangesSince(userID uint, cursor uint64, limit int) ([]models.SyncChange, error) {
+	var changes []models.SyncChange
+	err := r.db.
+		Where("user_id = ? AND seq > ?", userID, cursor).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&changes).Error
+
+	return changes, err
+}
+
+// The lookups below include soft-deleted rows so deletions can be
+// reported as tombstones.
+
+func (r *SyncRepository) FindEntriesByIDs(userID uint, ids []uint) ([]models.JournalEntry, error) {
+	var entries []models.JournalEntry
+	err := r.db.Unscoped().
+		Where("user_id = ? AND id IN ?", userID, ids).
+		Find(&entries).Error
+
+	return entries, err
+}
+
+func (r *SyncRepository) FindTasksByIDs(userID uint, ids []uint) ([]models.DailyTask, error) {
+	var tasks []models.DailyTask
+	err := r.db.Unscoped().
+		Where("id IN ?", ids).
+		Where("journal_entry_id IN (?)", userEntryIDs(r.db, userID)).
+		Find(&tasks).Error
+
+	return tasks, err
+}
+
+func (r *SyncRepository) FindSubTasksByIDs(userID uint, ids []uint) ([]models.DailySubTask, error) {
+	var subTasks []models.DailySubTask
+	err := r.db.Unscoped().
+		Where("id IN ?", ids).
+		Where("daily_task_id IN (?)", userTaskIDs(r.db, userID)).
+		Find(&subTasks).Error
+
+	return subTasks, err
+}
+
+// --------------------------
+// Push
+// --------------------------
+
+func (r *SyncRepository) ApplyEntryChange(userID uint, change models.SyncPushChange, data models.SyncEntryData) (*SyncApplyResult, error) {
+	result := &SyncApplyResult{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		changes := newChangeRecorder(tx, userID)
+		if err := changes.lock(); err != nil {
+			return err
+		}
+
+		var entry models.JournalEntry
+		found, err := findSyncTarget(tx.Unscoped().Where("user_id = ?", userID), change, &entry)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			seq, err := latestSeq(tx, models.SyncEntityJournalEntry, entry.ID)
+			if err != nil {
+				return err
+			}
+			result.ID, result.Seq, result.JournalEntryID = entry.ID, seq, entry.ID
+
+			if isConflict(change, seq) {
+				record := models.NewEntrySyncRecord(&entry, seq)
+				result.Conflict = &record
+				return nil
+			}
+		}
+
+		if change.Operation == models.SyncOperationDelete {
+			if !found || entry.DeletedAt.Valid {
+				return nil
+			}
+			if err := tx.Delete(&entry).Error; err != nil {
+				return err
+			}
+			result.Seq, err = changes.record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationDelete)
+			return err
+		}
+
+		if !found {
+			if data.Date == nil {
+				return ErrInvalidSyncData
+			}
+			entry = models.JournalEntry{
+				UserID:   userID,
+				ClientID: &change.ClientID,
+				Date:     startOfDay(*data.Date),
+				Version:  1,
+			}
+			applyEntryData(&entry, data)
+			if entry.Status == enums.EntryStatus.Unknown {
+				entry.Status = enums.EntryStatus.Draft
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			result.ID, result.JournalEntryID = entry.ID, entry.ID
+			result.Seq, err = changes.record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationUpsert)
+			return err
+		}
+
+		wasDeleted := entry.DeletedAt.Valid
+		applyEntryData(&entry, data)
+		if data.Date != nil {
+			entry.Date = startOfDay(*data.Date)
+		}
+
+		err = tx.Unscoped().Model(&models.JournalEntry{}).
+			Where("id = ?", entry.ID).
+			Updates(map[string]interface{}{
+				"date":                 entry.Date,
+				"mood":                 entry.Mood,
+				"this_day_description": entry.ThisDayDescription,
+				"daily_reflection":     entry.DailyReflection,
+				"status":               entry.Status,
+				"deleted_at":           nil,
+				"version":              gorm.Expr("version + 1"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		// A client-wins upsert of a trashed entry brings it back
+		if wasDeleted {
+			if err := changes.entryTree(entry.ID); err != nil {
+				return err
+			}
+			result.Seq, err = latestSeq(tx, models.SyncEntityJournalEntry, entry.ID)
+			return err
+		}
+
+		result.Seq, err = changes.record(models.SyncEntityJournalEntry, entry.ID, models.SyncOperationUpsert)
+		return err
+	})
+
+	return result, err
+}
+
+func (r *SyncRepository) ApplyTaskChange(userID uint, change models.SyncPushChange, data models.SyncTaskData) (*SyncApplyResult, error) {
+	result := &SyncApplyResult{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		changes := newChangeRecorder(tx, userID)
+		if err := changes.lock(); err != nil {
+			return err
+		}
+
+		var task models.DailyTask
+		scope := tx.Unscoped().Where("journal_entry_id IN (?)", userEntryIDs(tx, userID))
+		found, err := findSyncTarget(scope, change, &task)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			seq, err := latestSeq(tx, models.SyncEntityDailyTask, task.ID)
+			if err != nil {
+				return err
+			}
+			result.ID, result.Seq, result.JournalEntryID = task.ID, seq, task.JournalEntryID
+
+			if isConflict(change, seq) {
+				record := models.NewTaskSyncRecord(&task, seq)
+				result.Conflict = &record
+				return nil
+			}
+		}
+
+		if change.Operation == models.SyncOperationDelete {
+			if !found || task.DeletedAt.Valid {
+				return nil
+			}
+			if err := tx.Where("daily_task_id = ?", task.ID).Delete(&models.DailySubTask{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&task).Error; err != nil {
+				return err
+			}
+			if err := touchEntry(tx, changes, task.JournalEntryID); err != nil {
+				return err
+			}
+			result.Seq, err = changes.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationDelete)
+			return err
+		}
+
+		if !found {
+			var entry models.JournalEntry
+			parent := models.SyncPushChange{ID: data.JournalEntryID}
+			if data.JournalEntryClientID != nil {
+				parent.ClientID = *data.JournalEntryClientID
+			}
+			parentFound, err := findSyncTarget(tx.Where("user_id = ?", userID), parent, &entry)
+			if err != nil {
+				return err
+			}
+			if !parentFound {
+				return ErrRecordNotFound
+			}
+
+			task = models.DailyTask{
+				JournalEntryID: entry.ID,
+				ClientID:       &change.ClientID,
+			}
+			applyTaskData(&task, data)
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+		} else {
+			applyTaskData(&task, data)
+			err := tx.Unscoped().Model(&models.DailyTask{}).
+				Where("id = ?", task.ID).
+				Updates(map[string]interface{}{
+					"task":       task.Task,
+					"status":     task.Status,
+					"position":   task.Position,
+					"priority":   task.Priority,
+					"due_time":   task.DueTime,
+					"notes":      task.Notes,
+					"deleted_at": nil,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result.ID, result.JournalEntryID = task.ID, task.JournalEntryID
+		if err := touchEntry(tx, changes, task.JournalEntryID); err != nil {
+			return err
+		}
+		result.Seq, err = changes.record(models.SyncEntityDailyTask, task.ID, models.SyncOperationUpsert)
+		return err
+	})
+
+	return result, err
+}
+
+func (r *SyncRepository) ApplySubTaskChange(userID uint, change models.SyncPushChange, data models.SyncSubTaskData) (*SyncApplyResult, error) {
+	result := &SyncApplyResult{}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		changes := newChangeRecorder(tx, userID)
+		if err := changes.lock(); err != nil {
+			return err
+		}
+
+		var subTask models.DailySubTask
+		scope := tx.Unscoped().Where("daily_task_id IN (?)", userTaskIDs(tx, userID))
+		found, err := findSyncTarget(scope, change, &subTask)
+		if err != nil {
+			return err
+		}
+
+		var task models.DailyTask
+		if found {
+			if err := tx.Unscoped().First(&task, subTask.DailyTaskID).Error; err != nil {
+				return err
+			}
+
+			seq, err := latestSeq(tx, models.SyncEntitySubTask, subTask.ID)
+			if err != nil {
+				return err
+			}
+			result.ID, result.Seq, result.JournalEntryID = subTask.ID, seq, task.JournalEntryID
+
+			if isConflict(change, seq) {
+				record := models.NewSubTaskSyncRecord(&subTask, seq)
+				result.Conflict = &record
+				return nil
+			}
+		}
+
+		if change.Operation == models.SyncOperationDelete {
+			if !found || subTask.DeletedAt.Valid {
+				return nil
+			}
+			if err := tx.Delete(&subTask).Error; err != nil {
+				return err
+			}
+			if err := touchEntry(tx, changes, task.JournalEntryID); err != nil {
+				return err
+			}
+			result.Seq, err = changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationDelete)
+			return err
+		}
+
+		if !found {
+			parent := models.SyncPushChange{ID: data.DailyTaskID}
+			if data.DailyTaskClientID != nil {
+				parent.ClientID = *data.DailyTaskClientID
+			}
+			parentFound, err := findSyncTarget(tx.Where("journal_entry_id IN (?)", userEntryIDs(tx, userID)), parent, &task)
+			if err != nil {
+				return err
+			}
+			if !parentFound {
+				return ErrRecordNotFound
+			}
+
+			subTask = models.DailySubTask{
+				DailyTaskID: task.ID,
+				ClientID:    &change.ClientID,
+			}
+			applySubTaskData(&subTask, data)
+			if err := tx.Create(&subTask).Error; err != nil {
+				return err
+			}
+		} else {
+			applySubTaskData(&subTask, data)
+			err := tx.Unscoped().Model(&models.DailySubTask{}).
+				Where("id = ?", subTask.ID).
+				Updates(map[string]interface{}{
+					"sub_task":   subTask.SubTask,
+					"status":     subTask.Status,
+					"deleted_at": nil,
+				}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		result.ID, result.JournalEntryID = subTask.ID, task.JournalEntryID
+		if err := touchEntry(tx, changes, task.JournalEntryID); err != nil {
+			return err
+		}
+		result.Seq, err = changes.record(models.SyncEntitySubTask, subTask.ID, models.SyncOperationUpsert)
+		return err
+	})
+
+	return result, err
+}
+
+// findSyncTarget looks a record up by server ID or, failing that, by its
+// client generated UUID. A change that names an unknown server ID is an
+// error; an unknown client ID simply means the record is new.
+func findSyncTarget(scope *gorm.DB, change models.SyncPushChange, dest interface{}) (bool, error) {
+	var err error
+	switch {
+	case change.ID > 0:
+		err = scope.First(dest, change.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrRecordNotFound
+		}
+	case change.ClientID != "":
+		err = scope.Where("client_id = ?", change.ClientID).First(dest).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+	default:
+		return false, ErrInvalidSyncData
+	}
+
+	return err == nil, err
+}
+
+func isConflict(change models.SyncPushChange, serverSeq uint64) bool {
+	return change.BaseSeq < serverSeq && change.Resolution != "client_wins"
+}
+
+func latestSeq(tx *gorm.DB, entityType string, entityID uint) (uint64, error) {
+	var seq uint64
+	err := tx.Model(&models.SyncChange{}).
+		Select("COALESCE(MAX(seq), 0)").
+		Where("entity_type = ? AND entity_id = ?", entityType, entityID).
+		Scan(&seq).Error
+
+	return seq, err
+}
+
+// touchEntry bumps the version of the entry owning a changed task, since
+// the entry's ETag covers its tasks as well.
+func touchEntry(tx *gorm.DB, changes *changeRecorder, entryID uint) error {
+	err := tx.Model(&models.JournalEntry{}).
+		Where("id = ?", entryID).
+		Update("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		return err
+	}
+	_, err = changes.record(models.SyncEntityJournalEntry, entryID, models.SyncOperationUpsert)
+	return err
+}
+
+func userEntryIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Unscoped().
+		Model(&models.JournalEntry{}).
+		Select("id").
+		Where("user_id = ?", userID)
+}
+
+func userTaskIDs(db *gorm.DB, userID uint) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).
+		Unscoped().
+		Model(&models.DailyTask{}).
+		Select("id").
+		Where("journal_entry_id IN (?)", userEntryIDs(db, userID))
+}
+
+func applyEntryData(entry *models.JournalEntry, data models.SyncEntryData) {
+	if data.Mood != nil {
+		entry.Mood = *data.Mood
+	}
+	if data.ThisDayDescription != nil {
+		entry.ThisDayDescription = *data.ThisDayDescription
+	}
+	if data.DailyReflection != nil {
+		entry.DailyReflection = *data.DailyReflection
+	}
+	if data.Status != nil && *data.Status != enums.EntryStatus.Unknown {
+		entry.Status = *data.Status
+	}
+}
+
+func applyTaskData(task *models.DailyTask, data models.SyncTaskData) {
+	if data.Task != nil {
+		task.Task = *data.Task
+	}
+	if data.Status != nil {
+		task.Status = *data.Status
+	}
+	if data.Position != nil {
+		task.Position = *data.Position
+	}
+	if data.Priority != nil {
+		task.Priority = *data.Priority
+	}
+	if data.DueTime != nil {
+		task.DueTime = data.DueTime
+		if *data.DueTime == "" {
+			task.DueTime = nil
+		}
+	}
+	if data.Notes != nil {
+		task.Notes = *data.Notes
+	}
+}
+
+func applySubTaskData(subTask *models.DailySubTask, data models.SyncSubTaskData) {
+	if data.SubTask != nil {
+		subTask.SubTask = *data.SubTask
+	}
+	if data.Status != nil {
+		subTask.Status = *data.Status
+	}
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+type TemplateRepository struct {
+	db *gorm.DB
+}
+
+func NewTemplateRepository(db *gorm.DB) *TemplateRepository {
+	return &TemplateRepository{db}
+}
+
+// Create stores a template together with its first version.
+func (r *TemplateRepository) Create(template *models.Template, fields models.JSONB) (*models.TemplateVersion, error) {
+	version := &models.TemplateVersion{Version: 1, Fields: fields}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		template.Version = 1
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+
+		version.TemplateID = template.ID
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// Save updates the template's name and description.
+func (r *TemplateRepository) Save(template *models.Template) error {
+	return r.db.Model(template).Updates(map[string]interface{}{
+		"name":        template.Name,
+		"description": template.Description,
+	}).Error
+}
+
+// AddVersion stores new fields as the template's next version. Earlier
+// versions are kept for the entries written with them.
+func (r *TemplateRepository) AddVersion(template *models.Template, fields models.JSONB) (*models.TemplateVersion, error) {
+	var version *models.TemplateVersion
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Template{}).
+			Where("id = ? AND version = ?", template.ID, template.Version).
+			Update("version", gorm.Expr("version + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrVersionConflict
+		}
+
+		version = &models.TemplateVersion{
+			TemplateID: template.ID,
+			Version:    template.Version + 1,
+			Fields:     fields,
+		}
+		return tx.Create(version).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	template.Version = version.Version
+	return version, nil
+}
+
+func (r *TemplateRepository) Delete(template *models.Template) error {
+	return r.db.Delete(template).Error
+}
+
+func (r *TemplateRepository) FindByUserID(userID uint) ([]models.Template, error) {
+	var templates []models.Template
+	err := r.db.Where("user_id = ?", userID).Order("name ASC, id ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *TemplateRepository) FindByIDAndUserID(id, userID uint) (*models.Template, error) {
+	var template models.Template
+	err := r.db.Where("user_id = ?", userID).First(&template, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &template, err
+}
+
+// FindVersion returns a version of one of the user's templates. Versions
+// of deleted templates are still found, so entries using them render.
+func (r *TemplateRepository) FindVersion(templateID, userID uint, version int) (*models.TemplateVersion, error) {
+	var templateVersion models.TemplateVersion
+	err := r.db.
+		Joins("JOIN templates ON templates.id = template_versions.template_id").
+		Where("template_versions.template_id = ? AND template_versions.version = ? AND templates.user_id = ?",
+			templateID, version, userID).
+		Take(&templateVersion).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &templateVersion, err
+}
+
+// FindLatestVersions returns the latest version of each template.
+func (r *TemplateRepository) FindLatestVersions(templates []models.Template) ([]models.TemplateVersion, error) {
+	if len(templates) == 0 {
+		return nil, nil
+	}
+
+	conditions := r.db.Where("1 = 0")
+	for _, template := range templates {
+		conditions = conditions.Or("template_id = ? AND version = ?", template.ID, template.Version)
+	}
+
+	var versions []models.TemplateVersion
+	err := r.db.Where(conditions).Find(&versions).Error
+	return versions, err
+}
+
+// FindVersions lists every version of a template, oldest first.
+func (r *TemplateRepository) FindVersions(templateID uint) ([]models.TemplateVersion, error) {
+	var versions []models.TemplateVersion
+	err := r.db.Where("template_id = ?", templateID).Order("version ASC").Find(&versions).Error
+	return versions, err
+}
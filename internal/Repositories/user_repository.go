@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"errors"
+	"time"
 
 	"github.com/sugiiianaa/remember-my-story/internal/models"
 	"gorm.io/gorm"
@@ -15,6 +16,15 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 	return &UserRepository{db}
 }
 
+func (r *UserRepository) Transaction(fn func(tx *gorm.DB) error) error {
+	return r.db.Transaction(fn)
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *UserRepository) WithTx(tx *gorm.DB) *UserRepository {
+	return &UserRepository{tx}
+}
+
 func (r *UserRepository) Create(user *models.User) (uint, error) {
 	if err := r.db.Create(user).Error; err != nil {
 		return 0, err
@@ -32,3 +42,176 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 
 	return &user, err
 }
+
+func (r *UserRepository) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.First(&user, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &user, err
+}
+
+func (r *UserRepository) FindByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r *UserRepository) UpdateSettings(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// FindWithAutoCarryOver pages through users who enabled automatic task
+// carry-over, ordered by ID.
+func (r *UserRepository) FindWithAutoCarryOver(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Select("id", "time_zone").
+		Where("auto_carry_over = ? AND id > ?", true, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+// FindWithRemindersEnabled pages through users who turned on the daily
+// journaling reminder, ordered by ID.
+func (r *UserRepository) FindWithRemindersEnabled(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Where("reminder_enabled = ? AND id > ?", true, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+// MarkReminded records the local day a reminder was sent for. It reports
+// false when a reminder for that day was already recorded, so concurrent
+// runs never send it twice.
+func (r *UserRepository) MarkReminded(id uint, day time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (last_reminded_on IS NULL OR last_reminded_on < ?)", id, day).
+		Update("last_reminded_on", day)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindWithMemoryDigestEnabled pages through users who turned on the daily
+// "on this day" digest, ordered by ID.
+func (r *UserRepository) FindWithMemoryDigestEnabled(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Where("memory_digest_enabled = ? AND id > ?", true, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+// MarkDigestSent records the local day a digest was handled for, like
+// MarkReminded.
+func (r *UserRepository) MarkDigestSent(id uint, day time.Time) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND (last_digest_on IS NULL OR last_digest_on < ?)", id, day).
+		Update("last_digest_on", day)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindPage pages through all users, ordered by ID, loading only the
+// columns background jobs need.
+func (r *UserRepository) FindPage(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.
+		Select("id", "time_zone").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&users).Error
+
+	return users, err
+}
+
+// FindSession loads only the columns needed to check that a token is
+// still valid for the account.
+func (r *UserRepository) FindSession(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.
+		Select("id", "role", "token_version", "disabled_at").
+		First(&user, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &user, err
+}
+
+// RecordFailedLogin counts a wrong password and, on reaching maxAttempts,
+// locks the account until lockUntil. The counter is updated in one
+// statement so concurrent attempts are all counted.
+func (r *UserRepository) RecordFailedLogin(id uint, maxAttempts int, lockUntil time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_logins": gorm.Expr("failed_logins + 1"),
+			"locked_until": gorm.Expr(
+				"CASE WHEN failed_logins + 1 >= ? THEN ?::timestamptz ELSE locked_until END",
+				maxAttempts, lockUntil),
+		}).Error
+}
+
+// RecordLogin clears the failed attempts after a successful login.
+func (r *UserRepository) RecordLogin(id uint, at time.Time) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"failed_logins": 0,
+			"locked_until":  nil,
+			"last_login_at": at,
+		}).Error
+}
+
+// UpdateAccount changes account fields, such as the role or whether the
+// account is disabled.
+func (r *UserRepository) UpdateAccount(id uint, fields map[string]interface{}) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", id).Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// PromoteAdmins gives the admin role to the accounts with the given
+// emails, ending their current sessions so new tokens carry the role. It
+// returns the IDs of the accounts that were not admins yet.
+func (r *UserRepository) PromoteAdmins(emails []string) ([]uint, error) {
+	var ids []uint
+	if len(emails) == 0 {
+		return ids, nil
+	}
+	err := r.db.Raw(`
+		UPDATE users
+		SET role = @admin, token_version = token_version + 1, updated_at = NOW()
+		WHERE email IN @emails AND role <> @admin AND deleted_at IS NULL
+		RETURNING id`,
+		map[string]interface{}{
+			"admin":  models.RoleAdmin,
+			"emails": emails,
+		}).Scan(&ids).Error
+
+	return ids, err
+}
@@ -0,0 +1,191 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db}
+}
+
+// WithTx returns a copy of the repository that runs its queries in tx.
+func (r *WebhookRepository) WithTx(tx *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{tx}
+}
+
+func (r *WebhookRepository) Create(webhook *models.Webhook) error {
+	return r.db.Create(webhook).Error
+}
+
+func (r *WebhookRepository) FindByUserID(userID uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.Where("user_id = ?", userID).Order("id ASC").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *WebhookRepository) FindByIDAndUserID(id, userID uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := r.db.Where("user_id = ?", userID).First(&webhook, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &webhook, err
+}
+
+func (r *WebhookRepository) FindByIDs(ids []uint) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if len(ids) == 0 {
+		return webhooks, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&webhooks).Error
+	return webhooks, err
+}
+
+// FindSubscribed returns the user's active webhooks subscribed to event.
+func (r *WebhookRepository) FindSubscribed(userID uint, event string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := r.db.
+		Where("user_id = ? AND active = ? AND ? = ANY(string_to_array(events, ','))", userID, true, event).
+		Order("id ASC").
+		Find(&webhooks).Error
+
+	return webhooks, err
+}
+
+func (r *WebhookRepository) Update(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&models.Webhook{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// Delete removes a webhook and drops its undelivered events.
+func (r *WebhookRepository) Delete(webhook *models.Webhook) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ? AND status = ?", webhook.ID, models.WebhookDeliveryPending).
+			Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(webhook).Error
+	})
+}
+
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.Create(&deliveries).Error
+}
+
+// FindDeliveries pages through a webhook's deliveries, newest first.
+// status may be empty to include every status.
+func (r *WebhookRepository) FindDeliveries(webhookID uint, status string, beforeID uint, limit int) ([]models.WebhookDelivery, error) {
+	query := r.db.Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *WebhookRepository) FindDelivery(id, webhookID uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := r.db.Where("webhook_id = ?", webhookID).First(&delivery, id).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRecordNotFound
+	}
+
+	return &delivery, err
+}
+
+// ClaimDueDeliveries leases up to limit pending deliveries of active
+// webhooks whose next attempt is due, by pushing their next attempt to
+// leaseUntil. A worker that dies mid-delivery thus only delays them.
+func (r *WebhookRepository) ClaimDueDeliveries(now, leaseUntil time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db.Raw(`
+		UPDATE webhook_deliveries
+		SET next_attempt_at = @lease, updated_at = @now
+		WHERE id IN (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = @pending
+				AND d.next_attempt_at <= @now
+				AND w.active
+				AND w.deleted_at IS NULL
+			ORDER BY d.next_attempt_at ASC
+			LIMIT @limit
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		RETURNING *`,
+		map[string]interface{}{
+			"lease":   leaseUntil,
+			"now":     now,
+			"pending": models.WebhookDeliveryPending,
+			"limit":   limit,
+		}).Scan(&deliveries).Error
+
+	return deliveries, err
+}
+
+// RecordSuccess stores a successful attempt and resets the webhook's
+// failure counter.
+func (r *WebhookRepository) RecordSuccess(delivery *models.WebhookDelivery) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(delivery).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Webhook{}).
+			Where("id = ?", delivery.WebhookID).
+			Update("consecutive_failures", 0).Error
+	})
+}
+
+// RecordFailure stores a failed attempt and counts it against the
+// webhook, disabling the webhook once disableAfter attempts in a row have
+// failed. It reports whether the webhook was disabled.
+func (r *WebhookRepository) RecordFailure(delivery *models.WebhookDelivery, disableAfter int, reason string) (bool, error) {
+	disabled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(delivery).Error; err != nil {
+			return err
+		}
+
+		var failures int
+		if err := tx.Raw(
+			"UPDATE webhooks SET consecutive_failures = consecutive_failures + 1 WHERE id = ? RETURNING consecutive_failures",
+			delivery.WebhookID,
+		).Scan(&failures).Error; err != nil {
+			return err
+		}
+		if disableAfter <= 0 || failures < disableAfter {
+			return nil
+		}
+
+		result := tx.Model(&models.Webhook{}).
+			Where("id = ? AND active = ?", delivery.WebhookID, true).
+			Updates(map[string]interface{}{
+				"active":          false,
+				"disabled_at":     time.Now(),
+				"disabled_reason": reason,
+			})
+		disabled = result.RowsAffected > 0
+		return result.Error
+	})
+
+	return disabled, err
+}
@@ -60,12 +60,30 @@ var (
 		Status:  http.StatusConflict,
 	}
 
+	PreconditionFailed = ErrorCode{
+		Code:    "precondition_failed",
+		Message: "The resource was modified by someone else. Reload it and try again.",
+		Status:  http.StatusPreconditionFailed,
+	}
+
+	RequestInProgress = ErrorCode{
+		Code:    "request_in_progress",
+		Message: "A request with the same idempotency key is still being processed. Please retry shortly.",
+		Status:  http.StatusConflict,
+	}
+
 	TooManyRequests = ErrorCode{
 		Code:    "too_many_requests",
 		Message: "Too many requests. Please try again later.",
 		Status:  http.StatusTooManyRequests,
 	}
 
+	RequestTooLarge = ErrorCode{
+		Code:    "request_too_large",
+		Message: "The request body exceeds the maximum allowed size",
+		Status:  http.StatusRequestEntityTooLarge,
+	}
+
 	// ======================
 	// Authentication Errors
 	// ======================
@@ -87,6 +105,18 @@ var (
 		Status:  http.StatusUnauthorized,
 	}
 
+	AccountDisabled = ErrorCode{
+		Code:    "account_disabled",
+		Message: "This account has been disabled",
+		Status:  http.StatusForbidden,
+	}
+
+	AccountLocked = ErrorCode{
+		Code:    "account_locked",
+		Message: "Too many failed logins. Please try again later.",
+		Status:  http.StatusTooManyRequests,
+	}
+
 	// ======================
 	// User Related Errors
 	// ======================
@@ -116,4 +146,25 @@ var (
 		Message: "The requested record was not found in the database",
 		Status:  http.StatusNotFound,
 	}
+
+	// ======================
+	// Attachment Errors
+	// ======================
+	FileTooLarge = ErrorCode{
+		Code:    "file_too_large",
+		Message: "The uploaded file exceeds the maximum allowed size",
+		Status:  http.StatusRequestEntityTooLarge,
+	}
+
+	UnsupportedFileType = ErrorCode{
+		Code:    "unsupported_file_type",
+		Message: "The uploaded file type is not supported",
+		Status:  http.StatusUnsupportedMediaType,
+	}
+
+	InvalidSignedURL = ErrorCode{
+		Code:    "invalid_signed_url",
+		Message: "The download link is invalid or has expired",
+		Status:  http.StatusForbidden,
+	}
 )
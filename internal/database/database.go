@@ -23,5 +23,30 @@ func NewPostgresConnection(host, user, password, dbname, port string) (*gorm.DB,
 		return nil, fmt.Errorf("failed to auto migrate: %w", err)
 	}
 
+	// The security audit log only ever grows
+	for _, statement := range securityEventsAppendOnly {
+		if err := db.Exec(statement).Error; err != nil {
+			return nil, fmt.Errorf("failed to protect the security audit log: %w", err)
+		}
+	}
+
 	return db, nil
 }
+
+// securityEventsAppendOnly installs triggers that reject any update,
+// delete or truncate of security_events, whatever code issues it.
+var securityEventsAppendOnly = []string{
+	`CREATE OR REPLACE FUNCTION reject_security_event_change() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'security_events is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS security_events_append_only ON security_events`,
+	`CREATE TRIGGER security_events_append_only
+		BEFORE UPDATE OR DELETE ON security_events
+		FOR EACH ROW EXECUTE FUNCTION reject_security_event_change()`,
+	`DROP TRIGGER IF EXISTS security_events_no_truncate ON security_events`,
+	`CREATE TRIGGER security_events_no_truncate
+		BEFORE TRUNCATE ON security_events
+		FOR EACH STATEMENT EXECUTE FUNCTION reject_security_event_change()`,
+}
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// authorizeEntry checks that the user may take action on the entry and
+// returns the ID of its owner, which the services are then called with.
+// It writes the error response itself and reports false when denied.
+func authorizeEntry(c *gin.Context, policy *services.AccessPolicy, userID, entryID uint, action services.AccessAction) (uint, bool) {
+	ownerID, err := policy.AuthorizeEntry(userID, entryID, action)
+	if err != nil {
+		respondJournalError(c, err)
+		return 0, false
+	}
+	return ownerID, true
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// AdminHandler serves the admin API. Its routes sit behind
+// RequireRole(models.RoleAdmin).
+type AdminHandler struct {
+	service *services.AdminService
+}
+
+func NewAdminHandler(service *services.AdminService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+// Users lists accounts. ?q= searches the email and name, ?role= and
+// ?status= (active, disabled or locked) filter, and ?limit= and ?offset=
+// page through the results.
+func (h *AdminHandler) Users(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	users, err := h.service.Users(models.AdminUserQuery{
+		Search: c.Query("q"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}, limit, offset)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(users))
+}
+
+func (h *AdminHandler) User(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	user, err := h.service.User(id)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(user))
+}
+
+func (h *AdminHandler) Disable(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AdminDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	adminID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	user, err := h.service.Disable(adminID, id, req.Reason, requestInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(user))
+}
+
+func (h *AdminHandler) Enable(c *gin.Context) {
+	h.updateAccount(c, h.service.Enable)
+}
+
+func (h *AdminHandler) Unlock(c *gin.Context) {
+	h.updateAccount(c, h.service.Unlock)
+}
+
+// Logout ends every session of the account.
+func (h *AdminHandler) Logout(c *gin.Context) {
+	h.updateAccount(c, h.service.Logout)
+}
+
+func (h *AdminHandler) SetRole(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	adminID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	user, err := h.service.SetRole(adminID, id, req.Role, requestInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(user))
+}
+
+// SecurityEvents lists the audit log of every account, newest first.
+// ?user_id= and ?type= filter, and ?limit= and ?offset= page through it.
+func (h *AdminHandler) SecurityEvents(c *gin.Context) {
+	var query models.SecurityEventQuery
+	if value := c.Query("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+				apperrors.InvalidRequestData,
+				"user_id must be a number",
+			))
+			return
+		}
+		id := uint(userID)
+		query.UserID = &id
+	}
+	query.Type = c.Query("type")
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	events, err := h.service.SecurityEvents(query, limit, offset)
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(events))
+}
+
+func (h *AdminHandler) Stats(c *gin.Context) {
+	stats, err := h.service.Stats()
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(stats))
+}
+
+// updateAccount runs an account action that needs no request body.
+func (h *AdminHandler) updateAccount(c *gin.Context, action func(adminID, id uint, info models.RequestInfo) (*models.AdminUserResponse, error)) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	adminID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	user, err := action(adminID, id, requestInfo(c))
+	if err != nil {
+		respondAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(user))
+}
+
+func respondAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.UserNotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrCannotModifySelf):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(
+			apperrors.Conflict,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidUserFilter):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/media"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers on
+// top of the file itself when limiting the request body.
+const multipartOverhead = 1 << 20
+
+type AttachmentHandler struct {
+	service *services.AttachmentService
+	policy  *services.AccessPolicy
+}
+
+func NewAttachmentHandler(service *services.AttachmentService, policy *services.AccessPolicy) *AttachmentHandler {
+	return &AttachmentHandler{service: service, policy: policy}
+}
+
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	journalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, uint(journalID), services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.service.MaxFileSize()+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, helpers.ErrorResponse(
+				apperrors.FileTooLarge,
+				err.Error(),
+			))
+			return
+		}
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"multipart field \"file\" is required",
+		))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.service.Upload(c.Request.Context(), ownerID, uint(journalID), fileHeader.Filename, file)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(h.service.ToResponse(attachment)))
+}
+
+func (h *AttachmentHandler) List(c *gin.Context) {
+	journalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, uint(journalID), services.AccessRead)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.service.ListForEntry(ownerID, uint(journalID))
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	response := make([]interface{}, 0, len(attachments))
+	for i := range attachments {
+		response = append(response, h.service.ToResponse(&attachments[i]))
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(response))
+}
+
+func (h *AttachmentHandler) Get(c *gin.Context) {
+	journalID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessRead)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service.Get(ownerID, journalID, attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(h.service.ToResponse(attachment)))
+}
+
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	journalID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), ownerID, journalID, attachmentID); err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"attachment_id": attachmentID,
+	}))
+}
+
+// Download serves the file behind a signed URL. It is mounted outside the
+// auth middleware because the signature itself grants access. Range
+// requests are honoured so audio players can seek within voice memos.
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	attachmentID, ok := parseDownloadParam(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.service.OpenContent(
+		c.Request.Context(),
+		attachmentID,
+		c.Request.URL.Path,
+		c.Query("expires"),
+		c.Query("signature"),
+	)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", attachment.FileName))
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("ETag", fmt.Sprintf("%q", attachment.Checksum))
+	http.ServeContent(c.Writer, c.Request, attachment.FileName, attachment.CreatedAt, content)
+}
+
+func (h *AttachmentHandler) Thumbnail(c *gin.Context) {
+	attachmentID, ok := parseDownloadParam(c)
+	if !ok {
+		return
+	}
+
+	_, body, err := h.service.OpenThumbnail(
+		c.Request.Context(),
+		attachmentID,
+		c.Request.URL.Path,
+		c.Query("expires"),
+		c.Query("signature"),
+	)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+	defer body.Close()
+
+	c.Header("Content-Type", media.ThumbnailContentType)
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, body)
+}
+
+func (h *AttachmentHandler) Transcribe(c *gin.Context) {
+	journalID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	attachment, err := h.service.Transcribe(ownerID, journalID, attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, helpers.SuccessResponse(h.service.ToResponse(attachment)))
+}
+
+func (h *AttachmentHandler) UpdateTranscript(c *gin.Context) {
+	journalID, attachmentID, ok := parseAttachmentParams(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateTranscriptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	attachment, err := h.service.UpdateTranscript(ownerID, journalID, attachmentID, req.Transcript)
+	if err != nil {
+		respondAttachmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(h.service.ToResponse(attachment)))
+}
+
+func parseDownloadParam(c *gin.Context) (uint, bool) {
+	attachmentID, err := strconv.ParseUint(c.Param("attachmentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid attachment id",
+		))
+		return 0, false
+	}
+	return uint(attachmentID), true
+}
+
+func parseAttachmentParams(c *gin.Context) (uint, uint, bool) {
+	journalID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return 0, 0, false
+	}
+
+	attachmentID, err := strconv.ParseUint(c.Param("attachmentId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid attachment id",
+		))
+		return 0, 0, false
+	}
+
+	return uint(journalID), uint(attachmentID), true
+}
+
+func respondAttachmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJournalNotFound), errors.Is(err, services.ErrAttachmentNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, helpers.ErrorResponse(
+			apperrors.FileTooLarge,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrNotAudioAttachment), errors.Is(err, services.ErrImageTooLarge):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrUnsupportedFileType):
+		c.JSON(http.StatusUnsupportedMediaType, helpers.ErrorResponse(
+			apperrors.UnsupportedFileType,
+			err.Error(),
+		))
+	case errors.Is(err, helpers.ErrInvalidSignedURL):
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(
+			apperrors.InvalidSignedURL,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type BatchHandler struct {
+	service *services.BatchService
+}
+
+func NewBatchHandler(service *services.BatchService) *BatchHandler {
+	return &BatchHandler{service: service}
+}
+
+func (h *BatchHandler) Execute(c *gin.Context) {
+	var req models.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BatchModeAtomic
+	}
+
+	ops := make([]services.BatchOperation, len(req.Operations))
+	for i, opReq := range req.Operations {
+		ops[i] = decodeBatchOperation(opReq)
+	}
+
+	outcomes, committed := h.service.Execute(c.Request.Context(), userID, req.Mode, ops)
+
+	results := make([]models.BatchOperationResult, len(outcomes))
+	for i, outcome := range outcomes {
+		results[i] = models.BatchOperationResult{
+			Index:   i,
+			Status:  outcome.Status,
+			ID:      outcome.ID,
+			Version: outcome.Version,
+		}
+		if outcome.Err != nil {
+			code := batchErrorCode(outcome.Err)
+			results[i].ErrorCode = code.Code
+			results[i].Message = outcome.Err.Error()
+		}
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(models.BatchResponse{
+		Mode:      req.Mode,
+		Committed: committed,
+		Results:   results,
+	}))
+}
+
+// decodeBatchOperation parses and validates the payload of one operation
+// the same way the single-item endpoints bind their request bodies.
+func decodeBatchOperation(req models.BatchOperationRequest) services.BatchOperation {
+	op := services.BatchOperation{
+		Action:          req.Action,
+		Entity:          req.Entity,
+		ID:              req.ID,
+		JournalEntryID:  req.JournalEntryID,
+		ExpectedVersion: req.Version,
+	}
+
+	needsID := req.Action != models.BatchActionCreate
+	if needsID && req.ID == 0 {
+		op.Invalid = invalidBatchOperation("id is required")
+		return op
+	}
+
+	var payload interface{}
+	switch {
+	case req.Entity == models.BatchEntityJournalEntry && req.Action == models.BatchActionCreate:
+		op.Entry = &models.CreateJournalRequest{}
+		payload = op.Entry
+	case req.Entity == models.BatchEntityJournalEntry && req.Action == models.BatchActionUpdate:
+		op.EntryUpdate = &models.UpdateJournalRequest{}
+		payload = op.EntryUpdate
+	case req.Entity == models.BatchEntityDailyTask && req.Action == models.BatchActionCreate:
+		if req.JournalEntryID == 0 {
+			op.Invalid = invalidBatchOperation("journal_entry_id is required")
+			return op
+		}
+		op.Task = &models.DailyTaskRequest{}
+		payload = op.Task
+	case req.Entity == models.BatchEntityDailyTask && req.Action == models.BatchActionUpdate:
+		op.TaskPatch = &models.PatchDailyTaskRequest{}
+		payload = op.TaskPatch
+	default:
+		// Deletes carry no payload
+		return op
+	}
+
+	if len(req.Data) == 0 {
+		op.Invalid = invalidBatchOperation("data is required")
+		return op
+	}
+	if err := json.Unmarshal(req.Data, payload); err != nil {
+		op.Invalid = invalidBatchOperation(err.Error())
+		return op
+	}
+	if err := binding.Validator.ValidateStruct(payload); err != nil {
+		op.Invalid = invalidBatchOperation(err.Error())
+	}
+	return op
+}
+
+func invalidBatchOperation(reason string) error {
+	return fmt.Errorf("%w: %s", services.ErrInvalidBatchOperation, reason)
+}
+
+func batchErrorCode(err error) apperrors.ErrorCode {
+	if errors.Is(err, services.ErrInvalidBatchOperation) {
+		return apperrors.InvalidRequestData
+	}
+	return journalErrorCode(err)
+}
@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type CommentHandler struct {
+	service *services.CommentService
+}
+
+func NewCommentHandler(service *services.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+func (h *CommentHandler) List(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	comments, err := h.service.List(userID, entryID)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(comments))
+}
+
+func (h *CommentHandler) Create(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	comment, err := h.service.Create(userID, entryID, req)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(comment))
+}
+
+func (h *CommentHandler) Update(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseUintParam(c, "commentId")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	comment, err := h.service.Update(userID, entryID, commentID, req.Body)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(comment))
+}
+
+func (h *CommentHandler) Delete(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseUintParam(c, "commentId")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Delete(userID, entryID, commentID); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateSettings opens or closes the entry to new comments. Only the owner
+// may change it.
+func (h *CommentHandler) UpdateSettings(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CommentSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.SetCommentsDisabled(userID, entryID, *req.CommentsDisabled); err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"journal_id":        entryID,
+		"comments_disabled": *req.CommentsDisabled,
+	}))
+}
+
+func (h *CommentHandler) Reactions(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	reactions, err := h.service.Reactions(userID, entryID)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(reactions))
+}
+
+func (h *CommentHandler) React(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	reactions, err := h.service.React(userID, entryID, c.Param("kind"))
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(reactions))
+}
+
+func (h *CommentHandler) Unreact(c *gin.Context) {
+	entryID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	reactions, err := h.service.Unreact(userID, entryID, c.Param("kind"))
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(reactions))
+}
+
+func respondCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJournalNotFound),
+		errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrCommentsDisabled):
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(
+			apperrors.Forbidden,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrEmptyComment),
+		errors.Is(err, services.ErrInvalidReaction):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
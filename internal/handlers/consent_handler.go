@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type ConsentHandler struct {
+	service *services.ConsentService
+}
+
+func NewConsentHandler(service *services.ConsentService) *ConsentHandler {
+	return &ConsentHandler{service: service}
+}
+
+// Request asks a client to share their journal with the practitioner.
+func (h *ConsentHandler) Request(c *gin.Context) {
+	orgID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consent, err := h.service.Request(c.Request.Context(), userID, orgID, req)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(consent))
+}
+
+func (h *ConsentHandler) Requests(c *gin.Context) {
+	orgID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consents, err := h.service.Requests(userID, orgID)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(consents))
+}
+
+// Dashboard lists the consenting clients with their mood trends over the
+// last ?weeks= weeks.
+func (h *ConsentHandler) Dashboard(c *gin.Context) {
+	orgID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	weeks, _ := strconv.Atoi(c.Query("weeks"))
+
+	dashboard, err := h.service.Dashboard(userID, orgID, weeks)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(dashboard))
+}
+
+// ClientEntries lists the entries a client shares. ?limit= and ?offset=
+// page through them.
+func (h *ConsentHandler) ClientEntries(c *gin.Context) {
+	orgID, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+	clientID, ok := parseUintParam(c, "clientId")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.service.ClientEntries(userID, orgID, clientID, limit, offset)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entries))
+}
+
+// Mine lists the consent requests and consents of the signed-in client.
+func (h *ConsentHandler) Mine(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consents, err := h.service.Mine(userID)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(consents))
+}
+
+// Grant consents to a request, or changes the shared notebooks.
+func (h *ConsentHandler) Grant(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ConsentNotebooksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consent, err := h.service.Grant(userID, id, req.NotebookIDs)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(consent))
+}
+
+func (h *ConsentHandler) Decline(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consent, err := h.service.Decline(userID, id)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(consent))
+}
+
+func (h *ConsentHandler) Revoke(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	consent, err := h.service.Revoke(userID, id)
+	if err != nil {
+		respondOrganizationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(consent))
+}
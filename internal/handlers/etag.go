@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// versionETag renders a record version as a strong entity tag.
+func versionETag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// parseIfMatch returns the version the client expects to overwrite, or 0
+// when the request carries no If-Match header (or "*"). A header that
+// cannot match any version answers 412 and returns ok == false.
+func parseIfMatch(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+
+	// Only a single tag is meaningful for a versioned record
+	tag := strings.TrimSpace(strings.Split(header, ",")[0])
+	tag = strings.TrimPrefix(tag, "W/")
+
+	version, err := strconv.Atoi(strings.Trim(tag, `"`))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusPreconditionFailed, helpers.ErrorResponse(
+			apperrors.PreconditionFailed,
+			"If-Match does not match the current version",
+		))
+		return 0, false
+	}
+
+	return version, true
+}
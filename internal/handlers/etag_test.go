@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+func TestVersionETag(t *testing.T) {
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{version: 1, want: `"1"`},
+		{version: 42, want: `"42"`},
+	}
+
+	for _, tt := range tests {
+		if got := versionETag(tt.version); got != tt.want {
+			t.Errorf("versionETag(%d) = %s, want %s", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      string
+		wantVersion int
+		wantOK      bool
+	}{
+		{name: "no header", header: "", wantVersion: 0, wantOK: true},
+		{name: "any version", header: "*", wantVersion: 0, wantOK: true},
+		{name: "strong tag", header: `"3"`, wantVersion: 3, wantOK: true},
+		{name: "weak tag", header: `W/"3"`, wantVersion: 3, wantOK: true},
+		{name: "surrounding spaces", header: `  "7"  `, wantVersion: 7, wantOK: true},
+		{name: "first of several tags", header: `"4", "5"`, wantVersion: 4, wantOK: true},
+		{name: "unquoted", header: `12`, wantVersion: 12, wantOK: true},
+		{name: "zero version", header: `"0"`},
+		{name: "negative version", header: `"-1"`},
+		{name: "not a version", header: `"abc123"`},
+		{name: "attachment checksum", header: `"9f86d081884c7d659a2feaa0c55ad015"`},
+		{name: "empty tag", header: `""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPut, "/journals/1", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("If-Match", tt.header)
+			}
+
+			version, ok := parseIfMatch(c)
+			if ok != tt.wantOK || version != tt.wantVersion {
+				t.Fatalf("parseIfMatch(%q) = %d, %v, want %d, %v", tt.header, version, ok, tt.wantVersion, tt.wantOK)
+			}
+			if !tt.wantOK && w.Code != http.StatusPreconditionFailed {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusPreconditionFailed)
+			}
+			if tt.wantOK && w.Body.Len() != 0 {
+				t.Errorf("parseIfMatch(%q) wrote a response: %s", tt.header, w.Body)
+			}
+		})
+	}
+}
+
+func TestParseIfMatchRoundTripsETag(t *testing.T) {
+	for _, version := range []int{1, 2, 100} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/journals/1", nil)
+		c.Request.Header.Set("If-Match", versionETag(version))
+
+		if got, ok := parseIfMatch(c); !ok || got != version {
+			t.Errorf("parseIfMatch(versionETag(%d)) = %d, %v", version, got, ok)
+		}
+	}
+}
+
+func TestJournalErrorCodeVersionMismatch(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "version mismatch", err: services.ErrVersionMismatch},
+		{name: "wrapped version mismatch", err: fmt.Errorf("operation 2: %w", services.ErrVersionMismatch)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := journalErrorCode(tt.err); got.Status != http.StatusPreconditionFailed {
+				t.Errorf("journalErrorCode(%v) status = %d, want %d", tt.err, got.Status, http.StatusPreconditionFailed)
+			}
+		})
+	}
+}
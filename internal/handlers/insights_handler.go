@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// defaultInsightsDays is the range covered when the client gives none.
+const defaultInsightsDays = 90
+
+type InsightsHandler struct {
+	service *services.InsightsService
+}
+
+func NewInsightsHandler(service *services.InsightsService) *InsightsHandler {
+	return &InsightsHandler{service: service}
+}
+
+// Habits returns habit statistics for ?from= to ?to= (default: the last
+// 90 days). ?derive_from_sub_tasks=true treats a task with subtasks as
+// done only when all of them are.
+func (h *InsightsHandler) Habits(c *gin.Context) {
+	var ok bool
+
+	to := time.Now()
+	if value := c.Query("to"); value != "" {
+		if to, ok = parseDate(c, value, "to"); !ok {
+			return
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultInsightsDays - 1))
+	if value := c.Query("from"); value != "" {
+		if from, ok = parseDate(c, value, "from"); !ok {
+			return
+		}
+	}
+
+	derive := false
+	if value := c.Query("derive_from_sub_tasks"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+				apperrors.InvalidRequestData,
+				"invalid derive_from_sub_tasks",
+			))
+			return
+		}
+		derive = parsed
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	insights, err := h.service.Habits(userID, from, to, derive)
+	if errors.Is(err, services.ErrInvalidDateRange) {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+	if errors.Is(err, services.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.UserNotFound,
+			err.Error(),
+		))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(insights))
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -15,15 +16,16 @@ import (
 
 type JournalHandler struct {
 	service *services.JournalService
+	policy  *services.AccessPolicy
 }
 
-func NewJournalHandler(service *services.JournalService) *JournalHandler {
-	return &JournalHandler{service: service}
+func NewJournalHandler(service *services.JournalService, policy *services.AccessPolicy) *JournalHandler {
+	return &JournalHandler{service: service, policy: policy}
 }
 
 func (h *JournalHandler) CreateEntry(c *gin.Context) {
-	var entry models.JournalEntry
-	if err := c.ShouldBindJSON(&entry); err != nil {
+	var req models.CreateJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
 			apperrors.InvalidRequestData,
 			err.Error(),
@@ -31,55 +33,251 @@ func (h *JournalHandler) CreateEntry(c *gin.Context) {
 		return
 	}
 
-	// Validate mood
-	if entry.Mood == enums.Mood.Unknown {
+	// Validate mood, drafts may be saved before one is picked
+	if req.Mood == enums.Mood.Unknown && req.Status != enums.EntryStatus.Draft {
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
 			apperrors.InvalidRequestData,
-			fmt.Sprintf("%s is invalid mood.", entry.Mood),
+			fmt.Sprintf("%s is invalid mood.", req.Mood),
 		))
+		return
 	}
 
 	userID, err := helpers.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, helpers.ErrorResponse(
-			apperrors.Unauthorized,
-			err.Error(),
-		))
+		return
 	}
 
-	entry.UserID = userID
-
+	entry := req.ToJournalEntry(userID)
 	journalID, err := h.service.CreateEntry(&entry)
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
-			apperrors.InternalServerError,
-			err.Error(),
-		))
+		respondJournalError(c, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(entry.Version))
 	c.JSON(http.StatusCreated, helpers.SuccessResponse(map[string]interface{}{
 		"journal_id": journalID,
+		"version":    entry.Version,
 	}))
 }
 
 func (h *JournalHandler) GetEntry(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, uint(id), services.AccessRead)
+	if !ok {
+		return
+	}
+
+	// Routines are only materialized when the owner opens the entry
+	var entry *models.JournalEntry
+	if ownerID == userID {
+		entry, err = h.service.OpenEntry(c.Request.Context(), ownerID, uint(id))
+	} else {
+		entry, err = h.service.GetEntry(c.Request.Context(), ownerID, uint(id))
+	}
+
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	etag := versionETag(entry.Version)
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entry))
+}
+
+func (h *JournalHandler) UpdateEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	var req models.UpdateJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
 		return
 	}
 
-	entry, err := h.service.GetEntry(c.Request.Context(), uint(id))
+	ownerID, ok := authorizeEntry(c, h.policy, userID, uint(id), services.AccessEdit)
+	if !ok {
+		return
+	}
 
+	entry, err := h.service.UpdateEntry(c.Request.Context(), ownerID, uint(id), expectedVersion, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
-			apperrors.InternalServerError,
+		respondJournalError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(entry.Version))
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entry))
+}
+
+func (h *JournalHandler) Autosave(c *gin.Context) {
+	id, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AutosaveJournalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
 			err.Error(),
 		))
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, id, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Autosave(c.Request.Context(), ownerID, id, expectedVersion, req)
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(response.Version))
+	c.JSON(http.StatusOK, helpers.SuccessResponse(response))
+}
+
+func (h *JournalHandler) Publish(c *gin.Context) {
+	id, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, id, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.Publish(c.Request.Context(), ownerID, id, expectedVersion)
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(entry.Version))
 	c.JSON(http.StatusOK, helpers.SuccessResponse(entry))
 }
+
+func (h *JournalHandler) DeleteEntry(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, uint(id), services.AccessManage)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEntry(c.Request.Context(), ownerID, uint(id)); err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"journal_id": id,
+	}))
+}
+
+func respondJournalError(c *gin.Context, err error) {
+	code := journalErrorCode(err)
+	c.JSON(code.Status, helpers.ErrorResponse(code, err.Error()))
+}
+
+// journalErrorCode maps journal and task service errors to API errors.
+func journalErrorCode(err error) apperrors.ErrorCode {
+	switch {
+	case errors.Is(err, services.ErrJournalNotFound),
+		errors.Is(err, services.ErrTaskNotFound):
+		return apperrors.NotFound
+	case errors.Is(err, services.ErrForbidden):
+		return apperrors.Forbidden
+	case errors.Is(err, services.ErrVersionMismatch):
+		return apperrors.PreconditionFailed
+	case errors.Is(err, services.ErrEntryIncomplete),
+		errors.Is(err, services.ErrNotebookNotFound),
+		errors.Is(err, services.ErrNotebookArchived),
+		errors.Is(err, services.ErrPromptNotFound),
+		errors.Is(err, services.ErrTemplateNotFound),
+		errors.Is(err, services.ErrInvalidTemplateValues),
+		errors.Is(err, services.ErrInvalidTaskOrder),
+		errors.Is(err, services.ErrNothingToCarryOver),
+		errors.Is(err, services.ErrInvalidDateRange):
+		return apperrors.InvalidRequestData
+	case errors.Is(err, services.ErrUserNotFound):
+		return apperrors.UserNotFound
+	default:
+		return apperrors.InternalServerError
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type MemoryHandler struct {
+	service *services.MemoryService
+}
+
+func NewMemoryHandler(service *services.MemoryService) *MemoryHandler {
+	return &MemoryHandler{service: service}
+}
+
+// OnThisDay returns entries from the same day in earlier years. ?date=
+// picks another day than today; ?include=week,month,six_months adds those
+// look-backs.
+func (h *MemoryHandler) OnThisDay(c *gin.Context) {
+	var day time.Time
+	if value := c.Query("date"); value != "" {
+		var ok bool
+		if day, ok = parseDate(c, value, "date"); !ok {
+			return
+		}
+	}
+
+	var periods []string
+	if value := c.Query("include"); value != "" {
+		for _, period := range strings.Split(value, ",") {
+			switch period = strings.TrimSpace(period); period {
+			case models.MemoryPeriodWeek, models.MemoryPeriodMonth, models.MemoryPeriodSixMonths:
+				periods = append(periods, period)
+			default:
+				c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+					apperrors.InvalidRequestData,
+					"include must list week, month or six_months",
+				))
+				return
+			}
+		}
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	memories, err := h.service.OnThisDay(userID, day, periods)
+	if errors.Is(err, services.ErrUserNotFound) {
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.UserNotFound,
+			err.Error(),
+		))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(memories))
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type NotebookHandler struct {
+	service *services.NotebookService
+}
+
+func NewNotebookHandler(service *services.NotebookService) *NotebookHandler {
+	return &NotebookHandler{service: service}
+}
+
+// List returns the user's notebooks; ?include_archived=true adds the
+// archived ones.
+func (h *NotebookHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	includeArchived, _ := strconv.ParseBool(c.Query("include_archived"))
+	notebooks, err := h.service.List(userID, includeArchived)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(notebooks))
+}
+
+func (h *NotebookHandler) Get(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	notebook, err := h.service.Get(userID, id)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(notebook))
+}
+
+func (h *NotebookHandler) Create(c *gin.Context) {
+	var req models.NotebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	notebook, err := h.service.Create(userID, req)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(notebook))
+}
+
+func (h *NotebookHandler) Update(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.NotebookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	notebook, err := h.service.Update(userID, id, req)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(notebook))
+}
+
+// Delete removes a notebook. Its entries move to the default notebook.
+func (h *NotebookHandler) Delete(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Delete(userID, id); err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"notebook_id": id,
+	}))
+}
+
+// Entries lists the notebook's entries, newest first. ?q= searches the
+// entry text and tasks, ?from= and ?to= (inclusive) bound the date, and
+// ?limit= and ?offset= page through the results.
+func (h *NotebookHandler) Entries(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var from, to *time.Time
+	if value := c.Query("from"); value != "" {
+		day, ok := parseDate(c, value, "from")
+		if !ok {
+			return
+		}
+		from = &day
+	}
+	if value := c.Query("to"); value != "" {
+		day, ok := parseDate(c, value, "to")
+		if !ok {
+			return
+		}
+		day = day.AddDate(0, 0, 1)
+		to = &day
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	entries, err := h.service.Entries(userID, id, c.Query("q"), from, to, limit, offset)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entries))
+}
+
+// MoveEntries moves the listed entries into the notebook.
+func (h *NotebookHandler) MoveEntries(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.MoveEntriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	moved, err := h.service.MoveEntries(userID, id, req.EntryIDs)
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(moved))
+}
+
+// Export downloads the notebook as ?format=json (default) or markdown.
+func (h *NotebookHandler) Export(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "markdown" {
+		respondNotebookError(c, services.ErrInvalidExportFormat)
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	export, err := h.service.Export(userID, id, format, requestInfo(c))
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("notebook-%d-%s", id, export.ExportedAt.Format("2006-01-02"))
+	if format == "markdown" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".md"))
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", services.NotebookMarkdown(export))
+		return
+	}
+
+	body, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		respondNotebookError(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".json"))
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+func respondNotebookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNotebookNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrNotebookArchived),
+		errors.Is(err, services.ErrDefaultNotebook):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(
+			apperrors.Conflict,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidExportFormat),
+		errors.Is(err, services.ErrInvalidDateRange):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
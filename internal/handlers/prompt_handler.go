@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type PromptHandler struct {
+	service *services.PromptService
+}
+
+func NewPromptHandler(service *services.PromptService) *PromptHandler {
+	return &PromptHandler{service: service}
+}
+
+// List returns the library and the user's own prompts, optionally
+// filtered by ?theme= and ?mood=.
+func (h *PromptHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	prompts, err := h.service.List(userID, c.Query("theme"), enums.ParseMood(c.Query("mood")))
+	if err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(prompts))
+}
+
+func (h *PromptHandler) Create(c *gin.Context) {
+	var req models.PromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	prompt, err := h.service.Create(userID, req)
+	if err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(prompt))
+}
+
+func (h *PromptHandler) Update(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.PromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	prompt, err := h.service.Update(userID, id, req)
+	if err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(prompt))
+}
+
+func (h *PromptHandler) Delete(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Delete(userID, id); err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"prompt_id": id,
+	}))
+}
+
+// Daily returns the user's prompt of the day.
+func (h *PromptHandler) Daily(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	daily, err := h.service.Daily(userID)
+	if err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(daily))
+}
+
+// Entries lists the user's reflections that answered the prompt.
+func (h *PromptHandler) Entries(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	answers, err := h.service.Answers(userID, id)
+	if err != nil {
+		respondPromptError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(answers))
+}
+
+func respondPromptError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPromptNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.UserNotFound,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type PushHandler struct {
+	service *services.PushService
+}
+
+func NewPushHandler(service *services.PushService) *PushHandler {
+	return &PushHandler{service: service}
+}
+
+// Config returns the VAPID public key browsers subscribe with.
+func (h *PushHandler) Config(c *gin.Context) {
+	config, err := h.service.Config()
+	if err != nil {
+		respondPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(config))
+}
+
+func (h *PushHandler) Subscribe(c *gin.Context) {
+	var req models.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	subscription, err := h.service.Subscribe(userID, req, c.Request.UserAgent())
+	if err != nil {
+		respondPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(subscription))
+}
+
+func (h *PushHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	subscriptions, err := h.service.List(userID)
+	if err != nil {
+		respondPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(subscriptions))
+}
+
+func (h *PushHandler) Unsubscribe(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Unsubscribe(userID, uint(id)); err != nil {
+		respondPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"subscription_id": id,
+	}))
+}
+
+func respondPushError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrPushNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, helpers.ErrorResponse(
+			apperrors.ServiceUnavailable,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidPushSubscription), errors.Is(err, services.ErrUnsafePushEndpoint):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrPushSubscriptionNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type ReaderHandler struct {
+	service *services.ReaderService
+}
+
+func NewReaderHandler(service *services.ReaderService) *ReaderHandler {
+	return &ReaderHandler{service: service}
+}
+
+func (h *ReaderHandler) Invite(c *gin.Context) {
+	var req models.ReaderInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	grant, err := h.service.Invite(c.Request.Context(), userID, req)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(grant))
+}
+
+func (h *ReaderHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	grants, err := h.service.List(userID)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(grants))
+}
+
+func (h *ReaderHandler) UpdateRole(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.ReaderRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	grant, err := h.service.UpdateRole(userID, id, req.Role)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(grant))
+}
+
+func (h *ReaderHandler) Revoke(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Revoke(userID, id); err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Activity returns the audit trail of what the reader looked at.
+func (h *ReaderHandler) Activity(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	activity, err := h.service.Activity(userID, id)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(activity))
+}
+
+func (h *ReaderHandler) Invitations(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	invitations, err := h.service.Invitations(userID)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(invitations))
+}
+
+func (h *ReaderHandler) Accept(c *gin.Context) {
+	h.respond(c, true)
+}
+
+func (h *ReaderHandler) Decline(c *gin.Context) {
+	h.respond(c, false)
+}
+
+func (h *ReaderHandler) respond(c *gin.Context, accept bool) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	grant, err := h.service.Respond(userID, id, accept)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(grant))
+}
+
+func (h *ReaderHandler) SharedWithMe(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	grants, err := h.service.SharedWithMe(userID)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(grants))
+}
+
+func (h *ReaderHandler) Leave(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Leave(userID, id); err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Entries lists the entries a grant covers. ?q= searches their text and
+// ?limit= and ?offset= page through the results. Single entries are then
+// read through the regular journal endpoints.
+func (h *ReaderHandler) Entries(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.service.Entries(userID, id, c.Query("q"), limit, offset)
+	if err != nil {
+		respondReaderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entries))
+}
+
+func respondReaderError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrGrantNotFound),
+		errors.Is(err, services.ErrInvitationNotFound),
+		errors.Is(err, services.ErrNotebookNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrGrantExists):
+		c.JSON(http.StatusConflict, helpers.ErrorResponse(
+			apperrors.Conflict,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidInvitation):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.UserNotFound,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type RevisionHandler struct {
+	service *services.RevisionService
+	policy  *services.AccessPolicy
+}
+
+func NewRevisionHandler(service *services.RevisionService, policy *services.AccessPolicy) *RevisionHandler {
+	return &RevisionHandler{service: service, policy: policy}
+}
+
+func (h *RevisionHandler) List(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessRead)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.service.List(ownerID, journalID)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(revisions))
+}
+
+func (h *RevisionHandler) Get(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := parseRevision(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessRead)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.Get(ownerID, journalID, revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(response))
+}
+
+// Diff compares the revisions given by the "from" and "to" query params.
+func (h *RevisionHandler) Diff(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	from, ok := parseRevision(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := parseRevision(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessRead)
+	if !ok {
+		return
+	}
+
+	diff, err := h.service.Diff(ownerID, journalID, from, to)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(diff))
+}
+
+func (h *RevisionHandler) Restore(c *gin.Context) {
+	journalID, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := parseRevision(c, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, journalID, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	entry, err := h.service.Restore(ownerID, journalID, revision)
+	if err != nil {
+		respondRevisionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entry))
+}
+
+func parseJournalID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func parseRevision(c *gin.Context, value string) (int, bool) {
+	revision, err := strconv.Atoi(value)
+	if err != nil || revision <= 0 {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid revision",
+		))
+		return 0, false
+	}
+	return revision, true
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrJournalNotFound), errors.Is(err, services.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// dateLayout is the format of calendar dates in paths and query strings.
+const dateLayout = "2006-01-02"
+
+// defaultOccurrenceDays is how far ahead occurrences are listed when the
+// client gives no range.
+const defaultOccurrenceDays = 30
+
+type RoutineHandler struct {
+	service *services.RoutineService
+}
+
+func NewRoutineHandler(service *services.RoutineService) *RoutineHandler {
+	return &RoutineHandler{service: service}
+}
+
+func (h *RoutineHandler) Create(c *gin.Context) {
+	var req models.RoutineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	routine, err := h.service.Create(userID, req)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(routine))
+}
+
+func (h *RoutineHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	routines, err := h.service.List(userID)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(routines))
+}
+
+func (h *RoutineHandler) Get(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	routine, err := h.service.Get(userID, id)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(routine))
+}
+
+func (h *RoutineHandler) Update(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RoutineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	routine, err := h.service.Update(userID, id, req)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(routine))
+}
+
+func (h *RoutineHandler) Delete(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.Delete(userID, id); err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"routine_id": id,
+	}))
+}
+
+// Occurrences lists upcoming occurrences, from ?from= (default today) to
+// ?to= (default 30 days later).
+func (h *RoutineHandler) Occurrences(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	from := time.Now()
+	if value := c.Query("from"); value != "" {
+		if from, ok = parseDate(c, value, "from"); !ok {
+			return
+		}
+	}
+
+	to := from.AddDate(0, 0, defaultOccurrenceDays)
+	if value := c.Query("to"); value != "" {
+		if to, ok = parseDate(c, value, "to"); !ok {
+			return
+		}
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	occurrences, err := h.service.Occurrences(userID, id, from, to)
+	if err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(occurrences))
+}
+
+func (h *RoutineHandler) SetOccurrence(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	date, ok := parseDate(c, c.Param("date"), "date")
+	if !ok {
+		return
+	}
+
+	var req models.RoutineOccurrenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.SetOccurrence(userID, id, date, req); err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"routine_id": id,
+		"date":       date.Format(dateLayout),
+	}))
+}
+
+func (h *RoutineHandler) ClearOccurrence(c *gin.Context) {
+	id, ok := parseRoutineID(c)
+	if !ok {
+		return
+	}
+
+	date, ok := parseDate(c, c.Param("date"), "date")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	if err := h.service.ClearOccurrence(userID, id, date); err != nil {
+		respondRoutineError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(map[string]interface{}{
+		"routine_id": id,
+		"date":       date.Format(dateLayout),
+	}))
+}
+
+func parseRoutineID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			"invalid id",
+		))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func parseDate(c *gin.Context, value, name string) (time.Time, bool) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			fmt.Sprintf("invalid %s, expected YYYY-MM-DD", name),
+		))
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func respondRoutineError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrRoutineNotFound), errors.Is(err, services.ErrNotAnOccurrence):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidRecurrence), errors.Is(err, services.ErrInvalidDateRange):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type SecurityEventHandler struct {
+	service *services.SecurityEventService
+}
+
+func NewSecurityEventHandler(service *services.SecurityEventService) *SecurityEventHandler {
+	return &SecurityEventHandler{service: service}
+}
+
+// Mine lists the user's own security events, newest first. ?limit= and
+// ?offset= page through them.
+func (h *SecurityEventHandler) Mine(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	offset, _ := strconv.Atoi(c.Query("offset"))
+
+	events, err := h.service.Mine(userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(events))
+}
+
+// requestInfo identifies the request for the security audit log. The
+// request ID is the one LoggingMiddleware logs it under.
+func requestInfo(c *gin.Context) models.RequestInfo {
+	return models.RequestInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		RequestID: c.GetString("requestID"),
+	}
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+// sharePasswordHeader carries the password of a protected share link, so
+// it does not end up in URLs and access logs.
+const sharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	service *services.ShareService
+}
+
+func NewShareHandler(service *services.ShareService) *ShareHandler {
+	return &ShareHandler{service: service}
+}
+
+func (h *ShareHandler) Create(c *gin.Context) {
+	var req models.ShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	link, err := h.service.Create(userID, req)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, helpers.SuccessResponse(link))
+}
+
+func (h *ShareHandler) List(c *gin.Context) {
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	links, err := h.service.List(userID)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(links))
+}
+
+func (h *ShareHandler) Get(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	link, err := h.service.Get(userID, id)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(link))
+}
+
+// Revoke closes a share link immediately.
+func (h *ShareHandler) Revoke(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	link, err := h.service.Revoke(userID, id)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(link))
+}
+
+// Accesses returns who opened the link and when.
+func (h *ShareHandler) Accesses(c *gin.Context) {
+	id, ok := parseUintParam(c, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	accesses, err := h.service.Accesses(userID, id)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(accesses))
+}
+
+// Open is the public, unauthenticated view of a share link.
+func (h *ShareHandler) Open(c *gin.Context) {
+	content, err := h.service.Open(
+		c.Param("token"),
+		c.GetHeader(sharePasswordHeader),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	if err != nil {
+		respondShareError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Robots-Tag", "noindex")
+	c.JSON(http.StatusOK, helpers.SuccessResponse(content))
+}
+
+func respondShareError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrShareLinkNotFound),
+		errors.Is(err, services.ErrShareLinkExpired),
+		errors.Is(err, services.ErrJournalNotFound),
+		errors.Is(err, services.ErrNotebookNotFound):
+		c.JSON(http.StatusNotFound, helpers.ErrorResponse(
+			apperrors.NotFound,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrSharePasswordRequired),
+		errors.Is(err, services.ErrWrongSharePassword):
+		c.JSON(http.StatusUnauthorized, helpers.ErrorResponse(
+			apperrors.Unauthorized,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrTooManyShareAttempts):
+		c.JSON(http.StatusTooManyRequests, helpers.ErrorResponse(
+			apperrors.TooManyRequests,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrInvalidShareLink),
+		errors.Is(err, services.ErrCannotShareDraft):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type SyncHandler struct {
+	service *services.SyncService
+}
+
+func NewSyncHandler(service *services.SyncService) *SyncHandler {
+	return &SyncHandler{service: service}
+}
+
+// Pull returns the changes made after the ?since= cursor.
+func (h *SyncHandler) Pull(c *gin.Context) {
+	var cursor uint64
+	if since := c.Query("since"); since != "" {
+		parsed, err := strconv.ParseUint(since, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+				apperrors.InvalidRequestData,
+				"invalid since cursor",
+			))
+			return
+		}
+		cursor = parsed
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	feed, err := h.service.Changes(userID, cursor)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(feed))
+}
+
+// Push applies changes recorded offline and reports the outcome of each.
+func (h *SyncHandler) Push(c *gin.Context) {
+	var req models.SyncPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	outcomes, err := h.service.Push(userID, req.Changes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+		return
+	}
+
+	results := make([]models.SyncPushResult, 0, len(outcomes))
+	for _, outcome := range outcomes {
+		result := outcome.Result
+		if outcome.Err != nil {
+			code := apperrors.InvalidRequestData
+			if errors.Is(outcome.Err, services.ErrSyncRecordNotFound) {
+				code = apperrors.NotFound
+			}
+			result.ErrorCode = code.Code
+			result.Message = outcome.Err.Error()
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(models.SyncPushResponse{
+		Results: results,
+	}))
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+type TaskHandler struct {
+	journalService   *services.JournalService
+	carryOverService *services.CarryOverService
+	policy           *services.AccessPolicy
+}
+
+func NewTaskHandler(journalService *services.JournalService, carryOverService *services.CarryOverService, policy *services.AccessPolicy) *TaskHandler {
+	return &TaskHandler{
+		journalService:   journalService,
+		carryOverService: carryOverService,
+		policy:           policy,
+	}
+}
+
+// Reorder sets the order of an entry's tasks. It honors If-Match like
+// the other entry writes.
+func (h *TaskHandler) Reorder(c *gin.Context) {
+	id, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req models.TaskOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidRequestData,
+			err.Error(),
+		))
+		return
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, id, services.AccessEdit)
+	if !ok {
+		return
+	}
+
+	entry, err := h.journalService.ReorderTasks(c.Request.Context(), ownerID, id, expectedVersion, req.TaskIDs)
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(entry.Version))
+	c.JSON(http.StatusOK, helpers.SuccessResponse(entry))
+}
+
+// CarryOver copies the entry's unfinished tasks into a later day.
+func (h *TaskHandler) CarryOver(c *gin.Context) {
+	id, ok := parseJournalID(c)
+	if !ok {
+		return
+	}
+
+	var req models.CarryOverRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+				apperrors.InvalidRequestData,
+				err.Error(),
+			))
+			return
+		}
+	}
+
+	var toDate time.Time
+	if req.ToDate != "" {
+		if toDate, ok = parseDate(c, req.ToDate, "to_date"); !ok {
+			return
+		}
+	}
+
+	userID, err := helpers.GetUserIDFromContext(c)
+	if err != nil {
+		return
+	}
+
+	ownerID, ok := authorizeEntry(c, h.policy, userID, id, services.AccessManage)
+	if !ok {
+		return
+	}
+
+	response, err := h.carryOverService.CarryOver(c.Request.Context(), ownerID, id, toDate, req.TaskIDs)
+	if err != nil {
+		respondJournalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, helpers.SuccessResponse(response))
+}
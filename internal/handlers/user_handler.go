@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -28,7 +29,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	userID, err := h.authService.Register(req.Email, req.FullName, req.Password)
+	userID, err := h.authService.Register(req.Email, req.FullName, req.Password, requestInfo(c))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
 			apperrors.UserAlreadyExist,
@@ -52,13 +53,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	token, err := h.authService.Login(req.Email, req.Password)
+	token, err := h.authService.Login(req.Email, req.Password, requestInfo(c))
 
 	if err != nil {
-		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
-			apperrors.InvalidCredentials,
-			err.Error(),
-		))
+		respondLoginError(c, err)
 		return
 	}
 
@@ -66,3 +64,28 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"token": token,
 	}))
 }
+
+func respondLoginError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidCredentials):
+		c.JSON(http.StatusBadRequest, helpers.ErrorResponse(
+			apperrors.InvalidCredentials,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrAccountLocked):
+		c.JSON(http.StatusTooManyRequests, helpers.ErrorResponse(
+			apperrors.AccountLocked,
+			err.Error(),
+		))
+	case errors.Is(err, services.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, helpers.ErrorResponse(
+			apperrors.AccountDisabled,
+			err.Error(),
+		))
+	default:
+		c.JSON(http.StatusInternalServerError, helpers.ErrorResponse(
+			apperrors.InternalServerError,
+			err.Error(),
+		))
+	}
+}
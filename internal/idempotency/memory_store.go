@@ -0,0 +1,77 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryRecord struct {
+	response  *Response
+	expiresAt time.Time
+}
+
+// MemoryStore keeps records in process memory. It suits single-instance
+// deployments and development; replays do not survive a restart.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]memoryRecord),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if record, ok := s.records[key]; ok && now.Before(record.expiresAt) {
+		if record.response == nil {
+			return nil, ErrInProgress
+		}
+		return record.response, nil
+	}
+
+	s.records[key] = memoryRecord{expiresAt: now.Add(lockTTL)}
+	return nil, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, key string, response Response, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = memoryRecord{
+		response:  &response,
+		expiresAt: s.now().Add(ttl),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) PurgeExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var purged int64
+	for key, record := range s.records {
+		if !now.Before(record.expiresAt) {
+			delete(s.records, key)
+			purged++
+		}
+	}
+	return purged, nil
+}
@@ -0,0 +1,115 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresStore keeps records in the idempotency_keys table so replays
+// work across instances and restarts.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Begin(ctx context.Context, key string, lockTTL time.Duration) (*Response, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.IdempotencyKey{
+		Key:       key,
+		ExpiresAt: now.Add(lockTTL),
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var record models.IdempotencyKey
+	if err := db.First(&record, "key = ?", key).Error; err != nil {
+		// Released between the insert and the lookup
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInProgress
+		}
+		return nil, err
+	}
+
+	if record.ExpiresAt.Before(now) {
+		// Take over the expired record, unless another request beat us to it
+		result := db.Model(&models.IdempotencyKey{}).
+			Where("key = ? AND expires_at = ?", key, record.ExpiresAt).
+			Updates(map[string]interface{}{
+				"response_status": 0,
+				"response_header": nil,
+				"response_body":   nil,
+				"completed":       false,
+				"expires_at":      now.Add(lockTTL),
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return nil, nil
+		}
+		return nil, ErrInProgress
+	}
+
+	if !record.Completed {
+		return nil, ErrInProgress
+	}
+
+	response := &Response{
+		Status: record.ResponseStatus,
+		Header: http.Header{},
+		Body:   record.ResponseBody,
+	}
+	if len(record.ResponseHeader) > 0 {
+		if err := json.Unmarshal(record.ResponseHeader, &response.Header); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, key string, response Response, ttl time.Duration) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Model(&models.IdempotencyKey{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{
+			"response_status": response.Status,
+			"response_header": models.JSONB(header),
+			"response_body":   response.Body,
+			"completed":       true,
+			"expires_at":      time.Now().Add(ttl),
+		}).Error
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	return s.db.WithContext(ctx).
+		Where("key = ? AND completed = ?", key, false).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+func (s *PostgresStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result := s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&models.IdempotencyKey{})
+
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,36 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrInProgress is returned by Begin when another request holding the same
+// key has not finished yet.
+var ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// Response is the first response produced for a key, replayed verbatim to
+// retries.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Store keeps idempotency records. Keys already include the user and the
+// request fingerprint, so stores only deal with opaque strings.
+type Store interface {
+	// Begin reserves key for a new request. When the key was already used
+	// it returns the stored response instead; when a request holding the
+	// key is still running it returns ErrInProgress. A reservation that is
+	// never completed or released becomes available again after lockTTL.
+	Begin(ctx context.Context, key string, lockTTL time.Duration) (*Response, error)
+	// Complete stores the response for a reserved key until ttl elapses.
+	Complete(ctx context.Context, key string, response Response, ttl time.Duration) error
+	// Release drops a reservation so the request may be retried.
+	Release(ctx context.Context, key string) error
+	// PurgeExpired removes expired records and returns how many went.
+	PurgeExpired(ctx context.Context) (int64, error)
+}
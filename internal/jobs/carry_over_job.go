@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// CarryOverJob moves yesterday's unfinished tasks into today for users who
+// turned on automatic carry-over. It runs often enough to catch midnight
+// in every time zone shortly after it passes.
+type CarryOverJob struct {
+	service *services.CarryOverService
+	logger  *logrus.Logger
+}
+
+func NewCarryOverJob(service *services.CarryOverService, logger *logrus.Logger) *CarryOverJob {
+	return &CarryOverJob{service: service, logger: logger}
+}
+
+func (j *CarryOverJob) Name() string {
+	return "task_carry_over"
+}
+
+func (j *CarryOverJob) Run(ctx context.Context) error {
+	carried, err := j.service.CarryOverDue(ctx)
+	if carried > 0 {
+		j.logger.WithField("entries", carried).Info("Carried over unfinished tasks")
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/idempotency"
+)
+
+// IdempotencyPurgeJob drops stored responses whose idempotency keys have
+// expired.
+type IdempotencyPurgeJob struct {
+	store  idempotency.Store
+	logger *logrus.Logger
+}
+
+func NewIdempotencyPurgeJob(store idempotency.Store, logger *logrus.Logger) *IdempotencyPurgeJob {
+	return &IdempotencyPurgeJob{store: store, logger: logger}
+}
+
+func (j *IdempotencyPurgeJob) Name() string {
+	return "idempotency_purge"
+}
+
+func (j *IdempotencyPurgeJob) Run(ctx context.Context) error {
+	purged, err := j.store.PurgeExpired(ctx)
+	if purged > 0 {
+		j.logger.WithField("purged", purged).Debug("Purged expired idempotency keys")
+	}
+	return err
+}
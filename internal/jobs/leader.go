@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// LeaderElector decides which replica runs leader-only jobs.
+type LeaderElector interface {
+	// IsLeader reports whether this process currently holds leadership,
+	// trying to acquire it when it does not.
+	IsLeader(ctx context.Context) (bool, error)
+	// Release gives up leadership so another replica can take over.
+	Release() error
+}
+
+// PostgresLeaderElector elects a leader with a session-level advisory
+// lock. The lock is held on a dedicated connection, so it is released
+// automatically if the process dies or the connection drops.
+type PostgresLeaderElector struct {
+	db   *sql.DB
+	key  int64
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func NewPostgresLeaderElector(db *sql.DB, key int64) *PostgresLeaderElector {
+	return &PostgresLeaderElector{db: db, key: key}
+}
+
+func (e *PostgresLeaderElector) IsLeader(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn != nil {
+		if err := e.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The connection and with it the lock are gone
+		e.conn.Close()
+		e.conn = nil
+	}
+
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	e.conn = conn
+	return true, nil
+}
+
+func (e *PostgresLeaderElector) Release() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		return nil
+	}
+
+	_, err := e.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", e.key)
+	e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// AlwaysLeader makes every process a leader, for single-instance setups.
+type AlwaysLeader struct{}
+
+func (AlwaysLeader) IsLeader(ctx context.Context) (bool, error) { return true, nil }
+
+func (AlwaysLeader) Release() error { return nil }
@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// MemoryDigestJob sends the daily "on this day" digests that are due.
+type MemoryDigestJob struct {
+	service *services.MemoryService
+	logger  *logrus.Logger
+}
+
+func NewMemoryDigestJob(service *services.MemoryService, logger *logrus.Logger) *MemoryDigestJob {
+	return &MemoryDigestJob{service: service, logger: logger}
+}
+
+func (j *MemoryDigestJob) Name() string {
+	return "memory_digest"
+}
+
+func (j *MemoryDigestJob) Run(ctx context.Context) error {
+	sent, err := j.service.SendDailyDigests(ctx)
+	if sent > 0 {
+		j.logger.WithField("digests", sent).Info("Sent memory digests")
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// NotificationJob sends the notifications queued about comments and
+// reactions on users' entries.
+type NotificationJob struct {
+	service *services.NotificationService
+	logger  *logrus.Logger
+}
+
+func NewNotificationJob(service *services.NotificationService, logger *logrus.Logger) *NotificationJob {
+	return &NotificationJob{service: service, logger: logger}
+}
+
+func (j *NotificationJob) Name() string {
+	return "notifications"
+}
+
+func (j *NotificationJob) Run(ctx context.Context) error {
+	sent, err := j.service.SendQueued(ctx)
+	if sent > 0 {
+		j.logger.WithField("notifications", sent).Debug("Sent notifications")
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// ReminderJob sends the daily journaling reminders that are due. It runs
+// every minute so reminders go out close to the time users picked.
+type ReminderJob struct {
+	service *services.ReminderService
+	logger  *logrus.Logger
+}
+
+func NewReminderJob(service *services.ReminderService, logger *logrus.Logger) *ReminderJob {
+	return &ReminderJob{service: service, logger: logger}
+}
+
+func (j *ReminderJob) Name() string {
+	return "journal_reminders"
+}
+
+func (j *ReminderJob) Run(ctx context.Context) error {
+	sent, err := j.service.SendDueReminders(ctx)
+	if sent > 0 {
+		j.logger.WithField("reminders", sent).Info("Sent journal reminders")
+	}
+	return err
+}
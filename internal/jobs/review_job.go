@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// ReviewJob stores the review reports of periods that just ended. It runs
+// hourly so every time zone is covered soon after its midnight.
+type ReviewJob struct {
+	service *services.ReviewService
+	logger  *logrus.Logger
+}
+
+func NewReviewJob(service *services.ReviewService, logger *logrus.Logger) *ReviewJob {
+	return &ReviewJob{service: service, logger: logger}
+}
+
+func (j *ReviewJob) Name() string {
+	return "review_generation"
+}
+
+func (j *ReviewJob) Run(ctx context.Context) error {
+	generated, err := j.service.GenerateDue(ctx)
+	if generated > 0 {
+		j.logger.WithField("reviews", generated).Info("Generated review reports")
+	}
+	return err
+}
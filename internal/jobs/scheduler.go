@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a unit of background work run periodically by the Scheduler.
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type scheduledJob struct {
+	job        Job
+	interval   time.Duration
+	leaderOnly bool
+}
+
+// Scheduler runs registered jobs on fixed intervals inside the API
+// process until it is stopped.
+type Scheduler struct {
+	logger  *logrus.Logger
+	elector LeaderElector
+	jobs    []scheduledJob
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler. Leader-only jobs run on the replica
+// elected by elector; pass AlwaysLeader{} for a single instance.
+func NewScheduler(logger *logrus.Logger, elector LeaderElector) *Scheduler {
+	return &Scheduler{logger: logger, elector: elector}
+}
+
+// Every registers job to run once at start-up and then every interval on
+// every replica. Use it for per-process work such as in-memory caches.
+func (s *Scheduler) Every(interval time.Duration, job Job) {
+	s.jobs = append(s.jobs, scheduledJob{job: job, interval: interval})
+}
+
+// EveryOnLeader is like Every, but the job only runs on the elected
+// leader so work on shared data is not repeated by each replica.
+func (s *Scheduler) EveryOnLeader(interval time.Duration, job Job) {
+	s.jobs = append(s.jobs, scheduledJob{job: job, interval: interval, leaderOnly: true})
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, scheduled := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, scheduled)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return.
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+
+	if err := s.elector.Release(); err != nil {
+		s.logger.WithField("error", err.Error()).Error("Failed to release scheduler leadership")
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, scheduled scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(scheduled.interval)
+	defer ticker.Stop()
+
+	for {
+		if s.shouldRun(ctx, scheduled) {
+			s.run(ctx, scheduled.job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) shouldRun(ctx context.Context, scheduled scheduledJob) bool {
+	if !scheduled.leaderOnly {
+		return true
+	}
+
+	leader, err := s.elector.IsLeader(ctx)
+	if err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"job":   scheduled.job.Name(),
+			"error": err.Error(),
+		}).Error("Leader election failed")
+		return false
+	}
+	return leader
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	start := time.Now()
+
+	defer func() {
+		if err := recover(); err != nil {
+			s.logger.WithFields(logrus.Fields{
+				"job":   job.Name(),
+				"error": err,
+			}).Error("Job panicked")
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		s.logger.WithFields(logrus.Fields{
+			"job":     job.Name(),
+			"latency": time.Since(start),
+			"error":   err.Error(),
+		}).Error("Job failed")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"job":     job.Name(),
+		"latency": time.Since(start),
+	}).Debug("Job completed")
+}
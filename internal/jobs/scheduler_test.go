@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeJob struct {
+	runs  atomic.Int32
+	ran   chan struct{}
+	err   error
+	panic bool
+}
+
+func newFakeJob() *fakeJob {
+	return &fakeJob{ran: make(chan struct{}, 16)}
+}
+
+func (j *fakeJob) Name() string { return "fake" }
+
+func (j *fakeJob) Run(ctx context.Context) error {
+	j.runs.Add(1)
+	select {
+	case j.ran <- struct{}{}:
+	default:
+	}
+	if j.panic {
+		panic("job failed")
+	}
+	return j.err
+}
+
+type fakeElector struct {
+	leader   bool
+	err      error
+	released atomic.Bool
+}
+
+func (e *fakeElector) IsLeader(ctx context.Context) (bool, error) { return e.leader, e.err }
+
+func (e *fakeElector) Release() error {
+	e.released.Store(true)
+	return nil
+}
+
+func newTestLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// waitForRun reports whether job ran within a short timeout.
+func waitForRun(job *fakeJob) bool {
+	select {
+	case <-job.ran:
+		return true
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestSchedulerLeaderOnlyJobs(t *testing.T) {
+	tests := []struct {
+		name    string
+		elector *fakeElector
+		wantRun bool
+	}{
+		{name: "leader", elector: &fakeElector{leader: true}, wantRun: true},
+		{name: "not leader", elector: &fakeElector{leader: false}},
+		{name: "election error", elector: &fakeElector{leader: true, err: errors.New("connection refused")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaderJob, everyJob := newFakeJob(), newFakeJob()
+
+			scheduler := NewScheduler(newTestLogger(), tt.elector)
+			scheduler.EveryOnLeader(time.Hour, leaderJob)
+			scheduler.Every(time.Hour, everyJob)
+			scheduler.Start()
+
+			// Every job runs at start-up, so once the other job has run
+			// the leader-only one has had its chance too
+			if !waitForRun(everyJob) {
+				t.Fatal("job registered with Every did not run")
+			}
+			scheduler.Stop()
+
+			if got := leaderJob.runs.Load() > 0; got != tt.wantRun {
+				t.Errorf("leader-only job ran = %v, want %v", got, tt.wantRun)
+			}
+			if !tt.elector.released.Load() {
+				t.Error("Stop() did not release leadership")
+			}
+		})
+	}
+}
+
+func TestSchedulerRunsJobsEveryInterval(t *testing.T) {
+	job := newFakeJob()
+	scheduler := NewScheduler(newTestLogger(), AlwaysLeader{})
+	scheduler.Every(10*time.Millisecond, job)
+	scheduler.Start()
+	defer scheduler.Stop()
+
+	for i := 0; i < 3; i++ {
+		if !waitForRun(job) {
+			t.Fatalf("job ran %d times, want at least 3", job.runs.Load())
+		}
+	}
+}
+
+func TestSchedulerKeepsRunningAfterFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		job  *fakeJob
+	}{
+		{name: "error", job: &fakeJob{ran: make(chan struct{}, 16), err: errors.New("database is down")}},
+		{name: "panic", job: &fakeJob{ran: make(chan struct{}, 16), panic: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler := NewScheduler(newTestLogger(), AlwaysLeader{})
+			scheduler.Every(10*time.Millisecond, tt.job)
+			scheduler.Start()
+			defer scheduler.Stop()
+
+			for i := 0; i < 2; i++ {
+				if !waitForRun(tt.job) {
+					t.Fatalf("job ran %d times, want at least 2", tt.job.runs.Load())
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerStopWaitsForJobs(t *testing.T) {
+	job := newFakeJob()
+	scheduler := NewScheduler(newTestLogger(), AlwaysLeader{})
+	scheduler.Every(10*time.Millisecond, job)
+	scheduler.Start()
+
+	if !waitForRun(job) {
+		t.Fatal("job did not run")
+	}
+	scheduler.Stop()
+
+	runs := job.runs.Load()
+	time.Sleep(50 * time.Millisecond)
+	if got := job.runs.Load(); got != runs {
+		t.Errorf("job ran %d more times after Stop()", got-runs)
+	}
+}
+
+func TestSchedulerStopBeforeStart(t *testing.T) {
+	elector := &fakeElector{}
+	NewScheduler(newTestLogger(), elector).Stop()
+
+	if elector.released.Load() {
+		t.Error("Stop() released leadership for a scheduler that never started")
+	}
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// TranscriptionJob transcribes the voice memos waiting for it. Memos stay
+// pending in the database until they are done, so an interrupted
+// transcription is picked up again by the next run.
+type TranscriptionJob struct {
+	service *services.AttachmentService
+	logger  *logrus.Logger
+}
+
+func NewTranscriptionJob(service *services.AttachmentService, logger *logrus.Logger) *TranscriptionJob {
+	return &TranscriptionJob{service: service, logger: logger}
+}
+
+func (j *TranscriptionJob) Name() string {
+	return "transcription"
+}
+
+func (j *TranscriptionJob) Run(ctx context.Context) error {
+	transcribed, err := j.service.TranscribePending(ctx)
+	if transcribed > 0 {
+		j.logger.WithField("attachments", transcribed).Debug("Transcribed voice memos")
+	}
+	return err
+}
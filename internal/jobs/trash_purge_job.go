@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// TrashPurgeJob permanently deletes journal entries whose time in the
+// trash has run out.
+type TrashPurgeJob struct {
+	service *services.TrashService
+	logger  *logrus.Logger
+}
+
+func NewTrashPurgeJob(service *services.TrashService, logger *logrus.Logger) *TrashPurgeJob {
+	return &TrashPurgeJob{service: service, logger: logger}
+}
+
+func (j *TrashPurgeJob) Name() string {
+	return "trash_purge"
+}
+
+func (j *TrashPurgeJob) Run(ctx context.Context) error {
+	purged, err := j.service.PurgeExpired(ctx)
+	if purged > 0 {
+		j.logger.WithField("purged", purged).Info("Purged expired journal entries from trash")
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
+)
+
+// WebhookDeliveryJob works through the webhook delivery queue, sending
+// new events and retrying failed ones whose backoff has passed.
+type WebhookDeliveryJob struct {
+	service *services.WebhookService
+	logger  *logrus.Logger
+}
+
+func NewWebhookDeliveryJob(service *services.WebhookService, logger *logrus.Logger) *WebhookDeliveryJob {
+	return &WebhookDeliveryJob{service: service, logger: logger}
+}
+
+func (j *WebhookDeliveryJob) Name() string {
+	return "webhook_delivery"
+}
+
+func (j *WebhookDeliveryJob) Run(ctx context.Context) error {
+	delivered, err := j.service.DeliverDue(ctx)
+	if delivered > 0 {
+		j.logger.WithField("deliveries", delivered).Debug("Delivered webhook events")
+	}
+	return err
+}
@@ -0,0 +1,578 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+)
+
+var ErrUnsupportedAudio = errors.New("unsupported or corrupt audio file")
+
+// AudioMetadata describes an uploaded voice memo. Fields that cannot be
+// determined for a given container are left at their zero value.
+type AudioMetadata struct {
+	DurationMs int64
+	Codec      string
+	SampleRate int
+	Channels   int
+}
+
+// audioProbes maps the types returned by DetectAudioContentType to the
+// probe that knows how to read them.
+var audioProbes = map[string]func([]byte) (AudioMetadata, error){
+	"audio/wav":  probeWAV,
+	"audio/mpeg": probeMP3,
+	"audio/ogg":  probeOgg,
+	"audio/flac": probeFLAC,
+	"audio/mp4":  probeMP4,
+	"audio/webm": probeWebM,
+}
+
+func IsAudio(contentType string) bool {
+	return strings.HasPrefix(contentType, "audio/")
+}
+
+// DetectAudioContentType recognises the common audio containers from
+// their magic bytes. It returns an empty string when data is not audio.
+// http.DetectContentType is not enough on its own: it does not know FLAC
+// and reports M4A and WebM recordings as video.
+func DetectAudioContentType(data []byte) string {
+	switch {
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return "audio/wav"
+	case bytes.HasPrefix(data, []byte("fLaC")):
+		return "audio/flac"
+	case bytes.HasPrefix(data, []byte("OggS")):
+		return "audio/ogg"
+	case bytes.HasPrefix(data, []byte("ID3")):
+		return "audio/mpeg"
+	case len(data) >= 2 && data[0] == 0xFF && data[1]&0xE0 == 0xE0 && data[1]&0x06 != 0:
+		return "audio/mpeg"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && isAudioMP4Brand(string(data[8:12])):
+		return "audio/mp4"
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "audio/webm"
+	}
+	return ""
+}
+
+func isAudioMP4Brand(brand string) bool {
+	switch brand {
+	case "M4A ", "M4B ", "mp42", "isom", "3gp4", "3gp5":
+		return true
+	}
+	return false
+}
+
+// ProbeAudio extracts duration and codec information from an audio file.
+func ProbeAudio(data []byte, contentType string) (AudioMetadata, error) {
+	probe, ok := audioProbes[contentType]
+	if !ok {
+		return AudioMetadata{}, ErrUnsupportedAudio
+	}
+	return probe(data)
+}
+
+// --------------------------
+// WAV
+// --------------------------
+
+func probeWAV(data []byte) (AudioMetadata, error) {
+	var meta AudioMetadata
+	var byteRate uint32
+	var dataSize uint32
+
+	for offset := 12; offset+8 <= len(data); {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := binary.LittleEndian.Uint32(data[offset+4 : offset+8])
+		body := data[offset+8:]
+
+		switch chunkID {
+		case "fmt ":
+			if len(body) < 16 {
+				return meta, ErrUnsupportedAudio
+			}
+			meta.Codec = wavCodec(binary.LittleEndian.Uint16(body[0:2]))
+			meta.Channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			meta.SampleRate = int(binary.LittleEndian.Uint32(body[4:8]))
+			byteRate = binary.LittleEndian.Uint32(body[8:12])
+		case "data":
+			dataSize = chunkSize
+		}
+
+		// Chunks are word aligned
+		offset += 8 + int(chunkSize) + int(chunkSize&1)
+		if chunkID == "data" {
+			break
+		}
+	}
+
+	if byteRate == 0 {
+		return meta, ErrUnsupportedAudio
+	}
+
+	meta.DurationMs = int64(dataSize) * 1000 / int64(byteRate)
+	return meta, nil
+}
+
+func wavCodec(format uint16) string {
+	switch format {
+	case 1:
+		return "pcm"
+	case 3:
+		return "pcm_float"
+	case 6:
+		return "alaw"
+	case 7:
+		return "mulaw"
+	default:
+		return "unknown"
+	}
+}
+
+// --------------------------
+// MP3
+// --------------------------
+
+var mp3Bitrates = map[int][16]int{
+	// MPEG-1 Layer III
+	1: {0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0},
+	// MPEG-2/2.5 Layer III
+	2: {0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0},
+}
+
+var mp3SampleRates = map[int][3]int{
+	1: {44100, 48000, 32000},
+	2: {22050, 24000, 16000},
+	3: {11025, 12000, 8000}, // MPEG-2.5
+}
+
+func probeMP3(data []byte) (AudioMetadata, error) {
+	offset := 0
+
+	// Skip an ID3v2 tag, whose size is stored as a syncsafe integer
+	if len(data) >= 10 && string(data[0:3]) == "ID3" {
+		size := int(data[6]&0x7F)<<21 | int(data[7]&0x7F)<<14 | int(data[8]&0x7F)<<7 | int(data[9]&0x7F)
+		offset = 10 + size
+	}
+
+	for ; offset+4 <= len(data); offset++ {
+		if data[offset] != 0xFF || data[offset+1]&0xE0 != 0xE0 {
+			continue
+		}
+
+		header := binary.BigEndian.Uint32(data[offset : offset+4])
+		versionBits := int(header>>19) & 0x3
+		layerBits := int(header>>17) & 0x3
+		bitrateIndex := int(header>>12) & 0xF
+		sampleRateIndex := int(header>>10) & 0x3
+		channelMode := int(header>>6) & 0x3
+
+		// Only Layer III is supported, which is what every encoder emits
+		if layerBits != 1 || versionBits == 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+			continue
+		}
+
+		version := map[int]int{3: 1, 2: 2, 0: 3}[versionBits]
+		table := 1
+		if version != 1 {
+			table = 2
+		}
+
+		meta := AudioMetadata{
+			Codec:      "mp3",
+			SampleRate: mp3SampleRates[version][sampleRateIndex],
+			Channels:   2,
+		}
+		if channelMode == 3 {
+			meta.Channels = 1
+		}
+
+		samplesPerFrame := 1152
+		if version != 1 {
+			samplesPerFrame = 576
+		}
+
+		// A Xing/Info header carries the exact frame count for VBR files
+		if frames, ok := mp3XingFrames(data[offset:], version, meta.Channels); ok {
+			meta.DurationMs = int64(frames) * int64(samplesPerFrame) * 1000 / int64(meta.SampleRate)
+			return meta, nil
+		}
+
+		bitrate := mp3Bitrates[table][bitrateIndex] * 1000
+		meta.DurationMs = int64(len(data)-offset) * 8 * 1000 / int64(bitrate)
+		return meta, nil
+	}
+
+	return AudioMetadata{}, ErrUnsupportedAudio
+}
+
+func mp3XingFrames(frame []byte, version, channels int) (uint32, bool) {
+	sideInfo := 32
+	switch {
+	case version == 1 && channels == 1:
+		sideInfo = 17
+	case version != 1 && channels == 1:
+		sideInfo = 9
+	case version != 1:
+		sideInfo = 17
+	}
+
+	start := 4 + sideInfo
+	if len(frame) < start+12 {
+		return 0, false
+	}
+
+	tag := string(frame[start : start+4])
+	if tag != "Xing" && tag != "Info" {
+		return 0, false
+	}
+
+	flags := binary.BigEndian.Uint32(frame[start+4 : start+8])
+	if flags&0x1 == 0 {
+		return 0, false
+	}
+
+	return binary.BigEndian.Uint32(frame[start+8 : start+12]), true
+}
+
+// --------------------------
+// Ogg (Vorbis / Opus)
+// --------------------------
+
+func probeOgg(data []byte) (AudioMetadata, error) {
+	if len(data) < 27 {
+		return AudioMetadata{}, ErrUnsupportedAudio
+	}
+
+	segments := int(data[26])
+	payloadStart := 27 + segments
+	if len(data) < payloadStart+19 {
+		return AudioMetadata{}, ErrUnsupportedAudio
+	}
+	payload := data[payloadStart:]
+
+	var meta AudioMetadata
+	var preSkip uint64
+
+	switch {
+	case bytes.HasPrefix(payload, []byte("OpusHead")):
+		meta.Codec = "opus"
+		meta.Channels = int(payload[9])
+		preSkip = uint64(binary.LittleEndian.Uint16(payload[10:12]))
+		meta.SampleRate = int(binary.LittleEndian.Uint32(payload[12:16]))
+		// Opus granule positions always count 48 kHz samples
+		granule, ok := lastOggGranule(data)
+		if !ok || granule < preSkip {
+			return meta, ErrUnsupportedAudio
+		}
+		meta.DurationMs = int64((granule - preSkip) * 1000 / 48000)
+	case len(payload) >= 16 && payload[0] == 0x01 && string(payload[1:7]) == "vorbis":
+		meta.Codec = "vorbis"
+		meta.Channels = int(payload[11])
+		meta.SampleRate = int(binary.LittleEndian.Uint32(payload[12:16]))
+		granule, ok := lastOggGranule(data)
+		if !ok || meta.SampleRate == 0 {
+			return meta, ErrUnsupportedAudio
+		}
+		meta.DurationMs = int64(granule * 1000 / uint64(meta.SampleRate))
+	default:
+		return meta, ErrUnsupportedAudio
+	}
+
+	return meta, nil
+}
+
+// lastOggGranule returns the granule position of the last page, which is
+// the total number of samples in the stream.
+func lastOggGranule(data []byte) (uint64, bool) {
+	for i := len(data) - 14; i >= 0; i-- {
+		if data[i] == 'O' && bytes.HasPrefix(data[i:], []byte("OggS")) {
+			granule := binary.LittleEndian.Uint64(data[i+6 : i+14])
+			if granule != math.MaxUint64 {
+				return granule, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// --------------------------
+// FLAC
+// --------------------------
+
+func probeFLAC(data []byte) (AudioMetadata, error) {
+	// The STREAMINFO block always comes first, right after "fLaC"
+	if len(data) < 8+18 || data[4]&0x7F != 0 {
+		return AudioMetadata{}, ErrUnsupportedAudio
+	}
+
+	info := data[8:]
+	sampleRate := int(info[10])<<12 | int(info[11])<<4 | int(info[12])>>4
+	channels := int(info[12]>>1&0x7) + 1
+	totalSamples := uint64(info[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+
+	if sampleRate == 0 {
+		return AudioMetadata{}, ErrUnsupportedAudio
+	}
+
+	return AudioMetadata{
+		Codec:      "flac",
+		SampleRate: sampleRate,
+		Channels:   channels,
+		DurationMs: int64(totalSamples * 1000 / uint64(sampleRate)),
+	}, nil
+}
+
+// --------------------------
+// MP4 / M4A
+// --------------------------
+
+func probeMP4(data []byte) (AudioMetadata, error) {
+	var meta AudioMetadata
+
+	moov, ok := findMP4Box(data, "moov")
+	if !ok {
+		return meta, ErrUnsupportedAudio
+	}
+
+	if mvhd, ok := findMP4Box(moov, "mvhd"); ok && len(mvhd) >= 20 {
+		var timescale, duration uint64
+		if mvhd[0] == 1 && len(mvhd) >= 32 {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[20:24]))
+			duration = binary.BigEndian.Uint64(mvhd[24:32])
+		} else {
+			timescale = uint64(binary.BigEndian.Uint32(mvhd[12:16]))
+			duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+		}
+		if timescale > 0 {
+			meta.DurationMs = int64(duration * 1000 / timescale)
+		}
+	}
+
+	// moov/trak/mdia/minf/stbl/stsd holds the sample description
+	box := moov
+	for _, name := range []string{"trak", "mdia", "minf", "stbl", "stsd"} {
+		if box, ok = findMP4Box(box, name); !ok {
+			return meta, nil
+		}
+	}
+
+	// stsd: version/flags (4) + entry count (4), then the first entry
+	if len(box) >= 8+36 {
+		entry := box[8:]
+		switch string(entry[4:8]) {
+		case "mp4a":
+			meta.Codec = "aac"
+		case "alac":
+			meta.Codec = "alac"
+		case "Opus":
+			meta.Codec = "opus"
+		default:
+			meta.Codec = strings.TrimSpace(string(entry[4:8]))
+		}
+		meta.Channels = int(binary.BigEndian.Uint16(entry[24:26]))
+		meta.SampleRate = int(binary.BigEndian.Uint32(entry[32:36]) >> 16)
+	}
+
+	return meta, nil
+}
+
+// findMP4Box returns the payload of the first child box named name.
+func findMP4Box(data []byte, name string) ([]byte, bool) {
+	for offset := 0; offset+8 <= len(data); {
+		size := uint64(binary.BigEndian.Uint32(data[offset : offset+4]))
+		boxType := string(data[offset+4 : offset+8])
+		header := uint64(8)
+
+		switch size {
+		case 0:
+			size = uint64(len(data) - offset)
+		case 1:
+			if offset+16 > len(data) {
+				return nil, false
+			}
+			size = binary.BigEndian.Uint64(data[offset+8 : offset+16])
+			header = 16
+		}
+
+		// Compared against what is left so a 64-bit size cannot wrap
+		if size < header || size > uint64(len(data)-offset) {
+			return nil, false
+		}
+
+		if boxType == name {
+			return data[offset+int(header) : offset+int(size)], true
+		}
+		offset += int(size)
+	}
+	return nil, false
+}
+
+// --------------------------
+// WebM (Matroska)
+// --------------------------
+
+const (
+	ebmlSegment           = 0x18538067
+	ebmlInfo              = 0x1549A966
+	ebmlTimecodeScale     = 0x2AD7B1
+	ebmlDuration          = 0x4489
+	ebmlTracks            = 0x1654AE6B
+	ebmlTrackEntry        = 0xAE
+	ebmlCodecID           = 0x86
+	ebmlAudio             = 0xE1
+	ebmlSamplingFrequency = 0xB5
+	ebmlChannels          = 0x9F
+	ebmlUnknownSize       = -1
+)
+
+func probeWebM(data []byte) (AudioMetadata, error) {
+	var meta AudioMetadata
+	timecodeScale := uint64(1000000)
+	var duration float64
+
+	// Skip the EBML header element
+	_, size, n := readEBMLElement(data)
+	if n == 0 || size == ebmlUnknownSize || uint64(size) > uint64(len(data)-n) {
+		return meta, ErrUnsupportedAudio
+	}
+	rest := data[n+int(size):]
+
+	id, size, n := readEBMLElement(rest)
+	if id != ebmlSegment {
+		return meta, ErrUnsupportedAudio
+	}
+	segment := rest[n:]
+	if size != ebmlUnknownSize && uint64(size) < uint64(len(segment)) {
+		segment = segment[:size]
+	}
+
+	walkEBML(segment, func(id uint64, body []byte) bool {
+		switch id {
+		case ebmlInfo:
+			walkEBML(body, func(id uint64, body []byte) bool {
+				switch id {
+				case ebmlTimecodeScale:
+					timecodeScale = ebmlUint(body)
+				case ebmlDuration:
+					duration = ebmlFloat(body)
+				}
+				return true
+			})
+		case ebmlTracks:
+			walkEBML(body, func(id uint64, body []byte) bool {
+				if id != ebmlTrackEntry {
+					return true
+				}
+				walkEBML(body, func(id uint64, body []byte) bool {
+					switch id {
+					case ebmlCodecID:
+						meta.Codec = strings.ToLower(strings.TrimPrefix(string(body), "A_"))
+					case ebmlAudio:
+						walkEBML(body, func(id uint64, body []byte) bool {
+							switch id {
+							case ebmlSamplingFrequency:
+								meta.SampleRate = int(ebmlFloat(body))
+							case ebmlChannels:
+								meta.Channels = int(ebmlUint(body))
+							}
+							return true
+						})
+					}
+					return true
+				})
+				// Only the first track is described
+				return meta.Codec == ""
+			})
+		}
+		// Info and Tracks come before the clusters, no need to go further
+		return meta.Codec == "" || duration == 0
+	})
+
+	if meta.Codec == "" {
+		return meta, ErrUnsupportedAudio
+	}
+
+	// MediaRecorder output often lacks a duration; report what we know
+	meta.DurationMs = int64(duration * float64(timecodeScale) / 1e6)
+	return meta, nil
+}
+
+// walkEBML calls fn for every child element in data until fn returns false.
+func walkEBML(data []byte, fn func(id uint64, body []byte) bool) {
+	for len(data) > 0 {
+		id, size, n := readEBMLElement(data)
+		if n == 0 || size == ebmlUnknownSize || uint64(size) > uint64(len(data)-n) {
+			return
+		}
+		if !fn(id, data[n:n+int(size)]) {
+			return
+		}
+		data = data[n+int(size):]
+	}
+}
+
+// readEBMLElement decodes an element ID and size, returning the number of
+// header bytes consumed (0 when data is malformed).
+func readEBMLElement(data []byte) (uint64, int64, int) {
+	id, idLen := readEBMLVarInt(data, false)
+	if idLen == 0 {
+		return 0, 0, 0
+	}
+
+	size, sizeLen := readEBMLVarInt(data[idLen:], true)
+	if sizeLen == 0 {
+		return 0, 0, 0
+	}
+
+	if size == (uint64(1)<<(7*sizeLen))-1 {
+		return id, ebmlUnknownSize, idLen + sizeLen
+	}
+
+	return id, int64(size), idLen + sizeLen
+}
+
+func readEBMLVarInt(data []byte, stripMarker bool) (uint64, int) {
+	if len(data) == 0 || data[0] == 0 {
+		return 0, 0
+	}
+
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || len(data) < length {
+		return 0, 0
+	}
+
+	value := uint64(data[0])
+	if stripMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+	}
+
+	return value, length
+}
+
+func ebmlUint(body []byte) uint64 {
+	var value uint64
+	for _, b := range body {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(body []byte) float64 {
+	switch len(body) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(body)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(body))
+	}
+	return 0
+}
@@ -0,0 +1,194 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+)
+
+func mp4Box(name string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	box := binary.BigEndian.AppendUint32(nil, uint32(8+len(body)))
+	box = append(box, name...)
+	return append(box, body...)
+}
+
+// mp4LargeBox writes a box header with a 64-bit size and no payload.
+func mp4LargeBox(name string, size uint64) []byte {
+	box := binary.BigEndian.AppendUint32(nil, 1)
+	box = append(box, name...)
+	return binary.BigEndian.AppendUint64(box, size)
+}
+
+func testM4A() []byte {
+	mvhd := make([]byte, 20)
+	binary.BigEndian.PutUint32(mvhd[12:16], 1000) // Timescale
+	binary.BigEndian.PutUint32(mvhd[16:20], 2500) // Duration
+
+	entry := make([]byte, 36)
+	copy(entry[4:8], "mp4a")
+	binary.BigEndian.PutUint16(entry[24:26], 2)
+	binary.BigEndian.PutUint32(entry[32:36], 44100<<16)
+	stsd := append(make([]byte, 8), entry...)
+
+	moov := mp4Box("moov",
+		mp4Box("mvhd", mvhd),
+		mp4Box("trak", mp4Box("mdia", mp4Box("minf", mp4Box("stbl", mp4Box("stsd", stsd))))),
+	)
+	return append(mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00")), moov...)
+}
+
+// ebmlElement encodes an element with an 8-byte size.
+func ebmlElement(id uint64, body ...[]byte) []byte {
+	return ebmlElementSized(id, uint64(len(bytes.Join(body, nil))), body...)
+}
+
+func ebmlElementSized(id, size uint64, body ...[]byte) []byte {
+	var element []byte
+	for shift := 24; shift >= 0; shift -= 8 {
+		if b := byte(id >> shift); b != 0 || len(element) > 0 {
+			element = append(element, b)
+		}
+	}
+	sizeBytes := binary.BigEndian.AppendUint64(nil, size)
+	sizeBytes[0] = 0x01
+	element = append(element, sizeBytes...)
+	return append(element, bytes.Join(body, nil)...)
+}
+
+func ebmlFloat64(value float64) []byte {
+	return binary.BigEndian.AppendUint64(nil, math.Float64bits(value))
+}
+
+func testWebM() []byte {
+	segment := ebmlElement(ebmlSegment,
+		ebmlElement(ebmlInfo,
+			ebmlElement(ebmlTimecodeScale, []byte{0x0F, 0x42, 0x40}),
+			ebmlElement(ebmlDuration, ebmlFloat64(1500)),
+		),
+		ebmlElement(ebmlTracks,
+			ebmlElement(ebmlTrackEntry,
+				ebmlElement(ebmlCodecID, []byte("A_OPUS")),
+				ebmlElement(ebmlAudio,
+					ebmlElement(ebmlSamplingFrequency, ebmlFloat64(48000)),
+					ebmlElement(ebmlChannels, []byte{1}),
+				),
+			),
+		),
+	)
+	return append(ebmlElement(0x1A45DFA3, []byte("webm")), segment...)
+}
+
+func TestProbeAudio(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		want        AudioMetadata
+	}{
+		{
+			name:        "m4a",
+			contentType: "audio/mp4",
+			data:        testM4A(),
+			want:        AudioMetadata{DurationMs: 2500, Codec: "aac", SampleRate: 44100, Channels: 2},
+		},
+		{
+			name:        "webm",
+			contentType: "audio/webm",
+			data:        testWebM(),
+			want:        AudioMetadata{DurationMs: 1500, Codec: "opus", SampleRate: 48000, Channels: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectAudioContentType(tt.data); got != tt.contentType {
+				t.Fatalf("DetectAudioContentType() = %q, want %q", got, tt.contentType)
+			}
+			got, err := ProbeAudio(tt.data, tt.contentType)
+			if err != nil {
+				t.Fatalf("ProbeAudio() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ProbeAudio() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbeAudioRejectsCorruptSizes(t *testing.T) {
+	ftyp := mp4Box("ftyp", []byte("M4A \x00\x00\x00\x00"))
+	m4a := testM4A()
+	webm := testWebM()
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+	}{
+		{
+			name:        "mp4 truncated",
+			contentType: "audio/mp4",
+			data:        m4a[:len(m4a)-10],
+		},
+		{
+			name:        "mp4 box larger than file",
+			contentType: "audio/mp4",
+			data:        append(append([]byte{}, ftyp...), mp4Box("moov", make([]byte, 4))[:8]...),
+		},
+		{
+			// offset + size wraps around to 0 when added as uint64
+			name:        "mp4 64-bit size wraps",
+			contentType: "audio/mp4",
+			data:        append(append([]byte{}, ftyp...), mp4LargeBox("moov", math.MaxUint64-uint64(len(ftyp)-1))...),
+		},
+		{
+			name:        "mp4 64-bit size too large",
+			contentType: "audio/mp4",
+			data:        append(append([]byte{}, ftyp...), mp4LargeBox("moov", math.MaxUint64)...),
+		},
+		{
+			name:        "mp4 size smaller than header",
+			contentType: "audio/mp4",
+			data:        append(append([]byte{}, ftyp...), mp4LargeBox("moov", 8)...),
+		},
+		{
+			name:        "webm truncated",
+			contentType: "audio/webm",
+			data:        webm[:40],
+		},
+		{
+			name:        "webm header larger than file",
+			contentType: "audio/webm",
+			data:        ebmlElementSized(0x1A45DFA3, 1<<40, []byte("webm")),
+		},
+		{
+			name:        "webm header larger than int",
+			contentType: "audio/webm",
+			data:        ebmlElementSized(0x1A45DFA3, 1<<56-2, []byte("webm")),
+		},
+		{
+			name:        "webm track larger than segment",
+			contentType: "audio/webm",
+			data: append(ebmlElement(0x1A45DFA3, []byte("webm")), ebmlElement(ebmlSegment,
+				ebmlElementSized(ebmlTracks, 1<<56-2, ebmlElement(ebmlTrackEntry)),
+			)...),
+		},
+		{
+			name:        "webm header only",
+			contentType: "audio/webm",
+			data:        webm[:4],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ProbeAudio(tt.data, tt.contentType)
+			if !errors.Is(err, ErrUnsupportedAudio) {
+				t.Errorf("ProbeAudio() error = %v, want %v", err, ErrUnsupportedAudio)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package media
+
+import (
+	"net/http"
+	"strings"
+)
+
+// allowedContentTypes lists what users may attach to a journal entry. The
+// type is always sniffed from the file content, never trusted from the
+// client supplied header or file extension.
+var allowedContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+	"audio/wav":       true,
+	"audio/mpeg":      true,
+	"audio/ogg":       true,
+	"audio/flac":      true,
+	"audio/mp4":       true,
+	"audio/webm":      true,
+}
+
+// DetectContentType sniffs the MIME type of data, dropping any parameters
+// such as charset.
+func DetectContentType(data []byte) string {
+	if audioType := DetectAudioContentType(data); audioType != "" {
+		return audioType
+	}
+
+	contentType := http.DetectContentType(data)
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+func IsAllowedContentType(contentType string) bool {
+	return allowedContentTypes[contentType]
+}
+
+func IsImage(contentType string) bool {
+	return strings.HasPrefix(contentType, "image/")
+}
@@ -0,0 +1,119 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // register decoder
+	"image/jpeg"
+	_ "image/png" // register decoder
+)
+
+const ThumbnailContentType = "image/jpeg"
+
+// MaxImagePixels caps the dimensions of images that are decoded. A small
+// compressed file can declare a huge canvas, and decoding allocates all
+// of it.
+const MaxImagePixels = 40_000_000
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format")
+	ErrImageTooLarge    = errors.New("image dimensions exceed the maximum allowed")
+)
+
+// CheckImageSize reads the dimensions from the image header, without
+// decoding it, and returns ErrImageTooLarge when they exceed
+// MaxImagePixels.
+func CheckImageSize(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrUnsupportedImage
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return ErrUnsupportedImage
+	}
+	if int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return ErrImageTooLarge
+	}
+	return nil
+}
+
+// GenerateThumbnail decodes an image and returns a JPEG that fits inside a
+// maxSize x maxSize box, keeping the aspect ratio. Images already smaller
+// than the box are re-encoded without being upscaled. Images larger than
+// MaxImagePixels are refused before decoding.
+func GenerateThumbnail(data []byte, maxSize int) ([]byte, error) {
+	if err := CheckImageSize(data); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, ErrUnsupportedImage
+	}
+
+	dstWidth, dstHeight := width, height
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			dstWidth = maxSize
+			dstHeight = max(1, height*maxSize/width)
+		} else {
+			dstHeight = maxSize
+			dstWidth = max(1, width*maxSize/height)
+		}
+	}
+
+	dst := downscale(src, dstWidth, dstHeight)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale resizes src using box sampling: every destination pixel is the
+// average of the source pixels it covers.
+func downscale(src image.Image, dstWidth, dstHeight int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/dstHeight
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcHeight/dstHeight)
+
+		for x := 0; x < dstWidth; x++ {
+			x0 := bounds.Min.X + x*srcWidth/dstWidth
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcWidth/dstWidth)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
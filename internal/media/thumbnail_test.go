@@ -0,0 +1,104 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withPNGSize rewrites the dimensions in the IHDR chunk of a small PNG,
+// so it declares a canvas far larger than its pixel data.
+func withPNGSize(data []byte, width, height uint32) []byte {
+	patched := append([]byte{}, data...)
+	// Signature (8), then IHDR: length (4), type (4), width, height
+	binary.BigEndian.PutUint32(patched[16:20], width)
+	binary.BigEndian.PutUint32(patched[20:24], height)
+	binary.BigEndian.PutUint32(patched[29:33], crc32.ChecksumIEEE(patched[12:29]))
+	return patched
+}
+
+func TestCheckImageSize(t *testing.T) {
+	small := testPNG(t, 4, 4)
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "small", data: small},
+		{name: "at the cap", data: withPNGSize(small, 8000, 5000)},
+		{name: "one row over the cap", data: withPNGSize(small, 8000, 5001), want: ErrImageTooLarge},
+		{name: "huge canvas", data: withPNGSize(small, 20000, 20000), want: ErrImageTooLarge},
+		{name: "not an image", data: []byte("hello"), want: ErrUnsupportedImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckImageSize(tt.data); !errors.Is(err, tt.want) {
+				t.Errorf("CheckImageSize() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateThumbnail(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		wantWidth  int
+		wantHeight int
+		wantErr    error
+	}{
+		{name: "landscape", data: testPNG(t, 64, 32), wantWidth: 16, wantHeight: 8},
+		{name: "portrait", data: testPNG(t, 32, 64), wantWidth: 8, wantHeight: 16},
+		{name: "smaller than the box", data: testPNG(t, 10, 5), wantWidth: 10, wantHeight: 5},
+		{name: "over the pixel cap", data: withPNGSize(testPNG(t, 4, 4), 20000, 20000), wantErr: ErrImageTooLarge},
+		{name: "not an image", data: []byte("hello"), wantErr: ErrUnsupportedImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumbnail, err := GenerateThumbnail(tt.data, 16)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GenerateThumbnail() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateThumbnail() error = %v", err)
+			}
+
+			config, format, err := image.DecodeConfig(bytes.NewReader(thumbnail))
+			if err != nil {
+				t.Fatalf("decoding thumbnail: %v", err)
+			}
+			if format != "jpeg" || config.Width != tt.wantWidth || config.Height != tt.wantHeight {
+				t.Errorf("thumbnail = %s %dx%d, want jpeg %dx%d",
+					format, config.Width, config.Height, tt.wantWidth, tt.wantHeight)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -8,10 +9,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/services"
 	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// SessionValidator confirms that a token is still good for its account,
+// so disabled accounts and tokens issued before a forced logout are
+// refused even though they have not expired.
+type SessionValidator interface {
+	ValidateSession(userID uint, tokenVersion int) error
+}
+
+// AuthMiddleware authenticates the bearer token and puts the user's ID and
+// role into the context.
+func AuthMiddleware(jwtSecret string, sessions SessionValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -75,8 +87,56 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued before roles and versions existed carry neither
+		tokenVersion, _ := claims["tv"].(float64)
+		role, _ := claims["role"].(string)
+		if role == "" {
+			role = models.RoleUser
+		}
+
+		if err := sessions.ValidateSession(uint(userID), int(tokenVersion)); err != nil {
+			switch {
+			case errors.Is(err, services.ErrAccountDisabled):
+				c.AbortWithStatusJSON(http.StatusForbidden, helpers.ErrorResponse(
+					apperrors.AccountDisabled,
+					err.Error(),
+				))
+			case errors.Is(err, services.ErrSessionRevoked):
+				c.AbortWithStatusJSON(http.StatusUnauthorized, helpers.ErrorResponse(
+					apperrors.TokenExpired,
+					err.Error(),
+				))
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ErrorResponse(
+					apperrors.InternalServerError,
+					err.Error(),
+				))
+			}
+			return
+		}
+
 		c.Set("userID", uint(userID))
+		c.Set("userRole", role)
 		c.Next()
 	}
 
 }
+
+// RequireRole lets through only users whose token carries one of the
+// roles. It runs after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("userRole")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, helpers.ErrorResponse(
+			apperrors.Forbidden,
+			"This requires the "+strings.Join(roles, " or ")+" role",
+		))
+	}
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/apperrors"
+	"github.com/sugiiianaa/remember-my-story/internal/idempotency"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+const (
+	idempotencyHeader       = "Idempotency-Key"
+	idempotencyReplayHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength = 255
+	idempotencyPollInterval = 100 * time.Millisecond
+)
+
+// replayedHeaders are the response headers stored alongside the body.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type IdempotencyConfig struct {
+	TTL         time.Duration // How long a stored response is replayed
+	LockTTL     time.Duration // How long an unfinished request holds its key
+	WaitTimeout time.Duration // How long a concurrent duplicate waits before 409
+	MaxBodySize int64         // Largest body buffered to fingerprint a request
+}
+
+// IdempotencyMiddleware makes mutating requests sent with an
+// Idempotency-Key header safe to retry. The first response is stored under
+// the user, the key and a fingerprint of the request; retries get that
+// response back instead of running the handler again. It must run after
+// the auth middleware so responses are scoped to the user, and must not be
+// used on routes that issue credentials, which would then sit in the store.
+//
+// Multipart uploads are passed through untouched rather than buffered in
+// memory; the key is ignored for them.
+func IdempotencyMiddleware(store idempotency.Store, config IdempotencyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyHeader)
+		if key == "" || !isMutatingMethod(c.Request.Method) || isMultipart(c) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, helpers.ErrorResponse(
+				apperrors.InvalidRequestData,
+				fmt.Sprintf("%s must be at most %d characters", idempotencyHeader, maxIdempotencyKeyLength),
+			))
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, config.MaxBodySize))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, helpers.ErrorResponse(
+					apperrors.RequestTooLarge,
+					fmt.Sprintf("request body must be at most %d bytes", config.MaxBodySize),
+				))
+				return
+			}
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, helpers.ErrorResponse(
+					apperrors.InvalidRequestData,
+					"unable to read request body",
+				))
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		storeKey := idempotencyStoreKey(c, key, body)
+
+		cached, err := beginIdempotentRequest(c.Request.Context(), store, storeKey, config)
+		if errors.Is(err, idempotency.ErrInProgress) {
+			c.AbortWithStatusJSON(http.StatusConflict, helpers.ErrorResponse(
+				apperrors.RequestInProgress,
+				err.Error(),
+			))
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, helpers.ErrorResponse(
+				apperrors.InternalServerError,
+				err.Error(),
+			))
+			return
+		}
+
+		if cached != nil {
+			for _, name := range replayedHeaders {
+				if value := cached.Header.Get(name); value != "" {
+					c.Header(name, value)
+				}
+			}
+			c.Header(idempotencyReplayHeader, "true")
+			c.Writer.WriteHeader(cached.Status)
+			_, _ = c.Writer.Write(cached.Body)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		completed := false
+		defer func() {
+			// Handler panicked or failed: let the client retry the request
+			if !completed {
+				_ = store.Release(context.Background(), storeKey)
+			}
+		}()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		header := http.Header{}
+		for _, name := range replayedHeaders {
+			if value := c.Writer.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+
+		err = store.Complete(context.Background(), storeKey, idempotency.Response{
+			Status: status,
+			Header: header,
+			Body:   recorder.body.Bytes(),
+		}, config.TTL)
+		completed = err == nil
+	}
+}
+
+// beginIdempotentRequest reserves the key, waiting up to WaitTimeout for a
+// concurrent request with the same key to finish.
+func beginIdempotentRequest(ctx context.Context, store idempotency.Store, key string, config IdempotencyConfig) (*idempotency.Response, error) {
+	deadline := time.Now().Add(config.WaitTimeout)
+
+	for {
+		cached, err := store.Begin(ctx, key, config.LockTTL)
+		if !errors.Is(err, idempotency.ErrInProgress) || time.Now().After(deadline) {
+			return cached, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(idempotencyPollInterval):
+		}
+	}
+}
+
+// idempotencyStoreKey scopes the key to the caller and the exact request,
+// so the same key reused for a different request runs as a new one.
+func idempotencyStoreKey(c *gin.Context, key string, body []byte) string {
+	scope := "anonymous"
+	if userID, exists := c.Get("userID"); exists {
+		scope = fmt.Sprintf("user:%v", userID)
+	}
+
+	fingerprint := sha256.New()
+	fingerprint.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	fingerprint.Write(body)
+
+	hash := sha256.New()
+	hash.Write([]byte(scope + "\x00" + key + "\x00"))
+	hash.Write(fingerprint.Sum(nil))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func isMultipart(c *gin.Context) bool {
+	return strings.HasPrefix(c.ContentType(), "multipart/")
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// responseRecorder copies everything written to the client into body.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,331 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sugiiianaa/remember-my-story/internal/idempotency"
+)
+
+var testIdempotencyConfig = IdempotencyConfig{
+	TTL:         time.Hour,
+	LockTTL:     time.Minute,
+	WaitTimeout: 0,
+	MaxBodySize: 1 << 10,
+}
+
+type idempotentRequest struct {
+	method string
+	path   string
+	user   string
+	key    string
+	body   string
+}
+
+func (r idempotentRequest) send(router http.Handler) *httptest.ResponseRecorder {
+	method := r.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	path := r.path
+	if path == "" {
+		path = "/journals"
+	}
+
+	req := httptest.NewRequest(method, path, strings.NewReader(r.body))
+	req.Header.Set("Content-Type", "application/json")
+	if r.key != "" {
+		req.Header.Set(idempotencyHeader, r.key)
+	}
+	if r.user != "" {
+		req.Header.Set("X-Test-User", r.user)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newIdempotentRouter routes every request to handler behind the
+// middleware. The X-Test-User header stands in for the auth middleware.
+func newIdempotentRouter(store idempotency.Store, config IdempotencyConfig, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery(), func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set("userID", user)
+		}
+		c.Next()
+	})
+	router.Any("/*path", IdempotencyMiddleware(store, config), handler)
+	return router
+}
+
+// countingHandler answers with status and the number of runs so far.
+func countingHandler(runs *atomic.Int32, status int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		n := runs.Add(1)
+		c.Header("Location", fmt.Sprintf("/journals/%d", n))
+		c.Header("ETag", fmt.Sprintf(`"%d"`, n))
+		c.Header("X-Not-Replayed", "yes")
+		c.JSON(status, gin.H{"run": n})
+	}
+}
+
+func TestIdempotencyMiddlewareReplay(t *testing.T) {
+	first := idempotentRequest{user: "1", key: "key-1", body: `{"mood":"happy"}`}
+
+	tests := []struct {
+		name         string
+		status       int
+		second       idempotentRequest
+		wantRuns     int32
+		wantReplayed bool
+	}{
+		{name: "retry is replayed", status: http.StatusCreated, second: first, wantRuns: 1, wantReplayed: true},
+		{name: "client errors are replayed", status: http.StatusUnprocessableEntity, second: first, wantRuns: 1, wantReplayed: true},
+		{name: "server errors are not stored", status: http.StatusInternalServerError, second: first, wantRuns: 2},
+		{
+			name:     "same key with another body",
+			status:   http.StatusCreated,
+			second:   idempotentRequest{user: "1", key: "key-1", body: `{"mood":"sad"}`},
+			wantRuns: 2,
+		},
+		{
+			name:     "same key on another path",
+			status:   http.StatusCreated,
+			second:   idempotentRequest{path: "/tasks", user: "1", key: "key-1", body: `{"mood":"happy"}`},
+			wantRuns: 2,
+		},
+		{
+			name:     "same key from another user",
+			status:   http.StatusCreated,
+			second:   idempotentRequest{user: "2", key: "key-1", body: `{"mood":"happy"}`},
+			wantRuns: 2,
+		},
+		{
+			name:     "another key",
+			status:   http.StatusCreated,
+			second:   idempotentRequest{user: "1", key: "key-2", body: `{"mood":"happy"}`},
+			wantRuns: 2,
+		},
+		{
+			name:     "retry without a key",
+			status:   http.StatusCreated,
+			second:   idempotentRequest{user: "1", body: `{"mood":"happy"}`},
+			wantRuns: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs atomic.Int32
+			router := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, countingHandler(&runs, tt.status))
+
+			original := first.send(router)
+			retry := tt.second.send(router)
+
+			if got := runs.Load(); got != tt.wantRuns {
+				t.Fatalf("handler ran %d times, want %d", got, tt.wantRuns)
+			}
+			if got := retry.Header().Get(idempotencyReplayHeader) == "true"; got != tt.wantReplayed {
+				t.Fatalf("%s = %v, want %v", idempotencyReplayHeader, got, tt.wantReplayed)
+			}
+			if !tt.wantReplayed {
+				return
+			}
+
+			if retry.Code != original.Code || retry.Body.String() != original.Body.String() {
+				t.Errorf("replay = %d %s, want %d %s", retry.Code, retry.Body, original.Code, original.Body)
+			}
+			for _, name := range []string{"Content-Type", "ETag", "Location"} {
+				if retry.Header().Get(name) != original.Header().Get(name) {
+					t.Errorf("replayed %s = %q, want %q", name, retry.Header().Get(name), original.Header().Get(name))
+				}
+			}
+			if retry.Header().Get("X-Not-Replayed") != "" {
+				t.Error("replay carries a header that is not stored")
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewarePassesThrough(t *testing.T) {
+	multipartBody := func() (string, string) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		_ = writer.WriteField("name", "memo")
+		_ = writer.Close()
+		return buf.String(), writer.FormDataContentType()
+	}
+
+	tests := []struct {
+		name      string
+		method    string
+		multipart bool
+	}{
+		{name: "GET", method: http.MethodGet},
+		{name: "HEAD", method: http.MethodHead},
+		{name: "OPTIONS", method: http.MethodOptions},
+		{name: "multipart upload", method: http.MethodPost, multipart: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs atomic.Int32
+			router := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, countingHandler(&runs, http.StatusOK))
+
+			body, contentType := "", "application/json"
+			if tt.multipart {
+				body, contentType = multipartBody()
+			}
+
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(tt.method, "/journals", strings.NewReader(body))
+				req.Header.Set("Content-Type", contentType)
+				req.Header.Set(idempotencyHeader, "key-1")
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				if w.Header().Get(idempotencyReplayHeader) != "" {
+					t.Fatalf("request %d was replayed", i+1)
+				}
+			}
+			if got := runs.Load(); got != 2 {
+				t.Errorf("handler ran %d times, want 2", got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    idempotentRequest
+		wantStatus int
+	}{
+		{
+			name:       "key too long",
+			request:    idempotentRequest{key: strings.Repeat("k", maxIdempotencyKeyLength+1), body: `{}`},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "body too large",
+			request:    idempotentRequest{key: "key-1", body: strings.Repeat("a", int(testIdempotencyConfig.MaxBodySize)+1)},
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var runs atomic.Int32
+			router := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, countingHandler(&runs, http.StatusCreated))
+
+			if w := tt.request.send(router); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := runs.Load(); got != 0 {
+				t.Errorf("handler ran %d times, want 0", got)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareConflictWhileInProgress(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	var runs atomic.Int32
+
+	router := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, func(c *gin.Context) {
+		if runs.Add(1) == 1 {
+			close(started)
+			<-finish
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	request := idempotentRequest{user: "1", key: "key-1", body: `{"mood":"happy"}`}
+
+	var wg sync.WaitGroup
+	var original *httptest.ResponseRecorder
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		original = request.send(router)
+	}()
+	<-started
+
+	if w := request.send(router); w.Code != http.StatusConflict {
+		t.Errorf("concurrent duplicate status = %d, want %d", w.Code, http.StatusConflict)
+	}
+
+	close(finish)
+	wg.Wait()
+	if original.Code != http.StatusCreated {
+		t.Fatalf("original status = %d, want %d", original.Code, http.StatusCreated)
+	}
+
+	retry := request.send(router)
+	if retry.Header().Get(idempotencyReplayHeader) != "true" || runs.Load() != 1 {
+		t.Errorf("retry after completion was not replayed: handler ran %d times", runs.Load())
+	}
+}
+
+func TestIdempotencyMiddlewareWaitsForConcurrentDuplicate(t *testing.T) {
+	started := make(chan struct{})
+	var runs atomic.Int32
+
+	config := testIdempotencyConfig
+	config.WaitTimeout = 5 * time.Second
+	router := newIdempotentRouter(idempotency.NewMemoryStore(), config, func(c *gin.Context) {
+		if runs.Add(1) == 1 {
+			close(started)
+			time.Sleep(2 * idempotencyPollInterval)
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	request := idempotentRequest{user: "1", key: "key-1", body: `{}`}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		request.send(router)
+	}()
+	<-started
+
+	duplicate := request.send(router)
+	<-done
+
+	if duplicate.Code != http.StatusCreated || duplicate.Header().Get(idempotencyReplayHeader) != "true" {
+		t.Errorf("duplicate = %d replayed %q, want the replayed 201", duplicate.Code, duplicate.Header().Get(idempotencyReplayHeader))
+	}
+	if got := runs.Load(); got != 1 {
+		t.Errorf("handler ran %d times, want 1", got)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesKeyAfterPanic(t *testing.T) {
+	var runs atomic.Int32
+	router := newIdempotentRouter(idempotency.NewMemoryStore(), testIdempotencyConfig, func(c *gin.Context) {
+		if runs.Add(1) == 1 {
+			panic("handler failed")
+		}
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	request := idempotentRequest{user: "1", key: "key-1", body: `{}`}
+
+	if w := request.send(router); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if w := request.send(router); w.Code != http.StatusCreated || w.Header().Get(idempotencyReplayHeader) != "" {
+		t.Errorf("retry = %d replayed %q, want a fresh 201", w.Code, w.Header().Get(idempotencyReplayHeader))
+	}
+}
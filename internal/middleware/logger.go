@@ -17,6 +17,7 @@ func LoggingMiddleware(logger *logrus.Logger, env string) gin.HandlerFunc {
 		if requestID == "" {
 			requestID = "unknown"
 		}
+		c.Set("requestID", requestID)
 
 		// Capture request body for logging (only in debug mode)
 		var requestBody string
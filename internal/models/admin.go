@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Account states an admin can filter users by
+const (
+	AccountStatusActive   = "active"
+	AccountStatusDisabled = "disabled"
+	AccountStatusLocked   = "locked" // Temporarily, after too many wrong passwords
+)
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// AdminUserQuery filters the admin's user list. Search matches the email
+// or name.
+type AdminUserQuery struct {
+	Search string
+	Role   string
+	Status string
+}
+
+// AdminUserResponse describes an account without any of its journal
+// content; Entries is only a count.
+type AdminUserResponse struct {
+	ID             uint       `json:"id"`
+	Email          string     `json:"email"`
+	FullName       string     `json:"full_name"`
+	Role           string     `json:"role"`
+	Status         string     `json:"status"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason string     `json:"disabled_reason,omitempty"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	FailedLogins   int        `json:"failed_logins"`
+	LastLoginAt    *time.Time `json:"last_login_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	Entries        *int64     `json:"entries,omitempty"` // Only on the detail view
+}
+
+type AdminUsersResponse struct {
+	Users []AdminUserResponse `json:"users"`
+	Total int64               `json:"total"`
+}
+
+type AdminDisableRequest struct {
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+type AdminRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+type AdminUserStats struct {
+	Total              int64 `json:"total"`
+	Active             int64 `json:"active"`
+	Disabled           int64 `json:"disabled"`
+	Locked             int64 `json:"locked"`
+	Admins             int64 `json:"admins"`
+	NewLast7Days       int64 `json:"new_last_7_days"`
+	NewLast30Days      int64 `json:"new_last_30_days"`
+	LoggedInLast7Days  int64 `json:"logged_in_last_7_days"`
+	LoggedInLast30Days int64 `json:"logged_in_last_30_days"`
+}
+
+type AdminContentStats struct {
+	Entries           int64 `json:"entries"`
+	PublishedEntries  int64 `json:"published_entries"`
+	EntriesLast7Days  int64 `json:"entries_last_7_days"`
+	EntriesLast30Days int64 `json:"entries_last_30_days"`
+	WritersLast30Days int64 `json:"writers_last_30_days"` // Users with an entry in the period
+	Notebooks         int64 `json:"notebooks"`
+	Attachments       int64 `json:"attachments"`
+	AttachmentBytes   int64 `json:"attachment_bytes"`
+	ActiveShareLinks  int64 `json:"active_share_links"`
+	ReaderGrants      int64 `json:"reader_grants"`
+	Organizations     int64 `json:"organizations"`
+}
+
+// AdminStats holds aggregate counts only, never anything users wrote.
+type AdminStats struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Users       AdminUserStats    `json:"users"`
+	Content     AdminContentStats `json:"content"`
+}
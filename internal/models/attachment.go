@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+type Attachment struct {
+	gorm.Model
+	JournalEntryID uint   `gorm:"not null; index"`
+	UserID         uint   `gorm:"not null; index"`
+	FileName       string `gorm:"not null"`
+	ContentType    string `gorm:"not null"`
+	Size           int64  `gorm:"not null"`
+	Checksum       string `gorm:"not null"`
+	StorageKey     string `gorm:"not null; unique"`
+	ThumbnailKey   string
+
+	// Audio metadata, only filled for voice memos
+	DurationMs            int64
+	Codec                 string
+	SampleRate            int
+	Channels              int
+	Transcript            string
+	TranscriptStatus      enums.TranscriptStatusType `gorm:"not null; default:0; index"`
+	TranscriptRequestedAt *time.Time                 // When the pending transcription was asked for
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type AttachmentResponse struct {
+	ID               uint                       `json:"id"`
+	JournalEntryID   uint                       `json:"journal_entry_id"`
+	Kind             string                     `json:"kind"`
+	FileName         string                     `json:"file_name"`
+	ContentType      string                     `json:"content_type"`
+	Size             int64                      `json:"size"`
+	Checksum         string                     `json:"checksum"`
+	DurationMs       int64                      `json:"duration_ms,omitempty"`
+	Codec            string                     `json:"codec,omitempty"`
+	SampleRate       int                        `json:"sample_rate,omitempty"`
+	Channels         int                        `json:"channels,omitempty"`
+	Transcript       string                     `json:"transcript,omitempty"`
+	TranscriptStatus enums.TranscriptStatusType `json:"transcript_status"`
+	DownloadURL      string                     `json:"download_url"`
+	ThumbnailURL     string                     `json:"thumbnail_url,omitempty"`
+	URLExpiresAt     time.Time                  `json:"url_expires_at"`
+	CreatedAt        time.Time                  `json:"created_at"`
+}
+
+type UpdateTranscriptRequest struct {
+	Transcript string `json:"transcript"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "encoding/json"
+
+const (
+	BatchModeAtomic     = "atomic"
+	BatchModeBestEffort = "best_effort"
+
+	BatchActionCreate = "create"
+	BatchActionUpdate = "update"
+	BatchActionDelete = "delete"
+
+	BatchEntityJournalEntry = "journal_entry"
+	BatchEntityDailyTask    = "daily_task"
+)
+
+// --------------------------
+// Dtos
+// --------------------------
+type BatchRequest struct {
+	Mode       string                  `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperationRequest `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// BatchOperationRequest is one operation of a batch. Data has the same
+// shape as the body of the matching single-item endpoint: a journal entry
+// for entry creates, UpdateJournalRequest for entry updates,
+// DailyTaskRequest for task creates and PatchDailyTaskRequest for task
+// updates. Version, when set, must match the current entry version.
+type BatchOperationRequest struct {
+	Action         string          `json:"action" binding:"required,oneof=create update delete"`
+	Entity         string          `json:"entity" binding:"required,oneof=journal_entry daily_task"`
+	ID             uint            `json:"id"`
+	JournalEntryID uint            `json:"journal_entry_id"`
+	Version        int             `json:"version"`
+	Data           json.RawMessage `json:"data"`
+}
+
+type BatchResponse struct {
+	Mode      string                 `json:"mode"`
+	Committed bool                   `json:"committed"`
+	Results   []BatchOperationResult `json:"results"`
+}
+
+type BatchOperationResult struct {
+	Index     int    `json:"index"`
+	Status    string `json:"status"` // ok, error, rolled_back or skipped
+	ID        uint   `json:"id,omitempty"`
+	Version   int    `json:"version,omitempty"` // Version of the affected entry
+	ErrorCode string `json:"error_code,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reaction kinds readers can leave on an entry
+const (
+	ReactionHeart     = "heart"
+	ReactionHug       = "hug"
+	ReactionStrength  = "strength"
+	ReactionCelebrate = "celebrate"
+	ReactionInsight   = "insight"
+)
+
+// ReactionKinds lists the reaction kinds in display order.
+var ReactionKinds = []string{ReactionHeart, ReactionHug, ReactionStrength, ReactionCelebrate, ReactionInsight}
+
+// Comment is left on an entry by its owner or by a reader allowed to
+// comment. Threads are one level deep: replies point at a top-level
+// comment.
+type Comment struct {
+	gorm.Model
+	JournalEntryID uint   `gorm:"not null; index"`
+	AuthorID       uint   `gorm:"not null; index"`
+	ParentID       *uint  `gorm:"index"` // Top-level comment replied to
+	Body           string `gorm:"type:text; not null"`
+	EditedAt       *time.Time
+}
+
+// Reaction is one user's reaction of a kind to an entry.
+type Reaction struct {
+	ID             uint      `gorm:"primaryKey"`
+	JournalEntryID uint      `gorm:"not null; uniqueIndex:idx_reaction,priority:1"`
+	UserID         uint      `gorm:"not null; uniqueIndex:idx_reaction,priority:2"`
+	Kind           string    `gorm:"size:20; not null; uniqueIndex:idx_reaction,priority:3"`
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+type CommentRequest struct {
+	Body     string `json:"body" binding:"required,max=2000"`
+	ParentID *uint  `json:"parent_id"`
+}
+
+type UpdateCommentRequest struct {
+	Body string `json:"body" binding:"required,max=2000"`
+}
+
+type CommentSettingsRequest struct {
+	CommentsDisabled *bool `json:"comments_disabled" binding:"required"`
+}
+
+type CommentResponse struct {
+	ID        uint              `json:"id"`
+	EntryID   uint              `json:"entry_id"`
+	ParentID  *uint             `json:"parent_id,omitempty"`
+	Author    *ReaderUser       `json:"author,omitempty"`
+	Body      string            `json:"body"`
+	CreatedAt time.Time         `json:"created_at"`
+	EditedAt  *time.Time        `json:"edited_at,omitempty"`
+	CanEdit   bool              `json:"can_edit"`
+	CanDelete bool              `json:"can_delete"`
+	Replies   []CommentResponse `json:"replies,omitempty"`
+}
+
+type CommentsResponse struct {
+	CommentsDisabled bool              `json:"comments_disabled"`
+	Comments         []CommentResponse `json:"comments"`
+}
+
+type ReactionCount struct {
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"` // Whether the requesting user left one
+}
+
+type ReactionsResponse struct {
+	Reactions []ReactionCount `json:"reactions"`
+}
@@ -4,7 +4,8 @@ import "gorm.io/gorm"
 
 type DailySubTask struct {
 	gorm.Model
-	DailyTaskID uint `gorm:"index"` // Changed from TaskId to DailyTaskID
+	DailyTaskID uint    `gorm:"index"`                  // Changed from TaskId to DailyTaskID
+	ClientID    *string `gorm:"type:uuid; uniqueIndex"` // Generated by offline clients
 	SubTask     string
 	Status      bool
 }
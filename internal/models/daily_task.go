@@ -1,13 +1,21 @@
 package models
 
 import (
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
 	"gorm.io/gorm"
 )
 
 type DailyTask struct {
 	gorm.Model
-	JournalEntryID uint `gorm:"index"` // Add index for better performance
-	Task           string
-	Status         bool
-	SubTasks       []DailySubTask `gorm:"foreignKey:DailyTaskID"`
+	JournalEntryID    uint               `gorm:"index; uniqueIndex:idx_daily_task_routine,priority:1"` // Add index for better performance
+	ClientID          *string            `gorm:"type:uuid; uniqueIndex"`                               // Generated by offline clients
+	RoutineID         *uint              `gorm:"uniqueIndex:idx_daily_task_routine,priority:2"`        // Set when copied from a routine
+	CarriedOverFromID *uint              `gorm:"uniqueIndex"`                                          // Unfinished task this one continues
+	Position          int                `gorm:"not null; default:0"`
+	Priority          enums.PriorityType `gorm:"not null; default:0"`
+	DueTime           *string            `gorm:"size:5"` // HH:MM on the entry's day, in the user's time zone
+	Notes             string             `gorm:"not null; default:''"`
+	Task              string
+	Status            bool
+	SubTasks          []DailySubTask `gorm:"foreignKey:DailyTaskID"`
 }
@@ -0,0 +1,57 @@
+package enums
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type EntryStatusType int
+
+// EntryStatus "namespace" struct
+var EntryStatus = struct {
+	Unknown   EntryStatusType
+	Draft     EntryStatusType
+	Published EntryStatusType
+}{
+	Unknown:   0,
+	Draft:     1,
+	Published: 2,
+}
+
+func (s EntryStatusType) String() string {
+	switch s {
+	case EntryStatus.Draft:
+		return "Draft"
+	case EntryStatus.Published:
+		return "Published"
+	default:
+		return "Unknown"
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (s EntryStatusType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *EntryStatusType) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return err
+	}
+
+	*s = entryStatusFromString(str)
+	return nil
+}
+
+func entryStatusFromString(s string) EntryStatusType {
+	switch strings.ToLower(s) {
+	case "draft":
+		return EntryStatus.Draft
+	case "published":
+		return EntryStatus.Published
+	default:
+		return EntryStatus.Unknown
+	}
+}
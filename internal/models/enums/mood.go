@@ -57,6 +57,11 @@ func (m *MoodType) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ParseMood returns the mood named s, or Mood.Unknown.
+func ParseMood(s string) MoodType {
+	return moodFromString(s)
+}
+
 func moodFromString(s string) MoodType {
 	switch strings.ToLower(s) {
 	case "happy":
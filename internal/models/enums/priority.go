@@ -0,0 +1,63 @@
+package enums
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type PriorityType int
+
+// Priority "namespace" struct
+var Priority = struct {
+	None   PriorityType
+	Low    PriorityType
+	Medium PriorityType
+	High   PriorityType
+}{
+	None:   0,
+	Low:    1,
+	Medium: 2,
+	High:   3,
+}
+
+func (p PriorityType) String() string {
+	switch p {
+	case Priority.Low:
+		return "Low"
+	case Priority.Medium:
+		return "Medium"
+	case Priority.High:
+		return "High"
+	default:
+		return "None"
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (p PriorityType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (p *PriorityType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*p = priorityFromString(s)
+	return nil
+}
+
+func priorityFromString(s string) PriorityType {
+	switch strings.ToLower(s) {
+	case "low":
+		return Priority.Low
+	case "medium":
+		return Priority.Medium
+	case "high":
+		return Priority.High
+	default:
+		return Priority.None
+	}
+}
@@ -0,0 +1,67 @@
+package enums
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPriorityJSON(t *testing.T) {
+	tests := []struct {
+		priority PriorityType
+		json     string
+	}{
+		{priority: Priority.None, json: `"None"`},
+		{priority: Priority.Low, json: `"Low"`},
+		{priority: Priority.Medium, json: `"Medium"`},
+		{priority: Priority.High, json: `"High"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.priority.String(), func(t *testing.T) {
+			data, err := json.Marshal(tt.priority)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("Marshal() = %s, want %s", data, tt.json)
+			}
+
+			var got PriorityType
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got != tt.priority {
+				t.Errorf("Unmarshal(%s) = %v, want %v", data, got, tt.priority)
+			}
+		})
+	}
+}
+
+func TestPriorityUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    PriorityType
+		wantErr bool
+	}{
+		{name: "lower case", json: `"high"`, want: Priority.High},
+		{name: "upper case", json: `"MEDIUM"`, want: Priority.Medium},
+		{name: "unknown name", json: `"urgent"`, want: Priority.None},
+		{name: "empty", json: `""`, want: Priority.None},
+		{name: "number", json: `3`, wantErr: true},
+		{name: "null", json: `null`, want: Priority.None},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got PriorityType
+			err := json.Unmarshal([]byte(tt.json), &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal(%s) error = %v, want error %v", tt.json, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.json, got, tt.want)
+			}
+		})
+	}
+}
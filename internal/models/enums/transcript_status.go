@@ -0,0 +1,69 @@
+package enums
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+type TranscriptStatusType int
+
+// TranscriptStatus "namespace" struct
+var TranscriptStatus = struct {
+	None        TranscriptStatusType
+	Pending     TranscriptStatusType
+	Completed   TranscriptStatusType
+	Failed      TranscriptStatusType
+	Unavailable TranscriptStatusType
+}{
+	None:        0,
+	Pending:     1,
+	Completed:   2,
+	Failed:      3,
+	Unavailable: 4,
+}
+
+func (t TranscriptStatusType) String() string {
+	switch t {
+	case TranscriptStatus.Pending:
+		return "Pending"
+	case TranscriptStatus.Completed:
+		return "Completed"
+	case TranscriptStatus.Failed:
+		return "Failed"
+	case TranscriptStatus.Unavailable:
+		return "Unavailable"
+	default:
+		return "None"
+	}
+}
+
+// MarshalJSON implements json.Marshaler
+func (t TranscriptStatusType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *TranscriptStatusType) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	*t = transcriptStatusFromString(s)
+	return nil
+}
+
+func transcriptStatusFromString(s string) TranscriptStatusType {
+	switch strings.ToLower(s) {
+	case "pending":
+		return TranscriptStatus.Pending
+	case "completed":
+		return TranscriptStatus.Completed
+	case "failed":
+		return TranscriptStatus.Failed
+	case "unavailable":
+		return TranscriptStatus.Unavailable
+	default:
+		return TranscriptStatus.None
+	}
+}
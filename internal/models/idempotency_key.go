@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// IdempotencyKey stores the first response to a request sent with an
+// Idempotency-Key header. Rows without a response mark requests that are
+// still being processed.
+type IdempotencyKey struct {
+	Key            string    `gorm:"primaryKey; size:64"` // sha256 of user, key and request fingerprint
+	ResponseStatus int       `gorm:"not null; default:0"`
+	ResponseHeader JSONB     `gorm:"type:jsonb"`
+	ResponseBody   []byte    `gorm:"type:bytea"`
+	Completed      bool      `gorm:"not null; default:false"`
+	ExpiresAt      time.Time `gorm:"not null; index"`
+	CreatedAt      time.Time
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// HabitInsightsResponse summarizes task completion over a date range. A
+// habit is a routine, or for ad-hoc tasks, every task with the same text.
+type HabitInsightsResponse struct {
+	From                time.Time            `json:"from"`
+	To                  time.Time            `json:"to"`
+	DerivedFromSubTasks bool                 `json:"derived_from_sub_tasks"`
+	Tracked             int                  `json:"tracked"`
+	Completed           int                  `json:"completed"`
+	CompletionRate      float64              `json:"completion_rate"`
+	BestWeekday         *string              `json:"best_weekday,omitempty"`
+	WorstWeekday        *string              `json:"worst_weekday,omitempty"`
+	Weekdays            []WeekdayStats       `json:"weekdays"`
+	Habits              []HabitStats         `json:"habits"`
+	Weekly              []WeeklyHabitSummary `json:"weekly"`
+}
+
+// HabitStats describes one habit. Streaks count consecutive tracked days
+// on which the habit was completed, so a routine that only occurs on
+// Mondays keeps its streak across the rest of the week.
+type HabitStats struct {
+	Key            string         `json:"key"`
+	Label          string         `json:"label"`
+	RoutineID      *uint          `json:"routine_id,omitempty"`
+	Tracked        int            `json:"tracked"`
+	Completed      int            `json:"completed"`
+	CompletionRate float64        `json:"completion_rate"`
+	CurrentStreak  int            `json:"current_streak"`
+	LongestStreak  int            `json:"longest_streak"`
+	LastTracked    time.Time      `json:"last_tracked"`
+	BestWeekday    *string        `json:"best_weekday,omitempty"`
+	WorstWeekday   *string        `json:"worst_weekday,omitempty"`
+	Weekdays       []WeekdayStats `json:"weekdays"`
+}
+
+type WeekdayStats struct {
+	Weekday        string  `json:"weekday"`
+	Tracked        int     `json:"tracked"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+type WeeklyHabitSummary struct {
+	WeekStart      time.Time `json:"week_start"`
+	Habits         int       `json:"habits"`
+	Tracked        int       `json:"tracked"`
+	Completed      int       `json:"completed"`
+	CompletionRate float64   `json:"completion_rate"`
+}
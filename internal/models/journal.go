@@ -9,10 +9,231 @@ import (
 
 type JournalEntry struct {
 	gorm.Model
-	Date               time.Time      `gorm:"not null; index"`
-	Mood               enums.MoodType `gorm:"not null; index"`
-	ThisDayDescription string         `gorm:"not null"`
-	DailyReflection    string         `gorm:"not null"`
-	UserID             uint           `gorm:"not null; index"`
-	DailyTasks         []DailyTask    `gorm:"foreignKey:JournalEntryID"`
+	Date               time.Time             `gorm:"not null; index"`
+	Mood               enums.MoodType        `gorm:"not null; index"`
+	ThisDayDescription string                `gorm:"not null"`
+	DailyReflection    string                `gorm:"not null"`
+	UserID             uint                  `gorm:"not null; index"`
+	ClientID           *string               `gorm:"type:uuid; uniqueIndex"`     // Generated by offline clients
+	Status             enums.EntryStatusType `gorm:"not null; default:2; index"` // Rows predating drafts are published
+	Version            int                   `gorm:"not null; default:1"`        // Bumped on every write, exposed as ETag
+	NotebookID         *uint                 `gorm:"index"`                      // Nil for entries of the default notebook written before notebooks
+	PromptID           *uint                 `gorm:"index"`                      // Prompt the reflection answers
+	TemplateID         *uint                 `gorm:"index"`
+	TemplateVersion    *int                  // Template version TemplateValues were validated against
+	TemplateValues     JSONB                 `gorm:"type:jsonb"`
+	CommentsDisabled   bool                  `gorm:"not null; default:false"` // Set by the owner to close the entry to new comments
+	DailyTasks         []DailyTask           `gorm:"foreignKey:JournalEntryID"`
+	Attachments        []Attachment          `gorm:"foreignKey:JournalEntryID"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// CreateJournalRequest is the body of a new entry. Its keys are the entry
+// model's field names, but it has no IDs and no associations besides new
+// tasks, so nothing that already exists can be attached to the entry.
+type CreateJournalRequest struct {
+	Date               time.Time
+	Mood               enums.MoodType
+	ThisDayDescription string
+	DailyReflection    string
+	Status             enums.EntryStatusType
+	NotebookID         *uint
+	PromptID           *uint
+	TemplateID         *uint
+	TemplateValues     JSONB
+	DailyTasks         []CreateDailyTaskRequest
+}
+
+type CreateDailyTaskRequest struct {
+	Task     string
+	Status   bool
+	Priority enums.PriorityType
+	DueTime  *string
+	Notes    string
+	Position int
+	SubTasks []CreateDailySubTaskRequest
+}
+
+type CreateDailySubTaskRequest struct {
+	SubTask string
+	Status  bool
+}
+
+// ToJournalEntry builds the new entry owned by userID.
+func (r CreateJournalRequest) ToJournalEntry(userID uint) JournalEntry {
+	entry := JournalEntry{
+		Date:               r.Date,
+		Mood:               r.Mood,
+		ThisDayDescription: r.ThisDayDescription,
+		DailyReflection:    r.DailyReflection,
+		UserID:             userID,
+		Status:             r.Status,
+		NotebookID:         r.NotebookID,
+		PromptID:           r.PromptID,
+		TemplateID:         r.TemplateID,
+		TemplateValues:     r.TemplateValues,
+	}
+	for _, taskReq := range r.DailyTasks {
+		task := DailyTask{
+			Task:     taskReq.Task,
+			Status:   taskReq.Status,
+			Priority: taskReq.Priority,
+			DueTime:  taskReq.DueTime,
+			Notes:    taskReq.Notes,
+			Position: taskReq.Position,
+		}
+		for _, subTaskReq := range taskReq.SubTasks {
+			task.SubTasks = append(task.SubTasks, DailySubTask{
+				SubTask: subTaskReq.SubTask,
+				Status:  subTaskReq.Status,
+			})
+		}
+		entry.DailyTasks = append(entry.DailyTasks, task)
+	}
+	return entry
+}
+
+type UpdateJournalRequest struct {
+	Mood               enums.MoodType     `json:"mood" binding:"required"`
+	ThisDayDescription string             `json:"this_day_description" binding:"required"`
+	DailyReflection    string             `json:"daily_reflection" binding:"required"`
+	DailyTasks         []DailyTaskRequest `json:"daily_tasks" binding:"dive"`
+	PromptID           *uint              `json:"prompt_id"`       // Omitted leaves it unchanged, 0 clears it
+	TemplateID         *uint              `json:"template_id"`     // Omitted leaves it unchanged, 0 clears it
+	TemplateValues     JSONB              `json:"template_values"` // Checked against the template's latest version
+}
+
+// AutosaveJournalRequest carries the fields an editor periodically saves.
+// Omitted fields are left unchanged.
+type AutosaveJournalRequest struct {
+	Mood               *enums.MoodType `json:"mood"`
+	ThisDayDescription *string         `json:"this_day_description"`
+	DailyReflection    *string         `json:"daily_reflection"`
+}
+
+type AutosaveResponse struct {
+	JournalID uint      `json:"journal_id"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TaskOrderRequest struct {
+	TaskIDs []uint `json:"task_ids" binding:"required"`
+}
+
+// CarryOverRequest copies unfinished tasks into a later day. ToDate
+// (YYYY-MM-DD) defaults to the day after the entry; TaskIDs limits the
+// copy to some of the unfinished tasks.
+type CarryOverRequest struct {
+	ToDate  string `json:"to_date" binding:"omitempty,datetime=2006-01-02"`
+	TaskIDs []uint `json:"task_ids"`
+}
+
+type CarryOverResponse struct {
+	JournalID uint      `json:"journal_id"`
+	Date      time.Time `json:"date"`
+	Carried   int       `json:"carried"`
+	Version   int       `json:"version"`
+}
+
+type TrashedEntryResponse struct {
+	ID                 uint           `json:"id"`
+	Date               time.Time      `json:"date"`
+	Mood               enums.MoodType `json:"mood"`
+	ThisDayDescription string         `json:"this_day_description"`
+	DeletedAt          time.Time      `json:"deleted_at"`
+	PurgeAt            time.Time      `json:"purge_at"`
+}
+
+// DailyTaskRequest describes the desired state of a task. Tasks (and
+// subtasks) without an ID are created, the others are updated in place.
+type DailyTaskRequest struct {
+	ID       uint                  `json:"id"`
+	Task     string                `json:"task" binding:"required"`
+	Status   bool                  `json:"status"`
+	Priority enums.PriorityType    `json:"priority"`
+	DueTime  *string               `json:"due_time" binding:"omitempty,datetime=15:04"`
+	Notes    string                `json:"notes"`
+	SubTasks []DailySubTaskRequest `json:"sub_tasks" binding:"dive"`
+}
+
+// PatchDailyTaskRequest changes a single task. Omitted fields are left
+// unchanged; when sub_tasks is present it replaces the task's subtasks.
+type PatchDailyTaskRequest struct {
+	Task     *string                `json:"task" binding:"omitempty,min=1"`
+	Status   *bool                  `json:"status"`
+	Priority *enums.PriorityType    `json:"priority"`
+	DueTime  *string                `json:"due_time" binding:"omitempty,len=0|datetime=15:04"` // Empty string clears it
+	Notes    *string                `json:"notes"`
+	SubTasks *[]DailySubTaskRequest `json:"sub_tasks" binding:"omitempty,dive"`
+}
+
+// ApplyTo updates task in place.
+func (r PatchDailyTaskRequest) ApplyTo(task *DailyTask) {
+	if r.Task != nil {
+		task.Task = *r.Task
+	}
+	if r.Status != nil {
+		task.Status = *r.Status
+	}
+	if r.Priority != nil {
+		task.Priority = *r.Priority
+	}
+	if r.DueTime != nil {
+		task.DueTime = r.DueTime
+		if *r.DueTime == "" {
+			task.DueTime = nil
+		}
+	}
+	if r.Notes != nil {
+		task.Notes = *r.Notes
+	}
+	if r.SubTasks != nil {
+		task.SubTasks = toDailySubTasks(*r.SubTasks)
+	}
+}
+
+type DailySubTaskRequest struct {
+	ID      uint   `json:"id"`
+	SubTask string `json:"sub_task" binding:"required"`
+	Status  bool   `json:"status"`
+}
+
+// ToDailyTasks converts the request tasks into models.
+func (r UpdateJournalRequest) ToDailyTasks() []DailyTask {
+	tasks := make([]DailyTask, 0, len(r.DailyTasks))
+	for _, taskReq := range r.DailyTasks {
+		tasks = append(tasks, taskReq.ToDailyTask())
+	}
+	return tasks
+}
+
+// ToDailyTask converts the request into a task model with its subtasks.
+func (r DailyTaskRequest) ToDailyTask() DailyTask {
+	task := DailyTask{
+		Task:     r.Task,
+		Status:   r.Status,
+		Priority: r.Priority,
+		DueTime:  r.DueTime,
+		Notes:    r.Notes,
+	}
+	task.ID = r.ID
+	task.SubTasks = toDailySubTasks(r.SubTasks)
+	return task
+}
+
+func toDailySubTasks(requests []DailySubTaskRequest) []DailySubTask {
+	subTasks := make([]DailySubTask, 0, len(requests))
+	for _, subTaskReq := range requests {
+		subTask := DailySubTask{
+			SubTask: subTaskReq.SubTask,
+			Status:  subTaskReq.Status,
+		}
+		subTask.ID = subTaskReq.ID
+		subTasks = append(subTasks, subTask)
+	}
+	return subTasks
 }
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+// JournalRevision is an immutable snapshot of a journal entry and its
+// tasks, written every time the entry changes. It intentionally has no
+// UpdatedAt/DeletedAt columns.
+type JournalRevision struct {
+	ID                   uint      `gorm:"primarykey"`
+	JournalEntryID       uint      `gorm:"not null; uniqueIndex:idx_journal_revision"`
+	Revision             int       `gorm:"not null; uniqueIndex:idx_journal_revision"`
+	UserID               uint      `gorm:"not null; index"`
+	Snapshot             JSONB     `gorm:"not null"`
+	RestoredFromRevision *int      // Set when the revision was produced by a restore
+	CreatedAt            time.Time `gorm:"index"`
+}
+
+// EntrySnapshot is the content captured in JournalRevision.Snapshot.
+type EntrySnapshot struct {
+	Date               time.Time      `json:"date"`
+	Mood               enums.MoodType `json:"mood"`
+	ThisDayDescription string         `json:"this_day_description"`
+	DailyReflection    string         `json:"daily_reflection"`
+	DailyTasks         []TaskSnapshot `json:"daily_tasks"`
+	TemplateID         *uint          `json:"template_id,omitempty"`
+	TemplateVersion    *int           `json:"template_version,omitempty"`
+	TemplateValues     JSONB          `json:"template_values,omitempty"`
+}
+
+type TaskSnapshot struct {
+	ID       uint               `json:"id"`
+	Task     string             `json:"task"`
+	Status   bool               `json:"status"`
+	Priority enums.PriorityType `json:"priority"`
+	DueTime  *string            `json:"due_time,omitempty"`
+	Notes    string             `json:"notes,omitempty"`
+	SubTasks []SubTaskSnapshot  `json:"sub_tasks"`
+}
+
+type SubTaskSnapshot struct {
+	ID      uint   `json:"id"`
+	SubTask string `json:"sub_task"`
+	Status  bool   `json:"status"`
+}
+
+// NewEntrySnapshot captures the current state of entry. DailyTasks and
+// their SubTasks must already be loaded.
+func NewEntrySnapshot(entry *JournalEntry) EntrySnapshot {
+	snapshot := EntrySnapshot{
+		Date:               entry.Date,
+		Mood:               entry.Mood,
+		ThisDayDescription: entry.ThisDayDescription,
+		DailyReflection:    entry.DailyReflection,
+		DailyTasks:         make([]TaskSnapshot, 0, len(entry.DailyTasks)),
+		TemplateID:         entry.TemplateID,
+		TemplateVersion:    entry.TemplateVersion,
+		TemplateValues:     entry.TemplateValues,
+	}
+
+	for _, task := range entry.DailyTasks {
+		taskSnapshot := TaskSnapshot{
+			ID:       task.ID,
+			Task:     task.Task,
+			Status:   task.Status,
+			Priority: task.Priority,
+			DueTime:  task.DueTime,
+			Notes:    task.Notes,
+			SubTasks: make([]SubTaskSnapshot, 0, len(task.SubTasks)),
+		}
+		for _, subTask := range task.SubTasks {
+			taskSnapshot.SubTasks = append(taskSnapshot.SubTasks, SubTaskSnapshot{
+				ID:      subTask.ID,
+				SubTask: subTask.SubTask,
+				Status:  subTask.Status,
+			})
+		}
+		snapshot.DailyTasks = append(snapshot.DailyTasks, taskSnapshot)
+	}
+
+	return snapshot
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type RevisionSummary struct {
+	Revision             int       `json:"revision"`
+	RestoredFromRevision *int      `json:"restored_from_revision,omitempty"`
+	CreatedAt            time.Time `json:"created_at"`
+}
+
+type RevisionResponse struct {
+	RevisionSummary
+	Snapshot EntrySnapshot `json:"snapshot"`
+}
+
+type RevisionDiff struct {
+	FromRevision       int            `json:"from_revision"`
+	ToRevision         int            `json:"to_revision"`
+	Mood               *MoodChange    `json:"mood,omitempty"`
+	ThisDayDescription []TextDiffOp   `json:"this_day_description"`
+	DailyReflection    []TextDiffOp   `json:"daily_reflection"`
+	DailyTasks         []TaskDiffItem `json:"daily_tasks"`
+}
+
+type MoodChange struct {
+	From enums.MoodType `json:"from"`
+	To   enums.MoodType `json:"to"`
+}
+
+type TextDiffOp struct {
+	Op   string `json:"op"` // equal, insert or delete
+	Text string `json:"text"`
+}
+
+type TaskDiffItem struct {
+	Change string        `json:"change"` // added, removed or modified
+	From   *TaskSnapshot `json:"from,omitempty"`
+	To     *TaskSnapshot `json:"to,omitempty"`
+}
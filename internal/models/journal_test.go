@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+func TestPatchDailyTaskRequestApplyTo(t *testing.T) {
+	dueTime := "09:30"
+	original := func() DailyTask {
+		return DailyTask{
+			Task:     "Write three pages",
+			Status:   false,
+			Priority: enums.Priority.Medium,
+			DueTime:  &dueTime,
+			Notes:    "Before breakfast",
+			SubTasks: []DailySubTask{{SubTask: "Find a pen"}},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  func(task *DailyTask)
+	}{
+		{
+			name:  "empty patch",
+			patch: `{}`,
+			want:  func(task *DailyTask) {},
+		},
+		{
+			name:  "status only",
+			patch: `{"status":true}`,
+			want:  func(task *DailyTask) { task.Status = true },
+		},
+		{
+			name:  "priority",
+			patch: `{"priority":"high"}`,
+			want:  func(task *DailyTask) { task.Priority = enums.Priority.High },
+		},
+		{
+			name:  "new due time",
+			patch: `{"due_time":"18:00"}`,
+			want: func(task *DailyTask) {
+				later := "18:00"
+				task.DueTime = &later
+			},
+		},
+		{
+			name:  "empty due time clears it",
+			patch: `{"due_time":""}`,
+			want:  func(task *DailyTask) { task.DueTime = nil },
+		},
+		{
+			name:  "null due time leaves it",
+			patch: `{"due_time":null}`,
+			want:  func(task *DailyTask) {},
+		},
+		{
+			name:  "empty notes clear them",
+			patch: `{"notes":""}`,
+			want:  func(task *DailyTask) { task.Notes = "" },
+		},
+		{
+			name:  "sub tasks are replaced",
+			patch: `{"sub_tasks":[{"sub_task":"Buy a notebook","status":true}]}`,
+			want: func(task *DailyTask) {
+				task.SubTasks = []DailySubTask{{SubTask: "Buy a notebook", Status: true}}
+			},
+		},
+		{
+			name:  "empty sub tasks remove them",
+			patch: `{"sub_tasks":[]}`,
+			want:  func(task *DailyTask) { task.SubTasks = []DailySubTask{} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch PatchDailyTaskRequest
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.patch, err)
+			}
+
+			got := original()
+			patch.ApplyTo(&got)
+
+			want := original()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ApplyTo(%s) = %+v, want %+v", tt.patch, got, want)
+			}
+		})
+	}
+}
+
+func TestDailyTaskRequestToDailyTask(t *testing.T) {
+	dueTime := "07:15"
+	request := DailyTaskRequest{
+		ID:       4,
+		Task:     "Stretch",
+		Status:   true,
+		Priority: enums.Priority.Low,
+		DueTime:  &dueTime,
+		Notes:    "Ten minutes",
+		SubTasks: []DailySubTaskRequest{{ID: 9, SubTask: "Neck", Status: true}, {SubTask: "Back"}},
+	}
+
+	task := request.ToDailyTask()
+
+	if task.ID != 4 || task.Task != "Stretch" || !task.Status || task.Priority != enums.Priority.Low ||
+		task.DueTime == nil || *task.DueTime != "07:15" || task.Notes != "Ten minutes" {
+		t.Errorf("ToDailyTask() = %+v, want the request's fields", task)
+	}
+	if len(task.SubTasks) != 2 || task.SubTasks[0].ID != 9 || !task.SubTasks[0].Status || task.SubTasks[1].SubTask != "Back" {
+		t.Errorf("ToDailyTask() sub tasks = %+v", task.SubTasks)
+	}
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+const (
+	MemoryPeriodYear      = "year"
+	MemoryPeriodWeek      = "week"
+	MemoryPeriodMonth     = "month"
+	MemoryPeriodSixMonths = "six_months"
+)
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// OnThisDayResponse lists past entries written on the same calendar day
+// as Date, grouped by how long ago they were written.
+type OnThisDayResponse struct {
+	Date     string        `json:"date"` // YYYY-MM-DD in the user's time zone
+	Memories []MemoryGroup `json:"memories"`
+}
+
+type MemoryGroup struct {
+	Period  string        `json:"period"`
+	Ago     int           `json:"ago"` // Number of periods back, e.g. 3 for three years ago
+	Label   string        `json:"label"`
+	Date    string        `json:"date"`
+	Entries []MemoryEntry `json:"entries"`
+}
+
+type MemoryEntry struct {
+	ID                 uint           `json:"id"`
+	Date               time.Time      `json:"date"`
+	Mood               enums.MoodType `json:"mood"`
+	ThisDayDescription string         `json:"this_day_description"`
+	DailyReflection    string         `json:"daily_reflection"`
+}
@@ -5,4 +5,32 @@ var AllModels = []interface{}{
 	&JournalEntry{},
 	&DailyTask{},
 	&DailySubTask{},
+	&Attachment{},
+	&JournalRevision{},
+	&SyncChange{},
+	&IdempotencyKey{},
+	&Routine{},
+	&RoutineSubTask{},
+	&RoutineException{},
+	&PushSubscription{},
+	&Webhook{},
+	&WebhookDelivery{},
+	&Review{},
+	&Prompt{},
+	&PromptPick{},
+	&Notebook{},
+	&ShareLink{},
+	&ShareAccess{},
+	&ReaderGrant{},
+	&ReaderAccess{},
+	&Comment{},
+	&Reaction{},
+	&Organization{},
+	&OrganizationMember{},
+	&ClientConsent{},
+	&ConsentNotebook{},
+	&Template{},
+	&TemplateVersion{},
+	&SecurityEvent{},
+	&QueuedNotification{},
 }
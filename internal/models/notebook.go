@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// DefaultNotebookName is the name of the notebook created for every user.
+const DefaultNotebookName = "Personal"
+
+// Notebook groups a user's entries. Every user has exactly one default
+// notebook, which also holds the entries written before notebooks existed
+// (NotebookID is nil for those).
+type Notebook struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null; index; uniqueIndex:idx_notebook_default,where:is_default"`
+	Name        string `gorm:"size:100; not null"`
+	Color       string `gorm:"size:7; not null; default:'#6B7280'"`
+	Description string `gorm:"type:text; not null; default:''"`
+	IsDefault   bool   `gorm:"not null; default:false"`
+	Archived    bool   `gorm:"not null; default:false"` // Hidden from the list and closed to new entries
+	SortOrder   int    `gorm:"not null; default:0"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type NotebookRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Color       string `json:"color" binding:"omitempty,hexcolor,len=7"`
+	Description string `json:"description"`
+	Archived    *bool  `json:"archived"`
+	SortOrder   *int   `json:"sort_order"`
+}
+
+type NotebookResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color"`
+	Description string    `json:"description"`
+	IsDefault   bool      `json:"is_default"`
+	Archived    bool      `json:"archived"`
+	SortOrder   int       `json:"sort_order"`
+	EntryCount  int64     `json:"entry_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type MoveEntriesRequest struct {
+	EntryIDs []uint `json:"entry_ids" binding:"required,min=1,max=500"`
+}
+
+type MoveEntriesResponse struct {
+	NotebookID uint   `json:"notebook_id"`
+	EntryIDs   []uint `json:"entry_ids"` // Entries that were moved; unknown IDs are skipped
+}
+
+// NotebookEntry is an entry as listed in a notebook.
+type NotebookEntry struct {
+	ID      uint                  `json:"id"`
+	Date    time.Time             `json:"date"`
+	Mood    enums.MoodType        `json:"mood"`
+	Status  enums.EntryStatusType `json:"status"`
+	Excerpt string                `json:"excerpt"`
+	Version int                   `json:"version"`
+}
+
+type NotebookEntriesResponse struct {
+	Entries []NotebookEntry `json:"entries"`
+	HasMore bool            `json:"has_more"`
+}
+
+// NotebookExport is the JSON export of a notebook.
+type NotebookExport struct {
+	Notebook   NotebookResponse `json:"notebook"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Entries    []JournalEntry   `json:"entries"`
+}
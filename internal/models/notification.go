@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// QueuedNotification is a notification waiting to be sent to a user
+// through their reminder channels. It is queued in the transaction of the
+// change it is about and removed once it was sent.
+type QueuedNotification struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"not null; index"`
+	Kind      string `gorm:"size:50; not null"`
+	Title     string `gorm:"size:255; not null"`
+	Body      string `gorm:"type:text; not null; default:''"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Organization member roles
+const (
+	OrgRoleOwner        = "owner"        // Also manages the members
+	OrgRolePractitioner = "practitioner" // Works with consenting clients
+)
+
+// Client consent states
+const (
+	ConsentStatusPending  = "pending"
+	ConsentStatusActive   = "active"
+	ConsentStatusDeclined = "declined"
+	ConsentStatusRevoked  = "revoked"
+)
+
+// Organization is a practice, such as a counseling team, whose members
+// work with clients' journals.
+type Organization struct {
+	gorm.Model
+	Name string `gorm:"size:100; not null"`
+}
+
+type OrganizationMember struct {
+	ID             uint      `gorm:"primaryKey"`
+	OrganizationID uint      `gorm:"not null; uniqueIndex:idx_organization_member,priority:1"`
+	UserID         uint      `gorm:"not null; uniqueIndex:idx_organization_member,priority:2; index"`
+	Role           string    `gorm:"size:20; not null"`
+	CreatedAt      time.Time `gorm:"not null"`
+}
+
+// ClientConsent is a practitioner's request to follow a client's
+// journal. It starts as a request addressed to an email; once the client
+// consents, the practitioner sees the published entries of the notebooks
+// the client selected. Every practitioner query checks that the consent
+// is still active, so revoking it takes effect immediately.
+type ClientConsent struct {
+	gorm.Model
+	OrganizationID uint              `gorm:"not null; index"`
+	PractitionerID uint              `gorm:"not null; index"`
+	ClientID       *uint             `gorm:"index"` // Set when the client answers
+	InviteEmail    string            `gorm:"size:255; not null; index"`
+	Message        string            `gorm:"type:text; not null; default:''"`
+	Status         string            `gorm:"size:20; not null; index"`
+	GrantedAt      *time.Time        // Last time the client consented
+	RevokedAt      *time.Time        // Set when the client declined or revoked
+	Notebooks      []ConsentNotebook `gorm:"foreignKey:ConsentID"`
+}
+
+// ConsentNotebook is a notebook the client shares through a consent.
+type ConsentNotebook struct {
+	ConsentID  uint `gorm:"primaryKey"`
+	NotebookID uint `gorm:"primaryKey"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+type OrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type OrganizationResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"` // The requesting user's role
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required,oneof=owner practitioner"`
+}
+
+type OrganizationMemberResponse struct {
+	User     ReaderUser `json:"user"`
+	Role     string     `json:"role"`
+	JoinedAt time.Time  `json:"joined_at"`
+}
+
+type ConsentRequest struct {
+	Email   string `json:"email" binding:"required,email"`
+	Message string `json:"message" binding:"max=500"`
+}
+
+// ConsentNotebooksRequest selects the notebooks a client shares.
+type ConsentNotebooksRequest struct {
+	NotebookIDs []uint `json:"notebook_ids" binding:"required,min=1,max=50"`
+}
+
+type ConsentResponse struct {
+	ID           uint        `json:"id"`
+	Organization string      `json:"organization"`
+	Practitioner *ReaderUser `json:"practitioner,omitempty"`
+	Client       *ReaderUser `json:"client,omitempty"`
+	InviteEmail  string      `json:"invite_email"`
+	Message      string      `json:"message,omitempty"`
+	Status       string      `json:"status"`
+	NotebookIDs  []uint      `json:"notebook_ids"`
+	CreatedAt    time.Time   `json:"created_at"`
+	GrantedAt    *time.Time  `json:"granted_at,omitempty"`
+	RevokedAt    *time.Time  `json:"revoked_at,omitempty"`
+}
+
+type MoodWeek struct {
+	WeekStart string      `json:"week_start"`
+	Entries   int         `json:"entries"`
+	Moods     []MoodCount `json:"moods"` // Most frequent first
+}
+
+type ClientSummary struct {
+	ConsentID     uint       `json:"consent_id"`
+	Client        ReaderUser `json:"client"`
+	GrantedAt     *time.Time `json:"granted_at"`
+	LastEntryDate *time.Time `json:"last_entry_date"`
+	MoodTrend     []MoodWeek `json:"mood_trend"` // Oldest week first, weeks without entries left out
+}
+
+type PractitionerDashboard struct {
+	OrganizationID uint            `json:"organization_id"`
+	Weeks          int             `json:"weeks"`
+	Clients        []ClientSummary `json:"clients"`
+}
+
+type ClientEntriesResponse struct {
+	Entries []SharedEntry `json:"entries"`
+	HasMore bool          `json:"has_more"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// Prompt is a question that guides the daily reflection. Prompts without
+// a UserID belong to the shared library; the others were written by a user
+// for themselves.
+type Prompt struct {
+	gorm.Model
+	UserID  *uint          `gorm:"index"`
+	SeedKey *string        `gorm:"size:50; uniqueIndex"` // Identifies library prompts across seeding runs
+	Text    string         `gorm:"type:text; not null"`
+	Theme   string         `gorm:"size:50; not null; index"`
+	Mood    enums.MoodType `gorm:"not null; default:0"` // Mood the prompt suits, Unknown for any
+	Active  bool           `gorm:"not null; default:true"`
+}
+
+// PromptPick records the prompt offered to a user on a day, so the daily
+// prompt stays stable for the day and is not repeated soon after.
+type PromptPick struct {
+	ID       uint      `gorm:"primaryKey"`
+	UserID   uint      `gorm:"not null; uniqueIndex:idx_prompt_pick_day,priority:1"`
+	Date     time.Time `gorm:"type:date; not null; uniqueIndex:idx_prompt_pick_day,priority:2"`
+	PromptID uint      `gorm:"not null"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type PromptRequest struct {
+	Text   string         `json:"text" binding:"required,max=500"`
+	Theme  string         `json:"theme" binding:"required,max=50"`
+	Mood   enums.MoodType `json:"mood"`
+	Active *bool          `json:"active"`
+}
+
+type PromptResponse struct {
+	ID     uint           `json:"id"`
+	Text   string         `json:"text"`
+	Theme  string         `json:"theme"`
+	Mood   enums.MoodType `json:"mood"`
+	Custom bool           `json:"custom"` // Written by the user rather than from the library
+	Active bool           `json:"active"`
+}
+
+type DailyPromptResponse struct {
+	Date   string         `json:"date"`
+	Prompt PromptResponse `json:"prompt"`
+}
+
+// PromptAnswer is an entry that answered a prompt.
+type PromptAnswer struct {
+	EntryID         uint           `json:"entry_id"`
+	Date            time.Time      `json:"date"`
+	Mood            enums.MoodType `json:"mood"`
+	DailyReflection string         `json:"daily_reflection"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PushSubscription is a browser's Web Push subscription. Each device the
+// user enables notifications on registers its own.
+type PushSubscription struct {
+	gorm.Model
+	UserID     uint   `gorm:"not null; index"`
+	Endpoint   string `gorm:"type:text; not null; uniqueIndex"`
+	P256dh     string `gorm:"not null"` // base64url client public key
+	Auth       string `gorm:"not null"` // base64url authentication secret
+	DeviceName string `gorm:"not null; default:''"`
+	UserAgent  string `gorm:"not null; default:''"`
+	LastUsedAt *time.Time
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// PushSubscriptionRequest mirrors the JSON of a browser PushSubscription,
+// with an optional name for the device.
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required,url,startswith=https://"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+type PushSubscriptionResponse struct {
+	ID         uint       `json:"id"`
+	Endpoint   string     `json:"endpoint"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type PushConfigResponse struct {
+	PublicKey string `json:"public_key"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Reader roles, from least to most access
+const (
+	ReaderRoleViewer    = "viewer"    // Reads published entries
+	ReaderRoleCommenter = "commenter" // Also comments on and reacts to them
+	ReaderRoleEditor    = "editor"    // Also edits entries and tasks, drafts included
+)
+
+// Reader grant states
+const (
+	GrantStatusPending  = "pending"
+	GrantStatusAccepted = "accepted"
+	GrantStatusDeclined = "declined"
+	GrantStatusRevoked  = "revoked"
+)
+
+// ReaderGrant gives another registered user ongoing access to one of the
+// owner's notebooks, or to the owner's entries carrying a hashtag. It
+// starts as an invitation addressed to an email and takes effect once the
+// user with that email accepts it.
+type ReaderGrant struct {
+	gorm.Model
+	OwnerID     uint   `gorm:"not null; index"`
+	ReaderID    *uint  `gorm:"index"` // Set when the invitation is accepted
+	InviteEmail string `gorm:"size:255; not null; index"`
+	Role        string `gorm:"size:20; not null"`
+	NotebookID  *uint  `gorm:"index"`
+	Tag         string `gorm:"size:50; not null; default:''"` // Hashtag without the #, lower case
+	Message     string `gorm:"type:text; not null; default:''"`
+	Status      string `gorm:"size:20; not null; index"`
+	RespondedAt *time.Time
+	RevokedAt   *time.Time
+}
+
+// ReaderAccess is the audit trail of what readers did with the owner's
+// entries.
+type ReaderAccess struct {
+	ID             uint      `gorm:"primaryKey"`
+	GrantID        uint      `gorm:"not null; index:idx_reader_access_grant,priority:1"`
+	OwnerID        uint      `gorm:"not null; index"`
+	ReaderID       uint      `gorm:"not null"`
+	JournalEntryID *uint     // Nil when the reader listed entries
+	Action         string    `gorm:"size:20; not null"`
+	CreatedAt      time.Time `gorm:"not null; index:idx_reader_access_grant,priority:2"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// ReaderInviteRequest invites someone to a notebook or to a hashtag;
+// exactly one of NotebookID and Tag must be set.
+type ReaderInviteRequest struct {
+	Email      string `json:"email" binding:"required,email"`
+	Role       string `json:"role" binding:"required,oneof=viewer commenter editor"`
+	NotebookID *uint  `json:"notebook_id"`
+	Tag        string `json:"tag" binding:"max=50"`
+	Message    string `json:"message" binding:"max=500"`
+}
+
+type ReaderRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer commenter editor"`
+}
+
+type ReaderUser struct {
+	ID       uint   `json:"id"`
+	Email    string `json:"email"`
+	FullName string `json:"full_name"`
+}
+
+type ReaderGrantResponse struct {
+	ID          uint        `json:"id"`
+	Owner       *ReaderUser `json:"owner,omitempty"`
+	Reader      *ReaderUser `json:"reader,omitempty"`
+	InviteEmail string      `json:"invite_email"`
+	Role        string      `json:"role"`
+	NotebookID  *uint       `json:"notebook_id,omitempty"`
+	Tag         string      `json:"tag,omitempty"`
+	Message     string      `json:"message,omitempty"`
+	Status      string      `json:"status"`
+	CreatedAt   time.Time   `json:"created_at"`
+	RespondedAt *time.Time  `json:"responded_at,omitempty"`
+	RevokedAt   *time.Time  `json:"revoked_at,omitempty"`
+}
+
+type ReaderAccessResponse struct {
+	ReaderID  uint      `json:"reader_id"`
+	EntryID   *uint     `json:"entry_id,omitempty"`
+	Action    string    `json:"action"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+const (
+	ReviewPeriodWeek  = "week"
+	ReviewPeriodMonth = "month"
+	ReviewPeriodYear  = "year"
+)
+
+// Review is a generated report for one week, month or year of a user's
+// journal. Reports of periods that have not ended yet are regenerated on
+// request; final ones are kept as generated.
+type Review struct {
+	gorm.Model
+	UserID      uint      `gorm:"not null; uniqueIndex:idx_review_period,priority:1"`
+	Period      string    `gorm:"size:10; not null; uniqueIndex:idx_review_period,priority:2"`
+	StartDate   time.Time `gorm:"type:date; not null; uniqueIndex:idx_review_period,priority:3"`
+	EndDate     time.Time `gorm:"type:date; not null"` // Inclusive
+	Final       bool      `gorm:"not null; default:false"`
+	Report      JSONB     `gorm:"type:jsonb; not null"`
+	GeneratedAt time.Time `gorm:"not null"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type ReviewResponse struct {
+	ID          uint         `json:"id"`
+	Period      string       `json:"period"`
+	StartDate   string       `json:"start_date"`
+	EndDate     string       `json:"end_date"`
+	Final       bool         `json:"final"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Report      ReviewReport `json:"report"`
+}
+
+type ReviewSummary struct {
+	ID          uint      `json:"id"`
+	Period      string    `json:"period"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"`
+	Final       bool      `json:"final"`
+	EntryCount  int       `json:"entry_count"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// ReviewReport is the content stored in Review.Report.
+type ReviewReport struct {
+	EntryCount        int              `json:"entry_count"`
+	DaysJournaled     int              `json:"days_journaled"`
+	DaysInPeriod      int              `json:"days_in_period"`
+	MoodBreakdown     []MoodCount      `json:"mood_breakdown"`
+	Tasks             ReviewTaskStats  `json:"tasks"`
+	LongestReflection *ReviewEntryRef  `json:"longest_reflection"`
+	TopTags           []WordCount      `json:"top_tags"` // #hashtags used in the text
+	TopWords          []WordCount      `json:"top_words"`
+	Highlights        []ReviewEntryRef `json:"highlights"`
+}
+
+type MoodCount struct {
+	Mood  enums.MoodType `json:"mood"`
+	Count int            `json:"count"`
+}
+
+type ReviewTaskStats struct {
+	Total          int     `json:"total"`
+	Completed      int     `json:"completed"`
+	CompletionRate float64 `json:"completion_rate"`
+}
+
+type ReviewEntryRef struct {
+	EntryID   uint           `json:"entry_id"`
+	Date      time.Time      `json:"date"`
+	Mood      enums.MoodType `json:"mood"`
+	WordCount int            `json:"word_count"`
+	Excerpt   string         `json:"excerpt"`
+}
+
+type WordCount struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Routine is a recurring task template. Its task and subtasks are copied
+// into the matching day's journal entry when that entry is created or
+// first opened.
+type Routine struct {
+	gorm.Model
+	UserID     uint             `gorm:"not null; index"`
+	Task       string           `gorm:"not null"`
+	Recurrence string           `gorm:"not null"` // Canonical RRULE, e.g. FREQ=WEEKLY;BYDAY=MO,WE
+	StartDate  time.Time        `gorm:"not null; type:date"`
+	EndDate    *time.Time       `gorm:"type:date"`
+	Active     bool             `gorm:"not null; default:true"`
+	SubTasks   []RoutineSubTask `gorm:"foreignKey:RoutineID"`
+}
+
+type RoutineSubTask struct {
+	gorm.Model
+	RoutineID uint   `gorm:"not null; index"`
+	SubTask   string `gorm:"not null"`
+	Position  int    `gorm:"not null; default:0"`
+}
+
+// RoutineException skips or changes a single occurrence of a routine.
+type RoutineException struct {
+	gorm.Model
+	RoutineID uint      `gorm:"not null; uniqueIndex:idx_routine_exception,priority:1"`
+	Date      time.Time `gorm:"not null; type:date; uniqueIndex:idx_routine_exception,priority:2"`
+	Skip      bool      `gorm:"not null; default:false"`
+	Task      *string
+	SubTasks  JSONB `gorm:"type:jsonb"` // []string replacing the routine's subtasks
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type RoutineRequest struct {
+	Task       string            `json:"task" binding:"required"`
+	SubTasks   []string          `json:"sub_tasks" binding:"dive,required"`
+	Recurrence RecurrenceRequest `json:"recurrence" binding:"required"`
+	StartDate  *time.Time        `json:"start_date"` // Defaults to today
+	EndDate    *time.Time        `json:"end_date"`
+	Active     *bool             `json:"active"`
+}
+
+// RecurrenceRequest selects a recurrence. Type "interval" repeats every
+// Interval days; type "rrule" accepts an RRULE using FREQ (DAILY, WEEKLY,
+// MONTHLY), INTERVAL, BYDAY, BYMONTHDAY, UNTIL and COUNT.
+type RecurrenceRequest struct {
+	Type     string `json:"type" binding:"required,oneof=daily weekdays interval rrule"`
+	Interval int    `json:"interval" binding:"omitempty,min=1,max=366"`
+	RRule    string `json:"rrule"`
+}
+
+type RoutineResponse struct {
+	ID         uint       `json:"id"`
+	Task       string     `json:"task"`
+	SubTasks   []string   `json:"sub_tasks"`
+	Recurrence string     `json:"recurrence"`
+	StartDate  time.Time  `json:"start_date"`
+	EndDate    *time.Time `json:"end_date,omitempty"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// RoutineOccurrenceRequest skips one occurrence or overrides its task and
+// subtasks. Omitted fields keep the routine's values.
+type RoutineOccurrenceRequest struct {
+	Skip     bool      `json:"skip"`
+	Task     *string   `json:"task" binding:"omitempty,min=1"`
+	SubTasks *[]string `json:"sub_tasks" binding:"omitempty,dive,required"`
+}
+
+type RoutineOccurrenceResponse struct {
+	Date     time.Time `json:"date"`
+	Skip     bool      `json:"skip"`
+	Task     string    `json:"task"`
+	SubTasks []string  `json:"sub_tasks"`
+	Modified bool      `json:"modified"`
+}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Security event types
+const (
+	SecurityEventLoginSucceeded  = "login.succeeded"
+	SecurityEventLoginFailed     = "login.failed"
+	SecurityEventRegistered      = "account.registered"
+	SecurityEventDataExported    = "data.exported"
+	SecurityEventAccountDisabled = "account.disabled"     // By an admin
+	SecurityEventAccountEnabled  = "account.enabled"      // By an admin
+	SecurityEventAccountUnlocked = "account.unlocked"     // By an admin
+	SecurityEventSessionsRevoked = "sessions.revoked"     // By an admin
+	SecurityEventRoleChanged     = "account.role_changed" // By an admin, or from ADMIN_EMAILS at startup
+)
+
+// Why a login failed, recorded as the event's detail
+const (
+	LoginFailureUnknownEmail  = "unknown_email"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureLocked        = "account_locked"
+	LoginFailureDisabled      = "account_disabled"
+)
+
+// SecurityEvent is one entry of the append-only audit log of what happened
+// to an account and from where. Rows are only ever inserted; the database
+// rejects updates and deletes.
+type SecurityEvent struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    *uint     `gorm:"index:idx_security_event_user,priority:1"` // Empty for logins to unknown emails
+	Type      string    `gorm:"size:50; not null; index"`
+	Email     string    `gorm:"size:255; not null; default:''"` // The email a login was attempted with
+	ActorID   *uint     `gorm:"index"`                          // The admin who acted on the account
+	Detail    string    `gorm:"size:255; not null; default:''"`
+	IP        string    `gorm:"size:45; not null; default:''"`
+	UserAgent string    `gorm:"size:512; not null; default:''"`
+	RequestID string    `gorm:"size:100; not null; default:''"` // X-Request-ID, to find the request in the logs
+	CreatedAt time.Time `gorm:"not null; index; index:idx_security_event_user,priority:2"`
+}
+
+// RequestInfo identifies the request that caused a security event.
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// SecurityEventQuery filters the audit log. Empty fields match every
+// event.
+type SecurityEventQuery struct {
+	UserID *uint
+	Type   string
+}
+
+type SecurityEventResponse struct {
+	ID        uint      `json:"id"`
+	Type      string    `json:"type"`
+	UserID    *uint     `json:"user_id,omitempty"`  // Admin view only
+	Email     string    `json:"email,omitempty"`    // Admin view only
+	ActorID   *uint     `json:"actor_id,omitempty"` // Admin view only
+	Detail    string    `json:"detail,omitempty"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	RequestID string    `json:"request_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SecurityEventsResponse struct {
+	Events  []SecurityEventResponse `json:"events"`
+	HasMore bool                    `json:"has_more"`
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+// Share link targets
+const (
+	ShareTargetEntry    = "entry"
+	ShareTargetNotebook = "notebook"
+)
+
+// Share link states
+const (
+	ShareStatusActive    = "active"
+	ShareStatusExpired   = "expired"
+	ShareStatusExhausted = "exhausted" // View limit reached
+	ShareStatusRevoked   = "revoked"
+)
+
+// Share access outcomes
+const (
+	ShareAccessViewed        = "viewed"
+	ShareAccessWrongPassword = "wrong_password"
+	ShareAccessExpired       = "expired"
+	ShareAccessExhausted     = "exhausted"
+	ShareAccessRevoked       = "revoked"
+	ShareAccessThrottled     = "throttled"
+)
+
+// ShareLink gives read-only access to one entry or a whole notebook to
+// anyone holding its token. Only the token's hash is stored; the token
+// itself is shown once, when the link is created.
+type ShareLink struct {
+	gorm.Model
+	UserID         uint       `gorm:"not null; index"`
+	TokenHash      string     `gorm:"size:64; not null; uniqueIndex"`
+	TokenPrefix    string     `gorm:"size:8; not null"` // Lets the owner tell links apart
+	Label          string     `gorm:"size:100; not null; default:''"`
+	JournalEntryID *uint      `gorm:"index"`
+	NotebookID     *uint      `gorm:"index"`
+	PasswordHash   string     `gorm:"not null; default:''"`
+	ExpiresAt      *time.Time `gorm:"index"`
+	MaxViews       *int
+	ViewCount      int  `gorm:"not null; default:0"`
+	HideTasks      bool `gorm:"not null; default:false"`
+	HideMood       bool `gorm:"not null; default:false"`
+	RevokedAt      *time.Time
+	LastViewedAt   *time.Time
+}
+
+// ShareAccess is one attempt to open a share link.
+type ShareAccess struct {
+	ID          uint      `gorm:"primaryKey"`
+	ShareLinkID uint      `gorm:"not null; index:idx_share_access_link,priority:1"`
+	Outcome     string    `gorm:"size:20; not null"`
+	IP          string    `gorm:"size:45; not null; default:''"`
+	UserAgent   string    `gorm:"not null; default:''"`
+	CreatedAt   time.Time `gorm:"not null; index:idx_share_access_link,priority:2"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+
+// ShareLinkRequest creates a link to an entry or a notebook; exactly one
+// of EntryID and NotebookID must be set.
+type ShareLinkRequest struct {
+	EntryID        *uint  `json:"entry_id"`
+	NotebookID     *uint  `json:"notebook_id"`
+	Label          string `json:"label" binding:"max=100"`
+	Password       string `json:"password" binding:"omitempty,min=4,max=72"`
+	ExpiresInHours int    `json:"expires_in_hours" binding:"min=0"` // 0 uses the default expiry
+	MaxViews       *int   `json:"max_views" binding:"omitempty,min=1"`
+	HideTasks      bool   `json:"hide_tasks"`
+	HideMood       bool   `json:"hide_mood"`
+}
+
+type ShareLinkResponse struct {
+	ID           uint       `json:"id"`
+	Token        string     `json:"token,omitempty"` // Only returned when the link is created
+	URL          string     `json:"url,omitempty"`
+	TokenPrefix  string     `json:"token_prefix"`
+	Label        string     `json:"label"`
+	Target       string     `json:"target"`
+	EntryID      *uint      `json:"entry_id,omitempty"`
+	NotebookID   *uint      `json:"notebook_id,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	MaxViews     *int       `json:"max_views"`
+	ViewCount    int        `json:"view_count"`
+	HideTasks    bool       `json:"hide_tasks"`
+	HideMood     bool       `json:"hide_mood"`
+	Status       string     `json:"status"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	LastViewedAt *time.Time `json:"last_viewed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+type ShareAccessResponse struct {
+	Outcome   string    `json:"outcome"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SharedContent is what the holder of a share link sees.
+type SharedContent struct {
+	Target    string          `json:"target"`
+	Notebook  *SharedNotebook `json:"notebook,omitempty"`
+	Entries   []SharedEntry   `json:"entries"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+}
+
+type SharedNotebook struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type SharedEntry struct {
+	Date               time.Time       `json:"date"`
+	Mood               *enums.MoodType `json:"mood,omitempty"`
+	ThisDayDescription string          `json:"this_day_description"`
+	DailyReflection    string          `json:"daily_reflection"`
+	Tasks              []SharedTask    `json:"tasks,omitempty"`
+}
+
+type SharedTask struct {
+	Task     string       `json:"task"`
+	Done     bool         `json:"done"`
+	SubTasks []SharedTask `json:"sub_tasks,omitempty"`
+}
@@ -0,0 +1,197 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+const (
+	SyncEntityJournalEntry = "journal_entry"
+	SyncEntityDailyTask    = "daily_task"
+	SyncEntitySubTask      = "daily_sub_task"
+
+	SyncOperationUpsert = "upsert"
+	SyncOperationDelete = "delete"
+)
+
+// SyncChange is one row of the per-user change feed consumed by offline
+// clients. Seq is globally increasing and, because writers hold a per-user
+// lock, commits in order for any given user.
+type SyncChange struct {
+	Seq        uint64    `gorm:"primaryKey; autoIncrement"`
+	UserID     uint      `gorm:"not null; index:idx_sync_change_user_seq,priority:1"`
+	EntityType string    `gorm:"not null; index:idx_sync_change_entity,priority:1"`
+	EntityID   uint      `gorm:"not null; index:idx_sync_change_entity,priority:2"`
+	Operation  string    `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"index:idx_sync_change_user_seq,priority:2"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type SyncFeedResponse struct {
+	Changes    []SyncRecord `json:"changes"`
+	NextCursor uint64       `json:"next_cursor"`
+	HasMore    bool         `json:"has_more"`
+}
+
+// SyncRecord is the state of one record as seen by a sync client. Data is
+// omitted for deletions.
+type SyncRecord struct {
+	EntityType string      `json:"entity_type"`
+	ID         uint        `json:"id"`
+	ClientID   *string     `json:"client_id,omitempty"`
+	Operation  string      `json:"operation"`
+	Seq        uint64      `json:"seq"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	DeletedAt  *time.Time  `json:"deleted_at,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+}
+
+type SyncEntryData struct {
+	Date               *time.Time             `json:"date,omitempty"`
+	Mood               *enums.MoodType        `json:"mood,omitempty"`
+	ThisDayDescription *string                `json:"this_day_description,omitempty"`
+	DailyReflection    *string                `json:"daily_reflection,omitempty"`
+	Status             *enums.EntryStatusType `json:"status,omitempty"`
+	Version            int                    `json:"version,omitempty"`
+	NotebookID         *uint                  `json:"notebook_id,omitempty"` // Read only, entries move through the notebooks API
+}
+
+type SyncTaskData struct {
+	JournalEntryID       uint                `json:"journal_entry_id,omitempty"`
+	JournalEntryClientID *string             `json:"journal_entry_client_id,omitempty"`
+	Task                 *string             `json:"task,omitempty"`
+	Status               *bool               `json:"status,omitempty"`
+	Position             *int                `json:"position,omitempty"`
+	Priority             *enums.PriorityType `json:"priority,omitempty"`
+	DueTime              *string             `json:"due_time,omitempty"` // Empty string clears it
+	Notes                *string             `json:"notes,omitempty"`
+	CarriedOverFromID    *uint               `json:"carried_over_from_id,omitempty"`
+}
+
+type SyncSubTaskData struct {
+	DailyTaskID       uint    `json:"daily_task_id,omitempty"`
+	DailyTaskClientID *string `json:"daily_task_client_id,omitempty"`
+	SubTask           *string `json:"sub_task,omitempty"`
+	Status            *bool   `json:"status,omitempty"`
+}
+
+type SyncPushRequest struct {
+	Changes []SyncPushChange `json:"changes" binding:"required,max=500,dive"`
+}
+
+// SyncPushChange is a change made on a device while offline. New records
+// are identified by a client generated UUID; records known to the server
+// may also be addressed by ID. BaseSeq is the seq of the server state the
+// change was based on (0 for new records).
+type SyncPushChange struct {
+	EntityType string          `json:"entity_type" binding:"required,oneof=journal_entry daily_task daily_sub_task"`
+	Operation  string          `json:"operation" binding:"required,oneof=upsert delete"`
+	ID         uint            `json:"id"`
+	ClientID   string          `json:"client_id"`
+	BaseSeq    uint64          `json:"base_seq"`
+	Resolution string          `json:"resolution" binding:"omitempty,oneof=server_wins client_wins"`
+	Data       json.RawMessage `json:"data"`
+}
+
+type SyncPushResponse struct {
+	Results []SyncPushResult `json:"results"`
+}
+
+type SyncPushResult struct {
+	Index     int         `json:"index"`
+	ClientID  string      `json:"client_id,omitempty"`
+	Status    string      `json:"status"` // applied, conflict or error
+	ID        uint        `json:"id,omitempty"`
+	Seq       uint64      `json:"seq,omitempty"`
+	ErrorCode string      `json:"error_code,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	Server    *SyncRecord `json:"server,omitempty"` // Current server state on conflict
+}
+
+// NewEntrySyncRecord describes a journal entry for the change feed.
+func NewEntrySyncRecord(entry *JournalEntry, seq uint64) SyncRecord {
+	record := SyncRecord{
+		EntityType: SyncEntityJournalEntry,
+		ID:         entry.ID,
+		ClientID:   entry.ClientID,
+		Operation:  SyncOperationUpsert,
+		Seq:        seq,
+		UpdatedAt:  entry.UpdatedAt,
+	}
+
+	if entry.DeletedAt.Valid {
+		return tombstone(record, entry.DeletedAt.Time)
+	}
+
+	record.Data = SyncEntryData{
+		Date:               &entry.Date,
+		Mood:               &entry.Mood,
+		ThisDayDescription: &entry.ThisDayDescription,
+		DailyReflection:    &entry.DailyReflection,
+		Status:             &entry.Status,
+		Version:            entry.Version,
+		NotebookID:         entry.NotebookID,
+	}
+	return record
+}
+
+// NewTaskSyncRecord describes a daily task for the change feed.
+func NewTaskSyncRecord(task *DailyTask, seq uint64) SyncRecord {
+	record := SyncRecord{
+		EntityType: SyncEntityDailyTask,
+		ID:         task.ID,
+		ClientID:   task.ClientID,
+		Operation:  SyncOperationUpsert,
+		Seq:        seq,
+		UpdatedAt:  task.UpdatedAt,
+	}
+
+	if task.DeletedAt.Valid {
+		return tombstone(record, task.DeletedAt.Time)
+	}
+
+	record.Data = SyncTaskData{
+		JournalEntryID:    task.JournalEntryID,
+		Task:              &task.Task,
+		Status:            &task.Status,
+		Position:          &task.Position,
+		Priority:          &task.Priority,
+		DueTime:           task.DueTime,
+		Notes:             &task.Notes,
+		CarriedOverFromID: task.CarriedOverFromID,
+	}
+	return record
+}
+
+// NewSubTaskSyncRecord describes a daily subtask for the change feed.
+func NewSubTaskSyncRecord(subTask *DailySubTask, seq uint64) SyncRecord {
+	record := SyncRecord{
+		EntityType: SyncEntitySubTask,
+		ID:         subTask.ID,
+		ClientID:   subTask.ClientID,
+		Operation:  SyncOperationUpsert,
+		Seq:        seq,
+		UpdatedAt:  subTask.UpdatedAt,
+	}
+
+	if subTask.DeletedAt.Valid {
+		return tombstone(record, subTask.DeletedAt.Time)
+	}
+
+	record.Data = SyncSubTaskData{
+		DailyTaskID: subTask.DailyTaskID,
+		SubTask:     &subTask.SubTask,
+		Status:      &subTask.Status,
+	}
+	return record
+}
+
+func tombstone(record SyncRecord, deletedAt time.Time) SyncRecord {
+	record.Operation = SyncOperationDelete
+	record.DeletedAt = &deletedAt
+	return record
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSyncRecordConstructors(t *testing.T) {
+	clientID := "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6b"
+	updatedAt := time.Date(2026, 3, 15, 9, 0, 0, 0, time.UTC)
+	deletedAt := updatedAt.Add(time.Hour)
+
+	model := func(deleted bool) gorm.Model {
+		m := gorm.Model{ID: 7, UpdatedAt: updatedAt}
+		if deleted {
+			m.DeletedAt = gorm.DeletedAt{Time: deletedAt, Valid: true}
+		}
+		return m
+	}
+	entry := func(deleted bool) SyncRecord {
+		return NewEntrySyncRecord(&JournalEntry{Model: model(deleted), ClientID: &clientID, ThisDayDescription: "A walk", Version: 3}, 42)
+	}
+	task := func(deleted bool) SyncRecord {
+		return NewTaskSyncRecord(&DailyTask{Model: model(deleted), ClientID: &clientID, JournalEntryID: 5, Task: "Read"}, 42)
+	}
+	subTask := func(deleted bool) SyncRecord {
+		return NewSubTaskSyncRecord(&DailySubTask{Model: model(deleted), ClientID: &clientID, DailyTaskID: 6, SubTask: "Chapter one"}, 42)
+	}
+
+	tests := []struct {
+		name       string
+		record     SyncRecord
+		entityType string
+		deleted    bool
+	}{
+		{name: "entry", record: entry(false), entityType: SyncEntityJournalEntry},
+		{name: "deleted entry", record: entry(true), entityType: SyncEntityJournalEntry, deleted: true},
+		{name: "task", record: task(false), entityType: SyncEntityDailyTask},
+		{name: "deleted task", record: task(true), entityType: SyncEntityDailyTask, deleted: true},
+		{name: "subtask", record: subTask(false), entityType: SyncEntitySubTask},
+		{name: "deleted subtask", record: subTask(true), entityType: SyncEntitySubTask, deleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := tt.record
+			if record.EntityType != tt.entityType || record.ID != 7 || record.Seq != 42 || !record.UpdatedAt.Equal(updatedAt) {
+				t.Errorf("record = %s %d seq %d at %v, want %s 7 seq 42 at %v",
+					record.EntityType, record.ID, record.Seq, record.UpdatedAt, tt.entityType, updatedAt)
+			}
+			if record.ClientID == nil || *record.ClientID != clientID {
+				t.Errorf("ClientID = %v, want %s", record.ClientID, clientID)
+			}
+
+			if !tt.deleted {
+				if record.Operation != SyncOperationUpsert || record.DeletedAt != nil || record.Data == nil {
+					t.Errorf("record = %s deleted at %v with data %v, want an upsert with data", record.Operation, record.DeletedAt, record.Data)
+				}
+				return
+			}
+
+			if record.Operation != SyncOperationDelete {
+				t.Errorf("Operation = %s, want %s", record.Operation, SyncOperationDelete)
+			}
+			if record.DeletedAt == nil || !record.DeletedAt.Equal(deletedAt) {
+				t.Errorf("DeletedAt = %v, want %v", record.DeletedAt, deletedAt)
+			}
+			if record.Data != nil {
+				t.Errorf("tombstone carries data %v", record.Data)
+			}
+
+			body, err := json.Marshal(record)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var fields map[string]interface{}
+			_ = json.Unmarshal(body, &fields)
+			if _, ok := fields["data"]; ok {
+				t.Errorf("tombstone JSON has data: %s", body)
+			}
+		})
+	}
+}
+
+func TestNewEntrySyncRecordData(t *testing.T) {
+	notebookID := uint(2)
+	record := NewEntrySyncRecord(&JournalEntry{ThisDayDescription: "A walk", DailyReflection: "Calm", Version: 3, NotebookID: &notebookID}, 1)
+
+	data, ok := record.Data.(SyncEntryData)
+	if !ok {
+		t.Fatalf("Data = %T, want SyncEntryData", record.Data)
+	}
+	if *data.ThisDayDescription != "A walk" || *data.DailyReflection != "Calm" || data.Version != 3 || *data.NotebookID != notebookID {
+		t.Errorf("Data = %+v, want the entry's fields", data)
+	}
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Template field types
+const (
+	TemplateFieldText     = "text"
+	TemplateFieldNumber   = "number"
+	TemplateFieldRating   = "rating"
+	TemplateFieldCheckbox = "checkbox"
+	TemplateFieldList     = "list"
+)
+
+// Template is a user-defined set of fields an entry can fill in besides
+// its description and reflection. Changing the fields creates a new
+// version; entries keep the version they were written with, so they still
+// render after the template changes.
+type Template struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null; index"`
+	Name        string `gorm:"size:100; not null"`
+	Description string `gorm:"type:text; not null; default:''"`
+	Version     int    `gorm:"not null; default:1"` // Latest version, used by new entries
+}
+
+// TemplateVersion is an immutable snapshot of a template's fields.
+type TemplateVersion struct {
+	ID         uint      `gorm:"primaryKey"`
+	TemplateID uint      `gorm:"not null; uniqueIndex:idx_template_version,priority:1"`
+	Version    int       `gorm:"not null; uniqueIndex:idx_template_version,priority:2"`
+	Fields     JSONB     `gorm:"not null"` // []TemplateField
+	CreatedAt  time.Time `gorm:"not null"`
+}
+
+// TemplateField describes one field of a template. Min and Max bound
+// numbers; Max is the top of a rating scale (5 when unset); MaxLength and
+// MaxItems limit text and lists.
+type TemplateField struct {
+	Key       string   `json:"key" binding:"required,max=50"`
+	Label     string   `json:"label" binding:"required,max=200"`
+	Type      string   `json:"type" binding:"required,oneof=text number rating checkbox list"`
+	Required  bool     `json:"required"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MaxLength int      `json:"max_length,omitempty" binding:"min=0"`
+	MaxItems  int      `json:"max_items,omitempty" binding:"min=0"`
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type TemplateRequest struct {
+	Name        string          `json:"name" binding:"required,max=100"`
+	Description string          `json:"description"`
+	Fields      []TemplateField `json:"fields" binding:"required,min=1,max=50,dive"`
+}
+
+type TemplateResponse struct {
+	ID          uint            `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Version     int             `json:"version"`
+	Fields      []TemplateField `json:"fields"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type TemplateVersionResponse struct {
+	TemplateID uint            `json:"template_id"`
+	Version    int             `json:"version"`
+	Fields     []TemplateField `json:"fields"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
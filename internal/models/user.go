@@ -1,6 +1,16 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin" // Manages accounts, never reads journals
+)
 
 type User struct {
 	gorm.Model
@@ -8,6 +18,27 @@ type User struct {
 	FullName string         `gorm:"not null"`
 	Password string         `gorm:"not null"`
 	Journals []JournalEntry `gorm:"foreignKey:UserID"`
+
+	TimeZone      string `gorm:"not null; default:'UTC'"` // IANA name used to decide when a day ends
+	AutoCarryOver bool   `gorm:"not null; default:false; index"`
+
+	ReminderEnabled    bool       `gorm:"not null; default:false; index"`
+	ReminderTime       string     `gorm:"size:5; not null; default:'21:00'"` // HH:MM in the user's time zone
+	ReminderChannels   string     `gorm:"not null; default:'email'"`         // Comma-separated notify channels
+	ReminderWebhookURL string     `gorm:"not null; default:''"`
+	LastRemindedOn     *time.Time `gorm:"type:date"` // Local day of the last reminder sent
+
+	MemoryDigestEnabled bool       `gorm:"not null; default:false; index"`
+	MemoryDigestTime    string     `gorm:"size:5; not null; default:'08:00'"` // HH:MM in the user's time zone
+	LastDigestOn        *time.Time `gorm:"type:date"`                         // Local day of the last digest sent
+
+	Role           string     `gorm:"size:20; not null; default:'user'; index"` // Carried in the JWT
+	TokenVersion   int        `gorm:"not null; default:0"`                      // Bumped to invalidate every issued token
+	DisabledAt     *time.Time `gorm:"index"`
+	DisabledReason string     `gorm:"not null; default:''"`
+	FailedLogins   int        `gorm:"not null; default:0"` // Wrong passwords since the last successful login
+	LockedUntil    *time.Time
+	LastLoginAt    *time.Time
 }
 
 // --------------------------
@@ -23,3 +54,38 @@ type RegisterRequest struct {
 	FullName string `json:"full_name" binding:"required"`
 	Password string `json:"password" binding:"required,min=8"`
 }
+
+// UserSettingsRequest updates the user's preferences. Omitted fields are
+// left unchanged.
+type UserSettingsRequest struct {
+	TimeZone      *string `json:"time_zone"`
+	AutoCarryOver *bool   `json:"auto_carry_over"`
+}
+
+type UserSettingsResponse struct {
+	TimeZone      string `json:"time_zone"`
+	AutoCarryOver bool   `json:"auto_carry_over"`
+}
+
+// ReminderSettingsRequest updates the daily journaling reminder. Omitted
+// fields are left unchanged.
+type ReminderSettingsRequest struct {
+	Enabled    *bool     `json:"enabled"`
+	Time       *string   `json:"time" binding:"omitempty,datetime=15:04"`
+	Channels   *[]string `json:"channels" binding:"omitempty,min=1,dive,oneof=email webhook webpush"`
+	WebhookURL *string   `json:"webhook_url" binding:"omitempty,len=0|url"` // Empty string clears it
+
+	// The "on this day" digest goes out over the same channels
+	DigestEnabled *bool   `json:"digest_enabled"`
+	DigestTime    *string `json:"digest_time" binding:"omitempty,datetime=15:04"`
+}
+
+type ReminderSettingsResponse struct {
+	Enabled    bool     `json:"enabled"`
+	Time       string   `json:"time"`
+	Channels   []string `json:"channels"`
+	WebhookURL string   `json:"webhook_url,omitempty"`
+
+	DigestEnabled bool   `json:"digest_enabled"`
+	DigestTime    string `json:"digest_time"`
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookEventJournalCreated = "journal.created"
+	WebhookEventJournalUpdated = "journal.updated"
+	WebhookEventTaskCompleted  = "task.completed"
+	WebhookEventCommentCreated = "comment.created"
+	WebhookEventReactionAdded  = "reaction.added"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint a user registered to receive journal events.
+type Webhook struct {
+	gorm.Model
+	UserID              uint   `gorm:"not null; index"`
+	URL                 string `gorm:"type:text; not null"`
+	Secret              string `gorm:"not null"`            // Signs every payload, shown to the user once
+	Events              string `gorm:"type:text; not null"` // Comma-separated event names
+	Description         string `gorm:"not null; default:''"`
+	Active              bool   `gorm:"not null; default:true"`
+	ConsecutiveFailures int    `gorm:"not null; default:0"` // Failed attempts since the last success
+	DisabledAt          *time.Time
+	DisabledReason      string `gorm:"not null; default:''"`
+}
+
+// WebhookDelivery is one event queued for a webhook. Rows double as the
+// durable delivery queue and the delivery log.
+type WebhookDelivery struct {
+	ID             uint      `gorm:"primaryKey"`
+	WebhookID      uint      `gorm:"not null; index"`
+	EventID        string    `gorm:"size:32; not null; index"` // Shared by redeliveries of the same event
+	Event          string    `gorm:"size:50; not null"`
+	Payload        JSONB     `gorm:"type:jsonb; not null"`
+	Status         string    `gorm:"size:20; not null; default:'pending'; index:idx_webhook_delivery_queue,priority:1"`
+	Attempts       int       `gorm:"not null; default:0"`
+	NextAttemptAt  time.Time `gorm:"not null; index:idx_webhook_delivery_queue,priority:2"`
+	LastAttemptAt  *time.Time
+	ResponseStatus int    `gorm:"not null; default:0"`
+	ResponseBody   string `gorm:"type:text; not null; default:''"` // Truncated
+	Error          string `gorm:"type:text; not null; default:''"`
+	DurationMs     int64  `gorm:"not null; default:0"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// --------------------------
+// Dtos
+// --------------------------
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required,url"`
+	Events      []string `json:"events" binding:"required,min=1,dive,oneof=journal.created journal.updated task.completed comment.created reaction.added"`
+	Description string   `json:"description" binding:"max=255"`
+}
+
+// UpdateWebhookRequest changes a webhook. Omitted fields are left
+// unchanged; setting active re-enables a webhook that was disabled after
+// repeated failures.
+type UpdateWebhookRequest struct {
+	URL         *string   `json:"url" binding:"omitempty,url"`
+	Events      *[]string `json:"events" binding:"omitempty,min=1,dive,oneof=journal.created journal.updated task.completed comment.created reaction.added"`
+	Description *string   `json:"description" binding:"omitempty,max=255"`
+	Active      *bool     `json:"active"`
+}
+
+type WebhookResponse struct {
+	ID                  uint       `json:"id"`
+	URL                 string     `json:"url"`
+	Events              []string   `json:"events"`
+	Description         string     `json:"description"`
+	Active              bool       `json:"active"`
+	Secret              string     `json:"secret,omitempty"` // Only set when created or rotated
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uint       `json:"id"`
+	EventID        string     `json:"event_id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `json:"response_body,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	DurationMs     int64      `json:"duration_ms"`
+	Payload        JSONB      `json:"payload"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// WebhookEvent is the JSON body posted to webhook endpoints.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	UserID    uint        `json:"user_id"`
+	Data      interface{} `json:"data"`
+}
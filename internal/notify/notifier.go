@@ -0,0 +1,74 @@
+// Package notify delivers notifications to users over different channels.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelWebPush = "webpush"
+)
+
+// ErrNoAddress is returned when the recipient has no address for the
+// channel, e.g. no webhook URL configured.
+var ErrNoAddress = errors.New("recipient has no address for this channel")
+
+// ErrUnknownChannel is returned by the Dispatcher for channels that are
+// not configured.
+var ErrUnknownChannel = errors.New("notification channel is not configured")
+
+type Recipient struct {
+	UserID     uint
+	Email      string
+	Name       string
+	WebhookURL string
+}
+
+type Notification struct {
+	Recipient Recipient
+	Kind      string // e.g. "journal_reminder"
+	Title     string
+	Body      string
+	URL       string // Where the notification should lead, if anywhere
+}
+
+// Notifier delivers a notification over one channel.
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Dispatcher routes notifications to the notifiers of the requested
+// channels.
+type Dispatcher struct {
+	channels map[string]Notifier
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{channels: make(map[string]Notifier)}
+}
+
+// Register adds or replaces the notifier for a channel.
+func (d *Dispatcher) Register(channel string, notifier Notifier) {
+	d.channels[channel] = notifier
+}
+
+// Send delivers the notification on every channel, continuing past
+// failures. The returned error joins the failures of all channels.
+func (d *Dispatcher) Send(ctx context.Context, channels []string, notification Notification) error {
+	var errs []error
+	for _, channel := range channels {
+		notifier, ok := d.channels[channel]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, ErrUnknownChannel))
+			continue
+		}
+		if err := notifier.Notify(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+)
+
+// fakeNotifier records notifications in memory instead of sending them.
+// err, when set, is returned from every Notify call.
+type fakeNotifier struct {
+	mu   sync.Mutex
+	sent []Notification
+	err  error
+}
+
+func (n *fakeNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.err != nil {
+		return n.err
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+func (n *fakeNotifier) count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return len(n.sent)
+}
+
+func TestDispatcherSend(t *testing.T) {
+	errSMTP := errors.New("smtp: connection refused")
+
+	tests := []struct {
+		name          string
+		channels      []string
+		emailErr      error
+		wantErrs      []error
+		wantEmails    int
+		wantWebhooks  int
+		wantPushCount int
+	}{
+		{
+			name:       "single channel",
+			channels:   []string{ChannelEmail},
+			wantEmails: 1,
+		},
+		{
+			name:          "every channel",
+			channels:      []string{ChannelEmail, ChannelWebhook, ChannelWebPush},
+			wantEmails:    1,
+			wantWebhooks:  1,
+			wantPushCount: 1,
+		},
+		{
+			name:     "no channels",
+			channels: nil,
+		},
+		{
+			name:         "unknown channel",
+			channels:     []string{"sms", ChannelWebhook},
+			wantErrs:     []error{ErrUnknownChannel},
+			wantWebhooks: 1,
+		},
+		{
+			name:          "continues past a failing channel",
+			channels:      []string{ChannelEmail, ChannelWebhook, ChannelWebPush},
+			emailErr:      errSMTP,
+			wantErrs:      []error{errSMTP},
+			wantWebhooks:  1,
+			wantPushCount: 1,
+		},
+		{
+			name:         "joins every failure",
+			channels:     []string{ChannelEmail, "sms", ChannelWebhook},
+			emailErr:     errSMTP,
+			wantErrs:     []error{errSMTP, ErrUnknownChannel},
+			wantWebhooks: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			email := &fakeNotifier{err: tt.emailErr}
+			webhook := &fakeNotifier{}
+			push := &fakeNotifier{}
+
+			dispatcher := NewDispatcher()
+			dispatcher.Register(ChannelEmail, email)
+			dispatcher.Register(ChannelWebhook, webhook)
+			dispatcher.Register(ChannelWebPush, push)
+
+			err := dispatcher.Send(context.Background(), tt.channels, Notification{Kind: "journal_reminder"})
+			if len(tt.wantErrs) == 0 && err != nil {
+				t.Fatalf("Send() error = %v, want nil", err)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Send() error = %v, want it to wrap %v", err, want)
+				}
+			}
+
+			if got := email.count(); got != tt.wantEmails {
+				t.Errorf("emails sent = %d, want %d", got, tt.wantEmails)
+			}
+			if got := webhook.count(); got != tt.wantWebhooks {
+				t.Errorf("webhooks sent = %d, want %d", got, tt.wantWebhooks)
+			}
+			if got := push.count(); got != tt.wantPushCount {
+				t.Errorf("pushes sent = %d, want %d", got, tt.wantPushCount)
+			}
+		})
+	}
+}
+
+func TestDispatcherRegisterReplacesNotifier(t *testing.T) {
+	first := &fakeNotifier{}
+	second := &fakeNotifier{}
+
+	dispatcher := NewDispatcher()
+	dispatcher.Register(ChannelEmail, first)
+	dispatcher.Register(ChannelEmail, second)
+
+	if err := dispatcher.Send(context.Background(), []string{ChannelEmail}, Notification{}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if first.count() != 0 || second.count() != 1 {
+		t.Errorf("sent = %d and %d, want only the replacement to send", first.count(), second.count())
+	}
+}
+
+type recordingPushSender struct {
+	userID  uint
+	payload []byte
+}
+
+func (s *recordingPushSender) SendToUser(ctx context.Context, userID uint, payload []byte) error {
+	s.userID, s.payload = userID, payload
+	return nil
+}
+
+func TestWebPushNotifierSendsPayloadToUser(t *testing.T) {
+	sender := &recordingPushSender{}
+	notifier := NewWebPushNotifier(sender)
+
+	err := notifier.Notify(context.Background(), Notification{
+		Recipient: Recipient{UserID: 42, Email: "reader@example.com"},
+		Kind:      "journal_reminder",
+		Title:     "Time to write",
+		Body:      "How was your day?",
+		URL:       "/journals/new",
+	})
+	if err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	if sender.userID != 42 {
+		t.Errorf("sent to user %d, want 42", sender.userID)
+	}
+	var got pushPayload
+	if err := json.Unmarshal(sender.payload, &got); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	want := pushPayload{Kind: "journal_reminder", Title: "Time to write", Body: "How was your day?", URL: "/journals/new"}
+	if got != want {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
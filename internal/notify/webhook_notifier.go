@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+)
+
+// WebhookNotifier posts notifications as JSON to the recipient's webhook
+// URL.
+type WebhookNotifier struct {
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a notifier sending with client. Without one,
+// it uses a client that cannot reach private or local addresses, since
+// the URLs come from users.
+func NewWebhookNotifier(client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = safehttp.NewClient(10 * time.Second)
+	}
+	return &WebhookNotifier{client: client}
+}
+
+type webhookPayload struct {
+	Kind   string    `json:"kind"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	URL    string    `json:"url,omitempty"`
+	UserID uint      `json:"user_id"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.Recipient.WebhookURL == "" {
+		return ErrNoAddress
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		Kind:   notification.Kind,
+		Title:  notification.Title,
+		Body:   notification.Body,
+		URL:    notification.URL,
+		UserID: notification.Recipient.UserID,
+		SentAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Recipient.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
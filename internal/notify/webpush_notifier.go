@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// PushSender delivers an already serialized payload to every push
+// subscription of a user.
+type PushSender interface {
+	SendToUser(ctx context.Context, userID uint, payload []byte) error
+}
+
+// WebPushNotifier sends notifications to the user's browsers.
+type WebPushNotifier struct {
+	sender PushSender
+}
+
+func NewWebPushNotifier(sender PushSender) *WebPushNotifier {
+	return &WebPushNotifier{sender: sender}
+}
+
+type pushPayload struct {
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	URL   string `json:"url,omitempty"`
+}
+
+func (n *WebPushNotifier) Notify(ctx context.Context, notification Notification) error {
+	payload, err := json.Marshal(pushPayload{
+		Kind:  notification.Kind,
+		Title: notification.Title,
+		Body:  notification.Body,
+		URL:   notification.URL,
+	})
+	if err != nil {
+		return err
+	}
+	return n.sender.SendToUser(ctx, notification.Recipient.UserID, payload)
+}
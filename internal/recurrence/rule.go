@@ -0,0 +1,307 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// used by routines. Rules work on calendar dates; times of day and time
+// zones are ignored.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// maxCountScan bounds how far COUNT rules are walked day by day.
+const maxCountScan = 366 * 50
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Rule is a parsed recurrence rule. Supported parts are FREQ (DAILY,
+// WEEKLY or MONTHLY), INTERVAL, BYDAY without ordinals, BYMONTHDAY
+// (1-31), UNTIL and COUNT.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Until      *time.Time
+	Count      int
+}
+
+func EveryDay() Rule {
+	return Rule{Freq: Daily, Interval: 1}
+}
+
+func Weekdays() Rule {
+	return Rule{
+		Freq:     Weekly,
+		Interval: 1,
+		ByDay:    []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+}
+
+func EveryNDays(n int) Rule {
+	return Rule{Freq: Daily, Interval: n}
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE". An
+// optional "RRULE:" prefix is accepted.
+func Parse(s string) (Rule, error) {
+	rule := Rule{Interval: 1}
+	s = strings.TrimPrefix(strings.TrimSpace(strings.ToUpper(s)), "RRULE:")
+	if s == "" {
+		return rule, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return rule, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+
+		switch name {
+		case "FREQ":
+			switch Frequency(value) {
+			case Daily, Weekly, Monthly:
+				rule.Freq = Frequency(value)
+			default:
+				return rule, fmt.Errorf("%w: unsupported FREQ %s", ErrInvalidRule, value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 366 {
+				return rule, fmt.Errorf("%w: INTERVAL must be between 1 and 366", ErrInvalidRule)
+			}
+			rule.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				day, ok := weekdayCodes[code]
+				if !ok {
+					return rule, fmt.Errorf("%w: unsupported BYDAY %s", ErrInvalidRule, code)
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(item)
+				if err != nil || n < 1 || n > 31 {
+					return rule, fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31", ErrInvalidRule)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "UNTIL":
+			until, err := parseUntil(value)
+			if err != nil {
+				return rule, err
+			}
+			rule.Until = &until
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return rule, fmt.Errorf("%w: COUNT must be positive", ErrInvalidRule)
+			}
+			rule.Count = n
+		default:
+			return rule, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return rule, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Until != nil && rule.Count > 0 {
+		return rule, fmt.Errorf("%w: UNTIL and COUNT cannot be combined", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return Date(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: malformed UNTIL %s", ErrInvalidRule, value)
+}
+
+// String returns the rule in canonical RRULE form, without the prefix.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+
+	if len(r.ByDay) > 0 {
+		days := append([]time.Weekday(nil), r.ByDay...)
+		// Weeks start on Monday in RRULE
+		sort.Slice(days, func(i, j int) bool {
+			return (days[i]+6)%7 < (days[j]+6)%7
+		})
+		codes := make([]string, 0, len(days))
+		for _, day := range days {
+			codes = append(codes, strings.ToUpper(day.String()[:2]))
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, 0, len(r.ByMonthDay))
+		for _, day := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(day))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Occurs reports whether the rule, started on start, has an occurrence on
+// the calendar date of day.
+func (r Rule) Occurs(start, day time.Time) bool {
+	start, day = Date(start), Date(day)
+
+	if !r.matches(start, day) {
+		return false
+	}
+	if r.Count == 0 {
+		return true
+	}
+
+	// Walk from the start to find the ordinal of this occurrence
+	seen := 0
+	for d, i := start, 0; !d.After(day) && i < maxCountScan; d, i = d.AddDate(0, 0, 1), i+1 {
+		if r.matches(start, d) {
+			seen++
+			if seen > r.Count {
+				return false
+			}
+		}
+	}
+	return seen <= r.Count
+}
+
+// Between lists the occurrences from from to to, both inclusive.
+func (r Rule) Between(start, from, to time.Time) []time.Time {
+	from, to = Date(from), Date(to)
+	if from.Before(Date(start)) {
+		from = Date(start)
+	}
+
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if r.Occurs(start, d) {
+			dates = append(dates, d)
+		}
+	}
+	return dates
+}
+
+func (r Rule) matches(start, day time.Time) bool {
+	if day.Before(start) {
+		return false
+	}
+	if r.Until != nil && day.After(*r.Until) {
+		return false
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	switch r.Freq {
+	case Daily:
+		if daysBetween(start, day)%interval != 0 {
+			return false
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+
+	case Weekly:
+		weeks := daysBetween(weekStart(start), weekStart(day)) / 7
+		if weeks%interval != 0 {
+			return false
+		}
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+		return r.matchesByDay(day)
+
+	case Monthly:
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%interval != 0 {
+			return false
+		}
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			return day.Day() == start.Day()
+		}
+		return r.matchesByDay(day) && r.matchesByMonthDay(day)
+	}
+
+	return false
+}
+
+func (r Rule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Rule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	for _, monthDay := range r.ByMonthDay {
+		if day.Day() == monthDay {
+			return true
+		}
+	}
+	return false
+}
+
+// Date strips the time of day and location from t, keeping its calendar
+// date as midnight UTC.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
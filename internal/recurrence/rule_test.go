@@ -0,0 +1,233 @@
+package recurrence
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string
+	}{
+		{name: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "prefix and lowercase", rule: " rrule:freq=daily;interval=3 ", want: "FREQ=DAILY;INTERVAL=3"},
+		{name: "interval of one is implied", rule: "FREQ=WEEKLY;INTERVAL=1", want: "FREQ=WEEKLY"},
+		{name: "weekdays sorted from Monday", rule: "FREQ=WEEKLY;BYDAY=SU,WE,MO", want: "FREQ=WEEKLY;BYDAY=MO,WE,SU"},
+		{name: "month days", rule: "FREQ=MONTHLY;BYMONTHDAY=1,15", want: "FREQ=MONTHLY;BYMONTHDAY=1,15"},
+		{name: "until date", rule: "FREQ=DAILY;UNTIL=20260131", want: "FREQ=DAILY;UNTIL=20260131"},
+		{name: "until timestamp", rule: "FREQ=DAILY;UNTIL=20260131T235959Z", want: "FREQ=DAILY;UNTIL=20260131"},
+		{name: "count", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=10", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU;COUNT=10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("Parse(%q).String() = %q, want %q", tt.rule, got, tt.want)
+			}
+
+			again, err := Parse(rule.String())
+			if err != nil || again.String() != tt.want {
+				t.Errorf("Parse(%q) = %v, %v, want the same rule", tt.want, again, err)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidRules(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "empty", rule: ""},
+		{name: "prefix only", rule: "RRULE:"},
+		{name: "missing FREQ", rule: "INTERVAL=2"},
+		{name: "unsupported FREQ", rule: "FREQ=YEARLY"},
+		{name: "part without value", rule: "FREQ=DAILY;INTERVAL="},
+		{name: "part without equals", rule: "FREQ=DAILY;COUNT"},
+		{name: "zero interval", rule: "FREQ=DAILY;INTERVAL=0"},
+		{name: "interval too large", rule: "FREQ=DAILY;INTERVAL=367"},
+		{name: "BYDAY with ordinal", rule: "FREQ=MONTHLY;BYDAY=1MO"},
+		{name: "unknown weekday", rule: "FREQ=WEEKLY;BYDAY=XX"},
+		{name: "negative month day", rule: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{name: "month day too large", rule: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{name: "malformed UNTIL", rule: "FREQ=DAILY;UNTIL=tomorrow"},
+		{name: "zero COUNT", rule: "FREQ=DAILY;COUNT=0"},
+		{name: "UNTIL with COUNT", rule: "FREQ=DAILY;UNTIL=20260131;COUNT=3"},
+		{name: "unsupported part", rule: "FREQ=DAILY;BYHOUR=9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.rule); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.rule, err, ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestRuleBetween(t *testing.T) {
+	// January 5, 2026 is a Monday
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		from  time.Time
+		to    time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY",
+			start: date(2026, 1, 5), from: date(2026, 1, 5), to: date(2026, 1, 9),
+			want: []time.Time{date(2026, 1, 5), date(2026, 1, 6), date(2026, 1, 7), date(2026, 1, 8), date(2026, 1, 9)},
+		},
+		{
+			name:  "every other day",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: date(2026, 1, 5), from: date(2026, 1, 5), to: date(2026, 1, 10),
+			want: []time.Time{date(2026, 1, 5), date(2026, 1, 7), date(2026, 1, 9)},
+		},
+		{
+			name:  "nothing before the start",
+			rule:  "FREQ=DAILY",
+			start: date(2026, 1, 8), from: date(2026, 1, 1), to: date(2026, 1, 9),
+			want: []time.Time{date(2026, 1, 8), date(2026, 1, 9)},
+		},
+		{
+			name:  "daily on weekends",
+			rule:  "FREQ=DAILY;BYDAY=SA,SU",
+			start: date(2026, 1, 5), from: date(2026, 1, 5), to: date(2026, 1, 18),
+			want: []time.Time{date(2026, 1, 10), date(2026, 1, 11), date(2026, 1, 17), date(2026, 1, 18)},
+		},
+		{
+			name:  "weekly on the start's weekday",
+			rule:  "FREQ=WEEKLY",
+			start: date(2026, 1, 5), from: date(2026, 1, 5), to: date(2026, 1, 25),
+			want: []time.Time{date(2026, 1, 5), date(2026, 1, 12), date(2026, 1, 19)},
+		},
+		{
+			name:  "weekdays",
+			rule:  Weekdays().String(),
+			start: date(2026, 1, 5), from: date(2026, 1, 9), to: date(2026, 1, 13),
+			want: []time.Time{date(2026, 1, 9), date(2026, 1, 12), date(2026, 1, 13)},
+		},
+		{
+			// Weeks are counted from the Monday of the start's week
+			name:  "every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: date(2026, 1, 7), from: date(2026, 1, 5), to: date(2026, 1, 25),
+			want: []time.Time{date(2026, 1, 7), date(2026, 1, 19), date(2026, 1, 21)},
+		},
+		{
+			name:  "monthly skips short months",
+			rule:  "FREQ=MONTHLY",
+			start: date(2026, 1, 31), from: date(2026, 1, 1), to: date(2026, 5, 31),
+			want: []time.Time{date(2026, 1, 31), date(2026, 3, 31), date(2026, 5, 31)},
+		},
+		{
+			name:  "monthly on month days",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=1,15",
+			start: date(2026, 1, 1), from: date(2026, 1, 1), to: date(2026, 2, 15),
+			want: []time.Time{date(2026, 1, 1), date(2026, 1, 15), date(2026, 2, 1), date(2026, 2, 15)},
+		},
+		{
+			name:  "every other month",
+			rule:  "FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=10",
+			start: date(2026, 1, 1), from: date(2026, 1, 1), to: date(2026, 6, 30),
+			want: []time.Time{date(2026, 1, 10), date(2026, 3, 10), date(2026, 5, 10)},
+		},
+		{
+			name:  "monthly on a weekday",
+			rule:  "FREQ=MONTHLY;BYDAY=FR",
+			start: date(2026, 1, 1), from: date(2026, 1, 1), to: date(2026, 1, 31),
+			want: []time.Time{date(2026, 1, 2), date(2026, 1, 9), date(2026, 1, 16), date(2026, 1, 23), date(2026, 1, 30)},
+		},
+		{
+			name:  "until is inclusive",
+			rule:  "FREQ=DAILY;UNTIL=20260107",
+			start: date(2026, 1, 5), from: date(2026, 1, 1), to: date(2026, 1, 31),
+			want: []time.Time{date(2026, 1, 5), date(2026, 1, 6), date(2026, 1, 7)},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: date(2026, 1, 5), from: date(2026, 1, 1), to: date(2026, 1, 31),
+			want: []time.Time{date(2026, 1, 5), date(2026, 1, 6), date(2026, 1, 7)},
+		},
+		{
+			name:  "count over a weekend",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=4",
+			start: date(2026, 1, 8), from: date(2026, 1, 1), to: date(2026, 1, 31),
+			want: []time.Time{date(2026, 1, 8), date(2026, 1, 9), date(2026, 1, 12), date(2026, 1, 13)},
+		},
+		{
+			name:  "count from a later window",
+			rule:  "FREQ=DAILY;INTERVAL=2;COUNT=3",
+			start: date(2026, 1, 5), from: date(2026, 1, 8), to: date(2026, 1, 31),
+			want: []time.Time{date(2026, 1, 9)},
+		},
+		{
+			name:  "empty window",
+			rule:  "FREQ=DAILY",
+			start: date(2026, 1, 5), from: date(2026, 1, 10), to: date(2026, 1, 9),
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rule, err)
+			}
+			if got := rule.Between(tt.start, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Between() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleOccursIgnoresTimeOfDay(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	rule := EveryNDays(2)
+	start := time.Date(2026, 1, 5, 23, 30, 0, 0, tokyo)
+
+	tests := []struct {
+		name string
+		day  time.Time
+		want bool
+	}{
+		{name: "start date late in the day", day: time.Date(2026, 1, 5, 23, 59, 0, 0, tokyo), want: true},
+		{name: "next occurrence early in the day", day: time.Date(2026, 1, 7, 0, 5, 0, 0, time.UTC), want: true},
+		{name: "day in between", day: time.Date(2026, 1, 6, 12, 0, 0, 0, tokyo), want: false},
+		{name: "day before the start", day: time.Date(2026, 1, 3, 12, 0, 0, 0, tokyo), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.Occurs(start, tt.day); got != tt.want {
+				t.Errorf("Occurs(%v) = %v, want %v", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEveryDay(t *testing.T) {
+	got := EveryDay().Between(date(2026, 2, 27), date(2026, 2, 27), date(2026, 3, 2))
+	want := []time.Time{date(2026, 2, 27), date(2026, 2, 28), date(2026, 3, 1), date(2026, 3, 2)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Between() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// Package safehttp makes outgoing requests to URLs that users configure,
+// such as webhooks, without letting them reach the server's own network.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrUnsafeURL is returned for URLs that are not https or name a
+	// local or private host.
+	ErrUnsafeURL = errors.New("url must use https and point to a public host")
+
+	// ErrBlockedAddress is returned when a host resolves to an address
+	// outside the public internet.
+	ErrBlockedAddress = errors.New("destination address is not allowed")
+)
+
+// blockedNets are special-purpose ranges the net.IP predicates miss.
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // "This" network
+	"100.64.0.0/10", // Carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking
+	"240.0.0.0/4",   // Reserved, including broadcast
+	"64:ff9b::/96",  // NAT64, embeds IPv4 addresses
+)
+
+// NewClient returns a client that only connects to public addresses. The
+// address is checked when dialing, after DNS resolution, so a host that
+// resolves to a private address, or changes to one, is refused too.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   checkDial,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil, // A proxy would dial on our behalf
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+}
+
+// CheckURL rejects URLs that are not https or name a host that is
+// obviously local. Hosts are only resolved when dialing.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return ErrUnsafeURL
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrUnsafeURL
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublic(ip) {
+		return ErrUnsafeURL
+	}
+	return nil
+}
+
+// IsPublic reports whether ip is a globally routable unicast address.
+func IsPublic(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, block := range blockedNets {
+		if block.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkDial runs for every connection attempt with the resolved address.
+func checkDial(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublic(ip) {
+		return fmt.Errorf("%w: %s", ErrBlockedAddress, host)
+	}
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	blocks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, block, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
@@ -0,0 +1,107 @@
+package safehttp
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{ip: "8.8.8.8", want: true},
+		{ip: "93.184.216.34", want: true},
+		{ip: "2606:4700:4700::1111", want: true},
+		{ip: "127.0.0.1"},
+		{ip: "127.255.255.254"},
+		{ip: "::1"},
+		{ip: "10.1.2.3"},
+		{ip: "172.16.0.1"},
+		{ip: "192.168.1.1"},
+		{ip: "fd00::1"},
+		{ip: "169.254.169.254"}, // Cloud metadata endpoint
+		{ip: "fe80::1"},
+		{ip: "0.0.0.0"},
+		{ip: "::"},
+		{ip: "0.1.2.3"},
+		{ip: "100.64.0.1"},
+		{ip: "192.0.0.8"},
+		{ip: "198.18.0.1"},
+		{ip: "224.0.0.1"},
+		{ip: "ff02::1"},
+		{ip: "255.255.255.255"},
+		{ip: "240.0.0.1"},
+		{ip: "64:ff9b::7f00:1"}, // NAT64 of 127.0.0.1
+		{ip: "::ffff:127.0.0.1"},
+		{ip: "::ffff:10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("invalid test address %q", tt.ip)
+			}
+			if got := IsPublic(ip); got != tt.want {
+				t.Errorf("IsPublic(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "public https host", url: "https://hooks.example.com/journal"},
+		{name: "public https address", url: "https://93.184.216.34/hook"},
+		{name: "with port", url: "https://hooks.example.com:8443/hook"},
+		{name: "http", url: "http://hooks.example.com/journal", wantErr: true},
+		{name: "other scheme", url: "ftp://hooks.example.com/journal", wantErr: true},
+		{name: "no host", url: "https:///journal", wantErr: true},
+		{name: "relative", url: "/journal", wantErr: true},
+		{name: "malformed", url: "https://%zz", wantErr: true},
+		{name: "localhost", url: "https://localhost/hook", wantErr: true},
+		{name: "localhost with trailing dot", url: "https://LOCALHOST./hook", wantErr: true},
+		{name: "localhost subdomain", url: "https://api.localhost/hook", wantErr: true},
+		{name: "loopback address", url: "https://127.0.0.1/hook", wantErr: true},
+		{name: "loopback IPv6", url: "https://[::1]/hook", wantErr: true},
+		{name: "private address", url: "https://10.0.0.5/hook", wantErr: true},
+		{name: "metadata address", url: "https://169.254.169.254/latest/meta-data", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckURL(tt.url)
+			if tt.wantErr && !errors.Is(err, ErrUnsafeURL) {
+				t.Errorf("CheckURL(%q) error = %v, want %v", tt.url, err, ErrUnsafeURL)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("CheckURL(%q) error = %v, want nil", tt.url, err)
+			}
+		})
+	}
+}
+
+func TestNewClientRefusesLocalAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	_, err := NewClient(5 * time.Second).Get(server.URL)
+	if !errors.Is(err, ErrBlockedAddress) {
+		t.Errorf("Get() error = %v, want %v", err, ErrBlockedAddress)
+	}
+	if requests != 0 {
+		t.Errorf("server received %d requests, want none", requests)
+	}
+}
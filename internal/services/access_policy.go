@@ -0,0 +1,216 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+// AccessAction is something a user wants to do with an entry.
+type AccessAction string
+
+const (
+	AccessRead    AccessAction = "read"
+	AccessComment AccessAction = "comment"
+	AccessEdit    AccessAction = "edit"
+	AccessManage  AccessAction = "manage" // Delete, carry over: owner only
+)
+
+// readerRoleRank orders the roles; a role may do everything a lower one
+// may.
+var readerRoleRank = map[string]int{
+	models.ReaderRoleViewer:    1,
+	models.ReaderRoleCommenter: 2,
+	models.ReaderRoleEditor:    3,
+}
+
+// accessActionRank is the lowest role rank allowed to take an action.
+var accessActionRank = map[AccessAction]int{
+	AccessRead:    readerRoleRank[models.ReaderRoleViewer],
+	AccessComment: readerRoleRank[models.ReaderRoleCommenter],
+	AccessEdit:    readerRoleRank[models.ReaderRoleEditor],
+}
+
+// AccessPolicy decides who may do what with an entry. Owners may do
+// anything; other users need an accepted reader grant covering the entry,
+// through its notebook or one of its hashtags, with a role allowing the
+// action. Viewers and commenters only see published entries.
+//
+// Handlers authorize first and then call the services with the owner's
+// ID, so the services themselves stay scoped to a single user. Every
+// access by a reader is written to the grant's audit trail.
+type AccessPolicy struct {
+	journalRepo  *repositories.JournalRepository
+	grantRepo    *repositories.ReaderGrantRepository
+	notebookRepo *repositories.NotebookRepository
+}
+
+func NewAccessPolicy(
+	journalRepo *repositories.JournalRepository,
+	grantRepo *repositories.ReaderGrantRepository,
+	notebookRepo *repositories.NotebookRepository,
+) *AccessPolicy {
+	return &AccessPolicy{
+		journalRepo:  journalRepo,
+		grantRepo:    grantRepo,
+		notebookRepo: notebookRepo,
+	}
+}
+
+// within returns a policy that reads entries through journalRepo, so that
+// entries and tasks created earlier in the same transaction are found.
+func (p *AccessPolicy) within(journalRepo *repositories.JournalRepository) *AccessPolicy {
+	return &AccessPolicy{
+		journalRepo:  journalRepo,
+		grantRepo:    p.grantRepo,
+		notebookRepo: p.notebookRepo,
+	}
+}
+
+// AuthorizeEntry checks that actorID may take action on the entry and
+// returns the ID of the entry's owner. Entries the actor cannot see are
+// reported as ErrJournalNotFound; visible ones they may not change as
+// ErrForbidden.
+func (p *AccessPolicy) AuthorizeEntry(actorID, entryID uint, action AccessAction) (uint, error) {
+	entry, err := p.journalRepo.FindHeader(entryID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return 0, ErrJournalNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if entry.UserID == actorID {
+		return actorID, nil
+	}
+
+	grant, err := p.bestGrant(actorID, entry)
+	if err != nil {
+		return 0, err
+	}
+	if grant == nil {
+		return 0, ErrJournalNotFound
+	}
+
+	if !roleAllows(grant.Role, action) {
+		return 0, ErrForbidden
+	}
+
+	entryRef := entry.ID
+	err = p.grantRepo.LogAccess(&models.ReaderAccess{
+		GrantID:        grant.ID,
+		OwnerID:        entry.UserID,
+		ReaderID:       actorID,
+		JournalEntryID: &entryRef,
+		Action:         string(action),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return entry.UserID, nil
+}
+
+// AuthorizeTask is AuthorizeEntry for the entry owning a task. Tasks the
+// actor cannot see are reported as ErrTaskNotFound.
+func (p *AccessPolicy) AuthorizeTask(actorID, taskID uint, action AccessAction) (uint, error) {
+	entryID, err := p.journalRepo.FindTaskEntryID(taskID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return 0, ErrTaskNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	ownerID, err := p.AuthorizeEntry(actorID, entryID, action)
+	if errors.Is(err, ErrJournalNotFound) {
+		return 0, ErrTaskNotFound
+	}
+	return ownerID, err
+}
+
+// bestGrant returns the grant with the highest role among those the owner
+// gave the reader that cover the entry, or nil.
+func (p *AccessPolicy) bestGrant(readerID uint, entry *models.JournalEntry) (*models.ReaderGrant, error) {
+	grants, err := p.grantRepo.FindAccepted(entry.UserID, readerID)
+	if err != nil || len(grants) == 0 {
+		return nil, err
+	}
+
+	return pickGrant(grants, entry, func() (*uint, error) {
+		notebook, err := p.notebookRepo.FindDefault(entry.UserID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &notebook.ID, nil
+	})
+}
+
+// pickGrant returns the grant with the highest role among grants that
+// cover the entry, or nil. defaultNotebook is only called for entries
+// outside any notebook, which live in the owner's default one.
+func pickGrant(grants []models.ReaderGrant, entry *models.JournalEntry, defaultNotebook func() (*uint, error)) (*models.ReaderGrant, error) {
+	var hashtags map[string]bool
+	var defaultNotebookID *uint
+	defaultLoaded := false
+	var best *models.ReaderGrant
+
+	for i := range grants {
+		grant := &grants[i]
+
+		// Only editors work on drafts
+		if entry.Status != enums.EntryStatus.Published && grant.Role != models.ReaderRoleEditor {
+			continue
+		}
+
+		covered := false
+		switch {
+		case grant.NotebookID != nil:
+			notebookID := entry.NotebookID
+			if notebookID == nil {
+				if !defaultLoaded {
+					id, err := defaultNotebook()
+					if err != nil {
+						return nil, err
+					}
+					defaultNotebookID, defaultLoaded = id, true
+				}
+				notebookID = defaultNotebookID
+			}
+			covered = notebookID != nil && *notebookID == *grant.NotebookID
+
+		case grant.Tag != "":
+			if hashtags == nil {
+				hashtags = entryHashtags(entry)
+			}
+			covered = hashtags[grant.Tag]
+		}
+
+		if covered && (best == nil || readerRoleRank[grant.Role] > readerRoleRank[best.Role]) {
+			best = grant
+		}
+	}
+	return best, nil
+}
+
+// roleAllows reports whether a reader with role may take action. Manage
+// is never allowed to readers.
+func roleAllows(role string, action AccessAction) bool {
+	required, ok := accessActionRank[action]
+	return ok && readerRoleRank[role] >= required
+}
+
+// entryHashtags returns the lower-cased hashtags of an entry, without #.
+func entryHashtags(entry *models.JournalEntry) map[string]bool {
+	tags := make(map[string]bool)
+	for _, word := range tokenize(entry.ThisDayDescription + " " + entry.DailyReflection) {
+		if strings.HasPrefix(word, "#") && len(word) > 1 {
+			tags[word[1:]] = true
+		}
+	}
+	return tags
+}
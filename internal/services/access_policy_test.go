@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+func TestRoleAllows(t *testing.T) {
+	tests := []struct {
+		role string
+		want map[AccessAction]bool
+	}{
+		{
+			role: models.ReaderRoleViewer,
+			want: map[AccessAction]bool{AccessRead: true},
+		},
+		{
+			role: models.ReaderRoleCommenter,
+			want: map[AccessAction]bool{AccessRead: true, AccessComment: true},
+		},
+		{
+			role: models.ReaderRoleEditor,
+			want: map[AccessAction]bool{AccessRead: true, AccessComment: true, AccessEdit: true},
+		},
+		{
+			role: "owner",
+			want: map[AccessAction]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			for _, action := range []AccessAction{AccessRead, AccessComment, AccessEdit, AccessManage} {
+				if got := roleAllows(tt.role, action); got != tt.want[action] {
+					t.Errorf("roleAllows(%s, %s) = %v, want %v", tt.role, action, got, tt.want[action])
+				}
+			}
+		})
+	}
+}
+
+func TestPickGrant(t *testing.T) {
+	notebook := func(id uint) *uint { return &id }
+	grant := func(id uint, role string, notebookID *uint, tag string) models.ReaderGrant {
+		g := models.ReaderGrant{Role: role, NotebookID: notebookID, Tag: tag}
+		g.ID = id
+		return g
+	}
+	entry := func(status enums.EntryStatusType, notebookID *uint, text string) *models.JournalEntry {
+		return &models.JournalEntry{UserID: 1, Status: status, NotebookID: notebookID, ThisDayDescription: text}
+	}
+	published, draft := enums.EntryStatus.Published, enums.EntryStatus.Draft
+
+	tests := []struct {
+		name            string
+		grants          []models.ReaderGrant
+		entry           *models.JournalEntry
+		defaultNotebook *uint
+		wantGrantID     uint // 0 when no grant covers the entry
+	}{
+		{
+			name:        "viewer of the entry's notebook",
+			grants:      []models.ReaderGrant{grant(1, models.ReaderRoleViewer, notebook(7), "")},
+			entry:       entry(published, notebook(7), "A walk"),
+			wantGrantID: 1,
+		},
+		{
+			name:   "viewer of another notebook",
+			grants: []models.ReaderGrant{grant(1, models.ReaderRoleViewer, notebook(8), "")},
+			entry:  entry(published, notebook(7), "A walk"),
+		},
+		{
+			name:            "entry without notebook is in the default one",
+			grants:          []models.ReaderGrant{grant(1, models.ReaderRoleCommenter, notebook(3), "")},
+			entry:           entry(published, nil, "A walk"),
+			defaultNotebook: notebook(3),
+			wantGrantID:     1,
+		},
+		{
+			name:   "owner without a default notebook",
+			grants: []models.ReaderGrant{grant(1, models.ReaderRoleViewer, notebook(3), "")},
+			entry:  entry(published, nil, "A walk"),
+		},
+		{
+			name:        "commenter of a hashtag",
+			grants:      []models.ReaderGrant{grant(1, models.ReaderRoleCommenter, nil, "family")},
+			entry:       entry(published, nil, "Dinner with #Family tonight"),
+			wantGrantID: 1,
+		},
+		{
+			name:   "hashtag not on the entry",
+			grants: []models.ReaderGrant{grant(1, models.ReaderRoleCommenter, nil, "family")},
+			entry:  entry(published, nil, "Dinner with family tonight"),
+		},
+		{
+			name:   "viewer does not see drafts",
+			grants: []models.ReaderGrant{grant(1, models.ReaderRoleViewer, notebook(7), "")},
+			entry:  entry(draft, notebook(7), "Unfinished"),
+		},
+		{
+			name:   "commenter does not see drafts",
+			grants: []models.ReaderGrant{grant(1, models.ReaderRoleCommenter, nil, "work")},
+			entry:  entry(draft, nil, "Unfinished #work"),
+		},
+		{
+			name:        "editor sees drafts",
+			grants:      []models.ReaderGrant{grant(1, models.ReaderRoleEditor, notebook(7), "")},
+			entry:       entry(draft, notebook(7), "Unfinished"),
+			wantGrantID: 1,
+		},
+		{
+			name: "highest covering role wins",
+			grants: []models.ReaderGrant{
+				grant(1, models.ReaderRoleViewer, notebook(7), ""),
+				grant(2, models.ReaderRoleEditor, nil, "family"),
+				grant(3, models.ReaderRoleCommenter, nil, "family"),
+			},
+			entry:       entry(published, notebook(7), "Picnic #family"),
+			wantGrantID: 2,
+		},
+		{
+			name: "higher role that does not cover the entry is ignored",
+			grants: []models.ReaderGrant{
+				grant(1, models.ReaderRoleEditor, notebook(8), ""),
+				grant(2, models.ReaderRoleViewer, nil, "family"),
+			},
+			entry:       entry(published, notebook(7), "Picnic #family"),
+			wantGrantID: 2,
+		},
+		{
+			name: "only the editor grant covers a draft",
+			grants: []models.ReaderGrant{
+				grant(1, models.ReaderRoleCommenter, notebook(7), ""),
+				grant(2, models.ReaderRoleEditor, nil, "family"),
+			},
+			entry:       entry(draft, notebook(7), "Picnic #family"),
+			wantGrantID: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookups := 0
+			got, err := pickGrant(tt.grants, tt.entry, func() (*uint, error) {
+				lookups++
+				return tt.defaultNotebook, nil
+			})
+			if err != nil {
+				t.Fatalf("pickGrant() error = %v", err)
+			}
+
+			var gotID uint
+			if got != nil {
+				gotID = got.ID
+			}
+			if gotID != tt.wantGrantID {
+				t.Errorf("pickGrant() = grant %d, want grant %d", gotID, tt.wantGrantID)
+			}
+			if lookups > 1 {
+				t.Errorf("default notebook looked up %d times, want at most once", lookups)
+			}
+		})
+	}
+}
+
+func TestPickGrantReturnsLookupErrors(t *testing.T) {
+	errLookup := errors.New("connection reset")
+	notebookID := uint(3)
+	grants := []models.ReaderGrant{{Role: models.ReaderRoleViewer, NotebookID: &notebookID}}
+	entry := &models.JournalEntry{Status: enums.EntryStatus.Published}
+
+	_, err := pickGrant(grants, entry, func() (*uint, error) { return nil, errLookup })
+	if !errors.Is(err, errLookup) {
+		t.Errorf("pickGrant() error = %v, want %v", err, errLookup)
+	}
+}
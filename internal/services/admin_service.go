@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	adminUsersPageSize    = 50
+	adminUsersMaxPageSize = 200
+)
+
+// AdminService backs the admin API: finding accounts, disabling and
+// unlocking them, ending their sessions and reading aggregate statistics.
+// Admins manage accounts, not journals, so nothing it returns includes
+// what users wrote. Every change to an account is recorded in the security
+// audit log along with the admin who made it.
+type AdminService struct {
+	adminRepo *repositories.AdminRepository
+	userRepo  *repositories.UserRepository
+	events    *SecurityEventService
+	now       func() time.Time
+}
+
+func NewAdminService(
+	adminRepo *repositories.AdminRepository,
+	userRepo *repositories.UserRepository,
+	events *SecurityEventService,
+) *AdminService {
+	return &AdminService{adminRepo: adminRepo, userRepo: userRepo, events: events, now: time.Now}
+}
+
+// Users pages through the accounts matching the query, newest first.
+func (s *AdminService) Users(query models.AdminUserQuery, limit, offset int) (*models.AdminUsersResponse, error) {
+	switch query.Role {
+	case "", models.RoleUser, models.RoleAdmin:
+	default:
+		return nil, ErrInvalidUserFilter
+	}
+	switch query.Status {
+	case "", models.AccountStatusActive, models.AccountStatusDisabled, models.AccountStatusLocked:
+	default:
+		return nil, ErrInvalidUserFilter
+	}
+
+	if limit <= 0 {
+		limit = adminUsersPageSize
+	}
+	if limit > adminUsersMaxPageSize {
+		limit = adminUsersMaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	now := s.now()
+	users, total, err := s.adminRepo.FindUsers(query, now, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.AdminUsersResponse{Users: []models.AdminUserResponse{}, Total: total}
+	for i := range users {
+		response.Users = append(response.Users, toAdminUserResponse(&users[i], now))
+	}
+	return response, nil
+}
+
+// User describes one account, with how many entries it holds.
+func (s *AdminService) User(id uint) (*models.AdminUserResponse, error) {
+	user, err := s.adminRepo.FindUser(id)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.adminRepo.CountEntries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toAdminUserResponse(user, s.now())
+	response.Entries = &entries
+	return &response, nil
+}
+
+// Disable blocks the account from logging in and ends its sessions.
+func (s *AdminService) Disable(adminID, id uint, reason string, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	if adminID == id {
+		return nil, ErrCannotModifySelf
+	}
+	reason = strings.TrimSpace(reason)
+	return s.update(adminID, id, models.SecurityEventAccountDisabled, excerpt(reason, 250), map[string]interface{}{
+		"disabled_at":     s.now(),
+		"disabled_reason": reason,
+		"token_version":   gorm.Expr("token_version + 1"),
+	}, info)
+}
+
+// Enable lets a disabled account log in again.
+func (s *AdminService) Enable(adminID, id uint, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	return s.update(adminID, id, models.SecurityEventAccountEnabled, "", map[string]interface{}{
+		"disabled_at":     nil,
+		"disabled_reason": "",
+	}, info)
+}
+
+// Unlock lifts a lockout from wrong passwords before it ends on its own.
+func (s *AdminService) Unlock(adminID, id uint, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	return s.update(adminID, id, models.SecurityEventAccountUnlocked, "", map[string]interface{}{
+		"failed_logins": 0,
+		"locked_until":  nil,
+	}, info)
+}
+
+// Logout ends every session of the account; its tokens stop working on
+// their next request.
+func (s *AdminService) Logout(adminID, id uint, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	return s.update(adminID, id, models.SecurityEventSessionsRevoked, "", map[string]interface{}{
+		"token_version": gorm.Expr("token_version + 1"),
+	}, info)
+}
+
+// SetRole changes the account's role. Its sessions end so that the next
+// token carries the new role.
+func (s *AdminService) SetRole(adminID, id uint, role string, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	if adminID == id {
+		return nil, ErrCannotModifySelf
+	}
+	return s.update(adminID, id, models.SecurityEventRoleChanged, role, map[string]interface{}{
+		"role":          role,
+		"token_version": gorm.Expr("token_version + 1"),
+	}, info)
+}
+
+// SecurityEvents pages through the audit log of every account.
+func (s *AdminService) SecurityEvents(query models.SecurityEventQuery, limit, offset int) (*models.SecurityEventsResponse, error) {
+	return s.events.All(query, limit, offset)
+}
+
+// Stats returns aggregate counts over all accounts.
+func (s *AdminService) Stats() (*models.AdminStats, error) {
+	now := s.now()
+	users, err := s.adminRepo.UserStats(now)
+	if err != nil {
+		return nil, err
+	}
+	content, err := s.adminRepo.ContentStats(now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdminStats{
+		GeneratedAt: now,
+		Users:       *users,
+		Content:     *content,
+	}, nil
+}
+
+// PromoteAdmins makes the accounts with the given emails admins. It lets
+// the first admins be configured at startup. Each promotion is recorded
+// in the audit log without an acting admin.
+func (s *AdminService) PromoteAdmins(emails []string) (int, error) {
+	var cleaned []string
+	for _, email := range emails {
+		if email = strings.TrimSpace(email); email != "" {
+			cleaned = append(cleaned, email)
+		}
+	}
+
+	var promoted []uint
+	err := s.userRepo.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if promoted, err = s.userRepo.WithTx(tx).PromoteAdmins(cleaned); err != nil {
+			return err
+		}
+		events := s.events.withTx(tx)
+		for i := range promoted {
+			err := events.Record(models.SecurityEvent{
+				UserID: &promoted[i],
+				Type:   models.SecurityEventRoleChanged,
+				Detail: models.RoleAdmin,
+			}, models.RequestInfo{})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return len(promoted), err
+}
+
+// update changes the account and records the change as eventType, in one
+// transaction so no change is made without its audit entry.
+func (s *AdminService) update(adminID, id uint, eventType, detail string, fields map[string]interface{}, info models.RequestInfo) (*models.AdminUserResponse, error) {
+	err := s.userRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.userRepo.WithTx(tx).UpdateAccount(id, fields); err != nil {
+			return err
+		}
+		return s.events.withTx(tx).Record(models.SecurityEvent{
+			UserID:  &id,
+			Type:    eventType,
+			ActorID: &adminID,
+			Detail:  detail,
+		}, info)
+	})
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.User(id)
+}
+
+func toAdminUserResponse(user *models.User, now time.Time) models.AdminUserResponse {
+	response := models.AdminUserResponse{
+		ID:             user.ID,
+		Email:          user.Email,
+		FullName:       user.FullName,
+		Role:           user.Role,
+		Status:         models.AccountStatusActive,
+		DisabledAt:     user.DisabledAt,
+		DisabledReason: user.DisabledReason,
+		FailedLogins:   user.FailedLogins,
+		LastLoginAt:    user.LastLoginAt,
+		CreatedAt:      user.CreatedAt,
+	}
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		response.Status = models.AccountStatusLocked
+		response.LockedUntil = user.LockedUntil
+	}
+	if user.DisabledAt != nil {
+		response.Status = models.AccountStatusDisabled
+	}
+	return response
+}
@@ -0,0 +1,453 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/media"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"github.com/sugiiianaa/remember-my-story/internal/storage"
+	"github.com/sugiiianaa/remember-my-story/internal/transcription"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+)
+
+const thumbnailSize = 256
+
+const (
+	// transcriptionTimeout bounds a single background transcription call.
+	transcriptionTimeout = 10 * time.Minute
+
+	transcriptionBatchSize = 20
+)
+
+type AttachmentConfig struct {
+	MaxFileSize      int64         // Maximum upload size in bytes
+	MaxAudioFileSize int64         // Maximum upload size in bytes for voice memos
+	URLSecret        string        // Secret used to sign download URLs
+	URLTTL           time.Duration // How long a signed download URL stays valid
+	BaseURL          string        // Optional absolute prefix for download URLs
+}
+
+type AttachmentService struct {
+	attachmentRepo *repositories.AttachmentRepository
+	journalRepo    *repositories.JournalRepository
+	blobStore      storage.BlobStore
+	transcriber    transcription.Provider
+	config         AttachmentConfig
+	now            func() time.Time
+}
+
+func NewAttachmentService(
+	attachmentRepo *repositories.AttachmentRepository,
+	journalRepo *repositories.JournalRepository,
+	blobStore storage.BlobStore,
+	transcriber transcription.Provider,
+	config AttachmentConfig,
+) *AttachmentService {
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		journalRepo:    journalRepo,
+		blobStore:      blobStore,
+		transcriber:    transcriber,
+		config:         config,
+		now:            time.Now,
+	}
+}
+
+// MaxFileSize returns the largest upload accepted for any attachment type.
+func (s *AttachmentService) MaxFileSize() int64 {
+	return max(s.config.MaxFileSize, s.config.MaxAudioFileSize)
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, userID, journalID uint, fileName string, file io.Reader) (*models.Attachment, error) {
+	if _, err := s.findEntry(journalID, userID); err != nil {
+		return nil, err
+	}
+
+	// Read one byte past the limit so oversized files can be detected
+	data, err := io.ReadAll(io.LimitReader(file, s.MaxFileSize()+1))
+	if err != nil {
+		return nil, err
+	}
+
+	contentType := media.DetectContentType(data)
+	if !media.IsAllowedContentType(contentType) {
+		return nil, ErrUnsupportedFileType
+	}
+
+	limit := s.config.MaxFileSize
+	if media.IsAudio(contentType) {
+		limit = s.config.MaxAudioFileSize
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrFileTooLarge
+	}
+	if media.IsImage(contentType) && errors.Is(media.CheckImageSize(data), media.ErrImageTooLarge) {
+		return nil, ErrImageTooLarge
+	}
+
+	checksum := sha256.Sum256(data)
+	baseKey, err := newStorageKey(userID, journalID)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		JournalEntryID: journalID,
+		UserID:         userID,
+		FileName:       sanitizeFileName(fileName),
+		ContentType:    contentType,
+		Size:           int64(len(data)),
+		Checksum:       hex.EncodeToString(checksum[:]),
+		StorageKey:     baseKey,
+	}
+
+	if media.IsAudio(contentType) {
+		audio, err := media.ProbeAudio(data, contentType)
+		if err != nil {
+			return nil, ErrUnsupportedFileType
+		}
+		attachment.DurationMs = audio.DurationMs
+		attachment.Codec = audio.Codec
+		attachment.SampleRate = audio.SampleRate
+		attachment.Channels = audio.Channels
+		// Picked up by TranscribePending
+		requestedAt := s.now()
+		attachment.TranscriptStatus = enums.TranscriptStatus.Pending
+		attachment.TranscriptRequestedAt = &requestedAt
+	}
+
+	if err := s.blobStore.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store attachment: %w", err)
+	}
+
+	if media.IsImage(contentType) {
+		// A broken thumbnail should not fail the whole upload
+		if thumbnail, err := media.GenerateThumbnail(data, thumbnailSize); err == nil {
+			thumbnailKey := baseKey + "_thumb.jpg"
+			err = s.blobStore.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), media.ThumbnailContentType)
+			if err == nil {
+				attachment.ThumbnailKey = thumbnailKey
+			}
+		}
+	}
+
+	if _, err := s.attachmentRepo.Create(attachment); err != nil {
+		s.deleteBlobs(ctx, []models.Attachment{*attachment})
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Transcribe queues a new transcription of a voice memo, replacing any
+// transcript it already has.
+func (s *AttachmentService) Transcribe(userID, journalID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.Get(userID, journalID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !media.IsAudio(attachment.ContentType) {
+		return nil, ErrNotAudioAttachment
+	}
+
+	requestedAt := s.now()
+	if err := s.attachmentRepo.RequestTranscript(attachment.ID, requestedAt); err != nil {
+		return nil, err
+	}
+	attachment.TranscriptStatus = enums.TranscriptStatus.Pending
+	attachment.TranscriptRequestedAt = &requestedAt
+	attachment.Transcript = ""
+
+	return attachment, nil
+}
+
+// UpdateTranscript lets the owner correct or write the transcript by hand.
+func (s *AttachmentService) UpdateTranscript(userID, journalID, attachmentID uint, transcript string) (*models.Attachment, error) {
+	attachment, err := s.Get(userID, journalID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !media.IsAudio(attachment.ContentType) {
+		return nil, ErrNotAudioAttachment
+	}
+
+	if err := s.attachmentRepo.UpdateTranscript(attachment.ID, enums.TranscriptStatus.Completed, transcript); err != nil {
+		return nil, err
+	}
+	attachment.TranscriptStatus = enums.TranscriptStatus.Completed
+	attachment.Transcript = transcript
+
+	return attachment, nil
+}
+
+// TranscribePending transcribes the voice memos waiting for it, one at a
+// time, and returns how many it finished. Memos it does not get to because
+// ctx ends stay pending for the next run. Failed transcriptions are marked
+// as such and reported in the returned error.
+func (s *AttachmentService) TranscribePending(ctx context.Context) (int, error) {
+	var afterID uint
+	var errs []error
+	transcribed := 0
+
+	for {
+		attachments, err := s.attachmentRepo.FindPendingTranscripts(afterID, transcriptionBatchSize)
+		if err != nil {
+			return transcribed, err
+		}
+
+		for _, attachment := range attachments {
+			if err := ctx.Err(); err != nil {
+				return transcribed, err
+			}
+			afterID = attachment.ID
+
+			done, err := s.transcribe(ctx, attachment)
+			if ctx.Err() != nil {
+				return transcribed, ctx.Err()
+			}
+			if done {
+				transcribed++
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("attachment %d: %w", attachment.ID, err))
+			}
+		}
+
+		if len(attachments) < transcriptionBatchSize {
+			return transcribed, errors.Join(errs...)
+		}
+	}
+}
+
+// transcribe runs one transcription and stores its outcome, unless the
+// memo was queued again or its transcript written by hand meanwhile.
+func (s *AttachmentService) transcribe(ctx context.Context, attachment models.Attachment) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, transcriptionTimeout)
+	defer cancel()
+
+	status := enums.TranscriptStatus.Completed
+	transcript, err := s.transcribeBlob(ctx, attachment)
+	switch {
+	case errors.Is(err, transcription.ErrUnavailable):
+		status = enums.TranscriptStatus.Unavailable
+		err = nil
+	case err != nil:
+		status = enums.TranscriptStatus.Failed
+	}
+
+	done, updateErr := s.attachmentRepo.CompleteTranscript(attachment.ID, attachment.TranscriptRequestedAt, status, transcript)
+	if updateErr != nil {
+		return false, updateErr
+	}
+	return done && err == nil, err
+}
+
+func (s *AttachmentService) transcribeBlob(ctx context.Context, attachment models.Attachment) (string, error) {
+	body, err := s.blobStore.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	return s.transcriber.Transcribe(ctx, body, attachment.ContentType)
+}
+
+func (s *AttachmentService) ListForEntry(userID, journalID uint) ([]models.Attachment, error) {
+	if _, err := s.findEntry(journalID, userID); err != nil {
+		return nil, err
+	}
+	return s.attachmentRepo.FindByJournalEntryID(journalID)
+}
+
+func (s *AttachmentService) Get(userID, journalID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if attachment.UserID != userID || attachment.JournalEntryID != journalID {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, nil
+}
+
+func (s *AttachmentService) Delete(ctx context.Context, userID, journalID, attachmentID uint) error {
+	attachment, err := s.Get(userID, journalID, attachmentID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.deleteBlobs(ctx, []models.Attachment{*attachment}); err != nil {
+		return err
+	}
+
+	return s.attachmentRepo.Delete(attachment)
+}
+
+// DeleteBlobs removes the stored files (and thumbnails) of the given
+// attachments. It is used when the owning journal entry goes away.
+func (s *AttachmentService) DeleteBlobs(ctx context.Context, attachments []models.Attachment) error {
+	return s.deleteBlobs(ctx, attachments)
+}
+
+// OpenContent returns the stored file of an attachment after validating a
+// signed download URL. The returned reader is seekable so callers can
+// serve HTTP range requests, which audio players rely on for seeking.
+func (s *AttachmentService) OpenContent(ctx context.Context, attachmentID uint, path, expires, signature string) (*models.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := s.findSigned(attachmentID, path, expires, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, storage.NewBlobReader(ctx, s.blobStore, attachment.StorageKey, attachment.Size), nil
+}
+
+// OpenThumbnail returns the thumbnail of an image attachment after
+// validating a signed download URL.
+func (s *AttachmentService) OpenThumbnail(ctx context.Context, attachmentID uint, path, expires, signature string) (*models.Attachment, io.ReadCloser, error) {
+	attachment, err := s.findSigned(attachmentID, path, expires, signature)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attachment.ThumbnailKey == "" {
+		return nil, nil, ErrAttachmentNotFound
+	}
+
+	body, err := s.blobStore.Get(ctx, attachment.ThumbnailKey)
+	if errors.Is(err, storage.ErrBlobNotFound) {
+		return nil, nil, ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, body, nil
+}
+
+func (s *AttachmentService) findSigned(attachmentID uint, path, expires, signature string) (*models.Attachment, error) {
+	if err := helpers.VerifySignedURL(s.config.URLSecret, path, expires, signature); err != nil {
+		return nil, err
+	}
+
+	attachment, err := s.attachmentRepo.FindByID(attachmentID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+
+	return attachment, err
+}
+
+// ToResponse converts an attachment into its API representation with
+// freshly signed download URLs.
+func (s *AttachmentService) ToResponse(attachment *models.Attachment) models.AttachmentResponse {
+	expiresAt := time.Now().Add(s.config.URLTTL)
+
+	response := models.AttachmentResponse{
+		ID:               attachment.ID,
+		JournalEntryID:   attachment.JournalEntryID,
+		Kind:             attachmentKind(attachment.ContentType),
+		FileName:         attachment.FileName,
+		ContentType:      attachment.ContentType,
+		Size:             attachment.Size,
+		Checksum:         attachment.Checksum,
+		DurationMs:       attachment.DurationMs,
+		Codec:            attachment.Codec,
+		SampleRate:       attachment.SampleRate,
+		Channels:         attachment.Channels,
+		Transcript:       attachment.Transcript,
+		TranscriptStatus: attachment.TranscriptStatus,
+		DownloadURL:      s.signedURL(AttachmentContentPath(attachment.ID), expiresAt),
+		URLExpiresAt:     expiresAt,
+		CreatedAt:        attachment.CreatedAt,
+	}
+
+	if attachment.ThumbnailKey != "" {
+		response.ThumbnailURL = s.signedURL(AttachmentThumbnailPath(attachment.ID), expiresAt)
+	}
+
+	return response
+}
+
+func AttachmentContentPath(attachmentID uint) string {
+	return fmt.Sprintf("/api/v1/attachments/%d/content", attachmentID)
+}
+
+func AttachmentThumbnailPath(attachmentID uint) string {
+	return fmt.Sprintf("/api/v1/attachments/%d/thumbnail", attachmentID)
+}
+
+func (s *AttachmentService) signedURL(path string, expiresAt time.Time) string {
+	return strings.TrimRight(s.config.BaseURL, "/") + helpers.SignURL(s.config.URLSecret, path, expiresAt)
+}
+
+func (s *AttachmentService) findEntry(journalID, userID uint) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindByIDAndUserID(journalID, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	return entry, err
+}
+
+func (s *AttachmentService) deleteBlobs(ctx context.Context, attachments []models.Attachment) error {
+	var errs []error
+	for _, attachment := range attachments {
+		if err := s.blobStore.Delete(ctx, attachment.StorageKey); err != nil {
+			errs = append(errs, err)
+		}
+		if attachment.ThumbnailKey != "" {
+			if err := s.blobStore.Delete(ctx, attachment.ThumbnailKey); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func attachmentKind(contentType string) string {
+	switch {
+	case media.IsImage(contentType):
+		return "image"
+	case media.IsAudio(contentType):
+		return "audio"
+	default:
+		return "file"
+	}
+}
+
+func newStorageKey(userID, journalID uint) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("users/%d/journals/%d/%s", userID, journalID, hex.EncodeToString(random)), nil
+}
+
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
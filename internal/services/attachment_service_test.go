@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/storage"
+	"github.com/sugiiianaa/remember-my-story/internal/transcription"
+)
+
+// stubTranscriber returns a fixed transcript, or err when set, and keeps
+// the audio it was given.
+type stubTranscriber struct {
+	transcript  string
+	err         error
+	audio       string
+	contentType string
+}
+
+func (p *stubTranscriber) Transcribe(ctx context.Context, audio io.Reader, contentType string) (string, error) {
+	data, err := io.ReadAll(audio)
+	if err != nil {
+		return "", err
+	}
+	p.audio, p.contentType = string(data), contentType
+
+	if p.err != nil {
+		return "", p.err
+	}
+	return p.transcript, nil
+}
+
+func TestAttachmentServiceTranscribeBlob(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocalBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalBlobStore() error = %v", err)
+	}
+	if err := store.Put(ctx, "users/1/memo", strings.NewReader("memo audio"), 10, "audio/webm"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		storageKey  string
+		transcriber *stubTranscriber
+		want        string
+		wantErr     error
+		wantAudio   string
+	}{
+		{
+			name:        "transcript",
+			storageKey:  "users/1/memo",
+			transcriber: &stubTranscriber{transcript: "dear diary"},
+			want:        "dear diary",
+			wantAudio:   "memo audio",
+		},
+		{
+			name:        "no transcription configured",
+			storageKey:  "users/1/memo",
+			transcriber: &stubTranscriber{err: transcription.ErrUnavailable},
+			wantErr:     transcription.ErrUnavailable,
+			wantAudio:   "memo audio",
+		},
+		{
+			name:        "missing blob",
+			storageKey:  "users/1/gone",
+			transcriber: &stubTranscriber{transcript: "dear diary"},
+			wantErr:     storage.ErrBlobNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &AttachmentService{blobStore: store, transcriber: tt.transcriber}
+			attachment := models.Attachment{StorageKey: tt.storageKey, ContentType: "audio/webm"}
+
+			got, err := service.transcribeBlob(ctx, attachment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("transcribeBlob() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("transcribeBlob() = %q, want %q", got, tt.want)
+			}
+			if tt.transcriber.audio != tt.wantAudio {
+				t.Errorf("transcriber received %q, want %q", tt.transcriber.audio, tt.wantAudio)
+			}
+			if tt.wantAudio != "" && tt.transcriber.contentType != "audio/webm" {
+				t.Errorf("transcriber content type = %q, want %q", tt.transcriber.contentType, "audio/webm")
+			}
+		})
+	}
+}
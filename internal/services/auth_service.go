@@ -9,21 +9,42 @@ import (
 	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
 	"github.com/sugiiianaa/remember-my-story/internal/models"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+// LoginConfig controls the lockout after repeated wrong passwords.
+type LoginConfig struct {
+	MaxFailedAttempts int           // Zero turns the lockout off
+	LockoutDuration   time.Duration // How long the account stays locked
+}
+
 type AuthService struct {
-	userRepo  repositories.UserRepository
-	jwtSecret string
+	userRepo     repositories.UserRepository
+	notebookRepo *repositories.NotebookRepository
+	events       *SecurityEventService
+	jwtSecret    string
+	config       LoginConfig
+	now          func() time.Time
 }
 
-func NewAuthService(userRepo repositories.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(
+	userRepo repositories.UserRepository,
+	notebookRepo *repositories.NotebookRepository,
+	events *SecurityEventService,
+	jwtSecret string,
+	config LoginConfig,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:     userRepo,
+		notebookRepo: notebookRepo,
+		events:       events,
+		jwtSecret:    jwtSecret,
+		config:       config,
+		now:          time.Now,
 	}
 }
 
-func (s *AuthService) Register(email, fullName, password string) (uint, error) {
+func (s *AuthService) Register(email, fullName, password string, info models.RequestInfo) (uint, error) {
 	_, err := s.userRepo.FindByEmail(email)
 	if err != nil {
 		return 0, errors.New("user already exists")
@@ -40,27 +61,125 @@ func (s *AuthService) Register(email, fullName, password string) (uint, error) {
 		Password: string(hashedPassword),
 	}
 
-	return s.userRepo.Create(user)
+	// The user starts with a default notebook to write into, and the
+	// registration is in the audit log as soon as the account exists
+	var userID uint
+	err = s.notebookRepo.Transaction(func(tx *gorm.DB) error {
+		id, err := s.userRepo.WithTx(tx).Create(user)
+		if err != nil {
+			return err
+		}
+		if _, err := s.notebookRepo.WithTx(tx).EnsureDefault(id); err != nil {
+			return err
+		}
+		userID = id
+		return s.events.withTx(tx).Record(models.SecurityEvent{
+			UserID: &userID,
+			Type:   models.SecurityEventRegistered,
+			Email:  email,
+		}, info)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return userID, nil
 }
 
-func (s *AuthService) Login(email, password string) (string, error) {
+// Login checks the credentials and issues a token. After
+// MaxFailedAttempts wrong passwords the account is locked for
+// LockoutDuration; the count only resets on a successful login, so once a
+// lockout ends every further wrong password locks it again. Disabled
+// accounts are only reported as such to someone who knows the password.
+// Every attempt is recorded in the security audit log.
+func (s *AuthService) Login(email, password string, info models.RequestInfo) (string, error) {
 	user, err := s.userRepo.FindByEmail(email)
-	if err != nil || user == nil {
-		return "", errors.New("invalid credentials")
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", s.loginFailed(nil, email, models.LoginFailureUnknownEmail, ErrInvalidCredentials, info)
+	}
+
+	now := s.now()
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return "", s.loginFailed(user, email, models.LoginFailureLocked, ErrAccountLocked, info)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		if s.config.MaxFailedAttempts > 0 {
+			lockUntil := now.Add(s.config.LockoutDuration)
+			if err := s.userRepo.RecordFailedLogin(user.ID, s.config.MaxFailedAttempts, lockUntil); err != nil {
+				return "", err
+			}
+		}
+		return "", s.loginFailed(user, email, models.LoginFailureWrongPassword, ErrInvalidCredentials, info)
+	}
+
+	if user.DisabledAt != nil {
+		return "", s.loginFailed(user, email, models.LoginFailureDisabled, ErrAccountDisabled, info)
+	}
+	if err := s.userRepo.RecordLogin(user.ID, now); err != nil {
+		return "", err
+	}
+
+	err = s.events.Record(models.SecurityEvent{
+		UserID: &user.ID,
+		Type:   models.SecurityEventLoginSucceeded,
+		Email:  email,
+	}, info)
+	if err != nil {
+		return "", err
 	}
 
 	return s.generateJWTToken(user)
 }
 
+// loginFailed records a failed login and returns the error to report, or
+// the error recording it. user is nil when no account has the email.
+func (s *AuthService) loginFailed(user *models.User, email, reason string, loginErr error, info models.RequestInfo) error {
+	event := models.SecurityEvent{
+		Type:   models.SecurityEventLoginFailed,
+		Email:  email,
+		Detail: reason,
+	}
+	if user != nil {
+		event.UserID = &user.ID
+	}
+
+	if err := s.events.Record(event, info); err != nil {
+		return err
+	}
+	return loginErr
+}
+
+// ValidateSession checks that a token issued to the user is still good:
+// the account exists and is enabled, and no forced logout or role change
+// happened since the token was issued.
+func (s *AuthService) ValidateSession(userID uint, tokenVersion int) error {
+	user, err := s.userRepo.FindSession(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.DisabledAt != nil {
+		return ErrAccountDisabled
+	}
+	if user.TokenVersion != tokenVersion {
+		return ErrSessionRevoked
+	}
+	return nil
+}
+
 func (s *AuthService) generateJWTToken(user *models.User) (string, error) {
 	claims := jwt.MapClaims{
 		"sub":   fmt.Sprintf("%d", user.ID),
 		"email": user.Email,
+		"role":  user.Role,
+		"tv":    user.TokenVersion,
 		"exp":   time.Now().Add(24 * time.Hour).Unix(),
 	}
 
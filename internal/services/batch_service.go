@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+const (
+	BatchStatusOK         = "ok"
+	BatchStatusError      = "error"
+	BatchStatusRolledBack = "rolled_back"
+	BatchStatusSkipped    = "skipped"
+)
+
+var ErrInvalidBatchOperation = errors.New("invalid batch operation")
+
+// errBatchAborted rolls back an atomic batch after one of its operations
+// failed; the failure itself is reported in that operation's outcome.
+var errBatchAborted = errors.New("batch aborted")
+
+// BatchOperation is a decoded and validated batch item. Exactly one of the
+// payload fields is set, depending on Action and Entity.
+type BatchOperation struct {
+	Action          string
+	Entity          string
+	ID              uint
+	JournalEntryID  uint
+	ExpectedVersion int
+	Entry           *models.CreateJournalRequest
+	EntryUpdate     *models.UpdateJournalRequest
+	Task            *models.DailyTaskRequest
+	TaskPatch       *models.PatchDailyTaskRequest
+	Invalid         error // Set when the operation could not be decoded
+}
+
+type BatchOutcome struct {
+	Status  string
+	ID      uint
+	Version int
+	Err     error
+}
+
+// BatchService runs batches of entry and task writes. Operations on
+// existing entries and tasks go through the AccessPolicy, so readers with
+// the editor role can batch changes to the entries shared with them.
+type BatchService struct {
+	journalService *JournalService
+	policy         *AccessPolicy
+}
+
+func NewBatchService(journalService *JournalService, policy *AccessPolicy) *BatchService {
+	return &BatchService{journalService: journalService, policy: policy}
+}
+
+// Execute runs the operations in order. In atomic mode they share one
+// transaction and the first failure rolls back the whole batch; in
+// best-effort mode each operation commits on its own. It reports whether
+// anything was committed.
+func (s *BatchService) Execute(ctx context.Context, userID uint, mode string, ops []BatchOperation) ([]BatchOutcome, bool) {
+	outcomes := make([]BatchOutcome, len(ops))
+
+	if mode == models.BatchModeBestEffort {
+		committed := false
+		for i, op := range ops {
+			err := s.journalService.Transaction(func(service *JournalService) error {
+				var err error
+				outcomes[i], err = s.run(ctx, service, userID, op)
+				return err
+			})
+			if err != nil {
+				outcomes[i] = BatchOutcome{Status: BatchStatusError, Err: err}
+				continue
+			}
+			committed = true
+		}
+		return outcomes, committed
+	}
+
+	failed := -1
+	err := s.journalService.Transaction(func(service *JournalService) error {
+		for i, op := range ops {
+			outcome, err := s.run(ctx, service, userID, op)
+			if err != nil {
+				failed = i
+				outcomes[i] = BatchOutcome{Status: BatchStatusError, Err: err}
+				return errBatchAborted
+			}
+			outcomes[i] = outcome
+		}
+		return nil
+	})
+	if err == nil {
+		return outcomes, true
+	}
+
+	// The commit itself failed, blame the last operation
+	if failed < 0 {
+		failed = len(ops) - 1
+		outcomes[failed] = BatchOutcome{Status: BatchStatusError, Err: err}
+	}
+	for i := range outcomes {
+		switch {
+		case i < failed:
+			outcomes[i] = BatchOutcome{Status: BatchStatusRolledBack}
+		case i > failed:
+			outcomes[i] = BatchOutcome{Status: BatchStatusSkipped}
+		}
+	}
+	return outcomes, false
+}
+
+func (s *BatchService) run(ctx context.Context, service *JournalService, userID uint, op BatchOperation) (BatchOutcome, error) {
+	if op.Invalid != nil {
+		return BatchOutcome{}, op.Invalid
+	}
+
+	policy := s.policy.within(service.journalRepo)
+
+	switch {
+	case op.Entity == models.BatchEntityJournalEntry && op.Action == models.BatchActionCreate && op.Entry != nil:
+		entry := op.Entry.ToJournalEntry(userID)
+		id, err := service.CreateEntry(&entry)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: id, Version: entry.Version}, nil
+
+	case op.Entity == models.BatchEntityJournalEntry && op.Action == models.BatchActionUpdate && op.EntryUpdate != nil:
+		ownerID, err := policy.AuthorizeEntry(userID, op.ID, AccessEdit)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		entry, err := service.UpdateEntry(ctx, ownerID, op.ID, op.ExpectedVersion, *op.EntryUpdate)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: entry.ID, Version: entry.Version}, nil
+
+	case op.Entity == models.BatchEntityJournalEntry && op.Action == models.BatchActionDelete:
+		ownerID, err := policy.AuthorizeEntry(userID, op.ID, AccessManage)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		if op.ExpectedVersion > 0 {
+			entry, err := service.GetEntry(ctx, ownerID, op.ID)
+			if err != nil {
+				return BatchOutcome{}, err
+			}
+			if entry.Version != op.ExpectedVersion {
+				return BatchOutcome{}, ErrVersionMismatch
+			}
+		}
+		if err := service.DeleteEntry(ctx, ownerID, op.ID); err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: op.ID}, nil
+
+	case op.Entity == models.BatchEntityDailyTask && op.Action == models.BatchActionCreate && op.Task != nil:
+		ownerID, err := policy.AuthorizeEntry(userID, op.JournalEntryID, AccessEdit)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		task, entry, err := service.AddTask(ctx, ownerID, op.JournalEntryID, op.ExpectedVersion, *op.Task)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: task.ID, Version: entry.Version}, nil
+
+	case op.Entity == models.BatchEntityDailyTask && op.Action == models.BatchActionUpdate && op.TaskPatch != nil:
+		ownerID, err := policy.AuthorizeTask(userID, op.ID, AccessEdit)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		task, entry, err := service.UpdateTask(ctx, ownerID, op.ID, op.ExpectedVersion, *op.TaskPatch)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: task.ID, Version: entry.Version}, nil
+
+	case op.Entity == models.BatchEntityDailyTask && op.Action == models.BatchActionDelete:
+		ownerID, err := policy.AuthorizeTask(userID, op.ID, AccessEdit)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		entry, err := service.DeleteTask(ctx, ownerID, op.ID, op.ExpectedVersion)
+		if err != nil {
+			return BatchOutcome{}, err
+		}
+		return BatchOutcome{Status: BatchStatusOK, ID: op.ID, Version: entry.Version}, nil
+	}
+
+	return BatchOutcome{}, ErrInvalidBatchOperation
+}
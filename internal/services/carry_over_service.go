@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+// carryOverBatchSize limits how many users one automatic run loads at once.
+const carryOverBatchSize = 100
+
+// CarryOverService copies unfinished tasks into a later day's entry. Each
+// copy links back to its original through CarriedOverFromID, and a task is
+// only ever carried over once.
+type CarryOverService struct {
+	journalRepo    *repositories.JournalRepository
+	userRepo       *repositories.UserRepository
+	journalService *JournalService
+	now            func() time.Time
+}
+
+func NewCarryOverService(
+	journalRepo *repositories.JournalRepository,
+	userRepo *repositories.UserRepository,
+	journalService *JournalService,
+) *CarryOverService {
+	return &CarryOverService{
+		journalRepo:    journalRepo,
+		userRepo:       userRepo,
+		journalService: journalService,
+		now:            time.Now,
+	}
+}
+
+// CarryOver copies the unfinished tasks of an entry into the entry for
+// toDate (the next day when zero), creating that entry as a draft when
+// the user has none yet.
+func (s *CarryOverService) CarryOver(ctx context.Context, userID, entryID uint, toDate time.Time, taskIDs []uint) (*models.CarryOverResponse, error) {
+	source, err := s.journalService.GetEntry(ctx, userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	location := userLocation(user.TimeZone)
+
+	sourceDay := dayStart(source.Date.In(location), location)
+	targetDay := sourceDay.AddDate(0, 0, 1)
+	if !toDate.IsZero() {
+		targetDay = dayStart(toDate, location)
+	}
+	if !targetDay.After(sourceDay) {
+		return nil, ErrInvalidDateRange
+	}
+
+	return s.carry(ctx, userID, source, targetDay, taskIDs)
+}
+
+// CarryOverDue carries yesterday's unfinished tasks into today for every
+// user who enabled automatic carry-over, once their local day has rolled
+// over. It returns how many entries received tasks.
+func (s *CarryOverService) CarryOverDue(ctx context.Context) (int, error) {
+	var afterID uint
+	carried := 0
+
+	for {
+		users, err := s.userRepo.FindWithAutoCarryOver(afterID, carryOverBatchSize)
+		if err != nil {
+			return carried, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return carried, err
+			}
+			afterID = user.ID
+
+			location := userLocation(user.TimeZone)
+			today := dayStart(s.now().In(location), location)
+			yesterday := today.AddDate(0, 0, -1)
+
+			source, err := s.journalRepo.FindByUserIDAndDate(user.ID, yesterday, today)
+			if errors.Is(err, repositories.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return carried, err
+			}
+
+			_, err = s.carry(ctx, user.ID, source, today, nil)
+			if errors.Is(err, ErrNothingToCarryOver) {
+				continue
+			}
+			if err != nil {
+				return carried, err
+			}
+			carried++
+		}
+
+		if len(users) < carryOverBatchSize {
+			return carried, nil
+		}
+	}
+}
+
+func (s *CarryOverService) carry(ctx context.Context, userID uint, source *models.JournalEntry, targetDay time.Time, taskIDs []uint) (*models.CarryOverResponse, error) {
+	tasks, err := s.unfinishedTasks(source, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, ErrNothingToCarryOver
+	}
+
+	target, err := s.journalRepo.FindByUserIDAndDate(userID, targetDay, targetDay.AddDate(0, 0, 1))
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		entry := &models.JournalEntry{
+			UserID: userID,
+			Date:   targetDay,
+			Status: enums.EntryStatus.Draft,
+		}
+		if _, err := s.journalService.CreateEntry(entry); err != nil {
+			return nil, err
+		}
+		target, err = s.journalService.GetEntry(ctx, userID, entry.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range tasks {
+		tasks[i].Position = len(target.DailyTasks) + i
+	}
+
+	added, err := s.journalRepo.AddTasks(target, tasks)
+	if err != nil {
+		return nil, err
+	}
+	if added > 0 {
+		if err := s.journalRepo.AppendRevision(target.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	target, err = s.journalService.GetEntry(ctx, userID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CarryOverResponse{
+		JournalID: target.ID,
+		Date:      target.Date,
+		Carried:   added,
+		Version:   target.Version,
+	}, nil
+}
+
+// unfinishedTasks copies the open tasks of an entry, with their open
+// subtasks, leaving out tasks that were carried over before.
+func (s *CarryOverService) unfinishedTasks(source *models.JournalEntry, taskIDs []uint) ([]models.DailyTask, error) {
+	wanted := make(map[uint]bool, len(taskIDs))
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+
+	var candidates []models.DailyTask
+	var candidateIDs []uint
+	for _, task := range source.DailyTasks {
+		if task.Status || (len(wanted) > 0 && !wanted[task.ID]) {
+			continue
+		}
+		candidates = append(candidates, task)
+		candidateIDs = append(candidateIDs, task.ID)
+	}
+
+	carriedIDs, err := s.journalRepo.FindCarriedOverSourceIDs(candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	carried := make(map[uint]bool, len(carriedIDs))
+	for _, id := range carriedIDs {
+		carried[id] = true
+	}
+
+	tasks := make([]models.DailyTask, 0, len(candidates))
+	for _, original := range candidates {
+		if carried[original.ID] {
+			continue
+		}
+
+		originalID := original.ID
+		task := models.DailyTask{
+			CarriedOverFromID: &originalID,
+			Task:              original.Task,
+			Priority:          original.Priority,
+			DueTime:           original.DueTime,
+			Notes:             original.Notes,
+		}
+		for _, subTask := range original.SubTasks {
+			if !subTask.Status {
+				task.SubTasks = append(task.SubTasks, models.DailySubTask{SubTask: subTask.SubTask})
+			}
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+func dayStart(t time.Time, location *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
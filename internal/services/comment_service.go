@@ -0,0 +1,386 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	NotificationEntryComment  = "entry_comment"
+	NotificationEntryReaction = "entry_reaction"
+
+	// commentPreviewSize is how much of a comment the owner's notification
+	// quotes.
+	commentPreviewSize = 200
+)
+
+// CommentService manages comments and reactions on entries. Unlike the
+// owner-scoped services it is called with the acting user, who may be the
+// owner or a reader, and checks the AccessPolicy itself: reading needs the
+// viewer role, commenting and reacting the commenter role. Authors edit
+// their own comments; authors and the entry owner may delete them.
+type CommentService struct {
+	commentRepo    *repositories.CommentRepository
+	journalRepo    *repositories.JournalRepository
+	userRepo       *repositories.UserRepository
+	policy         *AccessPolicy
+	webhookService *WebhookService
+	notifications  *NotificationService
+	now            func() time.Time
+}
+
+func NewCommentService(
+	commentRepo *repositories.CommentRepository,
+	journalRepo *repositories.JournalRepository,
+	userRepo *repositories.UserRepository,
+	policy *AccessPolicy,
+	webhookService *WebhookService,
+	notifications *NotificationService,
+) *CommentService {
+	return &CommentService{
+		commentRepo:    commentRepo,
+		journalRepo:    journalRepo,
+		userRepo:       userRepo,
+		policy:         policy,
+		webhookService: webhookService,
+		notifications:  notifications,
+		now:            time.Now,
+	}
+}
+
+// List returns the entry's comment threads, oldest first.
+func (s *CommentService) List(actorID, entryID uint) (*models.CommentsResponse, error) {
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessRead)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.findEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.commentRepo.FindByEntryID(entryID)
+	if err != nil {
+		return nil, err
+	}
+	authors, err := s.authors(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.CommentsResponse{
+		CommentsDisabled: entry.CommentsDisabled,
+		Comments:         []models.CommentResponse{},
+	}
+	threads := make(map[uint]int)
+	for i := range comments {
+		comment := toCommentResponse(&comments[i], authors, actorID, ownerID)
+		if comments[i].ParentID == nil {
+			threads[comments[i].ID] = len(response.Comments)
+			response.Comments = append(response.Comments, comment)
+			continue
+		}
+		if index, ok := threads[*comments[i].ParentID]; ok {
+			response.Comments[index].Replies = append(response.Comments[index].Replies, comment)
+		}
+	}
+	return response, nil
+}
+
+// Create comments on an entry or replies to one of its comments. Replies
+// to replies join the thread of the top-level comment. The owner is
+// notified of comments left by others.
+func (s *CommentService) Create(actorID, entryID uint, req models.CommentRequest) (*models.CommentResponse, error) {
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessComment)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.findEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	comment := &models.Comment{
+		JournalEntryID: entryID,
+		AuthorID:       actorID,
+		Body:           body,
+	}
+	if req.ParentID != nil {
+		parent, err := s.find(entryID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	authors, err := s.authors([]models.Comment{*comment})
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.CommentResponse
+	err = s.commentRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.commentRepo.WithTx(tx).Create(comment); err != nil {
+			return err
+		}
+		response = toCommentResponse(comment, authors, actorID, ownerID)
+		if actorID == ownerID {
+			return nil
+		}
+		if err := s.webhookService.withTx(tx).Publish(ownerID, models.WebhookEventCommentCreated, response); err != nil {
+			return err
+		}
+		return s.notifications.withTx(tx).Queue(ownerID, NotificationEntryComment,
+			fmt.Sprintf("%s commented on your entry", displayName(authors[actorID])),
+			excerpt(body, commentPreviewSize))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Update edits the text of one of the actor's own comments.
+func (s *CommentService) Update(actorID, entryID, id uint, body string) (*models.CommentResponse, error) {
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessComment)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := s.findEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.CommentsDisabled {
+		return nil, ErrCommentsDisabled
+	}
+
+	comment, err := s.find(entryID, id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != actorID {
+		return nil, ErrForbidden
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	now := s.now()
+	comment.Body = body
+	comment.EditedAt = &now
+	if err := s.commentRepo.Save(comment); err != nil {
+		return nil, err
+	}
+
+	authors, err := s.authors([]models.Comment{*comment})
+	if err != nil {
+		return nil, err
+	}
+	response := toCommentResponse(comment, authors, actorID, ownerID)
+	return &response, nil
+}
+
+// Delete removes a comment and its replies. Authors delete their own
+// comments and the owner any comment on their entry.
+func (s *CommentService) Delete(actorID, entryID, id uint) error {
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessRead)
+	if err != nil {
+		return err
+	}
+
+	comment, err := s.find(entryID, id)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != actorID && ownerID != actorID {
+		return ErrForbidden
+	}
+	return s.commentRepo.Delete(comment)
+}
+
+// SetCommentsDisabled lets the owner close an entry to new comments, or
+// reopen it. Existing comments stay visible.
+func (s *CommentService) SetCommentsDisabled(actorID, entryID uint, disabled bool) error {
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.journalRepo.SetCommentsDisabled(entryID, ownerID, disabled)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrJournalNotFound
+	}
+	return err
+}
+
+// Reactions counts the entry's reactions by kind.
+func (s *CommentService) Reactions(actorID, entryID uint) (*models.ReactionsResponse, error) {
+	if _, err := s.policy.AuthorizeEntry(actorID, entryID, AccessRead); err != nil {
+		return nil, err
+	}
+	return s.reactions(actorID, entryID)
+}
+
+// React adds the actor's reaction of a kind to the entry. The owner is
+// notified the first time someone else reacts with it.
+func (s *CommentService) React(actorID, entryID uint, kind string) (*models.ReactionsResponse, error) {
+	if !validReaction(kind) {
+		return nil, ErrInvalidReaction
+	}
+	ownerID, err := s.policy.AuthorizeEntry(actorID, entryID, AccessComment)
+	if err != nil {
+		return nil, err
+	}
+
+	reactor, err := s.userRepo.FindByID(actorID)
+	if err != nil && !errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	err = s.commentRepo.Transaction(func(tx *gorm.DB) error {
+		added, err := s.commentRepo.WithTx(tx).AddReaction(&models.Reaction{
+			JournalEntryID: entryID,
+			UserID:         actorID,
+			Kind:           kind,
+		})
+		if err != nil || !added || actorID == ownerID {
+			return err
+		}
+
+		err = s.webhookService.withTx(tx).Publish(ownerID, models.WebhookEventReactionAdded, map[string]interface{}{
+			"entry_id": entryID,
+			"user_id":  actorID,
+			"kind":     kind,
+		})
+		if err != nil {
+			return err
+		}
+		return s.notifications.withTx(tx).Queue(ownerID, NotificationEntryReaction,
+			fmt.Sprintf("%s reacted to your entry", displayName(reactor)),
+			fmt.Sprintf("%s left a %s reaction.", displayName(reactor), kind))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.reactions(actorID, entryID)
+}
+
+// Unreact removes the actor's reaction of a kind.
+func (s *CommentService) Unreact(actorID, entryID uint, kind string) (*models.ReactionsResponse, error) {
+	if !validReaction(kind) {
+		return nil, ErrInvalidReaction
+	}
+	if _, err := s.policy.AuthorizeEntry(actorID, entryID, AccessRead); err != nil {
+		return nil, err
+	}
+
+	if err := s.commentRepo.RemoveReaction(entryID, actorID, kind); err != nil {
+		return nil, err
+	}
+	return s.reactions(actorID, entryID)
+}
+
+func (s *CommentService) reactions(actorID, entryID uint) (*models.ReactionsResponse, error) {
+	counts, err := s.commentRepo.CountReactions(entryID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	byKind := make(map[string]models.ReactionCount, len(counts))
+	for _, count := range counts {
+		byKind[count.Kind] = count
+	}
+	response := &models.ReactionsResponse{Reactions: []models.ReactionCount{}}
+	for _, kind := range models.ReactionKinds {
+		if count, ok := byKind[kind]; ok {
+			response.Reactions = append(response.Reactions, count)
+		}
+	}
+	return response, nil
+}
+
+func (s *CommentService) findEntry(id uint) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindHeader(id)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	return entry, err
+}
+
+func (s *CommentService) find(entryID, id uint) (*models.Comment, error) {
+	comment, err := s.commentRepo.FindByIDAndEntryID(id, entryID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// authors loads the authors of comments by ID.
+func (s *CommentService) authors(comments []models.Comment) (map[uint]*models.User, error) {
+	ids := make([]uint, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.AuthorID)
+	}
+
+	users, err := s.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	authors := make(map[uint]*models.User, len(users))
+	for i := range users {
+		authors[users[i].ID] = &users[i]
+	}
+	return authors, nil
+}
+
+func toCommentResponse(comment *models.Comment, authors map[uint]*models.User, actorID, ownerID uint) models.CommentResponse {
+	response := models.CommentResponse{
+		ID:        comment.ID,
+		EntryID:   comment.JournalEntryID,
+		ParentID:  comment.ParentID,
+		Body:      comment.Body,
+		CreatedAt: comment.CreatedAt,
+		EditedAt:  comment.EditedAt,
+		CanEdit:   comment.AuthorID == actorID,
+		CanDelete: comment.AuthorID == actorID || ownerID == actorID,
+	}
+	if author := authors[comment.AuthorID]; author != nil {
+		response.Author = &models.ReaderUser{ID: author.ID, Email: author.Email, FullName: author.FullName}
+	}
+	return response
+}
+
+func validReaction(kind string) bool {
+	for _, known := range models.ReactionKinds {
+		if kind == known {
+			return true
+		}
+	}
+	return false
+}
+
+func displayName(user *models.User) string {
+	if user == nil {
+		return "Someone"
+	}
+	return user.FullName
+}
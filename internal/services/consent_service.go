@@ -0,0 +1,468 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+)
+
+const (
+	NotificationConsentRequest = "consent_request"
+
+	dashboardDefaultWeeks = 8
+	dashboardMaxWeeks     = 52
+
+	clientEntriesPageSize    = 20
+	clientEntriesMaxPageSize = 100
+)
+
+// ConsentService lets practitioners ask clients for access to their
+// journals and follow the clients who consented. Clients choose which
+// notebooks they share and may revoke their consent at any time; the
+// practitioner queries check the consent in the same SQL statement, so a
+// revocation applies to the very next request.
+type ConsentService struct {
+	consentRepo  *repositories.ConsentRepository
+	orgRepo      *repositories.OrganizationRepository
+	userRepo     *repositories.UserRepository
+	notebookRepo *repositories.NotebookRepository
+	dispatcher   *notify.Dispatcher
+	now          func() time.Time
+}
+
+func NewConsentService(
+	consentRepo *repositories.ConsentRepository,
+	orgRepo *repositories.OrganizationRepository,
+	userRepo *repositories.UserRepository,
+	notebookRepo *repositories.NotebookRepository,
+	dispatcher *notify.Dispatcher,
+) *ConsentService {
+	return &ConsentService{
+		consentRepo:  consentRepo,
+		orgRepo:      orgRepo,
+		userRepo:     userRepo,
+		notebookRepo: notebookRepo,
+		dispatcher:   dispatcher,
+		now:          time.Now,
+	}
+}
+
+// Request asks a client, by email, to share their journal with the
+// practitioner. Clients with an account are told by email.
+func (s *ConsentService) Request(ctx context.Context, practitionerID, orgID uint, req models.ConsentRequest) (*models.ConsentResponse, error) {
+	membership, err := findMembership(s.orgRepo, practitionerID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	practitioner, err := s.findUser(practitionerID)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if strings.EqualFold(email, practitioner.Email) {
+		return nil, fmt.Errorf("%w: you cannot be your own client", ErrInvalidConsent)
+	}
+
+	exists, err := s.consentRepo.ExistsOpen(orgID, practitionerID, email)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrConsentExists
+	}
+
+	consent := &models.ClientConsent{
+		OrganizationID: orgID,
+		PractitionerID: practitionerID,
+		InviteEmail:    email,
+		Message:        strings.TrimSpace(req.Message),
+		Status:         models.ConsentStatusPending,
+	}
+	if err := s.consentRepo.Create(consent); err != nil {
+		return nil, err
+	}
+
+	client, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if client != nil {
+		// The request stands even when the email cannot be sent; it is
+		// listed under the client's consents either way.
+		_ = s.dispatcher.Send(ctx, []string{notify.ChannelEmail}, notify.Notification{
+			Recipient: notify.Recipient{
+				UserID: client.ID,
+				Email:  client.Email,
+				Name:   client.FullName,
+			},
+			Kind:  NotificationConsentRequest,
+			Title: fmt.Sprintf("%s asked to follow your journal", practitioner.FullName),
+			Body:  consentRequestBody(practitioner, membership.Name, consent.Message),
+		})
+	}
+
+	return s.response(consent)
+}
+
+// Requests lists the consents the practitioner asked for, newest first.
+func (s *ConsentService) Requests(practitionerID, orgID uint) ([]models.ConsentResponse, error) {
+	if _, err := findMembership(s.orgRepo, practitionerID, orgID); err != nil {
+		return nil, err
+	}
+
+	consents, err := s.consentRepo.FindByPractitioner(orgID, practitionerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(consents)
+}
+
+// Dashboard lists the practitioner's consenting clients with the weekly
+// moods of the entries they share over the last weeks.
+func (s *ConsentService) Dashboard(practitionerID, orgID uint, weeks int) (*models.PractitionerDashboard, error) {
+	if _, err := findMembership(s.orgRepo, practitionerID, orgID); err != nil {
+		return nil, err
+	}
+	if weeks <= 0 {
+		weeks = dashboardDefaultWeeks
+	}
+	if weeks > dashboardMaxWeeks {
+		weeks = dashboardMaxWeeks
+	}
+
+	consents, err := s.consentRepo.FindActiveByPractitioner(orgID, practitionerID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIDs := make([]uint, 0, len(consents))
+	for _, consent := range consents {
+		clientIDs = append(clientIDs, *consent.ClientID)
+	}
+	clients, err := s.userRepo.FindByIDs(clientIDs)
+	if err != nil {
+		return nil, err
+	}
+	clientsByID := make(map[uint]models.User, len(clients))
+	for _, client := range clients {
+		clientsByID[client.ID] = client
+	}
+
+	moodWeeks, err := s.consentRepo.MoodWeeks(orgID, practitionerID, weekStart(s.now().UTC()).AddDate(0, 0, -7*(weeks-1)))
+	if err != nil {
+		return nil, err
+	}
+	lastEntries, err := s.consentRepo.LastEntries(orgID, practitionerID)
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make(map[uint][]models.MoodWeek)
+	for _, row := range moodWeeks {
+		trend := trends[row.ConsentID]
+		label := row.WeekStart.Format("2006-01-02")
+		if len(trend) == 0 || trend[len(trend)-1].WeekStart != label {
+			trend = append(trend, models.MoodWeek{WeekStart: label})
+		}
+		week := &trend[len(trend)-1]
+		week.Entries += row.Count
+		week.Moods = append(week.Moods, models.MoodCount{Mood: row.Mood, Count: row.Count})
+		trends[row.ConsentID] = trend
+	}
+	lastByConsent := make(map[uint]time.Time, len(lastEntries))
+	for _, last := range lastEntries {
+		lastByConsent[last.ConsentID] = last.LastEntryDate
+	}
+
+	dashboard := &models.PractitionerDashboard{
+		OrganizationID: orgID,
+		Weeks:          weeks,
+		Clients:        []models.ClientSummary{},
+	}
+	for _, consent := range consents {
+		client, ok := clientsByID[*consent.ClientID]
+		if !ok {
+			continue
+		}
+
+		summary := models.ClientSummary{
+			ConsentID: consent.ID,
+			Client:    models.ReaderUser{ID: client.ID, Email: client.Email, FullName: client.FullName},
+			GrantedAt: consent.GrantedAt,
+			MoodTrend: []models.MoodWeek{},
+		}
+		if last, ok := lastByConsent[consent.ID]; ok {
+			summary.LastEntryDate = &last
+		}
+		for _, week := range trends[consent.ID] {
+			sort.Slice(week.Moods, func(i, j int) bool {
+				if week.Moods[i].Count != week.Moods[j].Count {
+					return week.Moods[i].Count > week.Moods[j].Count
+				}
+				return week.Moods[i].Mood < week.Moods[j].Mood
+			})
+			summary.MoodTrend = append(summary.MoodTrend, week)
+		}
+		dashboard.Clients = append(dashboard.Clients, summary)
+	}
+	return dashboard, nil
+}
+
+// ClientEntries returns a page of the published entries a client shares
+// with the practitioner, newest first.
+func (s *ConsentService) ClientEntries(practitionerID, orgID, clientID uint, limit, offset int) (*models.ClientEntriesResponse, error) {
+	if _, err := findMembership(s.orgRepo, practitionerID, orgID); err != nil {
+		return nil, err
+	}
+	_, err := s.consentRepo.FindActiveForClient(orgID, practitionerID, clientID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrConsentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = clientEntriesPageSize
+	}
+	if limit > clientEntriesMaxPageSize {
+		limit = clientEntriesMaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := s.consentRepo.FindEntries(orgID, practitionerID, clientID, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ClientEntriesResponse{Entries: []models.SharedEntry{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		response.HasMore = true
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, toSharedEntry(entry, false, false))
+	}
+	return response, nil
+}
+
+// Mine lists the requests addressed to the client and the consents they
+// answered.
+func (s *ConsentService) Mine(clientID uint) ([]models.ConsentResponse, error) {
+	client, err := s.findUser(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	consents, err := s.consentRepo.FindForClient(clientID, client.Email)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(consents)
+}
+
+// Grant consents to a request, sharing the selected notebooks, or changes
+// which notebooks an active consent shares.
+func (s *ConsentService) Grant(clientID, id uint, notebookIDs []uint) (*models.ConsentResponse, error) {
+	consent, err := s.findForClient(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Status != models.ConsentStatusPending && consent.Status != models.ConsentStatusActive {
+		return nil, fmt.Errorf("%w: the consent was %s", ErrInvalidConsent, consent.Status)
+	}
+
+	notebookIDs = uniqueIDs(notebookIDs)
+	for _, notebookID := range notebookIDs {
+		_, err := s.notebookRepo.FindByIDAndUserID(notebookID, clientID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrNotebookNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if consent.Status == models.ConsentStatusPending {
+		// The same practitioner may have asked under another address
+		active, err := s.consentRepo.FindActiveForClient(consent.OrganizationID, consent.PractitionerID, clientID)
+		if err != nil && !errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, err
+		}
+		if active != nil {
+			return nil, ErrConsentExists
+		}
+
+		now := s.now()
+		consent.ClientID = &clientID
+		consent.Status = models.ConsentStatusActive
+		consent.GrantedAt = &now
+	}
+
+	if err := s.consentRepo.SaveWithNotebooks(consent, notebookIDs); err != nil {
+		return nil, err
+	}
+	return s.response(consent)
+}
+
+// Decline turns down a pending request.
+func (s *ConsentService) Decline(clientID, id uint) (*models.ConsentResponse, error) {
+	consent, err := s.findForClient(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Status != models.ConsentStatusPending {
+		return nil, fmt.Errorf("%w: only pending requests can be declined", ErrInvalidConsent)
+	}
+
+	now := s.now()
+	consent.ClientID = &clientID
+	consent.Status = models.ConsentStatusDeclined
+	consent.RevokedAt = &now
+	if err := s.consentRepo.Save(consent); err != nil {
+		return nil, err
+	}
+	return s.response(consent)
+}
+
+// Revoke withdraws an active consent. The practitioner loses access on
+// their next request; a new request is needed to share again.
+func (s *ConsentService) Revoke(clientID, id uint) (*models.ConsentResponse, error) {
+	consent, err := s.findForClient(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if consent.Status != models.ConsentStatusActive {
+		return nil, fmt.Errorf("%w: only active consents can be revoked", ErrInvalidConsent)
+	}
+
+	now := s.now()
+	consent.Status = models.ConsentStatusRevoked
+	consent.RevokedAt = &now
+	if err := s.consentRepo.Save(consent); err != nil {
+		return nil, err
+	}
+	return s.response(consent)
+}
+
+func (s *ConsentService) findForClient(clientID, id uint) (*models.ClientConsent, error) {
+	client, err := s.findUser(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	consent, err := s.consentRepo.FindByIDForClient(id, clientID, client.Email)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrConsentNotFound
+	}
+	return consent, err
+}
+
+func (s *ConsentService) findUser(id uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (s *ConsentService) response(consent *models.ClientConsent) (*models.ConsentResponse, error) {
+	responses, err := s.toResponses([]models.ClientConsent{*consent})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// toResponses converts consents, loading their organizations, clients and
+// practitioners at once.
+func (s *ConsentService) toResponses(consents []models.ClientConsent) ([]models.ConsentResponse, error) {
+	var orgIDs, userIDs []uint
+	for _, consent := range consents {
+		orgIDs = append(orgIDs, consent.OrganizationID)
+		userIDs = append(userIDs, consent.PractitionerID)
+		if consent.ClientID != nil {
+			userIDs = append(userIDs, *consent.ClientID)
+		}
+	}
+
+	organizations, err := s.orgRepo.FindByIDs(uniqueIDs(orgIDs))
+	if err != nil {
+		return nil, err
+	}
+	orgNames := make(map[uint]string, len(organizations))
+	for _, organization := range organizations {
+		orgNames[organization.ID] = organization.Name
+	}
+
+	users, err := s.userRepo.FindByIDs(uniqueIDs(userIDs))
+	if err != nil {
+		return nil, err
+	}
+	usersByID := make(map[uint]*models.ReaderUser, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = &models.ReaderUser{ID: user.ID, Email: user.Email, FullName: user.FullName}
+	}
+
+	responses := make([]models.ConsentResponse, 0, len(consents))
+	for _, consent := range consents {
+		response := models.ConsentResponse{
+			ID:           consent.ID,
+			Organization: orgNames[consent.OrganizationID],
+			Practitioner: usersByID[consent.PractitionerID],
+			InviteEmail:  consent.InviteEmail,
+			Message:      consent.Message,
+			Status:       consent.Status,
+			NotebookIDs:  []uint{},
+			CreatedAt:    consent.CreatedAt,
+			GrantedAt:    consent.GrantedAt,
+			RevokedAt:    consent.RevokedAt,
+		}
+		if consent.ClientID != nil {
+			response.Client = usersByID[*consent.ClientID]
+		}
+		for _, notebook := range consent.Notebooks {
+			response.NotebookIDs = append(response.NotebookIDs, notebook.NotebookID)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func consentRequestBody(practitioner *models.User, organization, message string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s) of %s asked to follow your journal.", practitioner.FullName, practitioner.Email, organization)
+	if message != "" {
+		fmt.Fprintf(&b, "\n\n%s", message)
+	}
+	b.WriteString("\n\nYou choose which notebooks to share, and can revoke your consent at any time.")
+	return b.String()
+}
+
+// weekStart returns the Monday starting t's ISO week.
+func weekStart(t time.Time) time.Time {
+	day := dayStart(t, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,83 @@
+package services
+
+import "errors"
+
+var (
+	ErrJournalNotFound     = errors.New("journal entry not found")
+	ErrTaskNotFound        = errors.New("task not found")
+	ErrInvalidTaskOrder    = errors.New("task order must list every task of the entry exactly once")
+	ErrNothingToCarryOver  = errors.New("entry has no unfinished tasks to carry over")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidTimeZone     = errors.New("unknown time zone")
+	ErrWebhookURLRequired  = errors.New("webhook channel needs a webhook url")
+	ErrRevisionNotFound    = errors.New("revision not found")
+	ErrVersionMismatch     = errors.New("entry was changed by someone else")
+	ErrEntryIncomplete     = errors.New("entry needs a valid mood before it can be published")
+	ErrRoutineNotFound     = errors.New("routine not found")
+	ErrInvalidRecurrence   = errors.New("invalid recurrence")
+	ErrNotAnOccurrence     = errors.New("routine does not occur on this date")
+	ErrInvalidDateRange    = errors.New("invalid date range")
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrFileTooLarge        = errors.New("file exceeds the maximum allowed size")
+	ErrUnsupportedFileType = errors.New("file type is not supported")
+	ErrImageTooLarge       = errors.New("image is larger than 40 megapixels")
+	ErrNotAudioAttachment  = errors.New("attachment is not an audio file")
+
+	ErrPushNotConfigured        = errors.New("web push is not configured")
+	ErrInvalidPushSubscription  = errors.New("push subscription keys are invalid")
+	ErrUnsafePushEndpoint       = errors.New("push endpoint must use https and point to a public host")
+	ErrPushSubscriptionNotFound = errors.New("push subscription not found")
+
+	ErrInvalidReviewPeriod = errors.New("review period must be week, month or year")
+	ErrPromptNotFound      = errors.New("prompt not found")
+
+	ErrNotebookNotFound    = errors.New("notebook not found")
+	ErrNotebookArchived    = errors.New("notebook is archived")
+	ErrDefaultNotebook     = errors.New("the default notebook cannot be archived or deleted")
+	ErrInvalidExportFormat = errors.New("export format must be json or markdown")
+
+	ErrShareLinkNotFound     = errors.New("share link not found")
+	ErrShareLinkExpired      = errors.New("share link has expired or reached its view limit")
+	ErrInvalidShareLink      = errors.New("invalid share link")
+	ErrCannotShareDraft      = errors.New("drafts cannot be shared")
+	ErrSharePasswordRequired = errors.New("share link needs a password")
+	ErrWrongSharePassword    = errors.New("wrong share link password")
+	ErrTooManyShareAttempts  = errors.New("too many wrong passwords, try again later")
+
+	ErrForbidden          = errors.New("you do not have permission to do this")
+	ErrGrantNotFound      = errors.New("reader grant not found")
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvalidInvitation  = errors.New("invalid invitation")
+	ErrGrantExists        = errors.New("this person was already invited to it")
+
+	ErrCommentNotFound  = errors.New("comment not found")
+	ErrCommentsDisabled = errors.New("comments are disabled on this entry")
+	ErrEmptyComment     = errors.New("comment must not be empty")
+	ErrInvalidReaction  = errors.New("unknown reaction")
+
+	ErrOrganizationNotFound  = errors.New("organization not found")
+	ErrMemberNotFound        = errors.New("organization member not found")
+	ErrMemberExists          = errors.New("user is already a member of this organization")
+	ErrLastOrganizationOwner = errors.New("an organization needs at least one owner")
+	ErrConsentNotFound       = errors.New("consent not found")
+	ErrConsentExists         = errors.New("a consent with this client is already open")
+	ErrInvalidConsent        = errors.New("invalid consent")
+
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountDisabled    = errors.New("this account has been disabled")
+	ErrAccountLocked      = errors.New("too many failed logins, the account is locked for a while")
+	ErrSessionRevoked     = errors.New("this session has ended, please log in again")
+	ErrCannotModifySelf   = errors.New("admins cannot change their own account here")
+	ErrInvalidUserFilter  = errors.New("invalid role or status filter")
+
+	ErrTemplateNotFound        = errors.New("template not found")
+	ErrTemplateVersionNotFound = errors.New("template version not found")
+	ErrTemplateConflict        = errors.New("template was changed by another request")
+	ErrInvalidTemplate         = errors.New("invalid template")
+	ErrInvalidTemplateValues   = errors.New("invalid template values")
+
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDisabled         = errors.New("webhook is disabled")
+	ErrUnsafeWebhookURL        = errors.New("webhook url must use https and point to a public host")
+)
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/recurrence"
+)
+
+// maxInsightsRange limits how many days habit insights may cover.
+const maxInsightsRange = 366
+
+type InsightsService struct {
+	insightsRepo *repositories.InsightsRepository
+	userRepo     *repositories.UserRepository
+}
+
+func NewInsightsService(insightsRepo *repositories.InsightsRepository, userRepo *repositories.UserRepository) *InsightsService {
+	return &InsightsService{insightsRepo: insightsRepo, userRepo: userRepo}
+}
+
+// Habits computes completion rates, streaks and weekday statistics for
+// every habit tracked between from and to. Entries count towards the day
+// they fall on in the user's time zone.
+func (s *InsightsService) Habits(userID uint, from, to time.Time, deriveFromSubTasks bool) (*models.HabitInsightsResponse, error) {
+	from, to = recurrence.Date(from), recurrence.Date(to)
+	if to.Before(from) || to.Sub(from) > maxInsightsRange*24*time.Hour {
+		return nil, ErrInvalidDateRange
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	query := repositories.HabitQuery{
+		UserID:             userID,
+		From:               from,
+		To:                 to,
+		TimeZone:           userLocation(user.TimeZone).String(),
+		DeriveFromSubTasks: deriveFromSubTasks,
+	}
+
+	totals, err := s.insightsRepo.HabitTotals(query)
+	if err != nil {
+		return nil, err
+	}
+	weekdayTotals, err := s.insightsRepo.HabitWeekdayTotals(query)
+	if err != nil {
+		return nil, err
+	}
+	streaks, err := s.insightsRepo.HabitStreaks(query)
+	if err != nil {
+		return nil, err
+	}
+	weekly, err := s.insightsRepo.WeeklyTotals(query)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.HabitInsightsResponse{
+		From:                from,
+		To:                  to,
+		DerivedFromSubTasks: deriveFromSubTasks,
+		Habits:              make([]models.HabitStats, 0, len(totals)),
+		Weekly:              make([]models.WeeklyHabitSummary, 0, len(weekly)),
+	}
+
+	streakByHabit := make(map[string]repositories.HabitStreak, len(streaks))
+	for _, streak := range streaks {
+		streakByHabit[streak.HabitKey] = streak
+	}
+
+	weekdaysByHabit := make(map[string][]models.WeekdayStats)
+	var overall [8]models.WeekdayStats
+	for _, total := range weekdayTotals {
+		weekdaysByHabit[total.HabitKey] = append(weekdaysByHabit[total.HabitKey],
+			newWeekdayStats(total.Weekday, total.Tracked, total.Completed))
+		overall[total.Weekday].Tracked += total.Tracked
+		overall[total.Weekday].Completed += total.Completed
+	}
+
+	for _, total := range totals {
+		weekdays := weekdaysByHabit[total.HabitKey]
+		best, worst := bestAndWorstWeekday(weekdays)
+		streak := streakByHabit[total.HabitKey]
+
+		response.Habits = append(response.Habits, models.HabitStats{
+			Key:            total.HabitKey,
+			Label:          total.Label,
+			RoutineID:      total.RoutineID,
+			Tracked:        total.Tracked,
+			Completed:      total.Completed,
+			CompletionRate: completionRate(total.Completed, total.Tracked),
+			CurrentStreak:  streak.CurrentStreak,
+			LongestStreak:  streak.LongestStreak,
+			LastTracked:    total.LastTracked,
+			BestWeekday:    best,
+			WorstWeekday:   worst,
+			Weekdays:       weekdays,
+		})
+
+		response.Tracked += total.Tracked
+		response.Completed += total.Completed
+	}
+	response.CompletionRate = completionRate(response.Completed, response.Tracked)
+
+	response.Weekdays = []models.WeekdayStats{}
+	for weekday := 1; weekday <= 7; weekday++ {
+		if overall[weekday].Tracked > 0 {
+			response.Weekdays = append(response.Weekdays,
+				newWeekdayStats(weekday, overall[weekday].Tracked, overall[weekday].Completed))
+		}
+	}
+	response.BestWeekday, response.WorstWeekday = bestAndWorstWeekday(response.Weekdays)
+
+	for _, week := range weekly {
+		response.Weekly = append(response.Weekly, models.WeeklyHabitSummary{
+			WeekStart:      week.WeekStart,
+			Habits:         week.Habits,
+			Tracked:        week.Tracked,
+			Completed:      week.Completed,
+			CompletionRate: completionRate(week.Completed, week.Tracked),
+		})
+	}
+
+	return response, nil
+}
+
+// newWeekdayStats builds the statistics of an ISO weekday (1 = Monday).
+func newWeekdayStats(isoWeekday, tracked, completed int) models.WeekdayStats {
+	return models.WeekdayStats{
+		Weekday:        time.Weekday(isoWeekday % 7).String(),
+		Tracked:        tracked,
+		Completed:      completed,
+		CompletionRate: completionRate(completed, tracked),
+	}
+}
+
+// bestAndWorstWeekday picks the weekdays with the highest and lowest
+// completion rate. Ties go to the earlier day of the week.
+func bestAndWorstWeekday(weekdays []models.WeekdayStats) (*string, *string) {
+	if len(weekdays) == 0 {
+		return nil, nil
+	}
+
+	best, worst := weekdays[0], weekdays[0]
+	for _, weekday := range weekdays[1:] {
+		if weekday.CompletionRate > best.CompletionRate {
+			best = weekday
+		}
+		if weekday.CompletionRate < worst.CompletionRate {
+			worst = weekday
+		}
+	}
+	return &best.Weekday, &worst.Weekday
+}
+
+func completionRate(completed, tracked int) float64 {
+	if tracked == 0 {
+		return 0
+	}
+	return float64(completed) / float64(tracked)
+}
@@ -2,18 +2,65 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
 	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
 )
 
+// autosaveRevisionInterval is the minimum time between two revisions
+// recorded by autosave.
+const autosaveRevisionInterval = 5 * time.Minute
+
 type JournalService struct {
-	journalRepo *repositories.JournalRepository
+	journalRepo     *repositories.JournalRepository
+	revisionService *RevisionService
+	routineService  *RoutineService
+	webhookService  *WebhookService
+	promptService   *PromptService
+	templateService *TemplateService
+	notebookService *NotebookService
+}
+
+func NewJournalService(
+	journalRepo *repositories.JournalRepository,
+	revisionService *RevisionService,
+	routineService *RoutineService,
+	webhookService *WebhookService,
+	promptService *PromptService,
+	templateService *TemplateService,
+	notebookService *NotebookService,
+) *JournalService {
+	return &JournalService{
+		journalRepo:     journalRepo,
+		revisionService: revisionService,
+		routineService:  routineService,
+		webhookService:  webhookService,
+		promptService:   promptService,
+		templateService: templateService,
+		notebookService: notebookService,
+	}
 }
 
-func NewJournalService(journalRepo *repositories.JournalRepository) *JournalService {
-	return &JournalService{journalRepo: journalRepo}
+// Transaction runs fn with a copy of the service whose writes all happen
+// in one database transaction. Returning an error rolls everything back.
+func (s *JournalService) Transaction(fn func(service *JournalService) error) error {
+	return s.journalRepo.Transaction(func(tx *gorm.DB) error {
+		journalRepo := s.journalRepo.WithTx(tx)
+		return fn(&JournalService{
+			journalRepo:     journalRepo,
+			revisionService: s.revisionService.withTx(tx),
+			routineService:  s.routineService.withTx(tx, journalRepo),
+			webhookService:  s.webhookService.withTx(tx),
+			promptService:   s.promptService,
+			templateService: s.templateService,
+			notebookService: s.notebookService,
+		})
+	})
 }
 
 func (s *JournalService) CreateEntry(entry *models.JournalEntry) (uint, error) {
@@ -21,9 +68,375 @@ func (s *JournalService) CreateEntry(entry *models.JournalEntry) (uint, error) {
 	entry.Date = time.Date(entry.Date.Year(), entry.Date.Month(), entry.Date.Day(),
 		0, 0, 0, 0, entry.Date.Location())
 
-	return s.journalRepo.Create(entry)
+	if entry.Status == enums.EntryStatus.Unknown {
+		entry.Status = enums.EntryStatus.Published
+	}
+
+	// Drafts may be saved before the user picked a mood
+	if entry.Status == enums.EntryStatus.Published && entry.Mood == enums.Mood.Unknown {
+		return 0, ErrEntryIncomplete
+	}
+
+	notebookID, err := s.notebookService.ResolveForEntry(entry.UserID, entry.NotebookID)
+	if err != nil {
+		return 0, err
+	}
+	entry.NotebookID = &notebookID
+
+	if entry.PromptID != nil {
+		if err := s.promptService.CheckVisible(entry.UserID, *entry.PromptID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.applyTemplate(entry, entry.TemplateID, entry.TemplateValues); err != nil {
+		return 0, err
+	}
+
+	entry.Version = 1
+
+	// Routines due that day are part of the entry from the first revision
+	routineTasks, err := s.routineService.TasksFor(entry.UserID, entry.Date)
+	if err != nil {
+		return 0, err
+	}
+	entry.DailyTasks = append(entry.DailyTasks, routineTasks...)
+
+	// The event is queued with the entry so neither exists without the other
+	var id uint
+	err = s.Transaction(func(service *JournalService) error {
+		var err error
+		if id, err = service.journalRepo.Create(entry); err != nil {
+			return err
+		}
+		return service.webhookService.Publish(entry.UserID, models.WebhookEventJournalCreated, entry)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (s *JournalService) GetEntry(ctx context.Context, userID, id uint) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	return entry, err
+}
+
+// OpenEntry returns an entry for display, first adding the tasks of any
+// routine due that day that the entry does not have yet.
+func (s *JournalService) OpenEntry(ctx context.Context, userID, id uint) (*models.JournalEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed, err := s.routineService.Materialize(entry)
+	if err != nil || !changed {
+		return entry, err
+	}
+	return s.GetEntry(ctx, userID, id)
+}
+
+// UpdateEntry replaces the content and tasks of an entry. Every update is
+// recorded as a new revision. A non-zero expectedVersion (taken from the
+// If-Match header) makes the update fail with ErrVersionMismatch when the
+// entry was changed in the meantime.
+func (s *JournalService) UpdateEntry(ctx context.Context, userID, id uint, expectedVersion int, req models.UpdateJournalRequest) (*models.JournalEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Mood = req.Mood
+	entry.ThisDayDescription = req.ThisDayDescription
+	entry.DailyReflection = req.DailyReflection
+	entry.DailyTasks = req.ToDailyTasks()
+
+	if req.PromptID != nil {
+		entry.PromptID = nil
+		if *req.PromptID != 0 {
+			if err := s.promptService.CheckVisible(userID, *req.PromptID); err != nil {
+				return nil, err
+			}
+			entry.PromptID = req.PromptID
+		}
+	}
+
+	if req.TemplateID != nil || req.TemplateValues != nil {
+		templateID := entry.TemplateID
+		if req.TemplateID != nil {
+			templateID = req.TemplateID
+			if *templateID == 0 {
+				templateID = nil
+			}
+		}
+		if err := s.applyTemplate(entry, templateID, req.TemplateValues); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.save(ctx, userID, entry, expectedVersion)
+}
+
+// applyTemplate validates values against the latest version of the
+// template and stores both on the entry. A nil templateID detaches the
+// entry from its template.
+func (s *JournalService) applyTemplate(entry *models.JournalEntry, templateID *uint, values models.JSONB) error {
+	if templateID == nil {
+		if len(values) > 0 && string(values) != "null" {
+			return fmt.Errorf("%w: values need a template", ErrInvalidTemplateValues)
+		}
+		entry.TemplateID = nil
+		entry.TemplateVersion = nil
+		entry.TemplateValues = nil
+		return nil
+	}
+
+	version, normalized, err := s.templateService.ValidateValues(entry.UserID, *templateID, values)
+	if err != nil {
+		return err
+	}
+
+	entry.TemplateID = templateID
+	entry.TemplateVersion = &version
+	entry.TemplateValues = normalized
+	return nil
+}
+
+// Autosave stores the editor's in-progress text without touching tasks.
+// It is cheap enough to be called every few seconds, so it does not queue
+// journal.updated webhooks; they fire on the next save or publish.
+func (s *JournalService) Autosave(ctx context.Context, userID, id uint, expectedVersion int, req models.AutosaveJournalRequest) (*models.AutosaveResponse, error) {
+	if _, err := s.GetEntry(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if req.Mood != nil {
+		fields["mood"] = *req.Mood
+	}
+	if req.ThisDayDescription != nil {
+		fields["this_day_description"] = *req.ThisDayDescription
+	}
+	if req.DailyReflection != nil {
+		fields["daily_reflection"] = *req.DailyReflection
+	}
+
+	err := s.journalRepo.Autosave(id, userID, expectedVersion, fields, autosaveRevisionInterval)
+	if errors.Is(err, repositories.ErrVersionConflict) {
+		return nil, ErrVersionMismatch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.GetEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AutosaveResponse{
+		JournalID: entry.ID,
+		Version:   entry.Version,
+		UpdatedAt: entry.UpdatedAt,
+	}, nil
+}
+
+// Publish turns a draft into a published entry.
+func (s *JournalService) Publish(ctx context.Context, userID, id uint, expectedVersion int) (*models.JournalEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Mood == enums.Mood.Unknown {
+		return nil, ErrEntryIncomplete
+	}
+
+	entry.Status = enums.EntryStatus.Published
+
+	return s.save(ctx, userID, entry, expectedVersion)
 }
 
-func (s *JournalService) GetEntry(ctx context.Context, id uint) (*models.JournalEntry, error) {
-	return s.journalRepo.FindByID(id)
+// AddTask appends a task to an entry and returns the created task.
+func (s *JournalService) AddTask(ctx context.Context, userID, entryID uint, expectedVersion int, req models.DailyTaskRequest) (*models.DailyTask, *models.JournalEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, entryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing := make(map[uint]bool, len(entry.DailyTasks))
+	for _, task := range entry.DailyTasks {
+		existing[task.ID] = true
+	}
+
+	req.ID = 0
+	entry.DailyTasks = append(entry.DailyTasks, req.ToDailyTask())
+
+	entry, err = s.save(ctx, userID, entry, expectedVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range entry.DailyTasks {
+		if !existing[entry.DailyTasks[i].ID] {
+			return &entry.DailyTasks[i], entry, nil
+		}
+	}
+	return nil, nil, ErrTaskNotFound
+}
+
+// UpdateTask changes a single task of an entry. expectedVersion refers to
+// the version of the entry owning the task.
+func (s *JournalService) UpdateTask(ctx context.Context, userID, taskID uint, expectedVersion int, req models.PatchDailyTaskRequest) (*models.DailyTask, *models.JournalEntry, error) {
+	entry, index, err := s.findTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	req.ApplyTo(&entry.DailyTasks[index])
+
+	entry, err = s.save(ctx, userID, entry, expectedVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range entry.DailyTasks {
+		if entry.DailyTasks[i].ID == taskID {
+			return &entry.DailyTasks[i], entry, nil
+		}
+	}
+	return nil, nil, ErrTaskNotFound
+}
+
+// DeleteTask removes a task and its subtasks from an entry.
+func (s *JournalService) DeleteTask(ctx context.Context, userID, taskID uint, expectedVersion int) (*models.JournalEntry, error) {
+	entry, index, err := s.findTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.DailyTasks = append(entry.DailyTasks[:index], entry.DailyTasks[index+1:]...)
+
+	return s.save(ctx, userID, entry, expectedVersion)
+}
+
+// ReorderTasks sets the display order of an entry's tasks. taskIDs must
+// list every task of the entry exactly once.
+func (s *JournalService) ReorderTasks(ctx context.Context, userID, entryID uint, expectedVersion int, taskIDs []uint) (*models.JournalEntry, error) {
+	entry, err := s.GetEntry(ctx, userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(taskIDs) != len(entry.DailyTasks) {
+		return nil, ErrInvalidTaskOrder
+	}
+
+	byID := make(map[uint]models.DailyTask, len(entry.DailyTasks))
+	for _, task := range entry.DailyTasks {
+		byID[task.ID] = task
+	}
+
+	ordered := make([]models.DailyTask, 0, len(taskIDs))
+	for _, id := range taskIDs {
+		task, found := byID[id]
+		if !found {
+			return nil, ErrInvalidTaskOrder
+		}
+		delete(byID, id)
+		ordered = append(ordered, task)
+	}
+	entry.DailyTasks = ordered
+
+	return s.save(ctx, userID, entry, expectedVersion)
+}
+
+func (s *JournalService) findTask(ctx context.Context, userID, taskID uint) (*models.JournalEntry, int, error) {
+	task, err := s.journalRepo.FindTaskByIDAndUserID(taskID, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, 0, ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	entry, err := s.GetEntry(ctx, userID, task.JournalEntryID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range entry.DailyTasks {
+		if entry.DailyTasks[i].ID == taskID {
+			return entry, i, nil
+		}
+	}
+	return nil, 0, ErrTaskNotFound
+}
+
+func (s *JournalService) save(ctx context.Context, userID uint, entry *models.JournalEntry, expectedVersion int) (*models.JournalEntry, error) {
+	var saved *models.JournalEntry
+	err := s.Transaction(func(service *JournalService) error {
+		completedIDs, err := service.journalRepo.FindCompletedTaskIDs(entry.ID)
+		if err != nil {
+			return err
+		}
+
+		err = service.journalRepo.Update(entry, expectedVersion, nil)
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return ErrVersionMismatch
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := service.revisionService.ApplyRetention(entry.ID); err != nil {
+			return err
+		}
+
+		if saved, err = service.GetEntry(ctx, userID, entry.ID); err != nil {
+			return err
+		}
+
+		return service.publishUpdate(saved, completedIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// publishUpdate queues journal.updated for the entry and task.completed
+// for each task that was not completed before the update.
+func (s *JournalService) publishUpdate(entry *models.JournalEntry, previouslyCompleted []uint) error {
+	completed := make(map[uint]bool, len(previouslyCompleted))
+	for _, id := range previouslyCompleted {
+		completed[id] = true
+	}
+
+	for i := range entry.DailyTasks {
+		task := &entry.DailyTasks[i]
+		if !task.Status || completed[task.ID] {
+			continue
+		}
+		if err := s.webhookService.Publish(entry.UserID, models.WebhookEventTaskCompleted, task); err != nil {
+			return err
+		}
+	}
+
+	return s.webhookService.Publish(entry.UserID, models.WebhookEventJournalUpdated, entry)
+}
+
+// DeleteEntry moves an entry to the trash, from where it can be restored
+// until it is purged.
+func (s *JournalService) DeleteEntry(ctx context.Context, userID, id uint) error {
+	entry, err := s.GetEntry(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return s.journalRepo.Delete(entry)
 }
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+)
+
+const (
+	// digestBatchSize limits how many users one digest run loads at once.
+	digestBatchSize = 100
+	// digestExcerptLength is how much of each entry the digest quotes.
+	digestExcerptLength = 140
+
+	NotificationMemoryDigest = "memory_digest"
+)
+
+// MemoryService resurfaces past entries written on the same calendar day
+// in earlier years, and optionally a week, a month or six months ago.
+type MemoryService struct {
+	journalRepo *repositories.JournalRepository
+	userRepo    *repositories.UserRepository
+	dispatcher  *notify.Dispatcher
+	now         func() time.Time
+}
+
+func NewMemoryService(
+	journalRepo *repositories.JournalRepository,
+	userRepo *repositories.UserRepository,
+	dispatcher *notify.Dispatcher,
+) *MemoryService {
+	return &MemoryService{
+		journalRepo: journalRepo,
+		userRepo:    userRepo,
+		dispatcher:  dispatcher,
+		now:         time.Now,
+	}
+}
+
+// memoryWindow is one past day looked up for memories.
+type memoryWindow struct {
+	period string
+	ago    int
+	day    time.Time // Local midnight
+}
+
+// OnThisDay returns the memories for day (today in the user's time zone
+// when zero). periods adds the week, month and six_months look-backs to
+// the yearly ones.
+func (s *MemoryService) OnThisDay(userID uint, day time.Time, periods []string) (*models.OnThisDayResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	location := userLocation(user.TimeZone)
+	if day.IsZero() {
+		day = s.now().In(location)
+	}
+	today := dayStart(day, location)
+
+	return s.memories(userID, today, periods)
+}
+
+func (s *MemoryService) memories(userID uint, today time.Time, periods []string) (*models.OnThisDayResponse, error) {
+	response := &models.OnThisDayResponse{
+		Date:     today.Format("2006-01-02"),
+		Memories: []models.MemoryGroup{},
+	}
+
+	first, err := s.journalRepo.FindFirstEntryDate(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	windows := memoryWindows(today, first.In(today.Location()), periods)
+	ranges := make([]repositories.TimeRange, 0, len(windows))
+	for _, window := range windows {
+		ranges = append(ranges, repositories.TimeRange{From: window.day, To: window.day.AddDate(0, 0, 1)})
+	}
+
+	entries, err := s.journalRepo.FindPublishedInRanges(userID, ranges)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		end := window.day.AddDate(0, 0, 1)
+		group := models.MemoryGroup{
+			Period:  window.period,
+			Ago:     window.ago,
+			Label:   memoryLabel(window.period, window.ago),
+			Date:    window.day.Format("2006-01-02"),
+			Entries: []models.MemoryEntry{},
+		}
+		for _, entry := range entries {
+			if entry.Date.Before(window.day) || !entry.Date.Before(end) {
+				continue
+			}
+			group.Entries = append(group.Entries, models.MemoryEntry{
+				ID:                 entry.ID,
+				Date:               entry.Date,
+				Mood:               entry.Mood,
+				ThisDayDescription: entry.ThisDayDescription,
+				DailyReflection:    entry.DailyReflection,
+			})
+		}
+		if len(group.Entries) > 0 {
+			response.Memories = append(response.Memories, group)
+		}
+	}
+
+	return response, nil
+}
+
+// memoryWindows lists the days to look up, most recent first: the
+// requested short look-backs, then the same day of every earlier year
+// back to the user's first entry.
+func memoryWindows(today, first time.Time, periods []string) []memoryWindow {
+	var windows []memoryWindow
+
+	for _, period := range []string{models.MemoryPeriodWeek, models.MemoryPeriodMonth, models.MemoryPeriodSixMonths} {
+		if !containsString(periods, period) {
+			continue
+		}
+		switch period {
+		case models.MemoryPeriodWeek:
+			windows = append(windows, memoryWindow{period: period, ago: 1, day: today.AddDate(0, 0, -7)})
+		case models.MemoryPeriodMonth:
+			windows = append(windows, memoryWindow{period: period, ago: 1, day: sameDayBack(today, 0, 1)})
+		case models.MemoryPeriodSixMonths:
+			windows = append(windows, memoryWindow{period: period, ago: 6, day: sameDayBack(today, 0, 6)})
+		}
+	}
+
+	firstDay := dayStart(first, today.Location())
+	for years := 1; ; years++ {
+		day := sameDayBack(today, years, 0)
+		if day.Before(firstDay) {
+			break
+		}
+		windows = append(windows, memoryWindow{period: models.MemoryPeriodYear, ago: years, day: day})
+	}
+
+	return windows
+}
+
+// sameDayBack goes back whole years and months, clamping to the end of
+// shorter months so that e.g. Feb 29 maps to Feb 28 instead of Mar 1.
+func sameDayBack(day time.Time, years, months int) time.Time {
+	target := time.Date(day.Year()-years, day.Month()-time.Month(months), 1, 0, 0, 0, 0, day.Location())
+	lastDay := target.AddDate(0, 1, -1).Day()
+	return time.Date(target.Year(), target.Month(), min(day.Day(), lastDay), 0, 0, 0, 0, day.Location())
+}
+
+func memoryLabel(period string, ago int) string {
+	switch period {
+	case models.MemoryPeriodWeek:
+		return "1 week ago"
+	case models.MemoryPeriodMonth:
+		return "1 month ago"
+	case models.MemoryPeriodSixMonths:
+		return "6 months ago"
+	}
+	if ago == 1 {
+		return "1 year ago"
+	}
+	return fmt.Sprintf("%d years ago", ago)
+}
+
+// SendDailyDigests sends the "on this day" digest to every user who
+// enabled it, once per local day after their digest time. Days without
+// memories are skipped silently. It returns how many digests were sent.
+func (s *MemoryService) SendDailyDigests(ctx context.Context) (int, error) {
+	var afterID uint
+	var errs []error
+	sent := 0
+
+	for {
+		users, err := s.userRepo.FindWithMemoryDigestEnabled(afterID, digestBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			afterID = user.ID
+
+			delivered, err := s.sendDigest(ctx, user)
+			if delivered {
+				sent++
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(users) < digestBatchSize {
+			return sent, errors.Join(errs...)
+		}
+	}
+}
+
+func (s *MemoryService) sendDigest(ctx context.Context, user models.User) (bool, error) {
+	location := userLocation(user.TimeZone)
+	now := s.now().In(location)
+	today := dayStart(now, location)
+
+	at, err := time.ParseInLocation(reminderTimeLayout, user.MemoryDigestTime, location)
+	if err != nil {
+		return false, nil
+	}
+	due := time.Date(today.Year(), today.Month(), today.Day(), at.Hour(), at.Minute(), 0, 0, location)
+	if now.Before(due) {
+		return false, nil
+	}
+	if user.LastDigestOn != nil && !user.LastDigestOn.Before(localDate(today)) {
+		return false, nil
+	}
+
+	claimed, err := s.userRepo.MarkDigestSent(user.ID, localDate(today))
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	memories, err := s.memories(user.ID, today, nil)
+	if err != nil || len(memories.Memories) == 0 {
+		return false, err
+	}
+
+	notification := notify.Notification{
+		Recipient: notify.Recipient{
+			UserID:     user.ID,
+			Email:      user.Email,
+			Name:       user.FullName,
+			WebhookURL: user.ReminderWebhookURL,
+		},
+		Kind:  NotificationMemoryDigest,
+		Title: "On this day",
+		Body:  digestBody(memories),
+	}
+
+	return true, s.dispatcher.Send(ctx, reminderChannels(user.ReminderChannels), notification)
+}
+
+func digestBody(memories *models.OnThisDayResponse) string {
+	var body strings.Builder
+	for i, group := range memories.Memories {
+		if i > 0 {
+			body.WriteString("\n\n")
+		}
+		fmt.Fprintf(&body, "%s (%s):", group.Label, group.Date)
+		for _, entry := range group.Entries {
+			body.WriteString("\n- ")
+			body.WriteString(excerpt(entry.ThisDayDescription, digestExcerptLength))
+		}
+	}
+	return body.String()
+}
+
+// excerpt shortens text to at most length runes, cutting at a word
+// boundary where possible.
+func excerpt(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	cut := string(runes[:length])
+	if space := strings.LastIndex(cut, " "); space > length/2 {
+		cut = cut[:space]
+	}
+	return cut + "…"
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
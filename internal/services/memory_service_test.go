@@ -0,0 +1,172 @@
+package services
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+func TestSameDayBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    string
+		years  int
+		months int
+		want   string
+	}{
+		{name: "one year", day: "2026-03-15", years: 1, want: "2025-03-15"},
+		{name: "one month", day: "2026-03-15", months: 1, want: "2026-02-15"},
+		{name: "across the new year", day: "2026-01-10", months: 1, want: "2025-12-10"},
+		{name: "six months across the new year", day: "2026-03-15", months: 6, want: "2025-09-15"},
+		{name: "leap day to a common year", day: "2024-02-29", years: 1, want: "2023-02-28"},
+		{name: "leap day to a leap year", day: "2024-02-29", years: 4, want: "2020-02-29"},
+		{name: "end of a long month", day: "2026-03-31", months: 1, want: "2026-02-28"},
+		{name: "end of a long month to a 30-day month", day: "2026-03-31", months: 6, want: "2025-09-30"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			day, _ := time.Parse(time.DateOnly, tt.day)
+			if got := sameDayBack(day, tt.years, tt.months).Format(time.DateOnly); got != tt.want {
+				t.Errorf("sameDayBack(%s, %d, %d) = %s, want %s", tt.day, tt.years, tt.months, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryWindows(t *testing.T) {
+	tests := []struct {
+		name    string
+		today   string
+		first   string
+		periods []string
+		want    []string // period/ago/date
+	}{
+		{
+			name:  "yearly back to the first entry",
+			today: "2026-03-15", first: "2023-06-01",
+			want: []string{"year/1/2025-03-15", "year/2/2024-03-15"},
+		},
+		{
+			name:  "first entry on the same day years ago",
+			today: "2026-03-15", first: "2024-03-15",
+			want: []string{"year/1/2025-03-15", "year/2/2024-03-15"},
+		},
+		{
+			name:  "first entry this year",
+			today: "2026-03-15", first: "2026-01-01",
+			want: nil,
+		},
+		{
+			name:    "short look-backs come first",
+			today:   "2026-03-15",
+			first:   "2025-01-01",
+			periods: []string{models.MemoryPeriodSixMonths, models.MemoryPeriodWeek, models.MemoryPeriodMonth},
+			want:    []string{"week/1/2026-03-08", "month/1/2026-02-15", "six_months/6/2025-09-15", "year/1/2025-03-15"},
+		},
+		{
+			name:    "unknown periods are ignored",
+			today:   "2026-03-15",
+			first:   "2026-01-01",
+			periods: []string{"decade", models.MemoryPeriodWeek},
+			want:    []string{"week/1/2026-03-08"},
+		},
+		{
+			name:  "leap day",
+			today: "2024-02-29", first: "2020-01-01",
+			want: []string{"year/1/2023-02-28", "year/2/2022-02-28", "year/3/2021-02-28", "year/4/2020-02-29"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			today, _ := time.Parse(time.DateOnly, tt.today)
+			first, _ := time.Parse(time.DateOnly, tt.first)
+
+			var got []string
+			for _, window := range memoryWindows(today, first.Add(15*time.Hour), tt.periods) {
+				got = append(got, fmt.Sprintf("%s/%d/%s", window.period, window.ago, window.day.Format(time.DateOnly)))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("memoryWindows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryLabel(t *testing.T) {
+	tests := []struct {
+		period string
+		ago    int
+		want   string
+	}{
+		{period: models.MemoryPeriodWeek, ago: 1, want: "1 week ago"},
+		{period: models.MemoryPeriodMonth, ago: 1, want: "1 month ago"},
+		{period: models.MemoryPeriodSixMonths, ago: 6, want: "6 months ago"},
+		{period: models.MemoryPeriodYear, ago: 1, want: "1 year ago"},
+		{period: models.MemoryPeriodYear, ago: 5, want: "5 years ago"},
+	}
+
+	for _, tt := range tests {
+		if got := memoryLabel(tt.period, tt.ago); got != tt.want {
+			t.Errorf("memoryLabel(%s, %d) = %q, want %q", tt.period, tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		length int
+		want   string
+	}{
+		{name: "short text", text: "A quiet day.", length: 20, want: "A quiet day."},
+		{name: "collapses whitespace", text: "  A quiet\n\nday. ", length: 20, want: "A quiet day."},
+		{name: "cuts at a word", text: "We walked along the river until sunset", length: 20, want: "We walked along the…"},
+		{name: "cuts a long word", text: "Supercalifragilisticexpialidocious day", length: 10, want: "Supercalif…"},
+		{name: "counts runes", text: "Café au lait, croissant et confiture", length: 12, want: "Café au…"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := excerpt(tt.text, tt.length)
+			if got != tt.want {
+				t.Errorf("excerpt(%q, %d) = %q, want %q", tt.text, tt.length, got, tt.want)
+			}
+			if n := len([]rune(strings.TrimSuffix(got, "…"))); n > tt.length {
+				t.Errorf("excerpt is %d runes, longer than %d", n, tt.length)
+			}
+		})
+	}
+}
+
+func TestDigestBody(t *testing.T) {
+	memories := &models.OnThisDayResponse{
+		Date: "2026-03-15",
+		Memories: []models.MemoryGroup{
+			{
+				Label: "1 year ago",
+				Date:  "2025-03-15",
+				Entries: []models.MemoryEntry{
+					{ThisDayDescription: "First day at the new job."},
+					{ThisDayDescription: "Dinner with   friends."},
+				},
+			},
+			{
+				Label:   "3 years ago",
+				Date:    "2023-03-15",
+				Entries: []models.MemoryEntry{{ThisDayDescription: "Snow!"}},
+			},
+		},
+	}
+
+	want := "1 year ago (2025-03-15):\n- First day at the new job.\n- Dinner with friends.\n\n3 years ago (2023-03-15):\n- Snow!"
+	if got := digestBody(memories); got != want {
+		t.Errorf("digestBody() = %q, want %q", got, want)
+	}
+}
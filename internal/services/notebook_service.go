@@ -0,0 +1,359 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"gorm.io/gorm"
+)
+
+const (
+	notebookPageSize    = 50
+	notebookMaxPageSize = 200
+	notebookExcerptSize = 160
+)
+
+// NotebookService manages a user's notebooks and the entries in them.
+type NotebookService struct {
+	notebookRepo *repositories.NotebookRepository
+	journalRepo  *repositories.JournalRepository
+	events       *SecurityEventService
+}
+
+func NewNotebookService(
+	notebookRepo *repositories.NotebookRepository,
+	journalRepo *repositories.JournalRepository,
+	events *SecurityEventService,
+) *NotebookService {
+	return &NotebookService{
+		notebookRepo: notebookRepo,
+		journalRepo:  journalRepo,
+		events:       events,
+	}
+}
+
+// List returns the user's notebooks, the default one first. Archived
+// notebooks are left out unless includeArchived is set.
+func (s *NotebookService) List(userID uint, includeArchived bool) ([]models.NotebookResponse, error) {
+	// Users registered before notebooks existed get their default here
+	if _, err := s.notebookRepo.EnsureDefault(userID); err != nil {
+		return nil, err
+	}
+
+	notebooks, err := s.notebookRepo.FindByUserID(userID, includeArchived)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.journalRepo.CountByNotebook(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.NotebookResponse, 0, len(notebooks))
+	for _, notebook := range notebooks {
+		responses = append(responses, toNotebookResponse(notebook, counts))
+	}
+	return responses, nil
+}
+
+func (s *NotebookService) Get(userID, id uint) (*models.NotebookResponse, error) {
+	notebook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.journalRepo.CountByNotebook(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toNotebookResponse(*notebook, counts)
+	return &response, nil
+}
+
+func (s *NotebookService) Create(userID uint, req models.NotebookRequest) (*models.NotebookResponse, error) {
+	notebook := &models.Notebook{UserID: userID}
+	applyNotebookRequest(notebook, req)
+
+	if err := s.notebookRepo.Create(notebook); err != nil {
+		return nil, err
+	}
+
+	response := toNotebookResponse(*notebook, nil)
+	return &response, nil
+}
+
+func (s *NotebookService) Update(userID, id uint, req models.NotebookRequest) (*models.NotebookResponse, error) {
+	notebook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if notebook.IsDefault && req.Archived != nil && *req.Archived {
+		return nil, ErrDefaultNotebook
+	}
+	applyNotebookRequest(notebook, req)
+
+	if err := s.notebookRepo.Save(notebook); err != nil {
+		return nil, err
+	}
+
+	return s.Get(userID, id)
+}
+
+// Delete removes a notebook and moves its entries, trashed ones included,
+// into the default notebook.
+func (s *NotebookService) Delete(userID, id uint) error {
+	notebook, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+	if notebook.IsDefault {
+		return ErrDefaultNotebook
+	}
+
+	defaultNotebook, err := s.notebookRepo.EnsureDefault(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.notebookRepo.Transaction(func(tx *gorm.DB) error {
+		if err := s.journalRepo.WithTx(tx).MoveNotebookEntries(userID, notebook.ID, defaultNotebook.ID); err != nil {
+			return err
+		}
+		return s.notebookRepo.WithTx(tx).Delete(notebook)
+	})
+}
+
+// Entries lists a page of the notebook's entries, newest first. search
+// matches the entry text and task names; from and to bound the date.
+func (s *NotebookService) Entries(userID, id uint, search string, from, to *time.Time, limit, offset int) (*models.NotebookEntriesResponse, error) {
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, ErrInvalidDateRange
+	}
+
+	query, err := s.query(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	query.Search = strings.TrimSpace(search)
+	query.From, query.To = from, to
+
+	return listEntries(s.journalRepo, userID, *query, limit, offset)
+}
+
+// listEntries returns a page of the entries matching query, newest first.
+func listEntries(journalRepo *repositories.JournalRepository, userID uint, query repositories.NotebookQuery, limit, offset int) (*models.NotebookEntriesResponse, error) {
+	if limit <= 0 {
+		limit = notebookPageSize
+	}
+	if limit > notebookMaxPageSize {
+		limit = notebookMaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	entries, err := journalRepo.FindInNotebook(userID, query, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.NotebookEntriesResponse{Entries: []models.NotebookEntry{}}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		response.HasMore = true
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, models.NotebookEntry{
+			ID:      entry.ID,
+			Date:    entry.Date,
+			Mood:    entry.Mood,
+			Status:  entry.Status,
+			Excerpt: excerpt(entry.ThisDayDescription, notebookExcerptSize),
+			Version: entry.Version,
+		})
+	}
+	return response, nil
+}
+
+// Export returns every entry of the notebook with its tasks, oldest first.
+// The export is recorded in the security audit log.
+func (s *NotebookService) Export(userID, id uint, format string, info models.RequestInfo) (*models.NotebookExport, error) {
+	notebook, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := s.query(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.journalRepo.FindAllInNotebook(userID, *query)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.events.Record(models.SecurityEvent{
+		UserID: &userID,
+		Type:   models.SecurityEventDataExported,
+		Detail: fmt.Sprintf("notebook %d as %s", id, format),
+	}, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.NotebookExport{
+		Notebook:   *notebook,
+		ExportedAt: time.Now().UTC(),
+		Entries:    entries,
+	}, nil
+}
+
+// MoveEntries moves the user's entries into the notebook.
+func (s *NotebookService) MoveEntries(userID, id uint, entryIDs []uint) (*models.MoveEntriesResponse, error) {
+	notebook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if notebook.Archived {
+		return nil, ErrNotebookArchived
+	}
+
+	moved, err := s.journalRepo.MoveToNotebook(userID, entryIDs, notebook.ID)
+	if err != nil {
+		return nil, err
+	}
+	if moved == nil {
+		moved = []uint{}
+	}
+
+	return &models.MoveEntriesResponse{NotebookID: notebook.ID, EntryIDs: moved}, nil
+}
+
+// ResolveForEntry returns the notebook a new entry goes into: the given
+// one, or the user's default notebook when notebookID is nil.
+func (s *NotebookService) ResolveForEntry(userID uint, notebookID *uint) (uint, error) {
+	if notebookID == nil {
+		notebook, err := s.notebookRepo.EnsureDefault(userID)
+		if err != nil {
+			return 0, err
+		}
+		return notebook.ID, nil
+	}
+
+	notebook, err := s.find(userID, *notebookID)
+	if err != nil {
+		return 0, err
+	}
+	if notebook.Archived {
+		return 0, ErrNotebookArchived
+	}
+	return notebook.ID, nil
+}
+
+func (s *NotebookService) query(userID, id uint) (*repositories.NotebookQuery, error) {
+	notebook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &repositories.NotebookQuery{
+		NotebookID:        notebook.ID,
+		IncludeUnassigned: notebook.IsDefault,
+	}, nil
+}
+
+func (s *NotebookService) find(userID, id uint) (*models.Notebook, error) {
+	notebook, err := s.notebookRepo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrNotebookNotFound
+	}
+	return notebook, err
+}
+
+func applyNotebookRequest(notebook *models.Notebook, req models.NotebookRequest) {
+	notebook.Name = req.Name
+	notebook.Description = req.Description
+	if req.Color != "" {
+		notebook.Color = strings.ToUpper(req.Color)
+	}
+	if req.Archived != nil {
+		notebook.Archived = *req.Archived
+	}
+	if req.SortOrder != nil {
+		notebook.SortOrder = *req.SortOrder
+	}
+}
+
+func toNotebookResponse(notebook models.Notebook, counts map[uint]int64) models.NotebookResponse {
+	count := counts[notebook.ID]
+	if notebook.IsDefault {
+		count += counts[0]
+	}
+
+	return models.NotebookResponse{
+		ID:          notebook.ID,
+		Name:        notebook.Name,
+		Color:       notebook.Color,
+		Description: notebook.Description,
+		IsDefault:   notebook.IsDefault,
+		Archived:    notebook.Archived,
+		SortOrder:   notebook.SortOrder,
+		EntryCount:  count,
+		CreatedAt:   notebook.CreatedAt,
+		UpdatedAt:   notebook.UpdatedAt,
+	}
+}
+
+// NotebookMarkdown renders an export as a Markdown document.
+func NotebookMarkdown(export *models.NotebookExport) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", export.Notebook.Name)
+	if export.Notebook.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", export.Notebook.Description)
+	}
+	fmt.Fprintf(&b, "_Exported %s_\n", export.ExportedAt.Format(time.RFC3339))
+
+	for _, entry := range export.Entries {
+		fmt.Fprintf(&b, "\n## %s\n\n", entry.Date.Format("Monday, 2 January 2006"))
+		fmt.Fprintf(&b, "**Mood:** %s", entry.Mood)
+		if entry.Status == enums.EntryStatus.Draft {
+			b.WriteString(" · Draft")
+		}
+		b.WriteString("\n")
+
+		if entry.ThisDayDescription != "" {
+			fmt.Fprintf(&b, "\n%s\n", entry.ThisDayDescription)
+		}
+		if entry.DailyReflection != "" {
+			fmt.Fprintf(&b, "\n### Reflection\n\n%s\n", entry.DailyReflection)
+		}
+
+		if len(entry.DailyTasks) > 0 {
+			b.WriteString("\n### Tasks\n\n")
+			for _, task := range entry.DailyTasks {
+				fmt.Fprintf(&b, "- [%s] %s\n", markdownCheck(task.Status), task.Task)
+				for _, subTask := range task.SubTasks {
+					fmt.Fprintf(&b, "  - [%s] %s\n", markdownCheck(subTask.Status), subTask.SubTask)
+				}
+			}
+		}
+	}
+
+	return []byte(b.String())
+}
+
+func markdownCheck(done bool) string {
+	if done {
+		return "x"
+	}
+	return " "
+}
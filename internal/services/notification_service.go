@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+	"gorm.io/gorm"
+)
+
+const notificationBatchSize = 50
+
+// NotificationService queues notifications about activity on a user's
+// entries and sends them from a background job, so a slow or failing
+// channel never holds up the request that caused them.
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	userRepo         *repositories.UserRepository
+	dispatcher       *notify.Dispatcher
+}
+
+func NewNotificationService(
+	notificationRepo *repositories.NotificationRepository,
+	userRepo *repositories.UserRepository,
+	dispatcher *notify.Dispatcher,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		dispatcher:       dispatcher,
+	}
+}
+
+// withTx returns a copy of the service that queues notifications in tx, so
+// they are only sent if the change they are about commits.
+func (s *NotificationService) withTx(tx *gorm.DB) *NotificationService {
+	return &NotificationService{
+		notificationRepo: s.notificationRepo.WithTx(tx),
+		userRepo:         s.userRepo,
+		dispatcher:       s.dispatcher,
+	}
+}
+
+// Queue adds a notification for the user to the queue.
+func (s *NotificationService) Queue(userID uint, kind, title, body string) error {
+	return s.notificationRepo.Create(&models.QueuedNotification{
+		UserID: userID,
+		Kind:   kind,
+		Title:  title,
+		Body:   body,
+	})
+}
+
+// SendQueued sends the queued notifications through each recipient's
+// reminder channels and returns how many were delivered. Delivery is best
+// effort: a notification that fails is dropped and its error is reported
+// in the returned error.
+func (s *NotificationService) SendQueued(ctx context.Context) (int, error) {
+	var errs []error
+	sent := 0
+
+	for {
+		notifications, err := s.notificationRepo.FindQueued(notificationBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, notification := range notifications {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+
+			err := s.send(ctx, notification)
+			if ctx.Err() != nil {
+				// Interrupted by shutdown; sent again on the next run
+				return sent, ctx.Err()
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("notification %d to user %d: %w", notification.ID, notification.UserID, err))
+			} else {
+				sent++
+			}
+
+			if err := s.notificationRepo.Delete(notification.ID); err != nil {
+				return sent, err
+			}
+		}
+
+		if len(notifications) < notificationBatchSize {
+			return sent, errors.Join(errs...)
+		}
+	}
+}
+
+func (s *NotificationService) send(ctx context.Context, notification models.QueuedNotification) error {
+	user, err := s.userRepo.FindByID(notification.UserID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.dispatcher.Send(ctx, reminderChannels(user.ReminderChannels), notify.Notification{
+		Recipient: notify.Recipient{
+			UserID:     user.ID,
+			Email:      user.Email,
+			Name:       user.FullName,
+			WebhookURL: user.ReminderWebhookURL,
+		},
+		Kind:  notification.Kind,
+		Title: notification.Title,
+		Body:  notification.Body,
+	})
+}
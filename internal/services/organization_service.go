@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+// OrganizationService manages organizations and their members. Any member
+// may see the organization; only owners manage it.
+type OrganizationService struct {
+	orgRepo  *repositories.OrganizationRepository
+	userRepo *repositories.UserRepository
+}
+
+func NewOrganizationService(
+	orgRepo *repositories.OrganizationRepository,
+	userRepo *repositories.UserRepository,
+) *OrganizationService {
+	return &OrganizationService{orgRepo: orgRepo, userRepo: userRepo}
+}
+
+// Create makes an organization owned by the user.
+func (s *OrganizationService) Create(userID uint, req models.OrganizationRequest) (*models.OrganizationResponse, error) {
+	organization := &models.Organization{Name: strings.TrimSpace(req.Name)}
+	if err := s.orgRepo.Create(organization, userID); err != nil {
+		return nil, err
+	}
+
+	response := toOrganizationResponse(repositories.Membership{
+		Organization: *organization,
+		Role:         models.OrgRoleOwner,
+	})
+	return &response, nil
+}
+
+// List returns the organizations the user belongs to.
+func (s *OrganizationService) List(userID uint) ([]models.OrganizationResponse, error) {
+	memberships, err := s.orgRepo.FindByMemberID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.OrganizationResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		response = append(response, toOrganizationResponse(membership))
+	}
+	return response, nil
+}
+
+func (s *OrganizationService) Get(userID, id uint) (*models.OrganizationResponse, error) {
+	membership, err := findMembership(s.orgRepo, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toOrganizationResponse(*membership)
+	return &response, nil
+}
+
+func (s *OrganizationService) Update(userID, id uint, req models.OrganizationRequest) (*models.OrganizationResponse, error) {
+	membership, err := s.owned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	membership.Organization.Name = strings.TrimSpace(req.Name)
+	if err := s.orgRepo.Save(&membership.Organization); err != nil {
+		return nil, err
+	}
+
+	response := toOrganizationResponse(*membership)
+	return &response, nil
+}
+
+// Members lists the members of an organization the user belongs to.
+func (s *OrganizationService) Members(userID, id uint) ([]models.OrganizationMemberResponse, error) {
+	if _, err := findMembership(s.orgRepo, userID, id); err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.FindMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+	users, err := s.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+	}
+
+	response := make([]models.OrganizationMemberResponse, 0, len(members))
+	for _, member := range members {
+		user, ok := byID[member.UserID]
+		if !ok {
+			continue
+		}
+		response = append(response, models.OrganizationMemberResponse{
+			User:     models.ReaderUser{ID: user.ID, Email: user.Email, FullName: user.FullName},
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// AddMember adds a registered user to the organization.
+func (s *OrganizationService) AddMember(userID, id uint, req models.OrganizationMemberRequest) (*models.OrganizationMemberResponse, error) {
+	if _, err := s.owned(userID, id); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(req.Email))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	member := &models.OrganizationMember{
+		OrganizationID: id,
+		UserID:         user.ID,
+		Role:           req.Role,
+	}
+	added, err := s.orgRepo.AddMember(member)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, ErrMemberExists
+	}
+
+	return &models.OrganizationMemberResponse{
+		User:     models.ReaderUser{ID: user.ID, Email: user.Email, FullName: user.FullName},
+		Role:     member.Role,
+		JoinedAt: member.CreatedAt,
+	}, nil
+}
+
+// RemoveMember removes a member. Owners remove anyone and members may
+// leave, as long as an owner remains. A removed practitioner immediately
+// loses access to their clients' journals.
+func (s *OrganizationService) RemoveMember(userID, id, memberID uint) error {
+	membership, err := findMembership(s.orgRepo, userID, id)
+	if err != nil {
+		return err
+	}
+	if memberID != userID && membership.Role != models.OrgRoleOwner {
+		return ErrForbidden
+	}
+
+	member, err := s.orgRepo.FindMember(id, memberID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if member.Role == models.OrgRoleOwner {
+		owners, err := s.orgRepo.CountOwners(id)
+		if err != nil {
+			return err
+		}
+		if owners <= 1 {
+			return ErrLastOrganizationOwner
+		}
+	}
+	return s.orgRepo.RemoveMember(id, memberID)
+}
+
+func (s *OrganizationService) owned(userID, id uint) (*repositories.Membership, error) {
+	membership, err := findMembership(s.orgRepo, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Role != models.OrgRoleOwner {
+		return nil, ErrForbidden
+	}
+	return membership, nil
+}
+
+// findMembership returns an organization the user belongs to. Other
+// organizations are reported as not found.
+func findMembership(orgRepo *repositories.OrganizationRepository, userID, id uint) (*repositories.Membership, error) {
+	membership, err := orgRepo.FindMembership(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return membership, err
+}
+
+func toOrganizationResponse(membership repositories.Membership) models.OrganizationResponse {
+	return models.OrganizationResponse{
+		ID:        membership.ID,
+		Name:      membership.Name,
+		Role:      membership.Role,
+		CreatedAt: membership.CreatedAt,
+	}
+}
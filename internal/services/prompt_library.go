@@ -0,0 +1,58 @@
+package services
+
+import (
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+type libraryPrompt struct {
+	key   string
+	theme string
+	mood  enums.MoodType
+	text  string
+}
+
+// promptLibrary is seeded into the prompts table at start-up. Keys must
+// never change or be reused; retire a prompt by deactivating its row.
+var promptLibrary = []libraryPrompt{
+	{"gratitude-01", "gratitude", enums.Mood.Unknown, "What are three small things that went well today?"},
+	{"gratitude-02", "gratitude", enums.Mood.Unknown, "Who made your day a little better, and how?"},
+	{"gratitude-03", "gratitude", enums.Mood.Happy, "What made you smile today, and how can you make room for more of it?"},
+	{"gratitude-04", "gratitude", enums.Mood.Sad, "Even on a hard day, what is one thing you are thankful for?"},
+	{"reflection-01", "reflection", enums.Mood.Unknown, "What was the most meaningful moment of your day?"},
+	{"reflection-02", "reflection", enums.Mood.Unknown, "What did today teach you about yourself?"},
+	{"reflection-03", "reflection", enums.Mood.Unknown, "If you could relive one part of today, which would it be and why?"},
+	{"reflection-04", "reflection", enums.Mood.Sad, "What is weighing on you right now? Write it down without judging it."},
+	{"growth-01", "growth", enums.Mood.Unknown, "What challenged you today, and how did you respond?"},
+	{"growth-02", "growth", enums.Mood.Unknown, "What is one thing you would do differently if today happened again?"},
+	{"growth-03", "growth", enums.Mood.Energized, "You have energy today. What step toward a bigger goal could you take with it?"},
+	{"growth-04", "growth", enums.Mood.Unknown, "What did you learn today that you want to remember a year from now?"},
+	{"relationships-01", "relationships", enums.Mood.Unknown, "Who did you connect with today, and what did that conversation give you?"},
+	{"relationships-02", "relationships", enums.Mood.Unknown, "Is there someone you have been meaning to reach out to? What would you tell them?"},
+	{"relationships-03", "relationships", enums.Mood.Happy, "Who would you like to share today's good mood with?"},
+	{"self-care-01", "self-care", enums.Mood.Unknown, "How did you take care of yourself today?"},
+	{"self-care-02", "self-care", enums.Mood.Anxious, "What is one thing within your control right now, and one thing you can let go of?"},
+	{"self-care-03", "self-care", enums.Mood.Anxious, "Describe a place where you feel safe and calm. What makes it feel that way?"},
+	{"self-care-04", "self-care", enums.Mood.Sad, "What would you say to a friend who felt the way you feel today?"},
+	{"mindfulness-01", "mindfulness", enums.Mood.Calm, "What did you notice today that you would usually rush past?"},
+	{"mindfulness-02", "mindfulness", enums.Mood.Unknown, "Describe one moment today using all five senses."},
+	{"mindfulness-03", "mindfulness", enums.Mood.Calm, "What helped you feel at peace today?"},
+	{"goals-01", "goals", enums.Mood.Unknown, "What is one thing you want tomorrow to hold?"},
+	{"goals-02", "goals", enums.Mood.Energized, "What are you excited to work on this week?"},
+	{"goals-03", "goals", enums.Mood.Unknown, "Which of today's tasks mattered most, and which could have waited?"},
+}
+
+func libraryPrompts() []models.Prompt {
+	prompts := make([]models.Prompt, 0, len(promptLibrary))
+	for _, item := range promptLibrary {
+		key := item.key
+		prompts = append(prompts, models.Prompt{
+			SeedKey: &key,
+			Text:    item.text,
+			Theme:   item.theme,
+			Mood:    item.mood,
+			Active:  true,
+		})
+	}
+	return prompts
+}
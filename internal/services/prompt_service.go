@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+// promptRepeatWindow is how long a prompt is not offered again after it
+// was picked or answered.
+const promptRepeatWindow = 30 * 24 * time.Hour
+
+// PromptService manages the prompts library and the users' own prompts,
+// and picks the prompt of the day.
+type PromptService struct {
+	promptRepo *repositories.PromptRepository
+	userRepo   *repositories.UserRepository
+	now        func() time.Time
+}
+
+func NewPromptService(promptRepo *repositories.PromptRepository, userRepo *repositories.UserRepository) *PromptService {
+	return &PromptService{
+		promptRepo: promptRepo,
+		userRepo:   userRepo,
+		now:        time.Now,
+	}
+}
+
+// SeedLibrary stores library prompts added since the last start.
+func (s *PromptService) SeedLibrary() error {
+	return s.promptRepo.Seed(libraryPrompts())
+}
+
+func (s *PromptService) List(userID uint, theme string, mood enums.MoodType) ([]models.PromptResponse, error) {
+	prompts, err := s.promptRepo.FindForUser(userID, theme, mood)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.PromptResponse, 0, len(prompts))
+	for _, prompt := range prompts {
+		responses = append(responses, toPromptResponse(prompt))
+	}
+	return responses, nil
+}
+
+func (s *PromptService) Create(userID uint, req models.PromptRequest) (*models.PromptResponse, error) {
+	prompt := &models.Prompt{
+		UserID: &userID,
+		Text:   req.Text,
+		Theme:  req.Theme,
+		Mood:   req.Mood,
+		Active: req.Active == nil || *req.Active,
+	}
+	if err := s.promptRepo.Create(prompt); err != nil {
+		return nil, err
+	}
+
+	response := toPromptResponse(*prompt)
+	return &response, nil
+}
+
+// Update changes one of the user's own prompts; library prompts cannot be
+// edited.
+func (s *PromptService) Update(userID, id uint, req models.PromptRequest) (*models.PromptResponse, error) {
+	prompt, err := s.findOwned(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt.Text = req.Text
+	prompt.Theme = req.Theme
+	prompt.Mood = req.Mood
+	if req.Active != nil {
+		prompt.Active = *req.Active
+	}
+	if err := s.promptRepo.Save(prompt); err != nil {
+		return nil, err
+	}
+
+	response := toPromptResponse(*prompt)
+	return &response, nil
+}
+
+// Delete removes one of the user's own prompts. Entries that answered it
+// keep their reference.
+func (s *PromptService) Delete(userID, id uint) error {
+	prompt, err := s.findOwned(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.promptRepo.Delete(prompt)
+}
+
+// Daily returns the user's prompt for today in their time zone. The first
+// request of a day picks it deterministically from the prompts not seen
+// in the last 30 days; later requests return the same one.
+func (s *PromptService) Daily(userID uint) (*models.DailyPromptResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	location := userLocation(user.TimeZone)
+	today := localDate(dayStart(s.now().In(location), location))
+
+	pick, err := s.promptRepo.FindPick(userID, today)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		pick, err = s.pick(userID, today)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := s.promptRepo.FindVisible(pick.PromptID, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrPromptNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DailyPromptResponse{
+		Date:   today.Format("2006-01-02"),
+		Prompt: toPromptResponse(*prompt),
+	}, nil
+}
+
+func (s *PromptService) pick(userID uint, day time.Time) (*models.PromptPick, error) {
+	candidates, err := s.promptRepo.FindActiveForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, ErrPromptNotFound
+	}
+
+	recentIDs, err := s.promptRepo.FindRecentPromptIDs(userID, day.Add(-promptRepeatWindow))
+	if err != nil {
+		return nil, err
+	}
+	recent := make(map[uint]bool, len(recentIDs))
+	for _, id := range recentIDs {
+		recent[id] = true
+	}
+
+	fresh := make([]models.Prompt, 0, len(candidates))
+	for _, prompt := range candidates {
+		if !recent[prompt.ID] {
+			fresh = append(fresh, prompt)
+		}
+	}
+	// Every prompt was seen recently; start over rather than show nothing
+	if len(fresh) == 0 {
+		fresh = candidates
+	}
+
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d:%s", userID, day.Format("2006-01-02"))
+	chosen := fresh[hash.Sum64()%uint64(len(fresh))]
+
+	return s.promptRepo.CreatePick(&models.PromptPick{
+		UserID:   userID,
+		Date:     day,
+		PromptID: chosen.ID,
+	})
+}
+
+// Answers lists the user's entries that answered the prompt.
+func (s *PromptService) Answers(userID, id uint) ([]models.PromptAnswer, error) {
+	if err := s.CheckVisible(userID, id); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.promptRepo.FindAnswers(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	answers := make([]models.PromptAnswer, 0, len(entries))
+	for _, entry := range entries {
+		answers = append(answers, models.PromptAnswer{
+			EntryID:         entry.ID,
+			Date:            entry.Date,
+			Mood:            entry.Mood,
+			DailyReflection: entry.DailyReflection,
+		})
+	}
+	return answers, nil
+}
+
+// CheckVisible reports ErrPromptNotFound unless the prompt is in the
+// library or belongs to the user.
+func (s *PromptService) CheckVisible(userID, id uint) error {
+	_, err := s.promptRepo.FindVisible(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrPromptNotFound
+	}
+	return err
+}
+
+func (s *PromptService) findOwned(userID, id uint) (*models.Prompt, error) {
+	prompt, err := s.promptRepo.FindOwned(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrPromptNotFound
+	}
+	return prompt, err
+}
+
+func toPromptResponse(prompt models.Prompt) models.PromptResponse {
+	return models.PromptResponse{
+		ID:     prompt.ID,
+		Text:   prompt.Text,
+		Theme:  prompt.Theme,
+		Mood:   prompt.Mood,
+		Custom: prompt.UserID != nil,
+		Active: prompt.Active,
+	}
+}
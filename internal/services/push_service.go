@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+	"github.com/sugiiianaa/remember-my-story/internal/webpush"
+)
+
+// PushService manages the users' Web Push subscriptions and delivers
+// messages to them. Without VAPID keys configured client is nil and push
+// is disabled.
+type PushService struct {
+	repo   *repositories.PushSubscriptionRepository
+	client *webpush.Client
+}
+
+func NewPushService(repo *repositories.PushSubscriptionRepository, client *webpush.Client) *PushService {
+	return &PushService{repo: repo, client: client}
+}
+
+func (s *PushService) Config() (*models.PushConfigResponse, error) {
+	if s.client == nil {
+		return nil, ErrPushNotConfigured
+	}
+	return &models.PushConfigResponse{PublicKey: s.client.PublicKey()}, nil
+}
+
+func (s *PushService) Subscribe(userID uint, req models.PushSubscriptionRequest, userAgent string) (*models.PushSubscriptionResponse, error) {
+	if s.client == nil {
+		return nil, ErrPushNotConfigured
+	}
+
+	if err := safehttp.CheckURL(req.Endpoint); err != nil {
+		return nil, ErrUnsafePushEndpoint
+	}
+	target := webpush.Subscription{Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if err := target.Validate(); err != nil {
+		return nil, ErrInvalidPushSubscription
+	}
+
+	subscription := &models.PushSubscription{
+		UserID:     userID,
+		Endpoint:   req.Endpoint,
+		P256dh:     req.Keys.P256dh,
+		Auth:       req.Keys.Auth,
+		DeviceName: req.DeviceName,
+		UserAgent:  userAgent,
+	}
+	if err := s.repo.Upsert(subscription); err != nil {
+		return nil, err
+	}
+
+	// The upsert may have updated an existing row; read back its ID
+	subscription, err := s.repo.FindByEndpoint(req.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	response := toPushSubscriptionResponse(*subscription)
+	return &response, nil
+}
+
+func (s *PushService) List(userID uint) ([]models.PushSubscriptionResponse, error) {
+	subscriptions, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.PushSubscriptionResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		responses = append(responses, toPushSubscriptionResponse(subscription))
+	}
+	return responses, nil
+}
+
+func (s *PushService) Unsubscribe(userID, id uint) error {
+	err := s.repo.DeleteByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrPushSubscriptionNotFound
+	}
+	return err
+}
+
+// SendToUser delivers payload to every device of the user. Subscriptions
+// the push service reports as expired are deleted. It implements
+// notify.PushSender.
+func (s *PushService) SendToUser(ctx context.Context, userID uint, payload []byte) error {
+	if s.client == nil {
+		return ErrPushNotConfigured
+	}
+
+	subscriptions, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return notify.ErrNoAddress
+	}
+
+	var errs []error
+	delivered := 0
+	for _, subscription := range subscriptions {
+		err := s.client.Send(ctx, webpush.Subscription{
+			Endpoint: subscription.Endpoint,
+			P256dh:   subscription.P256dh,
+			Auth:     subscription.Auth,
+		}, payload)
+
+		switch {
+		case err == nil:
+			delivered++
+			if err := s.repo.MarkUsed(subscription.ID, time.Now()); err != nil {
+				errs = append(errs, err)
+			}
+		case errors.Is(err, webpush.ErrSubscriptionGone):
+			if err := s.repo.DeleteByEndpoint(subscription.Endpoint); err != nil {
+				errs = append(errs, err)
+			}
+		default:
+			errs = append(errs, fmt.Errorf("subscription %d: %w", subscription.ID, err))
+		}
+	}
+
+	if delivered == 0 && len(errs) == 0 {
+		// Every subscription had expired
+		return notify.ErrNoAddress
+	}
+	return errors.Join(errs...)
+}
+
+func toPushSubscriptionResponse(subscription models.PushSubscription) models.PushSubscriptionResponse {
+	return models.PushSubscriptionResponse{
+		ID:         subscription.ID,
+		Endpoint:   subscription.Endpoint,
+		DeviceName: subscription.DeviceName,
+		UserAgent:  subscription.UserAgent,
+		CreatedAt:  subscription.CreatedAt,
+		LastUsedAt: subscription.LastUsedAt,
+	}
+}
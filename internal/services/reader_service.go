@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+)
+
+const (
+	NotificationReaderInvitation = "reader_invitation"
+
+	// readerActivityLimit caps how many accesses the owner's audit trail
+	// returns.
+	readerActivityLimit = 200
+
+	// readerAccessList is the audit action for listing a grant's entries.
+	readerAccessList = "list"
+)
+
+// ReaderService manages trusted readers: other registered users the owner
+// invited to one of their notebooks or hashtags. What a reader may do with
+// a single entry is decided by the AccessPolicy.
+type ReaderService struct {
+	grantRepo    *repositories.ReaderGrantRepository
+	userRepo     *repositories.UserRepository
+	notebookRepo *repositories.NotebookRepository
+	journalRepo  *repositories.JournalRepository
+	dispatcher   *notify.Dispatcher
+	now          func() time.Time
+}
+
+func NewReaderService(
+	grantRepo *repositories.ReaderGrantRepository,
+	userRepo *repositories.UserRepository,
+	notebookRepo *repositories.NotebookRepository,
+	journalRepo *repositories.JournalRepository,
+	dispatcher *notify.Dispatcher,
+) *ReaderService {
+	return &ReaderService{
+		grantRepo:    grantRepo,
+		userRepo:     userRepo,
+		notebookRepo: notebookRepo,
+		journalRepo:  journalRepo,
+		dispatcher:   dispatcher,
+		now:          time.Now,
+	}
+}
+
+// Invite invites someone by email to one of the owner's notebooks or to
+// the owner's entries with a hashtag. The invitee is told by email when
+// they already have an account; otherwise the invitation waits until
+// they register with that address.
+func (s *ReaderService) Invite(ctx context.Context, ownerID uint, req models.ReaderInviteRequest) (*models.ReaderGrantResponse, error) {
+	tag := normalizeTag(req.Tag)
+	if (req.NotebookID == nil) == (tag == "") {
+		return nil, fmt.Errorf("%w: invite to either a notebook or a tag", ErrInvalidInvitation)
+	}
+	if req.Tag != "" && !validTag(tag) {
+		return nil, fmt.Errorf("%w: tags contain only letters and digits", ErrInvalidInvitation)
+	}
+
+	owner, err := s.findUser(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	email := strings.TrimSpace(req.Email)
+	if strings.EqualFold(email, owner.Email) {
+		return nil, fmt.Errorf("%w: you cannot invite yourself", ErrInvalidInvitation)
+	}
+
+	if req.NotebookID != nil {
+		_, err := s.notebookRepo.FindByIDAndUserID(*req.NotebookID, ownerID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrNotebookNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	exists, err := s.grantRepo.ExistsOpen(ownerID, email, req.NotebookID, tag)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrGrantExists
+	}
+
+	grant := &models.ReaderGrant{
+		OwnerID:     ownerID,
+		InviteEmail: email,
+		Role:        req.Role,
+		NotebookID:  req.NotebookID,
+		Tag:         tag,
+		Message:     strings.TrimSpace(req.Message),
+		Status:      models.GrantStatusPending,
+	}
+	if err := s.grantRepo.Create(grant); err != nil {
+		return nil, err
+	}
+
+	invitee, err := s.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if invitee != nil {
+		// The invitation stands even when the email cannot be sent; it is
+		// listed under the invitee's pending invitations either way.
+		_ = s.dispatcher.Send(ctx, []string{notify.ChannelEmail}, notify.Notification{
+			Recipient: notify.Recipient{
+				UserID: invitee.ID,
+				Email:  invitee.Email,
+				Name:   invitee.FullName,
+			},
+			Kind:  NotificationReaderInvitation,
+			Title: fmt.Sprintf("%s invited you to read their journal", owner.FullName),
+			Body:  invitationBody(owner, grant),
+		})
+	}
+
+	return s.toResponse(grant, owner, invitee), nil
+}
+
+// List returns the owner's invitations and grants, newest first.
+func (s *ReaderService) List(ownerID uint) ([]models.ReaderGrantResponse, error) {
+	grants, err := s.grantRepo.FindByOwnerID(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(grants)
+}
+
+// UpdateRole changes the role of a pending or accepted grant.
+func (s *ReaderService) UpdateRole(ownerID, id uint, role string) (*models.ReaderGrantResponse, error) {
+	grant, err := s.findOwned(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !grantOpen(grant) {
+		return nil, ErrGrantNotFound
+	}
+
+	grant.Role = role
+	if err := s.grantRepo.Save(grant); err != nil {
+		return nil, err
+	}
+	return s.response(grant)
+}
+
+// Revoke withdraws an invitation or ends a reader's access.
+func (s *ReaderService) Revoke(ownerID, id uint) error {
+	grant, err := s.findOwned(ownerID, id)
+	if err != nil {
+		return err
+	}
+	if !grantOpen(grant) {
+		return nil
+	}
+
+	now := s.now()
+	grant.Status = models.GrantStatusRevoked
+	grant.RevokedAt = &now
+	return s.grantRepo.Save(grant)
+}
+
+// Activity returns what the reader looked at and did through a grant,
+// newest first.
+func (s *ReaderService) Activity(ownerID, id uint) ([]models.ReaderAccessResponse, error) {
+	grant, err := s.findOwned(ownerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses, err := s.grantRepo.FindAccesses(grant.ID, readerActivityLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.ReaderAccessResponse, 0, len(accesses))
+	for _, access := range accesses {
+		response = append(response, models.ReaderAccessResponse{
+			ReaderID:  access.ReaderID,
+			EntryID:   access.JournalEntryID,
+			Action:    access.Action,
+			CreatedAt: access.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// Invitations lists the pending invitations addressed to the user's email.
+func (s *ReaderService) Invitations(userID uint) ([]models.ReaderGrantResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := s.grantRepo.FindPendingByEmail(user.Email)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(grants)
+}
+
+// Respond accepts or declines an invitation addressed to the user.
+func (s *ReaderService) Respond(userID, id uint, accept bool) (*models.ReaderGrantResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.grantRepo.FindPendingByIDAndEmail(id, user.Email)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrInvitationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	grant.RespondedAt = &now
+	grant.Status = models.GrantStatusDeclined
+	if accept {
+		grant.Status = models.GrantStatusAccepted
+		grant.ReaderID = &user.ID
+	}
+	if err := s.grantRepo.Save(grant); err != nil {
+		return nil, err
+	}
+	return s.response(grant)
+}
+
+// SharedWithMe lists the grants the reader currently holds.
+func (s *ReaderService) SharedWithMe(readerID uint) ([]models.ReaderGrantResponse, error) {
+	grants, err := s.grantRepo.FindAcceptedByReaderID(readerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(grants)
+}
+
+// Leave gives up a grant the reader holds.
+func (s *ReaderService) Leave(readerID, id uint) error {
+	grant, err := s.findHeld(readerID, id)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	grant.Status = models.GrantStatusRevoked
+	grant.RevokedAt = &now
+	return s.grantRepo.Save(grant)
+}
+
+// Entries lists the owner's entries a grant covers, newest first. Only
+// editors see drafts.
+func (s *ReaderService) Entries(readerID, id uint, search string, limit, offset int) (*models.NotebookEntriesResponse, error) {
+	grant, err := s.findHeld(readerID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := repositories.NotebookQuery{
+		Hashtag:       grant.Tag,
+		PublishedOnly: grant.Role != models.ReaderRoleEditor,
+		Search:        strings.TrimSpace(search),
+	}
+	if grant.NotebookID != nil {
+		notebook, err := s.notebookRepo.FindByIDAndUserID(*grant.NotebookID, grant.OwnerID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrNotebookNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		query.NotebookID = notebook.ID
+		query.IncludeUnassigned = notebook.IsDefault
+	}
+
+	response, err := listEntries(s.journalRepo, grant.OwnerID, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.grantRepo.LogAccess(&models.ReaderAccess{
+		GrantID:  grant.ID,
+		OwnerID:  grant.OwnerID,
+		ReaderID: readerID,
+		Action:   readerAccessList,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (s *ReaderService) findOwned(ownerID, id uint) (*models.ReaderGrant, error) {
+	grant, err := s.grantRepo.FindByIDAndOwnerID(id, ownerID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrGrantNotFound
+	}
+	return grant, err
+}
+
+func (s *ReaderService) findHeld(readerID, id uint) (*models.ReaderGrant, error) {
+	grant, err := s.grantRepo.FindAcceptedByIDAndReaderID(id, readerID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrGrantNotFound
+	}
+	return grant, err
+}
+
+func (s *ReaderService) findUser(id uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(id)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (s *ReaderService) response(grant *models.ReaderGrant) (*models.ReaderGrantResponse, error) {
+	responses, err := s.toResponses([]models.ReaderGrant{*grant})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// toResponses converts grants, loading their owners and readers at once.
+func (s *ReaderService) toResponses(grants []models.ReaderGrant) ([]models.ReaderGrantResponse, error) {
+	var ids []uint
+	for _, grant := range grants {
+		ids = append(ids, grant.OwnerID)
+		if grant.ReaderID != nil {
+			ids = append(ids, *grant.ReaderID)
+		}
+	}
+
+	users, err := s.userRepo.FindByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*models.User, len(users))
+	for i := range users {
+		byID[users[i].ID] = &users[i]
+	}
+
+	responses := make([]models.ReaderGrantResponse, 0, len(grants))
+	for i := range grants {
+		var reader *models.User
+		if grants[i].ReaderID != nil {
+			reader = byID[*grants[i].ReaderID]
+		}
+		responses = append(responses, *s.toResponse(&grants[i], byID[grants[i].OwnerID], reader))
+	}
+	return responses, nil
+}
+
+func (s *ReaderService) toResponse(grant *models.ReaderGrant, owner, reader *models.User) *models.ReaderGrantResponse {
+	response := &models.ReaderGrantResponse{
+		ID:          grant.ID,
+		InviteEmail: grant.InviteEmail,
+		Role:        grant.Role,
+		NotebookID:  grant.NotebookID,
+		Tag:         grant.Tag,
+		Message:     grant.Message,
+		Status:      grant.Status,
+		CreatedAt:   grant.CreatedAt,
+		RespondedAt: grant.RespondedAt,
+		RevokedAt:   grant.RevokedAt,
+	}
+	if owner != nil {
+		response.Owner = &models.ReaderUser{ID: owner.ID, Email: owner.Email, FullName: owner.FullName}
+	}
+	// Readers are only known once they accepted
+	if reader != nil && grant.ReaderID != nil {
+		response.Reader = &models.ReaderUser{ID: reader.ID, Email: reader.Email, FullName: reader.FullName}
+	}
+	return response
+}
+
+func grantOpen(grant *models.ReaderGrant) bool {
+	return grant.Status == models.GrantStatusPending || grant.Status == models.GrantStatusAccepted
+}
+
+// normalizeTag lower-cases a hashtag and drops its leading #.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// validTag reports whether tag is a single hashtag word, as split by
+// tokenize.
+func validTag(tag string) bool {
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return tag != ""
+}
+
+func invitationBody(owner *models.User, grant *models.ReaderGrant) string {
+	var b strings.Builder
+	what := "a notebook"
+	if grant.Tag != "" {
+		what = "their entries tagged #" + grant.Tag
+	}
+	fmt.Fprintf(&b, "%s (%s) invited you as a %s to %s.", owner.FullName, owner.Email, grant.Role, what)
+	if grant.Message != "" {
+		fmt.Fprintf(&b, "\n\n%s", grant.Message)
+	}
+	b.WriteString("\n\nOpen your invitations in the app to accept or decline.")
+	return b.String()
+}
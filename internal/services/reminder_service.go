@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/notify"
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+)
+
+const (
+	// reminderBatchSize limits how many users one reminder run loads at once.
+	reminderBatchSize = 100
+	// reminderTimeLayout is the format of User.ReminderTime.
+	reminderTimeLayout = "15:04"
+
+	NotificationJournalReminder = "journal_reminder"
+)
+
+// ReminderService nudges users who have not written today's entry by
+// their chosen time of day.
+type ReminderService struct {
+	userRepo    *repositories.UserRepository
+	journalRepo *repositories.JournalRepository
+	dispatcher  *notify.Dispatcher
+	now         func() time.Time
+}
+
+func NewReminderService(
+	userRepo *repositories.UserRepository,
+	journalRepo *repositories.JournalRepository,
+	dispatcher *notify.Dispatcher,
+) *ReminderService {
+	return &ReminderService{
+		userRepo:    userRepo,
+		journalRepo: journalRepo,
+		dispatcher:  dispatcher,
+		now:         time.Now,
+	}
+}
+
+func (s *ReminderService) GetSettings(userID uint) (*models.ReminderSettingsResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ReminderSettingsResponse{
+		Enabled:    user.ReminderEnabled,
+		Time:       user.ReminderTime,
+		Channels:   reminderChannels(user.ReminderChannels),
+		WebhookURL: user.ReminderWebhookURL,
+
+		DigestEnabled: user.MemoryDigestEnabled,
+		DigestTime:    user.MemoryDigestTime,
+	}, nil
+}
+
+func (s *ReminderService) UpdateSettings(userID uint, req models.ReminderSettingsRequest) (*models.ReminderSettingsResponse, error) {
+	current, err := s.GetSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if req.Enabled != nil {
+		fields["reminder_enabled"] = *req.Enabled
+	}
+	if req.Time != nil {
+		fields["reminder_time"] = *req.Time
+	}
+	if req.DigestEnabled != nil {
+		fields["memory_digest_enabled"] = *req.DigestEnabled
+	}
+	if req.DigestTime != nil {
+		fields["memory_digest_time"] = *req.DigestTime
+	}
+	channels := current.Channels
+	if req.Channels != nil {
+		channels = uniqueStrings(*req.Channels)
+		fields["reminder_channels"] = strings.Join(channels, ",")
+	}
+	webhookURL := current.WebhookURL
+	if req.WebhookURL != nil {
+		webhookURL = *req.WebhookURL
+		if webhookURL != "" {
+			if err := safehttp.CheckURL(webhookURL); err != nil {
+				return nil, ErrUnsafeWebhookURL
+			}
+		}
+		fields["reminder_webhook_url"] = webhookURL
+	}
+
+	for _, channel := range channels {
+		if channel == notify.ChannelWebhook && webhookURL == "" {
+			return nil, ErrWebhookURLRequired
+		}
+	}
+
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateSettings(userID, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetSettings(userID)
+}
+
+// SendDueReminders reminds every user whose reminder time has passed in
+// their time zone and who has not published an entry for that day yet.
+// Each user is reminded at most once per local day. It returns how many
+// reminders were sent; delivery failures are joined into the error
+// without stopping the run.
+func (s *ReminderService) SendDueReminders(ctx context.Context) (int, error) {
+	var afterID uint
+	var errs []error
+	sent := 0
+
+	for {
+		users, err := s.userRepo.FindWithRemindersEnabled(afterID, reminderBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return sent, err
+			}
+			afterID = user.ID
+
+			reminded, err := s.remind(ctx, user)
+			if reminded {
+				sent++
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if len(users) < reminderBatchSize {
+			return sent, errors.Join(errs...)
+		}
+	}
+}
+
+func (s *ReminderService) remind(ctx context.Context, user models.User) (bool, error) {
+	location := userLocation(user.TimeZone)
+	now := s.now().In(location)
+	today := dayStart(now, location)
+
+	at, err := time.ParseInLocation(reminderTimeLayout, user.ReminderTime, location)
+	if err != nil {
+		return false, nil
+	}
+	due := time.Date(today.Year(), today.Month(), today.Day(), at.Hour(), at.Minute(), 0, 0, location)
+	if now.Before(due) {
+		return false, nil
+	}
+	if user.LastRemindedOn != nil && !user.LastRemindedOn.Before(localDate(today)) {
+		return false, nil
+	}
+
+	written, err := s.journalRepo.HasPublishedEntry(user.ID, today, today.AddDate(0, 0, 1))
+	if err != nil || written {
+		return false, err
+	}
+
+	// Claim the day before sending so a failed delivery is not retried
+	// every minute until midnight
+	claimed, err := s.userRepo.MarkReminded(user.ID, localDate(today))
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	notification := notify.Notification{
+		Recipient: notify.Recipient{
+			UserID:     user.ID,
+			Email:      user.Email,
+			Name:       user.FullName,
+			WebhookURL: user.ReminderWebhookURL,
+		},
+		Kind:  NotificationJournalReminder,
+		Title: "Time to write today's story",
+		Body:  "You haven't written your journal entry for today yet. Take a few minutes to remember your day.",
+	}
+
+	return true, s.dispatcher.Send(ctx, reminderChannels(user.ReminderChannels), notification)
+}
+
+// localDate returns the calendar day of t as midnight UTC, the way date
+// columns are read back.
+func localDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func reminderChannels(value string) []string {
+	channels := []string{}
+	for _, channel := range strings.Split(value, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
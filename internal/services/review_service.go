@@ -0,0 +1,386 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+)
+
+const (
+	// reviewBatchSize limits how many users one generation run loads.
+	reviewBatchSize = 100
+	// reviewTopCount is how many tags and words a report lists.
+	reviewTopCount       = 10
+	reviewHighlightCount = 3
+	reviewExcerptLength  = 200
+	// reviewListLimit caps how many past reviews are listed.
+	reviewListLimit = 52
+)
+
+// reviewStopWords are left out of the most used words.
+var reviewStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "any": true, "can": true, "had": true, "her": true,
+	"was": true, "one": true, "our": true, "out": true, "day": true, "get": true,
+	"has": true, "him": true, "his": true, "how": true, "its": true, "may": true,
+	"new": true, "now": true, "old": true, "see": true, "two": true, "way": true,
+	"who": true, "did": true, "she": true, "too": true, "use": true, "that": true,
+	"with": true, "have": true, "this": true, "will": true, "your": true, "from": true,
+	"they": true, "been": true, "were": true, "what": true, "when": true, "then": true,
+	"them": true, "than": true, "there": true, "their": true, "which": true, "would": true,
+	"about": true, "after": true, "could": true, "into": true, "just": true, "like": true,
+	"some": true, "very": true, "also": true, "more": true, "much": true, "only": true,
+	"today": true, "i'm": true, "it's": true, "don't": true, "didn't": true, "because": true,
+}
+
+// ReviewService builds weekly, monthly and yearly review reports. Reports
+// of ended periods are generated once, by a background job or on first
+// request, and stored; the current period is regenerated on request.
+type ReviewService struct {
+	reviewRepo  *repositories.ReviewRepository
+	journalRepo *repositories.JournalRepository
+	userRepo    *repositories.UserRepository
+	now         func() time.Time
+}
+
+func NewReviewService(
+	reviewRepo *repositories.ReviewRepository,
+	journalRepo *repositories.JournalRepository,
+	userRepo *repositories.UserRepository,
+) *ReviewService {
+	return &ReviewService{
+		reviewRepo:  reviewRepo,
+		journalRepo: journalRepo,
+		userRepo:    userRepo,
+		now:         time.Now,
+	}
+}
+
+// Get returns the review of the period containing day, or of the last
+// ended period when day is zero.
+func (s *ReviewService) Get(userID uint, period string, day time.Time) (*models.ReviewResponse, error) {
+	if !validReviewPeriod(period) {
+		return nil, ErrInvalidReviewPeriod
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	location := userLocation(user.TimeZone)
+	today := dayStart(s.now().In(location), location)
+	if day.IsZero() {
+		start, _ := periodBounds(period, today)
+		day = start.AddDate(0, 0, -1)
+	}
+	start, end := periodBounds(period, dayStart(day, location))
+	if !start.Before(today.AddDate(0, 0, 1)) {
+		return nil, ErrInvalidDateRange
+	}
+
+	review, err := s.reviewRepo.Find(userID, period, localDate(start))
+	if err != nil && !errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, err
+	}
+	if review == nil || !review.Final {
+		review, err = s.generate(userID, period, start, end, !end.After(today))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return toReviewResponse(*review)
+}
+
+// List returns the stored reviews of a period, newest first.
+func (s *ReviewService) List(userID uint, period string) ([]models.ReviewSummary, error) {
+	if !validReviewPeriod(period) {
+		return nil, ErrInvalidReviewPeriod
+	}
+
+	reviews, err := s.reviewRepo.FindByUserID(userID, period, reviewListLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.ReviewSummary, 0, len(reviews))
+	for _, review := range reviews {
+		var report models.ReviewReport
+		if err := json.Unmarshal(review.Report, &report); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, models.ReviewSummary{
+			ID:          review.ID,
+			Period:      review.Period,
+			StartDate:   review.StartDate.Format("2006-01-02"),
+			EndDate:     review.EndDate.Format("2006-01-02"),
+			Final:       review.Final,
+			EntryCount:  report.EntryCount,
+			GeneratedAt: review.GeneratedAt,
+		})
+	}
+	return summaries, nil
+}
+
+// GenerateDue stores the final review of the last ended week, month and
+// year for every user who wrote in them. It returns how many reviews were
+// generated.
+func (s *ReviewService) GenerateDue(ctx context.Context) (int, error) {
+	var afterID uint
+	generated := 0
+
+	for {
+		users, err := s.userRepo.FindPage(afterID, reviewBatchSize)
+		if err != nil {
+			return generated, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return generated, err
+			}
+			afterID = user.ID
+
+			location := userLocation(user.TimeZone)
+			today := dayStart(s.now().In(location), location)
+
+			for _, period := range []string{models.ReviewPeriodWeek, models.ReviewPeriodMonth, models.ReviewPeriodYear} {
+				current, _ := periodBounds(period, today)
+				start, end := periodBounds(period, current.AddDate(0, 0, -1))
+
+				existing, err := s.reviewRepo.Find(user.ID, period, localDate(start))
+				if err != nil && !errors.Is(err, repositories.ErrRecordNotFound) {
+					return generated, err
+				}
+				if existing != nil && existing.Final {
+					continue
+				}
+
+				written, err := s.journalRepo.HasPublishedEntry(user.ID, start, end)
+				if err != nil {
+					return generated, err
+				}
+				if !written {
+					continue
+				}
+
+				if _, err := s.generate(user.ID, period, start, end, true); err != nil {
+					return generated, err
+				}
+				generated++
+			}
+		}
+
+		if len(users) < reviewBatchSize {
+			return generated, nil
+		}
+	}
+}
+
+func (s *ReviewService) generate(userID uint, period string, start, end time.Time, final bool) (*models.Review, error) {
+	entries, err := s.journalRepo.FindPublishedBetween(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	days := int(end.Sub(start).Hours()/24 + 0.5)
+	report, err := json.Marshal(buildReviewReport(entries, days))
+	if err != nil {
+		return nil, err
+	}
+
+	review := &models.Review{
+		UserID:      userID,
+		Period:      period,
+		StartDate:   localDate(start),
+		EndDate:     localDate(end.AddDate(0, 0, -1)),
+		Final:       final,
+		Report:      models.JSONB(report),
+		GeneratedAt: s.now(),
+	}
+	if err := s.reviewRepo.Save(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+func buildReviewReport(entries []models.JournalEntry, days int) models.ReviewReport {
+	report := models.ReviewReport{
+		EntryCount:    len(entries),
+		DaysInPeriod:  days,
+		MoodBreakdown: []models.MoodCount{},
+		TopTags:       []models.WordCount{},
+		TopWords:      []models.WordCount{},
+		Highlights:    []models.ReviewEntryRef{},
+	}
+
+	journaled := make(map[string]bool)
+	moods := make(map[enums.MoodType]int)
+	tags := make(map[string]int)
+	words := make(map[string]int)
+
+	for _, entry := range entries {
+		journaled[entry.Date.Format("2006-01-02")] = true
+		moods[entry.Mood]++
+
+		for _, task := range entry.DailyTasks {
+			report.Tasks.Total++
+			if task.Status {
+				report.Tasks.Completed++
+			}
+		}
+
+		for _, word := range tokenize(entry.ThisDayDescription + " " + entry.DailyReflection) {
+			if strings.HasPrefix(word, "#") {
+				if len(word) > 1 {
+					tags[word[1:]]++
+				}
+				continue
+			}
+			if len([]rune(word)) >= 3 && !reviewStopWords[word] {
+				words[word]++
+			}
+		}
+
+		reflectionWords := len(strings.Fields(entry.DailyReflection))
+		if reflectionWords > 0 && (report.LongestReflection == nil || reflectionWords > report.LongestReflection.WordCount) {
+			ref := reviewEntryRef(entry, entry.DailyReflection)
+			report.LongestReflection = &ref
+		}
+	}
+
+	report.DaysJournaled = len(journaled)
+	if report.Tasks.Total > 0 {
+		report.Tasks.CompletionRate = float64(report.Tasks.Completed) / float64(report.Tasks.Total)
+	}
+
+	for mood, count := range moods {
+		report.MoodBreakdown = append(report.MoodBreakdown, models.MoodCount{Mood: mood, Count: count})
+	}
+	sort.Slice(report.MoodBreakdown, func(i, j int) bool {
+		a, b := report.MoodBreakdown[i], report.MoodBreakdown[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Mood < b.Mood
+	})
+
+	report.TopTags = topWords(tags, reviewTopCount)
+	report.TopWords = topWords(words, reviewTopCount)
+	report.Highlights = highlights(entries, reviewHighlightCount)
+
+	return report
+}
+
+// highlights picks the days with a positive mood and the most written,
+// falling back to the longest entries.
+func highlights(entries []models.JournalEntry, count int) []models.ReviewEntryRef {
+	ranked := make([]models.JournalEntry, len(entries))
+	copy(ranked, entries)
+
+	positive := func(mood enums.MoodType) bool {
+		return mood == enums.Mood.Happy || mood == enums.Mood.Energized || mood == enums.Mood.Calm
+	}
+	length := func(entry models.JournalEntry) int {
+		return len(strings.Fields(entry.ThisDayDescription)) + len(strings.Fields(entry.DailyReflection))
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if positive(ranked[i].Mood) != positive(ranked[j].Mood) {
+			return positive(ranked[i].Mood)
+		}
+		return length(ranked[i]) > length(ranked[j])
+	})
+
+	refs := []models.ReviewEntryRef{}
+	for _, entry := range ranked {
+		if len(refs) == count {
+			break
+		}
+		refs = append(refs, reviewEntryRef(entry, entry.ThisDayDescription))
+	}
+	return refs
+}
+
+func reviewEntryRef(entry models.JournalEntry, text string) models.ReviewEntryRef {
+	return models.ReviewEntryRef{
+		EntryID:   entry.ID,
+		Date:      entry.Date,
+		Mood:      entry.Mood,
+		WordCount: len(strings.Fields(text)),
+		Excerpt:   excerpt(text, reviewExcerptLength),
+	}
+}
+
+// tokenize lower-cases text and splits it into words, keeping a leading
+// # for hashtags and apostrophes inside words.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '#' && r != '\''
+	})
+}
+
+func topWords(counts map[string]int, limit int) []models.WordCount {
+	words := make([]models.WordCount, 0, len(counts))
+	for word, count := range counts {
+		words = append(words, models.WordCount{Word: strings.Trim(word, "'"), Count: count})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if words[i].Count != words[j].Count {
+			return words[i].Count > words[j].Count
+		}
+		return words[i].Word < words[j].Word
+	})
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return words
+}
+
+// periodBounds returns the local [start, end) of the week (starting
+// Monday), month or year containing day.
+func periodBounds(period string, day time.Time) (time.Time, time.Time) {
+	location := day.Location()
+	switch period {
+	case models.ReviewPeriodWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		start := time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 0, 7)
+	case models.ReviewPeriodMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(day.Year(), 1, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(1, 0, 0)
+	}
+}
+
+func validReviewPeriod(period string) bool {
+	return period == models.ReviewPeriodWeek || period == models.ReviewPeriodMonth || period == models.ReviewPeriodYear
+}
+
+func toReviewResponse(review models.Review) (*models.ReviewResponse, error) {
+	response := &models.ReviewResponse{
+		ID:          review.ID,
+		Period:      review.Period,
+		StartDate:   review.StartDate.Format("2006-01-02"),
+		EndDate:     review.EndDate.Format("2006-01-02"),
+		Final:       review.Final,
+		GeneratedAt: review.GeneratedAt,
+	}
+	if err := json.Unmarshal(review.Report, &response.Report); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
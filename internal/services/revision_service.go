@@ -0,0 +1,299 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+// RevisionRetention controls how many revisions are kept per entry. Zero
+// values mean "keep forever".
+type RevisionRetention struct {
+	MaxPerEntry int
+	MaxAge      time.Duration
+}
+
+type RevisionService struct {
+	revisionRepo   *repositories.RevisionRepository
+	journalRepo    *repositories.JournalRepository
+	retention      RevisionRetention
+	webhookService *WebhookService
+}
+
+func NewRevisionService(
+	revisionRepo *repositories.RevisionRepository,
+	journalRepo *repositories.JournalRepository,
+	retention RevisionRetention,
+	webhookService *WebhookService,
+) *RevisionService {
+	return &RevisionService{
+		revisionRepo:   revisionRepo,
+		journalRepo:    journalRepo,
+		retention:      retention,
+		webhookService: webhookService,
+	}
+}
+
+// withTx returns a copy of the service whose repositories run in tx.
+func (s *RevisionService) withTx(tx *gorm.DB) *RevisionService {
+	return &RevisionService{
+		revisionRepo:   s.revisionRepo.WithTx(tx),
+		journalRepo:    s.journalRepo.WithTx(tx),
+		retention:      s.retention,
+		webhookService: s.webhookService.withTx(tx),
+	}
+}
+
+func (s *RevisionService) List(userID, journalID uint) ([]models.RevisionSummary, error) {
+	if _, err := s.findEntry(journalID, userID); err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.FindByJournalEntryID(journalID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.RevisionSummary, 0, len(revisions))
+	for _, revision := range revisions {
+		summaries = append(summaries, toRevisionSummary(revision))
+	}
+	return summaries, nil
+}
+
+func (s *RevisionService) Get(userID, journalID uint, revision int) (*models.RevisionResponse, error) {
+	if _, err := s.findEntry(journalID, userID); err != nil {
+		return nil, err
+	}
+
+	rev, snapshot, err := s.loadSnapshot(journalID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RevisionResponse{
+		RevisionSummary: toRevisionSummary(*rev),
+		Snapshot:        *snapshot,
+	}, nil
+}
+
+// Diff compares two revisions of the same entry. Text fields are diffed
+// word by word and tasks are matched by their ID.
+func (s *RevisionService) Diff(userID, journalID uint, from, to int) (*models.RevisionDiff, error) {
+	if _, err := s.findEntry(journalID, userID); err != nil {
+		return nil, err
+	}
+
+	_, fromSnapshot, err := s.loadSnapshot(journalID, from)
+	if err != nil {
+		return nil, err
+	}
+	_, toSnapshot, err := s.loadSnapshot(journalID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.RevisionDiff{
+		FromRevision:       from,
+		ToRevision:         to,
+		ThisDayDescription: toTextDiffOps(helpers.DiffWords(fromSnapshot.ThisDayDescription, toSnapshot.ThisDayDescription)),
+		DailyReflection:    toTextDiffOps(helpers.DiffWords(fromSnapshot.DailyReflection, toSnapshot.DailyReflection)),
+		DailyTasks:         diffTasks(fromSnapshot.DailyTasks, toSnapshot.DailyTasks),
+	}
+
+	if fromSnapshot.Mood != toSnapshot.Mood {
+		diff.Mood = &models.MoodChange{From: fromSnapshot.Mood, To: toSnapshot.Mood}
+	}
+
+	return diff, nil
+}
+
+// Restore brings an entry back to the content of an older revision. The
+// restore itself is recorded as a new revision, so it can be undone.
+func (s *RevisionService) Restore(userID, journalID uint, revision int) (*models.JournalEntry, error) {
+	var restored *models.JournalEntry
+	err := s.journalRepo.Transaction(func(tx *gorm.DB) error {
+		var err error
+		restored, err = s.withTx(tx).restore(userID, journalID, revision)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (s *RevisionService) restore(userID, journalID uint, revision int) (*models.JournalEntry, error) {
+	entry, err := s.findEntry(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, snapshot, err := s.loadSnapshot(journalID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Mood = snapshot.Mood
+	entry.ThisDayDescription = snapshot.ThisDayDescription
+	entry.DailyReflection = snapshot.DailyReflection
+	entry.DailyTasks = snapshotTasks(snapshot.DailyTasks)
+	entry.TemplateID = snapshot.TemplateID
+	entry.TemplateVersion = snapshot.TemplateVersion
+	entry.TemplateValues = snapshot.TemplateValues
+
+	if err := s.journalRepo.Update(entry, 0, &revision); err != nil {
+		return nil, err
+	}
+
+	if err := s.ApplyRetention(journalID); err != nil {
+		return nil, err
+	}
+
+	restored, err := s.findEntry(journalID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.webhookService.Publish(userID, models.WebhookEventJournalUpdated, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ApplyRetention drops revisions that fall outside the configured policy.
+func (s *RevisionService) ApplyRetention(journalID uint) error {
+	var cutoff time.Time
+	if s.retention.MaxAge > 0 {
+		cutoff = time.Now().Add(-s.retention.MaxAge)
+	}
+	return s.revisionRepo.Prune(journalID, s.retention.MaxPerEntry, cutoff)
+}
+
+func (s *RevisionService) findEntry(journalID, userID uint) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindByIDAndUserID(journalID, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	return entry, err
+}
+
+func (s *RevisionService) loadSnapshot(journalID uint, revision int) (*models.JournalRevision, *models.EntrySnapshot, error) {
+	rev, err := s.revisionRepo.FindByRevision(journalID, revision)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var snapshot models.EntrySnapshot
+	if err := json.Unmarshal(rev.Snapshot, &snapshot); err != nil {
+		return nil, nil, err
+	}
+
+	return rev, &snapshot, nil
+}
+
+func toRevisionSummary(revision models.JournalRevision) models.RevisionSummary {
+	return models.RevisionSummary{
+		Revision:             revision.Revision,
+		RestoredFromRevision: revision.RestoredFromRevision,
+		CreatedAt:            revision.CreatedAt,
+	}
+}
+
+func toTextDiffOps(ops []helpers.DiffOp) []models.TextDiffOp {
+	result := make([]models.TextDiffOp, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, models.TextDiffOp{Op: op.Op, Text: op.Text})
+	}
+	return result
+}
+
+func diffTasks(from, to []models.TaskSnapshot) []models.TaskDiffItem {
+	fromByID := make(map[uint]models.TaskSnapshot, len(from))
+	for _, task := range from {
+		fromByID[task.ID] = task
+	}
+
+	items := []models.TaskDiffItem{}
+	seen := make(map[uint]bool, len(to))
+
+	for _, task := range to {
+		task := task
+		seen[task.ID] = true
+
+		previous, ok := fromByID[task.ID]
+		switch {
+		case !ok:
+			items = append(items, models.TaskDiffItem{Change: "added", To: &task})
+		case !sameTask(previous, task):
+			items = append(items, models.TaskDiffItem{Change: "modified", From: &previous, To: &task})
+		}
+	}
+
+	for _, task := range from {
+		task := task
+		if !seen[task.ID] {
+			items = append(items, models.TaskDiffItem{Change: "removed", From: &task})
+		}
+	}
+
+	return items
+}
+
+func sameTask(a, b models.TaskSnapshot) bool {
+	if a.Task != b.Task || a.Status != b.Status || len(a.SubTasks) != len(b.SubTasks) {
+		return false
+	}
+	if a.Priority != b.Priority || a.Notes != b.Notes || !sameDueTime(a.DueTime, b.DueTime) {
+		return false
+	}
+	for i := range a.SubTasks {
+		if a.SubTasks[i] != b.SubTasks[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameDueTime(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// snapshotTasks turns snapshot tasks back into models. IDs are kept so
+// tasks that still exist are updated rather than recreated.
+func snapshotTasks(snapshots []models.TaskSnapshot) []models.DailyTask {
+	tasks := make([]models.DailyTask, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		task := models.DailyTask{
+			Task:     snapshot.Task,
+			Status:   snapshot.Status,
+			Priority: snapshot.Priority,
+			DueTime:  snapshot.DueTime,
+			Notes:    snapshot.Notes,
+		}
+		task.ID = snapshot.ID
+
+		for _, subSnapshot := range snapshot.SubTasks {
+			subTask := models.DailySubTask{
+				SubTask: subSnapshot.SubTask,
+				Status:  subSnapshot.Status,
+			}
+			subTask.ID = subSnapshot.ID
+			task.SubTasks = append(task.SubTasks, subTask)
+		}
+
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
@@ -0,0 +1,394 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/recurrence"
+	"gorm.io/gorm"
+)
+
+// maxOccurrenceRange limits how many days an occurrence listing may span.
+const maxOccurrenceRange = 366
+
+type RoutineService struct {
+	routineRepo *repositories.RoutineRepository
+	journalRepo *repositories.JournalRepository
+}
+
+func NewRoutineService(routineRepo *repositories.RoutineRepository, journalRepo *repositories.JournalRepository) *RoutineService {
+	return &RoutineService{
+		routineRepo: routineRepo,
+		journalRepo: journalRepo,
+	}
+}
+
+// withTx returns a copy of the service whose repositories run in tx.
+func (s *RoutineService) withTx(tx *gorm.DB, journalRepo *repositories.JournalRepository) *RoutineService {
+	return &RoutineService{
+		routineRepo: s.routineRepo.WithTx(tx),
+		journalRepo: journalRepo,
+	}
+}
+
+func (s *RoutineService) Create(userID uint, req models.RoutineRequest) (*models.RoutineResponse, error) {
+	routine := &models.Routine{UserID: userID, Active: true}
+	if err := applyRoutineRequest(routine, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.routineRepo.Create(routine); err != nil {
+		return nil, err
+	}
+	return toRoutineResponse(routine), nil
+}
+
+func (s *RoutineService) List(userID uint) ([]models.RoutineResponse, error) {
+	routines, err := s.routineRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.RoutineResponse, 0, len(routines))
+	for i := range routines {
+		response = append(response, *toRoutineResponse(&routines[i]))
+	}
+	return response, nil
+}
+
+func (s *RoutineService) Get(userID, id uint) (*models.RoutineResponse, error) {
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toRoutineResponse(routine), nil
+}
+
+// Update changes a routine from now on. Tasks already copied into entries
+// are not touched.
+func (s *RoutineService) Update(userID, id uint, req models.RoutineRequest) (*models.RoutineResponse, error) {
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyRoutineRequest(routine, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.routineRepo.Update(routine); err != nil {
+		return nil, err
+	}
+	return s.Get(userID, id)
+}
+
+func (s *RoutineService) Delete(userID, id uint) error {
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.routineRepo.Delete(routine)
+}
+
+// Occurrences lists the dates a routine falls on between from and to,
+// with skips and edits applied.
+func (s *RoutineService) Occurrences(userID, id uint, from, to time.Time) ([]models.RoutineOccurrenceResponse, error) {
+	from, to = recurrence.Date(from), recurrence.Date(to)
+	if to.Before(from) || to.Sub(from) > maxOccurrenceRange*24*time.Hour {
+		return nil, ErrInvalidDateRange
+	}
+
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := recurrence.Parse(routine.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := s.routineRepo.FindExceptionsBetween(routine.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	exceptionByDate := make(map[time.Time]*models.RoutineException, len(exceptions))
+	for i := range exceptions {
+		exceptionByDate[recurrence.Date(exceptions[i].Date)] = &exceptions[i]
+	}
+
+	occurrences := []models.RoutineOccurrenceResponse{}
+	for _, date := range rule.Between(routine.StartDate, from, to) {
+		if routine.EndDate != nil && date.After(recurrence.Date(*routine.EndDate)) {
+			break
+		}
+
+		occurrence := models.RoutineOccurrenceResponse{Date: date}
+		task := routineOccurrenceTask(routine, exceptionByDate[date])
+		if task == nil {
+			occurrence.Skip = true
+			occurrence.Modified = true
+		} else {
+			occurrence.Task = task.Task
+			occurrence.SubTasks = make([]string, 0, len(task.SubTasks))
+			for _, subTask := range task.SubTasks {
+				occurrence.SubTasks = append(occurrence.SubTasks, subTask.SubTask)
+			}
+			occurrence.Modified = exceptionByDate[date] != nil
+		}
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return occurrences, nil
+}
+
+// SetOccurrence skips or edits a single occurrence. It only affects
+// entries whose routine tasks have not been created yet.
+func (s *RoutineService) SetOccurrence(userID, id uint, date time.Time, req models.RoutineOccurrenceRequest) error {
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return err
+	}
+
+	date = recurrence.Date(date)
+	if !routineOccursOn(routine, date) {
+		return ErrNotAnOccurrence
+	}
+
+	exception := &models.RoutineException{
+		RoutineID: routine.ID,
+		Date:      date,
+		Skip:      req.Skip,
+		Task:      req.Task,
+	}
+	if req.SubTasks != nil {
+		subTasks, err := json.Marshal(*req.SubTasks)
+		if err != nil {
+			return err
+		}
+		exception.SubTasks = models.JSONB(subTasks)
+	}
+
+	return s.routineRepo.SaveException(exception)
+}
+
+// ClearOccurrence undoes a skip or edit.
+func (s *RoutineService) ClearOccurrence(userID, id uint, date time.Time) error {
+	routine, err := s.find(id, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.routineRepo.DeleteException(routine.ID, recurrence.Date(date))
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return ErrNotAnOccurrence
+	}
+	return err
+}
+
+// TasksFor builds the tasks a user's routines contribute to the entry for
+// date, ready to be stored.
+func (s *RoutineService) TasksFor(userID uint, date time.Time) ([]models.DailyTask, error) {
+	date = recurrence.Date(date)
+
+	candidates, err := s.routineRepo.FindDueCandidates(userID, date)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*models.Routine
+	var dueIDs []uint
+	for i := range candidates {
+		if routineOccursOn(&candidates[i], date) {
+			due = append(due, &candidates[i])
+			dueIDs = append(dueIDs, candidates[i].ID)
+		}
+	}
+	if len(due) == 0 {
+		return nil, nil
+	}
+
+	exceptions, err := s.routineRepo.FindExceptionsOn(dueIDs, date)
+	if err != nil {
+		return nil, err
+	}
+	exceptionByRoutine := make(map[uint]*models.RoutineException, len(exceptions))
+	for i := range exceptions {
+		exceptionByRoutine[exceptions[i].RoutineID] = &exceptions[i]
+	}
+
+	tasks := make([]models.DailyTask, 0, len(due))
+	for _, routine := range due {
+		if task := routineOccurrenceTask(routine, exceptionByRoutine[routine.ID]); task != nil {
+			tasks = append(tasks, *task)
+		}
+	}
+	return tasks, nil
+}
+
+// Materialize adds the routine tasks due on an entry's date that it does
+// not have yet. It reports whether the entry changed.
+func (s *RoutineService) Materialize(entry *models.JournalEntry) (bool, error) {
+	tasks, err := s.TasksFor(entry.UserID, entry.Date)
+	if err != nil || len(tasks) == 0 {
+		return false, err
+	}
+
+	present := make(map[uint]bool, len(entry.DailyTasks))
+	for _, task := range entry.DailyTasks {
+		if task.RoutineID != nil {
+			present[*task.RoutineID] = true
+		}
+	}
+
+	missing := tasks[:0]
+	for _, task := range tasks {
+		if !present[*task.RoutineID] {
+			missing = append(missing, task)
+		}
+	}
+	if len(missing) == 0 {
+		return false, nil
+	}
+
+	added, err := s.journalRepo.AddTasks(entry, missing)
+	return added > 0, err
+}
+
+func (s *RoutineService) find(id, userID uint) (*models.Routine, error) {
+	routine, err := s.routineRepo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrRoutineNotFound
+	}
+	return routine, err
+}
+
+func applyRoutineRequest(routine *models.Routine, req models.RoutineRequest) error {
+	rule, err := recurrenceRule(req.Recurrence)
+	if err != nil {
+		return err
+	}
+
+	startDate := recurrence.Date(time.Now())
+	if req.StartDate != nil {
+		startDate = recurrence.Date(*req.StartDate)
+	} else if !routine.StartDate.IsZero() {
+		startDate = recurrence.Date(routine.StartDate)
+	}
+
+	var endDate *time.Time
+	if req.EndDate != nil {
+		date := recurrence.Date(*req.EndDate)
+		if date.Before(startDate) {
+			return ErrInvalidDateRange
+		}
+		endDate = &date
+	}
+
+	routine.Task = req.Task
+	routine.Recurrence = rule.String()
+	routine.StartDate = startDate
+	routine.EndDate = endDate
+	if req.Active != nil {
+		routine.Active = *req.Active
+	}
+
+	routine.SubTasks = make([]models.RoutineSubTask, 0, len(req.SubTasks))
+	for i, subTask := range req.SubTasks {
+		routine.SubTasks = append(routine.SubTasks, models.RoutineSubTask{
+			SubTask:  subTask,
+			Position: i,
+		})
+	}
+	return nil
+}
+
+func recurrenceRule(req models.RecurrenceRequest) (recurrence.Rule, error) {
+	switch req.Type {
+	case "daily":
+		return recurrence.EveryDay(), nil
+	case "weekdays":
+		return recurrence.Weekdays(), nil
+	case "interval":
+		if req.Interval < 1 {
+			return recurrence.Rule{}, fmt.Errorf("%w: interval is required", ErrInvalidRecurrence)
+		}
+		return recurrence.EveryNDays(req.Interval), nil
+	case "rrule":
+		rule, err := recurrence.Parse(req.RRule)
+		if err != nil {
+			return rule, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+		}
+		return rule, nil
+	}
+	return recurrence.Rule{}, ErrInvalidRecurrence
+}
+
+func routineOccursOn(routine *models.Routine, date time.Time) bool {
+	if routine.EndDate != nil && date.After(recurrence.Date(*routine.EndDate)) {
+		return false
+	}
+
+	rule, err := recurrence.Parse(routine.Recurrence)
+	if err != nil {
+		return false
+	}
+	return rule.Occurs(routine.StartDate, date)
+}
+
+// routineOccurrenceTask builds the task for one occurrence, or nil when
+// the occurrence is skipped.
+func routineOccurrenceTask(routine *models.Routine, exception *models.RoutineException) *models.DailyTask {
+	task := &models.DailyTask{
+		RoutineID: &routine.ID,
+		Task:      routine.Task,
+	}
+
+	subTasks := make([]string, 0, len(routine.SubTasks))
+	for _, subTask := range routine.SubTasks {
+		subTasks = append(subTasks, subTask.SubTask)
+	}
+
+	if exception != nil {
+		if exception.Skip {
+			return nil
+		}
+		if exception.Task != nil {
+			task.Task = *exception.Task
+		}
+		if len(exception.SubTasks) > 0 {
+			var override []string
+			if err := json.Unmarshal(exception.SubTasks, &override); err == nil {
+				subTasks = override
+			}
+		}
+	}
+
+	for _, subTask := range subTasks {
+		task.SubTasks = append(task.SubTasks, models.DailySubTask{SubTask: subTask})
+	}
+	return task
+}
+
+func toRoutineResponse(routine *models.Routine) *models.RoutineResponse {
+	subTasks := make([]string, 0, len(routine.SubTasks))
+	for _, subTask := range routine.SubTasks {
+		subTasks = append(subTasks, subTask.SubTask)
+	}
+
+	return &models.RoutineResponse{
+		ID:         routine.ID,
+		Task:       routine.Task,
+		SubTasks:   subTasks,
+		Recurrence: routine.Recurrence,
+		StartDate:  routine.StartDate,
+		EndDate:    routine.EndDate,
+		Active:     routine.Active,
+		CreatedAt:  routine.CreatedAt,
+		UpdatedAt:  routine.UpdatedAt,
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	securityEventsPageSize    = 50
+	securityEventsMaxPageSize = 200
+)
+
+// SecurityEventService records security-relevant account events, such as
+// logins and admin actions, with the IP, user agent and request ID they
+// came from, and lets users review their own events and admins all of
+// them.
+type SecurityEventService struct {
+	eventRepo *repositories.SecurityEventRepository
+}
+
+func NewSecurityEventService(eventRepo *repositories.SecurityEventRepository) *SecurityEventService {
+	return &SecurityEventService{eventRepo: eventRepo}
+}
+
+// withTx returns a copy of the service that records events in tx, so they
+// are only kept if the change they describe commits.
+func (s *SecurityEventService) withTx(tx *gorm.DB) *SecurityEventService {
+	return &SecurityEventService{eventRepo: s.eventRepo.WithTx(tx)}
+}
+
+// Record appends the event, stamped with the request it came from.
+func (s *SecurityEventService) Record(event models.SecurityEvent, info models.RequestInfo) error {
+	event.IP = clip(info.IP, 45)
+	event.UserAgent = clip(info.UserAgent, 512)
+	event.RequestID = clip(info.RequestID, 100)
+	return s.eventRepo.Create(&event)
+}
+
+// Mine pages through the user's own events, newest first. Which admin
+// acted on the account is left out.
+func (s *SecurityEventService) Mine(userID uint, limit, offset int) (*models.SecurityEventsResponse, error) {
+	response, err := s.find(models.SecurityEventQuery{UserID: &userID}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range response.Events {
+		response.Events[i].UserID = nil
+		response.Events[i].Email = ""
+		response.Events[i].ActorID = nil
+	}
+	return response, nil
+}
+
+// All pages through every user's events, newest first, for admins.
+func (s *SecurityEventService) All(query models.SecurityEventQuery, limit, offset int) (*models.SecurityEventsResponse, error) {
+	return s.find(query, limit, offset)
+}
+
+func (s *SecurityEventService) find(query models.SecurityEventQuery, limit, offset int) (*models.SecurityEventsResponse, error) {
+	if limit <= 0 {
+		limit = securityEventsPageSize
+	}
+	if limit > securityEventsMaxPageSize {
+		limit = securityEventsMaxPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	events, err := s.eventRepo.Find(query, limit+1, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.SecurityEventsResponse{Events: []models.SecurityEventResponse{}}
+	if len(events) > limit {
+		events = events[:limit]
+		response.HasMore = true
+	}
+	for _, event := range events {
+		response.Events = append(response.Events, models.SecurityEventResponse{
+			ID:        event.ID,
+			Type:      event.Type,
+			UserID:    event.UserID,
+			Email:     event.Email,
+			ActorID:   event.ActorID,
+			Detail:    event.Detail,
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			RequestID: event.RequestID,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// clip cuts value to at most length runes so it fits its column.
+func clip(value string, length int) string {
+	if runes := []rune(value); len(runes) > length {
+		return string(runes[:length])
+	}
+	return value
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/models/enums"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// shareAccessLogLimit caps how many accesses the owner's log returns.
+const shareAccessLogLimit = 200
+
+// ShareConfig controls share links. BaseURL, when set, is used to build
+// the link returned on creation.
+type ShareConfig struct {
+	BaseURL             string
+	DefaultExpiry       time.Duration
+	MaxExpiry           time.Duration
+	MaxPasswordAttempts int           // Wrong passwords allowed per PasswordWindow
+	PasswordWindow      time.Duration // Window for MaxPasswordAttempts
+}
+
+// ShareService manages public read-only links to entries and notebooks.
+type ShareService struct {
+	shareRepo    *repositories.ShareLinkRepository
+	journalRepo  *repositories.JournalRepository
+	notebookRepo *repositories.NotebookRepository
+	config       ShareConfig
+	now          func() time.Time
+}
+
+func NewShareService(
+	shareRepo *repositories.ShareLinkRepository,
+	journalRepo *repositories.JournalRepository,
+	notebookRepo *repositories.NotebookRepository,
+	config ShareConfig,
+) *ShareService {
+	return &ShareService{
+		shareRepo:    shareRepo,
+		journalRepo:  journalRepo,
+		notebookRepo: notebookRepo,
+		config:       config,
+		now:          time.Now,
+	}
+}
+
+// Create makes a link to one of the user's published entries or to one of
+// their notebooks. The token is only part of this response.
+func (s *ShareService) Create(userID uint, req models.ShareLinkRequest) (*models.ShareLinkResponse, error) {
+	if (req.EntryID == nil) == (req.NotebookID == nil) {
+		return nil, fmt.Errorf("%w: share either an entry or a notebook", ErrInvalidShareLink)
+	}
+
+	if req.EntryID != nil {
+		entry, err := s.journalRepo.FindByIDAndUserID(*req.EntryID, userID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrJournalNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.Status != enums.EntryStatus.Published {
+			return nil, ErrCannotShareDraft
+		}
+	} else {
+		_, err := s.notebookRepo.FindByIDAndUserID(*req.NotebookID, userID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrNotebookNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	expiry := s.config.DefaultExpiry
+	if req.ExpiresInHours > 0 {
+		expiry = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if s.config.MaxExpiry > 0 && expiry > s.config.MaxExpiry {
+		return nil, fmt.Errorf("%w: links expire after at most %d hours", ErrInvalidShareLink, int(s.config.MaxExpiry.Hours()))
+	}
+
+	token, err := helpers.RandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+
+	link := &models.ShareLink{
+		UserID:         userID,
+		TokenHash:      helpers.HashToken(token),
+		TokenPrefix:    token[:8],
+		Label:          req.Label,
+		JournalEntryID: req.EntryID,
+		NotebookID:     req.NotebookID,
+		MaxViews:       req.MaxViews,
+		HideTasks:      req.HideTasks,
+		HideMood:       req.HideMood,
+	}
+	if expiry > 0 {
+		expiresAt := s.now().Add(expiry)
+		link.ExpiresAt = &expiresAt
+	}
+	if req.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, err
+		}
+		link.PasswordHash = string(hash)
+	}
+
+	if err := s.shareRepo.Create(link); err != nil {
+		return nil, err
+	}
+
+	response := s.toResponse(*link)
+	response.Token = token
+	if s.config.BaseURL != "" {
+		response.URL = strings.TrimRight(s.config.BaseURL, "/") + "/api/v1/shared/" + token
+	}
+	return &response, nil
+}
+
+func (s *ShareService) List(userID uint) ([]models.ShareLinkResponse, error) {
+	links, err := s.shareRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.ShareLinkResponse, 0, len(links))
+	for _, link := range links {
+		responses = append(responses, s.toResponse(link))
+	}
+	return responses, nil
+}
+
+func (s *ShareService) Get(userID, id uint) (*models.ShareLinkResponse, error) {
+	link, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.toResponse(*link)
+	return &response, nil
+}
+
+// Revoke closes a link immediately. It stays listed with its access log.
+func (s *ShareService) Revoke(userID, id uint) (*models.ShareLinkResponse, error) {
+	link, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.shareRepo.Revoke(link, s.now()); err != nil {
+		return nil, err
+	}
+
+	response := s.toResponse(*link)
+	return &response, nil
+}
+
+// Accesses returns the link's access log, newest first.
+func (s *ShareService) Accesses(userID, id uint) ([]models.ShareAccessResponse, error) {
+	link, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	accesses, err := s.shareRepo.FindAccesses(link.ID, shareAccessLogLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.ShareAccessResponse, 0, len(accesses))
+	for _, access := range accesses {
+		responses = append(responses, models.ShareAccessResponse{
+			Outcome:   access.Outcome,
+			IP:        access.IP,
+			UserAgent: access.UserAgent,
+			CreatedAt: access.CreatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// Open returns the content behind a token for a public visitor. Every
+// attempt on an existing link is logged for the owner; a successful one
+// counts towards the view limit.
+func (s *ShareService) Open(token, password, ip, userAgent string) (*models.SharedContent, error) {
+	link, err := s.shareRepo.FindByTokenHash(helpers.HashToken(token))
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	logAccess := func(outcome string) error {
+		return s.shareRepo.LogAccess(&models.ShareAccess{
+			ShareLinkID: link.ID,
+			Outcome:     outcome,
+			IP:          ip,
+			UserAgent:   userAgent,
+			CreatedAt:   now,
+		})
+	}
+	fail := func(outcome string, reason error) (*models.SharedContent, error) {
+		if err := logAccess(outcome); err != nil {
+			return nil, err
+		}
+		return nil, reason
+	}
+
+	switch shareStatus(*link, now) {
+	case models.ShareStatusRevoked:
+		return fail(models.ShareAccessRevoked, ErrShareLinkNotFound)
+	case models.ShareStatusExpired:
+		return fail(models.ShareAccessExpired, ErrShareLinkExpired)
+	case models.ShareStatusExhausted:
+		return fail(models.ShareAccessExhausted, ErrShareLinkExpired)
+	}
+
+	if link.PasswordHash != "" {
+		if s.config.MaxPasswordAttempts > 0 {
+			failures, err := s.shareRepo.CountAccesses(link.ID, models.ShareAccessWrongPassword, now.Add(-s.config.PasswordWindow))
+			if err != nil {
+				return nil, err
+			}
+			if failures >= int64(s.config.MaxPasswordAttempts) {
+				return fail(models.ShareAccessThrottled, ErrTooManyShareAttempts)
+			}
+		}
+		if password == "" {
+			return nil, ErrSharePasswordRequired
+		}
+		if bcrypt.CompareHashAndPassword([]byte(link.PasswordHash), []byte(password)) != nil {
+			return fail(models.ShareAccessWrongPassword, ErrWrongSharePassword)
+		}
+	}
+
+	content, err := s.content(link)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed, err := s.shareRepo.ClaimView(link, now)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		// Another visitor took the last view, or the link just closed
+		return fail(models.ShareAccessExhausted, ErrShareLinkExpired)
+	}
+
+	if err := logAccess(models.ShareAccessViewed); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+func (s *ShareService) content(link *models.ShareLink) (*models.SharedContent, error) {
+	content := &models.SharedContent{ExpiresAt: link.ExpiresAt}
+
+	if link.JournalEntryID != nil {
+		entry, err := s.journalRepo.FindByIDAndUserID(*link.JournalEntryID, link.UserID)
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			return nil, ErrShareLinkNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		// The entry may have gone back to draft since it was shared
+		if entry.Status != enums.EntryStatus.Published {
+			return nil, ErrShareLinkNotFound
+		}
+
+		content.Target = models.ShareTargetEntry
+		content.Entries = []models.SharedEntry{toSharedEntry(*entry, link.HideMood, link.HideTasks)}
+		return content, nil
+	}
+
+	notebook, err := s.notebookRepo.FindByIDAndUserID(*link.NotebookID, link.UserID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrShareLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.journalRepo.FindAllInNotebook(link.UserID, repositories.NotebookQuery{
+		NotebookID:        notebook.ID,
+		IncludeUnassigned: notebook.IsDefault,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content.Target = models.ShareTargetNotebook
+	content.Notebook = &models.SharedNotebook{Name: notebook.Name, Color: notebook.Color}
+	content.Entries = []models.SharedEntry{}
+	for _, entry := range entries {
+		// Drafts stay private even when their notebook is shared
+		if entry.Status != enums.EntryStatus.Published {
+			continue
+		}
+		content.Entries = append(content.Entries, toSharedEntry(entry, link.HideMood, link.HideTasks))
+	}
+	return content, nil
+}
+
+func (s *ShareService) find(userID, id uint) (*models.ShareLink, error) {
+	link, err := s.shareRepo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrShareLinkNotFound
+	}
+	return link, err
+}
+
+func (s *ShareService) toResponse(link models.ShareLink) models.ShareLinkResponse {
+	target := models.ShareTargetEntry
+	if link.NotebookID != nil {
+		target = models.ShareTargetNotebook
+	}
+
+	return models.ShareLinkResponse{
+		ID:           link.ID,
+		TokenPrefix:  link.TokenPrefix,
+		Label:        link.Label,
+		Target:       target,
+		EntryID:      link.JournalEntryID,
+		NotebookID:   link.NotebookID,
+		HasPassword:  link.PasswordHash != "",
+		ExpiresAt:    link.ExpiresAt,
+		MaxViews:     link.MaxViews,
+		ViewCount:    link.ViewCount,
+		HideTasks:    link.HideTasks,
+		HideMood:     link.HideMood,
+		Status:       shareStatus(link, s.now()),
+		RevokedAt:    link.RevokedAt,
+		LastViewedAt: link.LastViewedAt,
+		CreatedAt:    link.CreatedAt,
+	}
+}
+
+func shareStatus(link models.ShareLink, now time.Time) string {
+	switch {
+	case link.RevokedAt != nil:
+		return models.ShareStatusRevoked
+	case link.ExpiresAt != nil && !now.Before(*link.ExpiresAt):
+		return models.ShareStatusExpired
+	case link.MaxViews != nil && link.ViewCount >= *link.MaxViews:
+		return models.ShareStatusExhausted
+	default:
+		return models.ShareStatusActive
+	}
+}
+
+// toSharedEntry copies the parts of an entry shown to someone other than
+// its owner, leaving out the mood or the tasks when asked to.
+func toSharedEntry(entry models.JournalEntry, hideMood, hideTasks bool) models.SharedEntry {
+	shared := models.SharedEntry{
+		Date:               entry.Date,
+		ThisDayDescription: entry.ThisDayDescription,
+		DailyReflection:    entry.DailyReflection,
+	}
+	if !hideMood {
+		mood := entry.Mood
+		shared.Mood = &mood
+	}
+	if hideTasks {
+		return shared
+	}
+
+	for _, task := range entry.DailyTasks {
+		sharedTask := models.SharedTask{Task: task.Task, Done: task.Status}
+		for _, subTask := range task.SubTasks {
+			sharedTask.SubTasks = append(sharedTask.SubTasks, models.SharedTask{
+				Task: subTask.SubTask,
+				Done: subTask.Status,
+			})
+		}
+		shared.Tasks = append(shared.Tasks, sharedTask)
+	}
+	return shared
+}
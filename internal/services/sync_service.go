@@ -0,0 +1,280 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+// syncFeedLimit caps how many changes a single pull returns.
+const syncFeedLimit = 500
+
+var (
+	ErrSyncRecordNotFound = errors.New("record referenced by the change was not found")
+	ErrInvalidSyncChange  = errors.New("change is missing required fields or has an invalid client id")
+)
+
+// SyncOutcome pairs the result reported to the client with the error, if
+// any, that caused the change to be rejected.
+type SyncOutcome struct {
+	Result models.SyncPushResult
+	Err    error
+}
+
+// SyncService implements the offline sync protocol.
+//
+// Clients pull a per-user change feed ordered by seq and resume from the
+// last cursor they saw; deletions arrive as tombstones. Changes made while
+// offline are pushed together with the seq of the server state they were
+// based on. Conflicts are resolved per record: when the server has a newer
+// change the push is rejected and the current server record is returned
+// (server wins), unless the client asks for "client_wins" to overwrite it.
+type SyncService struct {
+	syncRepo       *repositories.SyncRepository
+	journalRepo    *repositories.JournalRepository
+	webhookService *WebhookService
+}
+
+func NewSyncService(
+	syncRepo *repositories.SyncRepository,
+	journalRepo *repositories.JournalRepository,
+	webhookService *WebhookService,
+) *SyncService {
+	return &SyncService{
+		syncRepo:       syncRepo,
+		journalRepo:    journalRepo,
+		webhookService: webhookService,
+	}
+}
+
+// Changes returns the latest state of every record changed after cursor.
+// A record changed several times within one page is reported once.
+func (s *SyncService) Changes(userID uint, cursor uint64) (*models.SyncFeedResponse, error) {
+	changes, err := s.syncRepo.FindChangesSince(userID, cursor, syncFeedLimit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.SyncFeedResponse{
+		Changes:    []models.SyncRecord{},
+		NextCursor: cursor,
+	}
+	if len(changes) > syncFeedLimit {
+		changes = changes[:syncFeedLimit]
+		response.HasMore = true
+	}
+	if len(changes) == 0 {
+		return response, nil
+	}
+	response.NextCursor = changes[len(changes)-1].Seq
+
+	// Keep only the last change per record, in feed order
+	type entityKey struct {
+		entityType string
+		id         uint
+	}
+	latest := make(map[entityKey]int, len(changes))
+	ids := make(map[string][]uint)
+	for i, change := range changes {
+		key := entityKey{change.EntityType, change.EntityID}
+		if _, seen := latest[key]; !seen {
+			ids[change.EntityType] = append(ids[change.EntityType], change.EntityID)
+		}
+		latest[key] = i
+	}
+
+	records := make(map[entityKey]func(seq uint64) models.SyncRecord)
+	if len(ids[models.SyncEntityJournalEntry]) > 0 {
+		entries, err := s.syncRepo.FindEntriesByIDs(userID, ids[models.SyncEntityJournalEntry])
+		if err != nil {
+			return nil, err
+		}
+		for i := range entries {
+			entry := &entries[i]
+			records[entityKey{models.SyncEntityJournalEntry, entry.ID}] = func(seq uint64) models.SyncRecord {
+				return models.NewEntrySyncRecord(entry, seq)
+			}
+		}
+	}
+	if len(ids[models.SyncEntityDailyTask]) > 0 {
+		tasks, err := s.syncRepo.FindTasksByIDs(userID, ids[models.SyncEntityDailyTask])
+		if err != nil {
+			return nil, err
+		}
+		for i := range tasks {
+			task := &tasks[i]
+			records[entityKey{models.SyncEntityDailyTask, task.ID}] = func(seq uint64) models.SyncRecord {
+				return models.NewTaskSyncRecord(task, seq)
+			}
+		}
+	}
+	if len(ids[models.SyncEntitySubTask]) > 0 {
+		subTasks, err := s.syncRepo.FindSubTasksByIDs(userID, ids[models.SyncEntitySubTask])
+		if err != nil {
+			return nil, err
+		}
+		for i := range subTasks {
+			subTask := &subTasks[i]
+			records[entityKey{models.SyncEntitySubTask, subTask.ID}] = func(seq uint64) models.SyncRecord {
+				return models.NewSubTaskSyncRecord(subTask, seq)
+			}
+		}
+	}
+
+	for i, change := range changes {
+		key := entityKey{change.EntityType, change.EntityID}
+		if latest[key] != i {
+			continue
+		}
+
+		build, ok := records[key]
+		if !ok || change.Operation == models.SyncOperationDelete {
+			// Purged from the trash, or deleted: only the tombstone remains
+			deletedAt := change.CreatedAt
+			response.Changes = append(response.Changes, models.SyncRecord{
+				EntityType: change.EntityType,
+				ID:         change.EntityID,
+				Operation:  models.SyncOperationDelete,
+				Seq:        change.Seq,
+				UpdatedAt:  change.CreatedAt,
+				DeletedAt:  &deletedAt,
+			})
+			continue
+		}
+		response.Changes = append(response.Changes, build(change.Seq))
+	}
+
+	return response, nil
+}
+
+// Push applies offline changes in order. Each change succeeds or fails on
+// its own; a rejected change does not stop the ones after it. Every entry
+// touched by the push gets a single new revision afterwards.
+func (s *SyncService) Push(userID uint, changes []models.SyncPushChange) ([]SyncOutcome, error) {
+	outcomes := make([]SyncOutcome, 0, len(changes))
+	touched := make(map[uint]bool)
+	var touchedOrder []uint
+
+	for i, change := range changes {
+		result, err := s.apply(userID, change)
+		outcome := SyncOutcome{
+			Result: models.SyncPushResult{Index: i, ClientID: change.ClientID},
+		}
+
+		switch {
+		case err != nil:
+			outcome.Result.Status = "error"
+			outcome.Err = mapSyncError(err)
+		case result.Conflict != nil:
+			outcome.Result.Status = "conflict"
+			outcome.Result.ID = result.ID
+			outcome.Result.Seq = result.Seq
+			outcome.Result.Server = result.Conflict
+		default:
+			outcome.Result.Status = "applied"
+			outcome.Result.ID = result.ID
+			outcome.Result.Seq = result.Seq
+			if result.JournalEntryID > 0 && !touched[result.JournalEntryID] {
+				touched[result.JournalEntryID] = true
+				touchedOrder = append(touchedOrder, result.JournalEntryID)
+			}
+		}
+
+		// Infrastructure failures abort the push; the client retries it
+		if outcome.Err != nil && !isSyncClientError(outcome.Err) {
+			return nil, outcome.Err
+		}
+		outcomes = append(outcomes, outcome)
+	}
+
+	for _, entryID := range touchedOrder {
+		err := s.journalRepo.Transaction(func(tx *gorm.DB) error {
+			journalRepo := s.journalRepo.WithTx(tx)
+			if err := journalRepo.AppendRevision(entryID); err != nil {
+				return err
+			}
+
+			entry, err := journalRepo.FindByIDAndUserID(entryID, userID)
+			if err != nil {
+				return err
+			}
+			return s.webhookService.withTx(tx).Publish(userID, models.WebhookEventJournalUpdated, entry)
+		})
+		if errors.Is(err, repositories.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return outcomes, nil
+}
+
+func (s *SyncService) apply(userID uint, change models.SyncPushChange) (*repositories.SyncApplyResult, error) {
+	if change.ID == 0 && !helpers.IsUUID(change.ClientID) {
+		return nil, ErrInvalidSyncChange
+	}
+	if change.ClientID != "" && !helpers.IsUUID(change.ClientID) {
+		return nil, ErrInvalidSyncChange
+	}
+
+	switch change.EntityType {
+	case models.SyncEntityJournalEntry:
+		var data models.SyncEntryData
+		if err := decodeSyncData(change, &data); err != nil {
+			return nil, err
+		}
+		return s.syncRepo.ApplyEntryChange(userID, change, data)
+
+	case models.SyncEntityDailyTask:
+		var data models.SyncTaskData
+		if err := decodeSyncData(change, &data); err != nil {
+			return nil, err
+		}
+		if data.DueTime != nil && *data.DueTime != "" {
+			if _, err := time.Parse("15:04", *data.DueTime); err != nil {
+				return nil, ErrInvalidSyncChange
+			}
+		}
+		return s.syncRepo.ApplyTaskChange(userID, change, data)
+
+	case models.SyncEntitySubTask:
+		var data models.SyncSubTaskData
+		if err := decodeSyncData(change, &data); err != nil {
+			return nil, err
+		}
+		return s.syncRepo.ApplySubTaskChange(userID, change, data)
+	}
+
+	return nil, ErrInvalidSyncChange
+}
+
+func decodeSyncData(change models.SyncPushChange, dest interface{}) error {
+	if change.Operation == models.SyncOperationDelete || len(change.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(change.Data, dest); err != nil {
+		return ErrInvalidSyncChange
+	}
+	return nil
+}
+
+func mapSyncError(err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrRecordNotFound):
+		return ErrSyncRecordNotFound
+	case errors.Is(err, repositories.ErrInvalidSyncData):
+		return ErrInvalidSyncChange
+	}
+	return err
+}
+
+func isSyncClientError(err error) bool {
+	return errors.Is(err, ErrSyncRecordNotFound) || errors.Is(err, ErrInvalidSyncChange)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+func TestDecodeSyncData(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		data      string
+		wantErr   error
+		wantTask  string
+	}{
+		{name: "upsert", operation: models.SyncOperationUpsert, data: `{"task":"Read"}`, wantTask: "Read"},
+		{name: "upsert without data", operation: models.SyncOperationUpsert},
+		{name: "delete ignores data", operation: models.SyncOperationDelete, data: `not json`},
+		{name: "malformed data", operation: models.SyncOperationUpsert, data: `{"task":`, wantErr: ErrInvalidSyncChange},
+		{name: "wrong type", operation: models.SyncOperationUpsert, data: `{"task":1}`, wantErr: ErrInvalidSyncChange},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			change := models.SyncPushChange{Operation: tt.operation, Data: json.RawMessage(tt.data)}
+
+			var data models.SyncTaskData
+			err := decodeSyncData(change, &data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("decodeSyncData() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var task string
+			if data.Task != nil {
+				task = *data.Task
+			}
+			if task != tt.wantTask {
+				t.Errorf("decodeSyncData() task = %q, want %q", task, tt.wantTask)
+			}
+		})
+	}
+}
+
+func TestMapSyncError(t *testing.T) {
+	errOther := errors.New("connection reset")
+
+	tests := []struct {
+		name            string
+		err             error
+		want            error
+		wantClientError bool
+	}{
+		{name: "not found", err: repositories.ErrRecordNotFound, want: ErrSyncRecordNotFound, wantClientError: true},
+		{name: "wrapped not found", err: fmt.Errorf("task 3: %w", repositories.ErrRecordNotFound), want: ErrSyncRecordNotFound, wantClientError: true},
+		{name: "invalid data", err: repositories.ErrInvalidSyncData, want: ErrInvalidSyncChange, wantClientError: true},
+		{name: "other errors pass through", err: errOther, want: errOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mapSyncError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("mapSyncError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+			if isSyncClientError(got) != tt.wantClientError {
+				t.Errorf("isSyncClientError(%v) = %v, want %v", got, !tt.wantClientError, tt.wantClientError)
+			}
+		})
+	}
+}
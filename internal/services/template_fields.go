@@ -0,0 +1,197 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+// defaultRatingScale is the top of a rating field's scale when the field
+// does not set Max.
+const defaultRatingScale = 5
+
+var templateFieldKey = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// validateTemplateFields checks the field definitions of a template.
+func validateTemplateFields(fields []models.TemplateField) error {
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !templateFieldKey.MatchString(field.Key) {
+			return fmt.Errorf("%w: key %q must be lowercase letters, digits and underscores", ErrInvalidTemplate, field.Key)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("%w: key %q is used twice", ErrInvalidTemplate, field.Key)
+		}
+		seen[field.Key] = true
+
+		switch field.Type {
+		case models.TemplateFieldNumber:
+			if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+				return fmt.Errorf("%w: %s has min above max", ErrInvalidTemplate, field.Key)
+			}
+		case models.TemplateFieldRating:
+			if field.Min != nil {
+				return fmt.Errorf("%w: %s ratings always start at 1", ErrInvalidTemplate, field.Key)
+			}
+			if field.Max != nil && (*field.Max != math.Trunc(*field.Max) || *field.Max < 2 || *field.Max > 10) {
+				return fmt.Errorf("%w: %s rating scale must be a whole number from 2 to 10", ErrInvalidTemplate, field.Key)
+			}
+		case models.TemplateFieldText, models.TemplateFieldCheckbox, models.TemplateFieldList:
+			if field.Min != nil || field.Max != nil {
+				return fmt.Errorf("%w: min and max only apply to number and rating fields", ErrInvalidTemplate)
+			}
+		default:
+			return fmt.Errorf("%w: %s has unknown type %q", ErrInvalidTemplate, field.Key, field.Type)
+		}
+
+		if field.MaxLength > 0 && field.Type != models.TemplateFieldText {
+			return fmt.Errorf("%w: max_length only applies to text fields", ErrInvalidTemplate)
+		}
+		if field.MaxItems > 0 && field.Type != models.TemplateFieldList {
+			return fmt.Errorf("%w: max_items only applies to list fields", ErrInvalidTemplate)
+		}
+	}
+	return nil
+}
+
+// validateTemplateValues checks an entry's values against the fields of a
+// template version and returns them normalized: null, blank and empty
+// values are dropped, as are blank list items.
+func validateTemplateValues(fields []models.TemplateField, values models.JSONB) (models.JSONB, error) {
+	raw := map[string]json.RawMessage{}
+	trimmed := bytes.TrimSpace(values)
+	if len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("%w: values must be an object", ErrInvalidTemplateValues)
+		}
+	}
+
+	byKey := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		byKey[field.Key] = true
+	}
+	for key := range raw {
+		if !byKey[key] {
+			return nil, fmt.Errorf("%w: %q is not a field of the template", ErrInvalidTemplateValues, key)
+		}
+	}
+
+	normalized := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		value, present := raw[field.Key]
+		if present && bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			present = false
+		}
+		if !present {
+			if field.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidTemplateValues, field.Key)
+			}
+			continue
+		}
+
+		parsed, err := parseTemplateValue(field, value)
+		if err != nil {
+			return nil, err
+		}
+		if parsed != nil {
+			normalized[field.Key] = parsed
+		}
+	}
+
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return nil, err
+	}
+	return models.JSONB(encoded), nil
+}
+
+// parseTemplateValue decodes one value. It returns nil for an empty
+// optional value.
+func parseTemplateValue(field models.TemplateField, value json.RawMessage) (interface{}, error) {
+	switch field.Type {
+	case models.TemplateFieldText:
+		var text string
+		if err := json.Unmarshal(value, &text); err != nil {
+			return nil, fmt.Errorf("%w: %s must be text", ErrInvalidTemplateValues, field.Key)
+		}
+		if strings.TrimSpace(text) == "" {
+			if field.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidTemplateValues, field.Key)
+			}
+			return nil, nil
+		}
+		if field.MaxLength > 0 && utf8.RuneCountInString(text) > field.MaxLength {
+			return nil, fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidTemplateValues, field.Key, field.MaxLength)
+		}
+		return text, nil
+
+	case models.TemplateFieldNumber:
+		var number float64
+		if err := json.Unmarshal(value, &number); err != nil {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidTemplateValues, field.Key)
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, fmt.Errorf("%w: %s must be at least %g", ErrInvalidTemplateValues, field.Key, *field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, fmt.Errorf("%w: %s must be at most %g", ErrInvalidTemplateValues, field.Key, *field.Max)
+		}
+		return number, nil
+
+	case models.TemplateFieldRating:
+		scale := defaultRatingScale
+		if field.Max != nil {
+			scale = int(*field.Max)
+		}
+		var rating float64
+		if err := json.Unmarshal(value, &rating); err != nil || rating != math.Trunc(rating) || rating < 1 || rating > float64(scale) {
+			return nil, fmt.Errorf("%w: %s must be a whole number from 1 to %d", ErrInvalidTemplateValues, field.Key, scale)
+		}
+		return int(rating), nil
+
+	case models.TemplateFieldCheckbox:
+		var checked bool
+		if err := json.Unmarshal(value, &checked); err != nil {
+			return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidTemplateValues, field.Key)
+		}
+		return checked, nil
+
+	case models.TemplateFieldList:
+		var items []string
+		if err := json.Unmarshal(value, &items); err != nil {
+			return nil, fmt.Errorf("%w: %s must be a list of text", ErrInvalidTemplateValues, field.Key)
+		}
+		kept := make([]string, 0, len(items))
+		for _, item := range items {
+			if strings.TrimSpace(item) != "" {
+				kept = append(kept, item)
+			}
+		}
+		if len(kept) == 0 {
+			if field.Required {
+				return nil, fmt.Errorf("%w: %s needs at least one item", ErrInvalidTemplateValues, field.Key)
+			}
+			return nil, nil
+		}
+		if field.MaxItems > 0 && len(kept) > field.MaxItems {
+			return nil, fmt.Errorf("%w: %s has more than %d items", ErrInvalidTemplateValues, field.Key, field.MaxItems)
+		}
+		return kept, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s has unknown type %q", ErrInvalidTemplateValues, field.Key, field.Type)
+}
+
+func decodeTemplateFields(fields models.JSONB) ([]models.TemplateField, error) {
+	var decoded []models.TemplateField
+	if err := json.Unmarshal(fields, &decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+// TemplateService manages user-defined entry templates and checks the
+// values entries store for them.
+type TemplateService struct {
+	templateRepo *repositories.TemplateRepository
+}
+
+func NewTemplateService(templateRepo *repositories.TemplateRepository) *TemplateService {
+	return &TemplateService{templateRepo: templateRepo}
+}
+
+func (s *TemplateService) List(userID uint) ([]models.TemplateResponse, error) {
+	templates, err := s.templateRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.templateRepo.FindLatestVersions(templates)
+	if err != nil {
+		return nil, err
+	}
+	fieldsByTemplate := make(map[uint]models.JSONB, len(versions))
+	for _, version := range versions {
+		fieldsByTemplate[version.TemplateID] = version.Fields
+	}
+
+	responses := make([]models.TemplateResponse, 0, len(templates))
+	for _, template := range templates {
+		response, err := toTemplateResponse(template, fieldsByTemplate[template.ID])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+func (s *TemplateService) Get(userID, id uint) (*models.TemplateResponse, error) {
+	template, version, err := s.findLatest(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateResponse(*template, version.Fields)
+}
+
+func (s *TemplateService) Create(userID uint, req models.TemplateRequest) (*models.TemplateResponse, error) {
+	if err := validateTemplateFields(req.Fields); err != nil {
+		return nil, err
+	}
+	fields, err := json.Marshal(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.Template{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	version, err := s.templateRepo.Create(template, models.JSONB(fields))
+	if err != nil {
+		return nil, err
+	}
+
+	return toTemplateResponse(*template, version.Fields)
+}
+
+// Update renames the template and, when its fields changed, stores them
+// as a new version. Entries written with earlier versions keep them.
+func (s *TemplateService) Update(userID, id uint, req models.TemplateRequest) (*models.TemplateResponse, error) {
+	if err := validateTemplateFields(req.Fields); err != nil {
+		return nil, err
+	}
+	fields, err := json.Marshal(req.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	template, version, err := s.findLatest(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	if err := s.templateRepo.Save(template); err != nil {
+		return nil, err
+	}
+
+	// Compare decoded fields, jsonb does not keep the stored formatting
+	current, err := decodeTemplateFields(version.Fields)
+	if err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(current, req.Fields) {
+		version, err = s.templateRepo.AddVersion(template, models.JSONB(fields))
+		if errors.Is(err, repositories.ErrVersionConflict) {
+			return nil, ErrTemplateConflict
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return toTemplateResponse(*template, version.Fields)
+}
+
+// Delete removes the template. Its versions are kept so entries that used
+// it still render.
+func (s *TemplateService) Delete(userID, id uint) error {
+	template, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(template)
+}
+
+// Versions lists every version of the template, oldest first.
+func (s *TemplateService) Versions(userID, id uint) ([]models.TemplateVersionResponse, error) {
+	template, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.templateRepo.FindVersions(template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.TemplateVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		response, err := toTemplateVersionResponse(version)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// Version returns one version of a template, also after the template was
+// deleted, so clients can render the entries written with it.
+func (s *TemplateService) Version(userID, id uint, version int) (*models.TemplateVersionResponse, error) {
+	templateVersion, err := s.templateRepo.FindVersion(id, userID, version)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrTemplateVersionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return toTemplateVersionResponse(*templateVersion)
+}
+
+// ValidateValues checks entry values against the latest version of the
+// template and returns that version with the normalized values.
+func (s *TemplateService) ValidateValues(userID, id uint, values models.JSONB) (int, models.JSONB, error) {
+	_, version, err := s.findLatest(userID, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fields, err := decodeTemplateFields(version.Fields)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	normalized, err := validateTemplateValues(fields, values)
+	if err != nil {
+		return 0, nil, err
+	}
+	return version.Version, normalized, nil
+}
+
+func (s *TemplateService) find(userID, id uint) (*models.Template, error) {
+	template, err := s.templateRepo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrTemplateNotFound
+	}
+	return template, err
+}
+
+func (s *TemplateService) findLatest(userID, id uint) (*models.Template, *models.TemplateVersion, error) {
+	template, err := s.find(userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	version, err := s.templateRepo.FindVersion(template.ID, userID, template.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return template, version, nil
+}
+
+func toTemplateResponse(template models.Template, fields models.JSONB) (*models.TemplateResponse, error) {
+	decoded, err := decodeTemplateFields(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TemplateResponse{
+		ID:          template.ID,
+		Name:        template.Name,
+		Description: template.Description,
+		Version:     template.Version,
+		Fields:      decoded,
+		CreatedAt:   template.CreatedAt,
+		UpdatedAt:   template.UpdatedAt,
+	}, nil
+}
+
+func toTemplateVersionResponse(version models.TemplateVersion) (*models.TemplateVersionResponse, error) {
+	fields, err := decodeTemplateFields(version.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TemplateVersionResponse{
+		TemplateID: version.TemplateID,
+		Version:    version.Version,
+		Fields:     fields,
+		CreatedAt:  version.CreatedAt,
+	}, nil
+}
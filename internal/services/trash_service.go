@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+// trashPurgeBatchSize limits how many entries a single purge run removes.
+const trashPurgeBatchSize = 100
+
+type TrashService struct {
+	journalRepo       *repositories.JournalRepository
+	attachmentService *AttachmentService
+	retention         time.Duration
+}
+
+func NewTrashService(
+	journalRepo *repositories.JournalRepository,
+	attachmentService *AttachmentService,
+	retention time.Duration,
+) *TrashService {
+	return &TrashService{
+		journalRepo:       journalRepo,
+		attachmentService: attachmentService,
+		retention:         retention,
+	}
+}
+
+func (s *TrashService) List(userID uint) ([]models.TrashedEntryResponse, error) {
+	entries, err := s.journalRepo.FindTrashedByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.TrashedEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		response = append(response, models.TrashedEntryResponse{
+			ID:                 entry.ID,
+			Date:               entry.Date,
+			Mood:               entry.Mood,
+			ThisDayDescription: entry.ThisDayDescription,
+			DeletedAt:          entry.DeletedAt.Time,
+			PurgeAt:            entry.DeletedAt.Time.Add(s.retention),
+		})
+	}
+	return response, nil
+}
+
+func (s *TrashService) Restore(userID, id uint) error {
+	entry, err := s.findTrashed(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.journalRepo.Restore(entry)
+}
+
+// DeletePermanently removes a trashed entry right away instead of waiting
+// for the scheduled purge.
+func (s *TrashService) DeletePermanently(ctx context.Context, userID, id uint) error {
+	entry, err := s.findTrashed(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.purge(ctx, entry)
+}
+
+// PurgeExpired hard-deletes entries that have been in the trash for longer
+// than the retention period, together with their tasks, revisions and
+// attachment files. It returns the number of purged entries.
+func (s *TrashService) PurgeExpired(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	purged := 0
+
+	for {
+		entries, err := s.journalRepo.FindTrashedBefore(cutoff, trashPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		for i := range entries {
+			if err := ctx.Err(); err != nil {
+				return purged, err
+			}
+			if err := s.purge(ctx, &entries[i]); err != nil {
+				return purged, err
+			}
+			purged++
+		}
+
+		if len(entries) < trashPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// purge removes stored files first so a failure never leaves blobs behind
+// without a record pointing at them.
+func (s *TrashService) purge(ctx context.Context, entry *models.JournalEntry) error {
+	if err := s.attachmentService.DeleteBlobs(ctx, entry.Attachments); err != nil {
+		return err
+	}
+	return s.journalRepo.HardDelete(entry)
+}
+
+func (s *TrashService) findTrashed(id, userID uint) (*models.JournalEntry, error) {
+	entry, err := s.journalRepo.FindTrashedByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrJournalNotFound
+	}
+	return entry, err
+}
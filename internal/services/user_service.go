@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+)
+
+type UserService struct {
+	userRepo *repositories.UserRepository
+}
+
+func NewUserService(userRepo *repositories.UserRepository) *UserService {
+	return &UserService{userRepo: userRepo}
+}
+
+func (s *UserService) GetSettings(userID uint) (*models.UserSettingsResponse, error) {
+	user, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserSettingsResponse{
+		TimeZone:      user.TimeZone,
+		AutoCarryOver: user.AutoCarryOver,
+	}, nil
+}
+
+func (s *UserService) UpdateSettings(userID uint, req models.UserSettingsRequest) (*models.UserSettingsResponse, error) {
+	if _, err := s.find(userID); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if req.TimeZone != nil {
+		location, err := time.LoadLocation(*req.TimeZone)
+		if err != nil || *req.TimeZone == "" || *req.TimeZone == "Local" {
+			return nil, ErrInvalidTimeZone
+		}
+		fields["time_zone"] = location.String()
+	}
+	if req.AutoCarryOver != nil {
+		fields["auto_carry_over"] = *req.AutoCarryOver
+	}
+
+	if len(fields) > 0 {
+		if err := s.userRepo.UpdateSettings(userID, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetSettings(userID)
+}
+
+// Location returns the user's time zone, falling back to UTC.
+func (s *UserService) Location(userID uint) (*time.Location, error) {
+	user, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+	return userLocation(user.TimeZone), nil
+}
+
+func (s *UserService) find(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	return user, err
+}
+
+func userLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,500 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	repositories "github.com/sugiiianaa/remember-my-story/internal/Repositories"
+	"github.com/sugiiianaa/remember-my-story/internal/models"
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+	"github.com/sugiiianaa/remember-my-story/pkg/helpers"
+	"gorm.io/gorm"
+)
+
+const (
+	// webhookClaimBatchSize limits how many deliveries one run sends.
+	webhookClaimBatchSize = 50
+	// webhookLease is how long a claimed delivery stays hidden from other
+	// runs while it is being sent.
+	webhookLease = 5 * time.Minute
+	// webhookResponseLimit is how much of a response body the log keeps.
+	webhookResponseLimit = 2048
+	// webhookDeliveryPageSize is the default page size of the delivery log.
+	webhookDeliveryPageSize = 50
+)
+
+type WebhookConfig struct {
+	// MaxAttempts bounds how often one delivery is tried before it is
+	// marked failed.
+	MaxAttempts int
+	// Backoff is the delay after the first failed attempt. It doubles with
+	// every further attempt up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// DisableAfter disables a webhook after this many failed attempts in a
+	// row, across all of its deliveries.
+	DisableAfter int
+	Timeout      time.Duration
+}
+
+// WebhookService manages the users' webhooks, queues journal events for
+// them and delivers the queue with retries.
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	client *http.Client
+	config WebhookConfig
+	now    func() time.Time
+}
+
+func NewWebhookService(repo *repositories.WebhookRepository, config WebhookConfig) *WebhookService {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = 30 * time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 6 * time.Hour
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	return &WebhookService{
+		repo:   repo,
+		client: newWebhookClient(config.Timeout),
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// newWebhookClient returns the client deliveries are sent with. It only
+// reaches public addresses, since responses are shown in the delivery log.
+func newWebhookClient(timeout time.Duration) *http.Client {
+	client := safehttp.NewClient(timeout)
+	// A redirect is reported as a failure, not followed
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}
+
+// withTx returns a copy of the service that queues events in tx, so they
+// are only delivered if the change that caused them commits.
+func (s *WebhookService) withTx(tx *gorm.DB) *WebhookService {
+	return &WebhookService{
+		repo:   s.repo.WithTx(tx),
+		client: s.client,
+		config: s.config,
+		now:    s.now,
+	}
+}
+
+func (s *WebhookService) Create(userID uint, req models.WebhookRequest) (*models.WebhookResponse, error) {
+	if err := safehttp.CheckURL(req.URL); err != nil {
+		return nil, ErrUnsafeWebhookURL
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &models.Webhook{
+		UserID:      userID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      strings.Join(uniqueStrings(req.Events), ","),
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := s.repo.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	response := toWebhookResponse(*webhook)
+	response.Secret = secret
+	return &response, nil
+}
+
+func (s *WebhookService) List(userID uint) ([]models.WebhookResponse, error) {
+	webhooks, err := s.repo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, toWebhookResponse(webhook))
+	}
+	return responses, nil
+}
+
+func (s *WebhookService) Get(userID, id uint) (*models.WebhookResponse, error) {
+	webhook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	response := toWebhookResponse(*webhook)
+	return &response, nil
+}
+
+func (s *WebhookService) Update(userID, id uint, req models.UpdateWebhookRequest) (*models.WebhookResponse, error) {
+	webhook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	if req.URL != nil {
+		if err := safehttp.CheckURL(*req.URL); err != nil {
+			return nil, ErrUnsafeWebhookURL
+		}
+		fields["url"] = *req.URL
+	}
+	if req.Events != nil {
+		fields["events"] = strings.Join(uniqueStrings(*req.Events), ",")
+	}
+	if req.Description != nil {
+		fields["description"] = *req.Description
+	}
+	if req.Active != nil {
+		fields["active"] = *req.Active
+		if *req.Active && !webhook.Active {
+			fields["consecutive_failures"] = 0
+			fields["disabled_at"] = nil
+			fields["disabled_reason"] = ""
+		}
+	}
+
+	if len(fields) > 0 {
+		if err := s.repo.Update(webhook.ID, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.Get(userID, id)
+}
+
+func (s *WebhookService) Delete(userID, id uint) error {
+	webhook, err := s.find(userID, id)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(webhook)
+}
+
+// RotateSecret replaces the signing secret. Deliveries sent afterwards,
+// including retries of older events, are signed with the new one.
+func (s *WebhookService) RotateSecret(userID, id uint) (*models.WebhookResponse, error) {
+	webhook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(webhook.ID, map[string]interface{}{"secret": secret}); err != nil {
+		return nil, err
+	}
+
+	response, err := s.Get(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	response.Secret = secret
+	return response, nil
+}
+
+// Deliveries returns a page of the webhook's delivery log, newest first.
+func (s *WebhookService) Deliveries(userID, id uint, status string, beforeID uint, limit int) ([]models.WebhookDeliveryResponse, error) {
+	if _, err := s.find(userID, id); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > webhookDeliveryPageSize {
+		limit = webhookDeliveryPageSize
+	}
+
+	deliveries, err := s.repo.FindDeliveries(id, status, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, toWebhookDeliveryResponse(delivery))
+	}
+	return responses, nil
+}
+
+// Redeliver queues the event of an earlier delivery again, as a new
+// delivery with fresh attempts.
+func (s *WebhookService) Redeliver(userID, id, deliveryID uint) (*models.WebhookDeliveryResponse, error) {
+	webhook, err := s.find(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !webhook.Active {
+		return nil, ErrWebhookDisabled
+	}
+
+	original, err := s.repo.FindDelivery(deliveryID, webhook.ID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := models.WebhookDelivery{
+		WebhookID:     webhook.ID,
+		EventID:       original.EventID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.WebhookDeliveryPending,
+		NextAttemptAt: s.now(),
+	}
+	deliveries := []models.WebhookDelivery{delivery}
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+
+	response := toWebhookDeliveryResponse(deliveries[0])
+	return &response, nil
+}
+
+// Publish queues event for every active webhook of the user subscribed
+// to it. data becomes the "data" field of the payload.
+func (s *WebhookService) Publish(userID uint, event string, data interface{}) error {
+	webhooks, err := s.repo.FindSubscribed(userID, event)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+
+	eventID, err := newWebhookEventID()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(models.WebhookEvent{
+		ID:        eventID,
+		Event:     event,
+		CreatedAt: s.now().UTC(),
+		UserID:    userID,
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	deliveries := make([]models.WebhookDelivery, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, models.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       eventID,
+			Event:         event,
+			Payload:       models.JSONB(payload),
+			Status:        models.WebhookDeliveryPending,
+			NextAttemptAt: s.now(),
+		})
+	}
+	return s.repo.CreateDeliveries(deliveries)
+}
+
+// DeliverDue sends queued deliveries whose next attempt is due. It
+// returns how many were sent successfully.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+
+	for {
+		now := s.now()
+		deliveries, err := s.repo.ClaimDueDeliveries(now, now.Add(webhookLease), webhookClaimBatchSize)
+		if err != nil || len(deliveries) == 0 {
+			return delivered, err
+		}
+
+		webhookIDs := make([]uint, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		webhooks, err := s.repo.FindByIDs(webhookIDs)
+		if err != nil {
+			return delivered, err
+		}
+		byID := make(map[uint]models.Webhook, len(webhooks))
+		for _, webhook := range webhooks {
+			byID[webhook.ID] = webhook
+		}
+
+		for i := range deliveries {
+			if err := ctx.Err(); err != nil {
+				return delivered, err
+			}
+
+			webhook, ok := byID[deliveries[i].WebhookID]
+			if !ok || !webhook.Active {
+				// Disabled by an earlier failure in this batch; the lease
+				// runs out and it waits until the webhook is re-enabled
+				continue
+			}
+
+			ok, err := s.deliver(ctx, webhook, &deliveries[i])
+			if err != nil {
+				return delivered, err
+			}
+			if ok {
+				delivered++
+			} else {
+				byID[webhook.ID] = s.refresh(webhook)
+			}
+		}
+
+		if len(deliveries) < webhookClaimBatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// deliver makes one attempt and records its outcome. The returned error
+// only reports failures to record it.
+func (s *WebhookService) deliver(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) (bool, error) {
+	start := s.now()
+	status, body, sendErr := s.send(ctx, webhook, delivery)
+
+	delivery.Attempts++
+	delivery.LastAttemptAt = &start
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	delivery.ResponseStatus = status
+	delivery.ResponseBody = body
+	delivery.Error = ""
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliverySucceeded
+		return true, s.repo.RecordSuccess(delivery)
+	}
+
+	delivery.Error = sendErr.Error()
+	if delivery.Attempts >= s.config.MaxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+	} else {
+		delivery.NextAttemptAt = s.now().Add(s.backoff(delivery.Attempts))
+	}
+
+	reason := fmt.Sprintf("%d delivery attempts in a row failed, last: %s", s.config.DisableAfter, sendErr.Error())
+	_, err := s.repo.RecordFailure(delivery, s.config.DisableAfter, reason)
+	return false, err
+}
+
+func (s *WebhookService) send(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "RememberMyStory-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set("X-Webhook-Delivery", fmt.Sprint(delivery.ID))
+	req.Header.Set("X-Webhook-Signature", helpers.SignWebhookPayload(webhook.Secret, s.now(), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	if resp.StatusCode/100 != 2 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (s *WebhookService) backoff(attempts int) time.Duration {
+	delay := s.config.Backoff
+	for i := 1; i < attempts && delay < s.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, s.config.MaxBackoff)
+}
+
+// refresh reloads a webhook after a failure, which may have disabled it.
+func (s *WebhookService) refresh(webhook models.Webhook) models.Webhook {
+	webhooks, err := s.repo.FindByIDs([]uint{webhook.ID})
+	if err != nil || len(webhooks) == 0 {
+		webhook.Active = false
+		return webhook
+	}
+	return webhooks[0]
+}
+
+func (s *WebhookService) find(userID, id uint) (*models.Webhook, error) {
+	webhook, err := s.repo.FindByIDAndUserID(id, userID)
+	if errors.Is(err, repositories.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+func newWebhookSecret() (string, error) {
+	token, err := helpers.RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + token, nil
+}
+
+func newWebhookEventID() (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(random), nil
+}
+
+func toWebhookResponse(webhook models.Webhook) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:                  webhook.ID,
+		URL:                 webhook.URL,
+		Events:              strings.Split(webhook.Events, ","),
+		Description:         webhook.Description,
+		Active:              webhook.Active,
+		ConsecutiveFailures: webhook.ConsecutiveFailures,
+		DisabledAt:          webhook.DisabledAt,
+		DisabledReason:      webhook.DisabledReason,
+		CreatedAt:           webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery models.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		ResponseBody:   delivery.ResponseBody,
+		Error:          delivery.Error,
+		DurationMs:     delivery.DurationMs,
+		Payload:        delivery.Payload,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.Status == models.WebhookDeliveryPending {
+		next := delivery.NextAttemptAt
+		response.NextAttemptAt = &next
+	}
+	return response
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// s3StubServer is a tiny in-memory stand-in for an S3-compatible service.
+// It understands the object PUT/GET/HEAD/DELETE calls made by S3BlobStore,
+// so the S3 code path can be tested without running MinIO.
+type s3StubServer struct {
+	mu      sync.RWMutex
+	objects map[string]stubObject
+}
+
+type stubObject struct {
+	data        []byte
+	contentType string
+}
+
+func newS3StubServer() *s3StubServer {
+	return &s3StubServer{objects: make(map[string]stubObject)}
+}
+
+func (s *s3StubServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "missing signature", http.StatusForbidden)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+
+	switch r.Method {
+	case http.MethodPut:
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.objects[key] = stubObject{data: buf.Bytes(), contentType: r.Header.Get("Content-Type")}
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		obj, ok := s.objects[key]
+		s.mu.RUnlock()
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		http.ServeContent(w, r, key, stubModTime, bytes.NewReader(obj.data))
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, key)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// keys returns the object keys currently stored, including the bucket
+// prefix, in sorted order.
+func (s *s3StubServer) keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var stubModTime = time.Unix(0, 0)
+
+func newTestS3BlobStore(t *testing.T) (*S3BlobStore, *s3StubServer) {
+	t.Helper()
+
+	stub := newS3StubServer()
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3BlobStore(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "attachments",
+		AccessKey: "test-access-key",
+		SecretKey: "test-secret-key",
+	}, server.Client())
+	if err != nil {
+		t.Fatalf("NewS3BlobStore() error = %v", err)
+	}
+	return store, stub
+}
+
+func readBlob(t *testing.T, body io.ReadCloser) string {
+	t.Helper()
+
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("reading blob: %v", err)
+	}
+	return string(data)
+}
+
+func TestS3BlobStorePutAndGet(t *testing.T) {
+	ctx := context.Background()
+	store, stub := newTestS3BlobStore(t)
+
+	const content = "hello, attachment"
+	if err := store.Put(ctx, "users/1/notes.txt", strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	if got, want := stub.keys(), []string{"attachments/users/1/notes.txt"}; len(got) != 1 || got[0] != want[0] {
+		t.Errorf("stored keys = %v, want %v", got, want)
+	}
+
+	body, err := store.Get(ctx, "users/1/notes.txt")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := readBlob(t, body); got != content {
+		t.Errorf("Get() = %q, want %q", got, content)
+	}
+}
+
+func TestS3BlobStoreGetRange(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestS3BlobStore(t)
+
+	const content = "0123456789"
+	if err := store.Put(ctx, "audio.webm", strings.NewReader(content), int64(len(content)), "audio/webm"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		offset int64
+		length int64
+		want   string
+	}{
+		{name: "whole blob", offset: 0, length: -1, want: content},
+		{name: "prefix", offset: 0, length: 4, want: "0123"},
+		{name: "middle", offset: 3, length: 4, want: "3456"},
+		{name: "suffix", offset: 7, length: -1, want: "789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := store.GetRange(ctx, "audio.webm", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("GetRange() error = %v", err)
+			}
+			if got := readBlob(t, body); got != tt.want {
+				t.Errorf("GetRange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestS3BlobStoreEncodesKeys(t *testing.T) {
+	ctx := context.Background()
+	store, stub := newTestS3BlobStore(t)
+
+	key := "users/1/voice memo (1).m4a"
+	if err := store.Put(ctx, key, strings.NewReader("memo"), 4, "audio/mp4"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := stub.keys(); len(got) != 1 || got[0] != "attachments/"+key {
+		t.Errorf("stored keys = %v, want [attachments/%s]", got, key)
+	}
+
+	body, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := readBlob(t, body); got != "memo" {
+		t.Errorf("Get() = %q, want %q", got, "memo")
+	}
+}
+
+func TestS3BlobStoreDelete(t *testing.T) {
+	ctx := context.Background()
+	store, stub := newTestS3BlobStore(t)
+
+	if err := store.Put(ctx, "photo.jpg", strings.NewReader("jpeg"), 4, "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := store.Delete(ctx, "photo.jpg"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if got := stub.keys(); len(got) != 0 {
+		t.Errorf("stored keys after Delete() = %v, want none", got)
+	}
+
+	if _, err := store.Get(ctx, "photo.jpg"); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, ErrBlobNotFound)
+	}
+	if err := store.Delete(ctx, "photo.jpg"); err != nil {
+		t.Errorf("Delete() of a missing blob error = %v, want nil", err)
+	}
+}
+
+func TestS3BlobStoreReportsServiceErrors(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	store, err := NewS3BlobStore(S3Config{Endpoint: server.URL, Bucket: "attachments"}, server.Client())
+	if err != nil {
+		t.Fatalf("NewS3BlobStore() error = %v", err)
+	}
+
+	err = store.Put(ctx, "photo.jpg", strings.NewReader("jpeg"), 4, "image/jpeg")
+	if err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("Put() error = %v, want a 403 failure", err)
+	}
+	if _, err := store.Get(ctx, "photo.jpg"); err == nil || errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() error = %v, want a 403 failure", err)
+	}
+}
+
+func TestNewS3BlobStoreValidatesConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  S3Config
+	}{
+		{name: "missing endpoint", cfg: S3Config{Bucket: "attachments"}},
+		{name: "endpoint without scheme", cfg: S3Config{Endpoint: "minio:9000", Bucket: "attachments"}},
+		{name: "missing bucket", cfg: S3Config{Endpoint: "http://minio:9000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewS3BlobStore(tt.cfg, nil); err == nil {
+				t.Error("NewS3BlobStore() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPProvider sends the raw audio to an external speech-to-text service
+// and expects a JSON body of the form {"text": "..."} in return.
+type HTTPProvider struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+func NewHTTPProvider(endpoint, apiKey string, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &HTTPProvider{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   client,
+	}
+}
+
+func (p *HTTPProvider) Transcribe(ctx context.Context, audio io.Reader, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, audio)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return "", fmt.Errorf("transcription service returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("invalid transcription response: %w", err)
+	}
+
+	return result.Text, nil
+}
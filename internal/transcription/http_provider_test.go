@@ -0,0 +1,77 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPProviderTranscribe(t *testing.T) {
+	tests := []struct {
+		name    string
+		apiKey  string
+		status  int
+		body    string
+		want    string
+		wantErr bool
+	}{
+		{name: "transcript", apiKey: "secret", status: http.StatusOK, body: `{"text":"dear diary"}`, want: "dear diary"},
+		{name: "without api key", status: http.StatusOK, body: `{"text":"dear diary"}`, want: "dear diary"},
+		{name: "empty transcript", status: http.StatusOK, body: `{"text":""}`, want: ""},
+		{name: "service error", status: http.StatusBadGateway, body: `{"error":"busy"}`, wantErr: true},
+		{name: "invalid response", status: http.StatusOK, body: `not json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAudio, gotContentType, gotAuthorization string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				audio, _ := io.ReadAll(r.Body)
+				gotAudio = string(audio)
+				gotContentType = r.Header.Get("Content-Type")
+				gotAuthorization = r.Header.Get("Authorization")
+
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			provider := NewHTTPProvider(server.URL, tt.apiKey, server.Client())
+			got, err := provider.Transcribe(context.Background(), strings.NewReader("audio bytes"), "audio/webm")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Transcribe() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Transcribe() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Transcribe() = %q, want %q", got, tt.want)
+			}
+
+			if gotAudio != "audio bytes" || gotContentType != "audio/webm" {
+				t.Errorf("service received %q as %q, want the audio as audio/webm", gotAudio, gotContentType)
+			}
+			wantAuthorization := ""
+			if tt.apiKey != "" {
+				wantAuthorization = "Bearer " + tt.apiKey
+			}
+			if gotAuthorization != wantAuthorization {
+				t.Errorf("Authorization = %q, want %q", gotAuthorization, wantAuthorization)
+			}
+		})
+	}
+}
+
+func TestNoopProviderIsUnavailable(t *testing.T) {
+	_, err := NoopProvider{}.Transcribe(context.Background(), strings.NewReader("audio"), "audio/webm")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Transcribe() error = %v, want %v", err, ErrUnavailable)
+	}
+}
@@ -0,0 +1,23 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrUnavailable is returned by providers that cannot transcribe audio, so
+// callers can tell "no transcription configured" apart from a failure.
+var ErrUnavailable = errors.New("transcription is not available")
+
+// Provider turns spoken audio into text.
+type Provider interface {
+	Transcribe(ctx context.Context, audio io.Reader, contentType string) (string, error)
+}
+
+// NoopProvider is used when no transcription backend is configured.
+type NoopProvider struct{}
+
+func (NoopProvider) Transcribe(ctx context.Context, audio io.Reader, contentType string) (string, error) {
+	return "", ErrUnavailable
+}
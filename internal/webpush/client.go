@@ -0,0 +1,241 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+)
+
+// ErrSubscriptionGone is returned when the push service reports that the
+// subscription expired or was removed (404 or 410); it should be deleted.
+var ErrSubscriptionGone = errors.New("push subscription is no longer valid")
+
+// maxRetryWait caps how long a Retry-After header can delay a retry.
+const maxRetryWait = time.Minute
+
+// StatusError is returned for other responses the push service rejected.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("push service returned status %d", e.StatusCode)
+}
+
+// Subscription is the PushSubscription a browser handed to the client,
+// with its keys base64url encoded.
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Validate checks that the endpoint is an https URL on a public host and
+// that the subscription's keys can be used to encrypt messages.
+func (s Subscription) Validate() error {
+	if err := safehttp.CheckURL(s.Endpoint); err != nil {
+		return err
+	}
+	uaPublic, err := decode(s.P256dh)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	if _, err := ecdh.P256().NewPublicKey(uaPublic); err != nil {
+		return ErrInvalidKey
+	}
+	authSecret, err := decode(s.Auth)
+	if err != nil || len(authSecret) != authSecretSize {
+		return ErrInvalidKey
+	}
+	return nil
+}
+
+type Config struct {
+	Keys    *VAPIDKeys
+	Subject string // mailto: or https: contact for the push service operator
+	// TTL is how long the push service keeps an undelivered message.
+	TTL time.Duration
+	// MaxAttempts bounds deliveries of one message, including the first.
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles each time.
+	Backoff time.Duration
+}
+
+// Client delivers messages to push services.
+type Client struct {
+	config Config
+	client *http.Client
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewClient returns a client that sends with client, or by default with
+// one that only connects to public addresses and does not follow
+// redirects, since endpoints are URLs users registered.
+func NewClient(config Config, client *http.Client) *Client {
+	if client == nil {
+		client = safehttp.NewClient(30 * time.Second)
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = time.Second
+	}
+	return &Client{config: config, client: client, sleep: sleep}
+}
+
+// PublicKey returns the VAPID public key clients subscribe with.
+func (c *Client) PublicKey() string {
+	return c.config.Keys.PublicKey()
+}
+
+// Send encrypts payload for the subscription and delivers it, retrying
+// on network errors, 429 and 5xx responses with exponential backoff.
+func (c *Client) Send(ctx context.Context, subscription Subscription, payload []byte) error {
+	uaPublic, err := decode(subscription.P256dh)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	authSecret, err := decode(subscription.Auth)
+	if err != nil {
+		return ErrInvalidKey
+	}
+	body, err := Encrypt(payload, uaPublic, authSecret)
+	if err != nil {
+		return err
+	}
+	authorization, err := c.authorization(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	delay := c.config.Backoff
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := c.deliver(ctx, subscription.Endpoint, authorization, body)
+		if err == nil || !retryable(err) || attempt >= c.config.MaxAttempts {
+			return err
+		}
+
+		wait := delay
+		if retryAfter > wait {
+			wait = min(retryAfter, maxRetryWait)
+		}
+		if err := c.sleep(ctx, wait); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) deliver(ctx context.Context, endpoint, authorization string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(c.config.TTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", authorization)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, &temporaryError{err}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode/100 == 2:
+		return 0, nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return 0, ErrSubscriptionGone
+	default:
+		return retryAfter(resp.Header.Get("Retry-After")), &StatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+// authorization builds the VAPID header for the endpoint's push service.
+func (c *Client) authorization(endpoint string) (string, error) {
+	target, err := url.Parse(endpoint)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": target.Scheme + "://" + target.Host,
+		"exp": time.Now().Add(12 * time.Hour).Unix(),
+		"sub": c.config.Subject,
+	})
+	signed, err := token.SignedString(c.config.Keys.private)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, c.config.Keys.PublicKey()), nil
+}
+
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string { return e.err.Error() }
+
+func (e *temporaryError) Unwrap() error { return e.err }
+
+func retryable(err error) bool {
+	var temporary *temporaryError
+	if errors.As(err, &temporary) {
+		return true
+	}
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusTooManyRequests || status.StatusCode >= 500
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date.
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,440 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sugiiianaa/remember-my-story/internal/safehttp"
+)
+
+var errInvalidMessage = errors.New("invalid web push message")
+
+// decrypt reverses Encrypt for the holder of the subscription's private
+// key, so tests can check what a browser would receive.
+func decrypt(message []byte, uaPrivate *ecdh.PrivateKey, authSecret []byte) ([]byte, error) {
+	if len(message) < headerSize+tagSize {
+		return nil, errInvalidMessage
+	}
+	salt := message[:saltSize]
+	idLength := int(message[saltSize+4])
+	if idLength != publicKeySize {
+		return nil, errInvalidMessage
+	}
+	asPublic := message[saltSize+5 : headerSize]
+
+	senderKey, err := ecdh.P256().NewPublicKey(asPublic)
+	if err != nil {
+		return nil, errInvalidMessage
+	}
+	sharedSecret, err := uaPrivate.ECDH(senderKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, nonce, err := contentKey(sharedSecret, authSecret, uaPrivate.PublicKey().Bytes(), asPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, nonce, message[headerSize:], nil)
+	if err != nil {
+		return nil, errInvalidMessage
+	}
+
+	// Strip the padding back to the record delimiter
+	for i := len(plaintext) - 1; i >= 0; i-- {
+		switch plaintext[i] {
+		case 0x00:
+			continue
+		case 0x02:
+			return plaintext[:i], nil
+		}
+		break
+	}
+	return nil, errInvalidMessage
+}
+
+// stubPushService is an in-memory stand-in for a browser push service.
+// It hands out subscriptions whose endpoints point at itself, decrypts
+// what it receives with the subscriber keys and can be told which status
+// codes to answer with.
+type stubPushService struct {
+	mu            sync.Mutex
+	subscriptions map[string]*stubSubscription
+	nextID        int
+}
+
+type stubSubscription struct {
+	key        *ecdh.PrivateKey
+	authSecret []byte
+	responses  []int
+	messages   [][]byte
+	attempts   int
+}
+
+// newStubPushService mounts a stub push service on a test server.
+func newStubPushService(t *testing.T) (*stubPushService, *httptest.Server) {
+	t.Helper()
+
+	stub := &stubPushService{subscriptions: make(map[string]*stubSubscription)}
+	server := httptest.NewServer(stub)
+	t.Cleanup(server.Close)
+	return stub, server
+}
+
+// newSubscription creates a subscription served by the stub at baseURL.
+func (s *stubPushService) newSubscription(t *testing.T, baseURL string) Subscription {
+	t.Helper()
+
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating subscription key: %v", err)
+	}
+	authSecret := make([]byte, authSecretSize)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatalf("generating auth secret: %v", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	id := fmt.Sprintf("sub-%d", s.nextID)
+	s.subscriptions[id] = &stubSubscription{key: key, authSecret: authSecret}
+
+	return Subscription{
+		Endpoint: strings.TrimRight(baseURL, "/") + "/push/" + id,
+		P256dh:   encode(key.PublicKey().Bytes()),
+		Auth:     encode(authSecret),
+	}
+}
+
+// respondWith queues status codes returned for the next deliveries to
+// the subscription; afterwards deliveries succeed with 201.
+func (s *stubPushService) respondWith(endpoint string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subscription, ok := s.subscriptions[stubID(endpoint)]; ok {
+		subscription.responses = append(subscription.responses, statuses...)
+	}
+}
+
+// expire makes the push service answer 410 Gone for the subscription.
+func (s *stubPushService) expire(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscriptions, stubID(endpoint))
+}
+
+// messages returns the decrypted payloads delivered to the subscription.
+func (s *stubPushService) messages(endpoint string) [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.subscriptions[stubID(endpoint)]
+	if !ok {
+		return nil
+	}
+	return append([][]byte(nil), subscription.messages...)
+}
+
+// attempts returns how many deliveries reached the subscription.
+func (s *stubPushService) attempts(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if subscription, ok := s.subscriptions[stubID(endpoint)]; ok {
+		return subscription.attempts
+	}
+	return 0
+}
+
+func (s *stubPushService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "vapid t=") {
+		http.Error(w, "missing VAPID authorization", http.StatusUnauthorized)
+		return
+	}
+	if r.Header.Get("Content-Encoding") != "aes128gcm" {
+		http.Error(w, "unsupported content encoding", http.StatusBadRequest)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscription, ok := s.subscriptions[stubID(r.URL.Path)]
+	if !ok {
+		http.Error(w, "subscription expired", http.StatusGone)
+		return
+	}
+	subscription.attempts++
+	if len(subscription.responses) > 0 {
+		status := subscription.responses[0]
+		subscription.responses = subscription.responses[1:]
+		if status/100 != 2 {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+	}
+
+	payload, err := decrypt(body, subscription.key, subscription.authSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	subscription.messages = append(subscription.messages, payload)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func stubID(endpointOrPath string) string {
+	return endpointOrPath[strings.LastIndex(endpointOrPath, "/")+1:]
+}
+
+func newTestVAPIDKeys(t *testing.T) *VAPIDKeys {
+	t.Helper()
+
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys() error = %v", err)
+	}
+	keys, err := ParseVAPIDKeys(publicKey, privateKey)
+	if err != nil {
+		t.Fatalf("ParseVAPIDKeys() error = %v", err)
+	}
+	return keys
+}
+
+// newTestClient returns a client that can reach the loopback test server
+// and records the retry delays instead of sleeping.
+func newTestClient(t *testing.T, server *httptest.Server, maxAttempts int) (*Client, *[]time.Duration) {
+	t.Helper()
+
+	client := NewClient(Config{
+		Keys:        newTestVAPIDKeys(t),
+		Subject:     "mailto:push@example.com",
+		MaxAttempts: maxAttempts,
+		Backoff:     time.Second,
+	}, server.Client())
+
+	var waits []time.Duration
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return client, &waits
+}
+
+func TestClientSend(t *testing.T) {
+	var statusErr *StatusError
+
+	tests := []struct {
+		name         string
+		responses    []int
+		expire       bool
+		wantErr      func(error) bool
+		wantAttempts int
+		wantWaits    []time.Duration
+		wantMessages int
+	}{
+		{
+			name:         "delivered",
+			wantAttempts: 1,
+			wantMessages: 1,
+		},
+		{
+			name:         "retries server errors with backoff",
+			responses:    []int{http.StatusServiceUnavailable, http.StatusInternalServerError},
+			wantAttempts: 3,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+			wantMessages: 1,
+		},
+		{
+			name:         "retries rate limiting",
+			responses:    []int{http.StatusTooManyRequests},
+			wantAttempts: 2,
+			wantWaits:    []time.Duration{time.Second},
+			wantMessages: 1,
+		},
+		{
+			name:      "gives up after max attempts",
+			responses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			wantErr: func(err error) bool {
+				return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadGateway
+			},
+			wantAttempts: 3,
+			wantWaits:    []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:      "does not retry client errors",
+			responses: []int{http.StatusBadRequest},
+			wantErr: func(err error) bool {
+				return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusBadRequest
+			},
+			wantAttempts: 1,
+		},
+		{
+			name:   "expired subscription",
+			expire: true,
+			wantErr: func(err error) bool {
+				return errors.Is(err, ErrSubscriptionGone)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub, server := newStubPushService(t)
+			client, waits := newTestClient(t, server, 3)
+
+			subscription := stub.newSubscription(t, server.URL)
+			stub.respondWith(subscription.Endpoint, tt.responses...)
+			if tt.expire {
+				stub.expire(subscription.Endpoint)
+			}
+
+			err := client.Send(context.Background(), subscription, []byte(`{"title":"Time to write"}`))
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("Send() error = %v, not the expected error", err)
+			}
+
+			if got := stub.attempts(subscription.Endpoint); got != tt.wantAttempts {
+				t.Errorf("delivery attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if fmt.Sprint(*waits) != fmt.Sprint(tt.wantWaits) {
+				t.Errorf("retry waits = %v, want %v", *waits, tt.wantWaits)
+			}
+
+			messages := stub.messages(subscription.Endpoint)
+			if len(messages) != tt.wantMessages {
+				t.Fatalf("delivered messages = %d, want %d", len(messages), tt.wantMessages)
+			}
+			for _, message := range messages {
+				if string(message) != `{"title":"Time to write"}` {
+					t.Errorf("decrypted message = %q", message)
+				}
+			}
+		})
+	}
+}
+
+func TestClientSendHonorsRetryAfter(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	stub := &stubPushService{subscriptions: make(map[string]*stubSubscription)}
+	subscription := stub.newSubscription(t, server.URL)
+	client, waits := newTestClient(t, server, 3)
+
+	if err := client.Send(context.Background(), subscription, []byte("hello")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 5*time.Second {
+		t.Errorf("retry waits = %v, want [5s]", *waits)
+	}
+}
+
+func TestClientSendRejectsInvalidKeys(t *testing.T) {
+	stub, server := newStubPushService(t)
+	client, _ := newTestClient(t, server, 1)
+	subscription := stub.newSubscription(t, server.URL)
+
+	tests := []struct {
+		name         string
+		subscription Subscription
+	}{
+		{name: "malformed p256dh", subscription: Subscription{Endpoint: subscription.Endpoint, P256dh: "!!", Auth: subscription.Auth}},
+		{name: "p256dh not on the curve", subscription: Subscription{Endpoint: subscription.Endpoint, P256dh: encode(make([]byte, 65)), Auth: subscription.Auth}},
+		{name: "short auth secret", subscription: Subscription{Endpoint: subscription.Endpoint, P256dh: subscription.P256dh, Auth: encode([]byte("short"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Send(context.Background(), tt.subscription, []byte("hello"))
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Send() error = %v, want %v", err, ErrInvalidKey)
+			}
+		})
+	}
+	if got := stub.attempts(subscription.Endpoint); got != 0 {
+		t.Errorf("delivery attempts = %d, want none", got)
+	}
+}
+
+func TestDefaultClientRefusesPrivateEndpoints(t *testing.T) {
+	stub, server := newStubPushService(t)
+	subscription := stub.newSubscription(t, server.URL)
+
+	client := NewClient(Config{Keys: newTestVAPIDKeys(t), Subject: "mailto:push@example.com", MaxAttempts: 1}, nil)
+	err := client.Send(context.Background(), subscription, []byte("hello"))
+	if !errors.Is(err, safehttp.ErrBlockedAddress) {
+		t.Errorf("Send() error = %v, want %v", err, safehttp.ErrBlockedAddress)
+	}
+	if got := stub.attempts(subscription.Endpoint); got != 0 {
+		t.Errorf("delivery attempts = %d, want none", got)
+	}
+}
+
+func TestSubscriptionValidate(t *testing.T) {
+	stub := &stubPushService{subscriptions: make(map[string]*stubSubscription)}
+	valid := stub.newSubscription(t, "https://fcm.googleapis.com/fcm/send")
+
+	withEndpoint := func(endpoint string) Subscription {
+		subscription := valid
+		subscription.Endpoint = endpoint
+		return subscription
+	}
+
+	tests := []struct {
+		name         string
+		subscription Subscription
+		want         error
+	}{
+		{name: "valid", subscription: valid},
+		{name: "http endpoint", subscription: withEndpoint("http://fcm.googleapis.com/fcm/send/sub-1"), want: safehttp.ErrUnsafeURL},
+		{name: "loopback endpoint", subscription: withEndpoint("https://127.0.0.1/push/sub-1"), want: safehttp.ErrUnsafeURL},
+		{name: "private endpoint", subscription: withEndpoint("https://10.0.0.8/push/sub-1"), want: safehttp.ErrUnsafeURL},
+		{name: "localhost endpoint", subscription: withEndpoint("https://localhost/push/sub-1"), want: safehttp.ErrUnsafeURL},
+		{name: "invalid p256dh", subscription: Subscription{Endpoint: valid.Endpoint, P256dh: "abc", Auth: valid.Auth}, want: ErrInvalidKey},
+		{name: "invalid auth", subscription: Subscription{Endpoint: valid.Endpoint, P256dh: valid.P256dh, Auth: "abc"}, want: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.subscription.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
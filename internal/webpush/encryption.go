@@ -0,0 +1,107 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	saltSize       = 16
+	authSecretSize = 16
+	publicKeySize  = 65
+	// recordSize is the aes128gcm record size; messages fit one record.
+	recordSize = 4096
+	headerSize = saltSize + 4 + 1 + publicKeySize
+	tagSize    = 16
+
+	// MaxPayloadSize is the largest plaintext that fits a single record.
+	MaxPayloadSize = recordSize - headerSize - tagSize - 1
+)
+
+var ErrPayloadTooLarge = errors.New("web push payload is too large")
+
+// Encrypt encrypts payload for a subscription with the aes128gcm content
+// coding (RFC 8188) using the key derivation of RFC 8291. uaPublic and
+// authSecret are the subscription's decoded p256dh and auth keys.
+func Encrypt(payload, uaPublic, authSecret []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	senderKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	return encrypt(payload, uaPublic, authSecret, senderKey, salt)
+}
+
+func encrypt(payload, uaPublic, authSecret []byte, senderKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	receiverKey, err := ecdh.P256().NewPublicKey(uaPublic)
+	if err != nil || len(authSecret) != authSecretSize {
+		return nil, ErrInvalidKey
+	}
+	sharedSecret, err := senderKey.ECDH(receiverKey)
+	if err != nil {
+		return nil, err
+	}
+
+	asPublic := senderKey.PublicKey().Bytes()
+	gcm, nonce, err := contentKey(sharedSecret, authSecret, uaPublic, asPublic, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// A single record ends with the 0x02 delimiter; no further padding
+	plaintext := append(append(make([]byte, 0, len(payload)+1), payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// contentKey derives the AES-GCM key and nonce of a message (RFC 8291,
+// section 3.4).
+func contentKey(sharedSecret, authSecret, uaPublic, asPublic, salt []byte) (cipher.AEAD, []byte, error) {
+	keyInfo := append([]byte("WebPush: info\x00"), uaPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, authSecret, keyInfo), ikm); err != nil {
+		return nil, nil, err
+	}
+
+	prk := hkdf.Extract(sha256.New, ikm, salt)
+	cek := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: aes128gcm\x00")), cek); err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, 12)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, prk, []byte("Content-Encoding: nonce\x00")), nonce); err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gcm, nonce, nil
+}
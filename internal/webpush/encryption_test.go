@@ -0,0 +1,153 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+// Test vector from RFC 8291, section 5.
+var rfc8291 = struct {
+	plaintext  string
+	asPrivate  string
+	asPublic   string
+	uaPrivate  string
+	uaPublic   string
+	salt       string
+	authSecret string
+	message    string
+}{
+	plaintext:  "When I grow up, I want to be a watermelon",
+	asPrivate:  "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw",
+	asPublic:   "BP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A8",
+	uaPrivate:  "q1dXpw3UpT5VOmu_cf_v6ih07Aems3njxI-JWgLcM94",
+	uaPublic:   "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+	salt:       "DGv6ra1nlYgDCS1FRnbzlw",
+	authSecret: "BTBZMqHH6r4Tts7J_aSIgg",
+	message: "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_" +
+		"yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+}
+
+func mustDecode(t *testing.T, value string) []byte {
+	t.Helper()
+
+	data, err := decode(value)
+	if err != nil {
+		t.Fatalf("decode(%q) error = %v", value, err)
+	}
+	return data
+}
+
+func TestEncryptRFC8291Vector(t *testing.T) {
+	senderKey, err := ecdh.P256().NewPrivateKey(mustDecode(t, rfc8291.asPrivate))
+	if err != nil {
+		t.Fatalf("sender key: %v", err)
+	}
+	if got := encode(senderKey.PublicKey().Bytes()); got != rfc8291.asPublic {
+		t.Fatalf("sender public key = %s, want %s", got, rfc8291.asPublic)
+	}
+
+	message, err := encrypt(
+		[]byte(rfc8291.plaintext),
+		mustDecode(t, rfc8291.uaPublic),
+		mustDecode(t, rfc8291.authSecret),
+		senderKey,
+		mustDecode(t, rfc8291.salt),
+	)
+	if err != nil {
+		t.Fatalf("encrypt() error = %v", err)
+	}
+	if got := encode(message); got != rfc8291.message {
+		t.Errorf("encrypt() = %s, want %s", got, rfc8291.message)
+	}
+}
+
+func TestDecryptRFC8291Vector(t *testing.T) {
+	uaPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, rfc8291.uaPrivate))
+	if err != nil {
+		t.Fatalf("receiver key: %v", err)
+	}
+	if got := encode(uaPrivate.PublicKey().Bytes()); got != rfc8291.uaPublic {
+		t.Fatalf("receiver public key = %s, want %s", got, rfc8291.uaPublic)
+	}
+
+	plaintext, err := decrypt(mustDecode(t, rfc8291.message), uaPrivate, mustDecode(t, rfc8291.authSecret))
+	if err != nil {
+		t.Fatalf("decrypt() error = %v", err)
+	}
+	if string(plaintext) != rfc8291.plaintext {
+		t.Errorf("decrypt() = %q, want %q", plaintext, rfc8291.plaintext)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("receiver key: %v", err)
+	}
+	uaPublic := uaPrivate.PublicKey().Bytes()
+	authSecret := make([]byte, authSecretSize)
+	if _, err := rand.Read(authSecret); err != nil {
+		t.Fatalf("auth secret: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		payload    []byte
+		uaPublic   []byte
+		authSecret []byte
+		wantErr    error
+	}{
+		{name: "short payload", payload: []byte(`{"title":"Time to write"}`), uaPublic: uaPublic, authSecret: authSecret},
+		{name: "empty payload", payload: []byte{}, uaPublic: uaPublic, authSecret: authSecret},
+		{name: "largest payload", payload: bytes.Repeat([]byte("a"), MaxPayloadSize), uaPublic: uaPublic, authSecret: authSecret},
+		{name: "payload too large", payload: bytes.Repeat([]byte("a"), MaxPayloadSize+1), uaPublic: uaPublic, authSecret: authSecret, wantErr: ErrPayloadTooLarge},
+		{name: "public key not on the curve", payload: []byte("hi"), uaPublic: make([]byte, publicKeySize), authSecret: authSecret, wantErr: ErrInvalidKey},
+		{name: "short auth secret", payload: []byte("hi"), uaPublic: uaPublic, authSecret: authSecret[:8], wantErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := Encrypt(tt.payload, tt.uaPublic, tt.authSecret)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Encrypt() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(message) > recordSize {
+				t.Errorf("message is %d bytes, larger than one %d byte record", len(message), recordSize)
+			}
+			plaintext, err := decrypt(message, uaPrivate, tt.authSecret)
+			if err != nil {
+				t.Fatalf("decrypt() error = %v", err)
+			}
+			if !bytes.Equal(plaintext, tt.payload) {
+				t.Errorf("decrypt() = %q, want %q", plaintext, tt.payload)
+			}
+		})
+	}
+}
+
+func TestEncryptUsesFreshKeys(t *testing.T) {
+	uaPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("receiver key: %v", err)
+	}
+	authSecret := make([]byte, authSecretSize)
+
+	first, err := Encrypt([]byte("hello"), uaPrivate.PublicKey().Bytes(), authSecret)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	second, err := Encrypt([]byte("hello"), uaPrivate.PublicKey().Bytes(), authSecret)
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if bytes.Equal(first[:headerSize], second[:headerSize]) {
+		t.Error("two messages share a salt and sender key")
+	}
+}
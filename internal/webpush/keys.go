@@ -0,0 +1,79 @@
+// Package webpush sends Web Push messages: payloads are encrypted per
+// RFC 8291 and requests are authorized with VAPID (RFC 8292).
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid web push key")
+
+// VAPIDKeys identify this application server to push services. Browsers
+// only accept messages signed with the key the subscription was made with.
+type VAPIDKeys struct {
+	public  []byte // Uncompressed P-256 point
+	private *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys creates a new key pair, returned base64url encoded the
+// way ParseVAPIDKeys expects them.
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return encode(key.PublicKey().Bytes()), encode(key.Bytes()), nil
+}
+
+// ParseVAPIDKeys reads a base64url encoded key pair: the uncompressed
+// public point and the raw 32-byte private scalar.
+func ParseVAPIDKeys(publicKey, privateKey string) (*VAPIDKeys, error) {
+	privateBytes, err := decode(privateKey)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	key, err := ecdh.P256().NewPrivateKey(privateBytes)
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	public := key.PublicKey().Bytes()
+	if publicKey != "" {
+		given, err := decode(publicKey)
+		if err != nil || string(given) != string(public) {
+			return nil, ErrInvalidKey
+		}
+	}
+
+	// ecdh validated the scalar; build the equivalent ECDSA key for signing
+	curve := elliptic.P256()
+	signer := &ecdsa.PrivateKey{D: new(big.Int).SetBytes(privateBytes)}
+	signer.PublicKey.Curve = curve
+	signer.PublicKey.X = new(big.Int).SetBytes(public[1:33])
+	signer.PublicKey.Y = new(big.Int).SetBytes(public[33:])
+
+	return &VAPIDKeys{public: public, private: signer}, nil
+}
+
+// PublicKey returns the base64url encoded public key clients pass as
+// applicationServerKey when subscribing.
+func (k *VAPIDKeys) PublicKey() string {
+	return encode(k.public)
+}
+
+func encode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decode accepts base64url with or without padding, as browsers and
+// libraries disagree on it.
+func decode(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
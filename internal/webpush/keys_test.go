@@ -0,0 +1,133 @@
+package webpush
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestParseVAPIDKeys(t *testing.T) {
+	// The application server key pair of RFC 8291, section 5
+	publicKey, privateKey := rfc8291.asPublic, rfc8291.asPrivate
+
+	tests := []struct {
+		name       string
+		publicKey  string
+		privateKey string
+		wantErr    error
+	}{
+		{name: "key pair", publicKey: publicKey, privateKey: privateKey},
+		{name: "padded base64", publicKey: publicKey + "=", privateKey: privateKey + "="},
+		{name: "private key only", privateKey: privateKey},
+		{name: "mismatched public key", publicKey: rfc8291.uaPublic, privateKey: privateKey, wantErr: ErrInvalidKey},
+		{name: "malformed private key", privateKey: "not base64!", wantErr: ErrInvalidKey},
+		{name: "short private key", privateKey: encode([]byte("short")), wantErr: ErrInvalidKey},
+		{name: "zero private key", privateKey: encode(make([]byte, 32)), wantErr: ErrInvalidKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseVAPIDKeys(tt.publicKey, tt.privateKey)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ParseVAPIDKeys() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && keys.PublicKey() != publicKey {
+				t.Errorf("PublicKey() = %s, want %s", keys.PublicKey(), publicKey)
+			}
+		})
+	}
+}
+
+func TestGenerateVAPIDKeysRoundTrip(t *testing.T) {
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("GenerateVAPIDKeys() error = %v", err)
+	}
+	keys, err := ParseVAPIDKeys(publicKey, privateKey)
+	if err != nil {
+		t.Fatalf("ParseVAPIDKeys() error = %v", err)
+	}
+	if keys.PublicKey() != publicKey {
+		t.Errorf("PublicKey() = %s, want %s", keys.PublicKey(), publicKey)
+	}
+}
+
+func TestClientAuthorization(t *testing.T) {
+	keys, err := ParseVAPIDKeys(rfc8291.asPublic, rfc8291.asPrivate)
+	if err != nil {
+		t.Fatalf("ParseVAPIDKeys() error = %v", err)
+	}
+	client := NewClient(Config{Keys: keys, Subject: "mailto:push@example.com"}, nil)
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		wantAudience string
+		wantErr      bool
+	}{
+		{name: "fcm", endpoint: "https://fcm.googleapis.com/fcm/send/abc", wantAudience: "https://fcm.googleapis.com"},
+		{name: "with port", endpoint: "https://push.example.com:8443/v1/abc", wantAudience: "https://push.example.com:8443"},
+		{name: "relative endpoint", endpoint: "/push/abc", wantErr: true},
+		{name: "malformed endpoint", endpoint: "https://%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorization, err := client.authorization(tt.endpoint)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("authorization() = %q, want an error", authorization)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("authorization() error = %v", err)
+			}
+
+			token, publicKey, ok := strings.Cut(strings.TrimPrefix(authorization, "vapid t="), ", k=")
+			if !ok || !strings.HasPrefix(authorization, "vapid t=") {
+				t.Fatalf("authorization = %q, want vapid t=..., k=...", authorization)
+			}
+			if publicKey != rfc8291.asPublic {
+				t.Errorf("k = %s, want %s", publicKey, rfc8291.asPublic)
+			}
+
+			claims := jwt.MapClaims{}
+			parsed, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
+				return &keys.private.PublicKey, nil
+			}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(tt.wantAudience))
+			if err != nil || !parsed.Valid {
+				t.Fatalf("token does not verify: %v", err)
+			}
+			if sub, _ := claims.GetSubject(); sub != "mailto:push@example.com" {
+				t.Errorf("sub = %q, want %q", sub, "mailto:push@example.com")
+			}
+
+			// RFC 8292 caps exp at 24 hours from now
+			exp, err := claims.GetExpirationTime()
+			if err != nil || exp == nil {
+				t.Fatalf("exp missing: %v", err)
+			}
+			if until := time.Until(exp.Time); until <= 0 || until > 24*time.Hour {
+				t.Errorf("exp is %v from now, want within 24h", until)
+			}
+		})
+	}
+}
+
+func TestVAPIDKeysSignWithPublicKey(t *testing.T) {
+	keys, err := ParseVAPIDKeys(rfc8291.asPublic, rfc8291.asPrivate)
+	if err != nil {
+		t.Fatalf("ParseVAPIDKeys() error = %v", err)
+	}
+	public, err := keys.private.PublicKey.ECDH()
+	if err != nil {
+		t.Fatalf("signing key has no valid public point: %v", err)
+	}
+	if encode(public.Bytes()) != rfc8291.asPublic {
+		t.Errorf("signing key public point = %s, want %s", encode(public.Bytes()), rfc8291.asPublic)
+	}
+}
@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseSignedURL splits a URL made by SignURL into its parts.
+func parseSignedURL(t *testing.T, signed string) (path, expires, signature string) {
+	t.Helper()
+
+	u, err := url.Parse(signed)
+	if err != nil {
+		t.Fatalf("url.Parse(%q) error = %v", signed, err)
+	}
+	query := u.Query()
+	return u.Path, query.Get("expires"), query.Get("signature")
+}
+
+func TestVerifySignedURL(t *testing.T) {
+	const secret = "url-signing-secret"
+	const path = "/api/v1/attachments/42/content"
+
+	valid := SignURL(secret, path, time.Now().Add(time.Hour))
+	_, expires, signature := parseSignedURL(t, valid)
+	expired := SignURL(secret, path, time.Now().Add(-time.Minute))
+	_, expiredExpires, expiredSignature := parseSignedURL(t, expired)
+
+	later := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10)
+	flipped := []byte(signature)
+	if flipped[0] == 'a' {
+		flipped[0] = 'b'
+	} else {
+		flipped[0] = 'a'
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		path      string
+		expires   string
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", secret: secret, path: path, expires: expires, signature: signature},
+		{name: "expired", secret: secret, path: path, expires: expiredExpires, signature: expiredSignature, wantErr: true},
+		{name: "extended expiry", secret: secret, path: path, expires: later, signature: signature, wantErr: true},
+		{name: "other path", secret: secret, path: "/api/v1/attachments/43/content", expires: expires, signature: signature, wantErr: true},
+		{name: "thumbnail of the same attachment", secret: secret, path: "/api/v1/attachments/42/thumbnail", expires: expires, signature: signature, wantErr: true},
+		{name: "other secret", secret: "another-secret", path: path, expires: expires, signature: signature, wantErr: true},
+		{name: "tampered signature", secret: secret, path: path, expires: expires, signature: string(flipped), wantErr: true},
+		{name: "truncated signature", secret: secret, path: path, expires: expires, signature: signature[:len(signature)-2], wantErr: true},
+		{name: "uppercase signature", secret: secret, path: path, expires: expires, signature: strings.ToUpper(signature), wantErr: true},
+		{name: "missing signature", secret: secret, path: path, expires: expires, wantErr: true},
+		{name: "missing expiry", secret: secret, path: path, signature: signature, wantErr: true},
+		{name: "malformed expiry", secret: secret, path: path, expires: "tomorrow", signature: signature, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySignedURL(tt.secret, tt.path, tt.expires, tt.signature)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignedURL) {
+				t.Errorf("VerifySignedURL() error = %v, want %v", err, ErrInvalidSignedURL)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("VerifySignedURL() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestSignURL(t *testing.T) {
+	expiresAt := time.Unix(1_700_000_000, 0)
+	signed := SignURL("secret", "/api/v1/attachments/1/content", expiresAt)
+
+	path, expires, signature := parseSignedURL(t, signed)
+	if path != "/api/v1/attachments/1/content" {
+		t.Errorf("path = %q, want the signed path", path)
+	}
+	if expires != "1700000000" {
+		t.Errorf("expires = %q, want %q", expires, "1700000000")
+	}
+	if len(signature) != 64 {
+		t.Errorf("signature = %q, want 64 hex characters", signature)
+	}
+	if again := SignURL("secret", "/api/v1/attachments/1/content", expiresAt); again != signed {
+		t.Errorf("SignURL() is not deterministic: %q and %q", signed, again)
+	}
+}
+
+func TestDeriveKey(t *testing.T) {
+	first := DeriveKey("jwt-secret", "remember-my-story/attachment-url-signing")
+
+	tests := []struct {
+		name   string
+		secret string
+		label  string
+		same   bool
+	}{
+		{name: "same inputs", secret: "jwt-secret", label: "remember-my-story/attachment-url-signing", same: true},
+		{name: "other label", secret: "jwt-secret", label: "remember-my-story/other-purpose"},
+		{name: "other secret", secret: "other-secret", label: "remember-my-story/attachment-url-signing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DeriveKey(tt.secret, tt.label); (got == first) != tt.same {
+				t.Errorf("DeriveKey() = %s, same as the first key = %v, want %v", got, got == first, tt.same)
+			}
+		})
+	}
+	if first == "jwt-secret" {
+		t.Error("DeriveKey() returned the secret itself")
+	}
+}
@@ -0,0 +1,129 @@
+package helpers
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+type DiffOp struct {
+	Op   string
+	Text string
+}
+
+// maxDiffEdits bounds the work done by diffTokens. Texts that differ by
+// more edits are reported as a full replacement.
+const maxDiffEdits = 4000
+
+var diffTokenPattern = regexp.MustCompile(`\S+\s*|\s+`)
+
+// DiffWords compares two texts word by word (keeping whitespace attached
+// to the preceding word) and returns the operations that turn a into b.
+// Consecutive operations of the same kind are merged.
+func DiffWords(a, b string) []DiffOp {
+	return diffTokens(diffTokenPattern.FindAllString(a, -1), diffTokenPattern.FindAllString(b, -1))
+}
+
+// diffTokens implements Myers' O(ND) difference algorithm.
+func diffTokens(a, b []string) []DiffOp {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace[d] keeps the window of v (diagonals -d..d) seen before step d
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace, d)
+			}
+		}
+	}
+
+	return replaceAll(a, b)
+}
+
+func backtrackDiff(a, b []string, trace [][]int, d int) []DiffOp {
+	var reversed []DiffOp
+	x, y := len(a), len(b)
+
+	for ; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		// v[d+k] holds diagonal k of the window stored for step d
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+
+		prevX := 0
+		if d > 0 {
+			prevX = v[d+prevK]
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, DiffOp{Op: DiffEqual, Text: a[x]})
+		}
+
+		if d > 0 {
+			if x == prevX {
+				y--
+				reversed = append(reversed, DiffOp{Op: DiffInsert, Text: b[y]})
+			} else {
+				x--
+				reversed = append(reversed, DiffOp{Op: DiffDelete, Text: a[x]})
+			}
+		}
+	}
+
+	var ops []DiffOp
+	for i := len(reversed) - 1; i >= 0; i-- {
+		op := reversed[i]
+		if len(ops) > 0 && ops[len(ops)-1].Op == op.Op {
+			ops[len(ops)-1].Text += op.Text
+			continue
+		}
+		ops = append(ops, op)
+	}
+
+	return ops
+}
+
+func replaceAll(a, b []string) []DiffOp {
+	var ops []DiffOp
+	if len(a) > 0 {
+		ops = append(ops, DiffOp{Op: DiffDelete, Text: strings.Join(a, "")})
+	}
+	if len(b) > 0 {
+		ops = append(ops, DiffOp{Op: DiffInsert, Text: strings.Join(b, "")})
+	}
+	return ops
+}
@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// applyDiff rebuilds both texts from the operations.
+func applyDiff(ops []DiffOp) (a, b string) {
+	var before, after strings.Builder
+	for _, op := range ops {
+		switch op.Op {
+		case DiffEqual:
+			before.WriteString(op.Text)
+			after.WriteString(op.Text)
+		case DiffDelete:
+			before.WriteString(op.Text)
+		case DiffInsert:
+			after.WriteString(op.Text)
+		}
+	}
+	return before.String(), after.String()
+}
+
+func TestDiffWords(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []DiffOp
+	}{
+		{name: "both empty", a: "", b: "", want: nil},
+		{
+			name: "identical",
+			a:    "Today was a good day.",
+			b:    "Today was a good day.",
+			want: []DiffOp{{Op: DiffEqual, Text: "Today was a good day."}},
+		},
+		{
+			name: "from empty",
+			a:    "",
+			b:    "Dear diary",
+			want: []DiffOp{{Op: DiffInsert, Text: "Dear diary"}},
+		},
+		{
+			name: "to empty",
+			a:    "Dear diary",
+			b:    "",
+			want: []DiffOp{{Op: DiffDelete, Text: "Dear diary"}},
+		},
+		{
+			name: "replaced word",
+			a:    "the quick fox",
+			b:    "the slow fox",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "the "},
+				{Op: DiffDelete, Text: "quick "},
+				{Op: DiffInsert, Text: "slow "},
+				{Op: DiffEqual, Text: "fox"},
+			},
+		},
+		{
+			name: "inserted words",
+			a:    "I went home.",
+			b:    "I went straight back home.",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "I went "},
+				{Op: DiffInsert, Text: "straight back "},
+				{Op: DiffEqual, Text: "home."},
+			},
+		},
+		{
+			name: "deleted words",
+			a:    "It was a very very long day",
+			b:    "It was a long day",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "It was a "},
+				{Op: DiffDelete, Text: "very very "},
+				{Op: DiffEqual, Text: "long day"},
+			},
+		},
+		{
+			// Whitespace belongs to the word before it
+			name: "appended word",
+			a:    "dear diary",
+			b:    "dear diary today",
+			want: []DiffOp{
+				{Op: DiffEqual, Text: "dear "},
+				{Op: DiffDelete, Text: "diary"},
+				{Op: DiffInsert, Text: "diary today"},
+			},
+		},
+		{
+			name: "changed whitespace",
+			a:    "one two",
+			b:    "one\n\ntwo",
+			want: []DiffOp{
+				{Op: DiffDelete, Text: "one "},
+				{Op: DiffInsert, Text: "one\n\n"},
+				{Op: DiffEqual, Text: "two"},
+			},
+		},
+		{
+			name: "leading whitespace",
+			a:    "  indented",
+			b:    "indented",
+			want: []DiffOp{
+				{Op: DiffDelete, Text: "  "},
+				{Op: DiffEqual, Text: "indented"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DiffWords(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffWords() = %q, want %q", got, tt.want)
+			}
+			if a, b := applyDiff(got); a != tt.a || b != tt.b {
+				t.Errorf("DiffWords() rebuilds %q -> %q, want %q -> %q", a, b, tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestDiffWordsMergesAdjacentOperations(t *testing.T) {
+	ops := DiffWords("a b c d e f", "a x c y e z")
+	for i := 1; i < len(ops); i++ {
+		if ops[i].Op == ops[i-1].Op {
+			t.Fatalf("operations %d and %d are both %s: %q", i-1, i, ops[i].Op, ops)
+		}
+	}
+	if a, b := applyDiff(ops); a != "a b c d e f" || b != "a x c y e z" {
+		t.Errorf("DiffWords() rebuilds %q -> %q", a, b)
+	}
+}
+
+func TestDiffWordsFallsBackToReplacement(t *testing.T) {
+	var before, after []string
+	for i := 0; i < maxDiffEdits/2+100; i++ {
+		before = append(before, fmt.Sprintf("old%d", i))
+		after = append(after, fmt.Sprintf("new%d", i))
+	}
+	a, b := strings.Join(before, " "), strings.Join(after, " ")
+
+	want := []DiffOp{{Op: DiffDelete, Text: a}, {Op: DiffInsert, Text: b}}
+	if got := DiffWords(a, b); !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffWords() returned %d operations, want a full replacement", len(got))
+	}
+}
@@ -0,0 +1,31 @@
+package helpers
+
+import "testing"
+
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want bool
+	}{
+		{name: "lowercase", s: "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6b", want: true},
+		{name: "uppercase", s: "3F2B8C1E-9D4A-4E7B-8A6F-1C2D3E4F5A6B", want: true},
+		{name: "nil UUID", s: "00000000-0000-0000-0000-000000000000", want: true},
+		{name: "empty", s: ""},
+		{name: "without hyphens", s: "3f2b8c1e9d4a4e7b8a6f1c2d3e4f5a6b"},
+		{name: "braces", s: "{3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6b}"},
+		{name: "hyphen out of place", s: "3f2b8c1e9-d4a-4e7b-8a6f-1c2d3e4f5a6b"},
+		{name: "not hexadecimal", s: "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6g"},
+		{name: "too short", s: "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6"},
+		{name: "too long", s: "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5a6b0"},
+		{name: "multi-byte characters", s: "3f2b8c1e-9d4a-4e7b-8a6f-1c2d3e4f5aé"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsUUID(tt.s); got != tt.want {
+				t.Errorf("IsUUID(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}